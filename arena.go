@@ -0,0 +1,120 @@
+package optional
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const defaultSlabChunk = 1024
+
+// Slab is a caller-managed arena of values of type T, such as structs of optional fields, used for
+// bulk decoding. The values are allocated from chunks of memory which are reused after [Slab.Reset]
+// instead of being collected one by one, reducing the allocations and GC pauses of the ingestion paths
+// creating millions of short-lived values.
+//
+// Only the values of T are allocated from the slab: the memory referenced by them, such as strings, slices,
+// maps and pointers decoded into their fields, is allocated on the heap as usual and stays there until collected.
+// The slab pays off for T made of fixed-size fields, such as numbers, booleans and Type of them.
+//
+// The values returned by [Slab.Alloc] must not be used after [Slab.Reset]. Slab is not safe for concurrent use.
+//
+// Slab is experimental and may change in the future.
+type Slab[T any] struct {
+	chunks [][]T
+	chunk  int // chunk is the index of the chunk the values are allocated from.
+	n      int // n is the number of the allocated values.
+	size   int
+}
+
+// NewSlab returns the slab allocating the values in chunks of the given size.
+// By default, chunks of 1024 values are used.
+func NewSlab[T any](chunkSize int) *Slab[T] {
+	if chunkSize <= 0 {
+		chunkSize = defaultSlabChunk
+	}
+
+	return &Slab[T]{size: chunkSize}
+}
+
+// Alloc returns the pointer to the next zero value of the slab.
+func (s *Slab[T]) Alloc() *T {
+	if s.size <= 0 {
+		s.size = defaultSlabChunk
+	}
+
+	for {
+		if s.chunk == len(s.chunks) {
+			s.chunks = append(s.chunks, make([]T, 0, s.size))
+		}
+
+		if c := s.chunks[s.chunk]; len(c) < cap(c) {
+			s.chunks[s.chunk] = c[:len(c)+1]
+			s.n++
+
+			return &s.chunks[s.chunk][len(c)]
+		}
+
+		s.chunk++
+	}
+}
+
+// Len returns the number of the values allocated since the last [Slab.Reset].
+func (s *Slab[T]) Len() int {
+	return s.n
+}
+
+// Reset frees all the values of the slab wholesale, keeping the memory for the following allocations.
+func (s *Slab[T]) Reset() {
+	var zero T
+
+	for i, c := range s.chunks {
+		for j := range c {
+			c[j] = zero
+		}
+
+		s.chunks[i] = c[:0]
+	}
+
+	s.chunk = 0
+	s.n = 0
+}
+
+// release frees the last allocated value.
+func (s *Slab[T]) release() {
+	var zero T
+
+	c := s.chunks[s.chunk]
+	c[len(c)-1] = zero
+
+	s.chunks[s.chunk] = c[:len(c)-1]
+	s.n--
+}
+
+// DecodeAll decodes the stream of JSON values, such as newline delimited JSON, with [Decoder] into the values
+// allocated from the slab, calling fn for each of them in order. Decoding stops at the end of the stream or at the
+// first error, including the one returned by fn.
+//
+// The values are allocated from the slab, so fn must not retain them after the slab is reset. Only the top-level
+// values come from the slab, the strings, slices, maps and pointers decoded into them are allocated on the heap.
+func DecodeAll[T any](r io.Reader, s *Slab[T], fn func(*T) error, opts ...Option) error {
+	dec := NewDecoder(r, opts...)
+
+	for i := 0; ; i++ {
+		v := s.Alloc()
+
+		if err := dec.Decode(v); err != nil {
+			s.release()
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("optional: decode value %d: %w", i, err)
+		}
+
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package optional_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type event struct {
+	ID   optional.Type[int]    `json:"id"`
+	Name optional.Type[string] `json:"name"`
+}
+
+func TestSlab(t *testing.T) {
+	t.Parallel()
+
+	s := optional.NewSlab[event](2)
+
+	a, b, c := s.Alloc(), s.Alloc(), s.Alloc()
+	require.Equal(t, 3, s.Len())
+
+	a.ID.SetValue(1)
+	b.ID.SetNull()
+	c.Name.SetValue("c")
+
+	assert.Equal(t, optional.Some(1), a.ID)
+	assert.Equal(t, optional.Null[int](), b.ID)
+	assert.Equal(t, optional.Some("c"), c.Name)
+
+	s.Reset()
+	require.Equal(t, 0, s.Len())
+
+	assert.Equal(t, event{}, *s.Alloc())
+	assert.Equal(t, event{}, *s.Alloc())
+	assert.Equal(t, event{}, *s.Alloc())
+}
+
+func TestDecodeAll(t *testing.T) {
+	t.Parallel()
+
+	s := optional.NewSlab[event](0)
+
+	var got []event
+
+	err := optional.DecodeAll(strings.NewReader("{\"id\":1,\"name\":\"a\"}\n{\"id\":null}\n{}\n"), s, func(e *event) error {
+		got = append(got, *e)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []event{
+		{ID: optional.Some(1), Name: optional.Some("a")},
+		{ID: optional.Null[int]()},
+		{},
+	}, got)
+	assert.Equal(t, 3, s.Len())
+}
+
+func TestDecodeAll_Error(t *testing.T) {
+	t.Parallel()
+
+	s := optional.NewSlab[event](0)
+
+	err := optional.DecodeAll(strings.NewReader(`{"id":1} {"id":"x"}`), s, func(*event) error { return nil })
+	require.ErrorContains(t, err, "optional: decode value 1")
+
+	errStop := errors.New("stop")
+
+	err = optional.DecodeAll(strings.NewReader(`{"id":1} {"id":2}`), s, func(*event) error { return errStop })
+	require.ErrorIs(t, err, errStop)
+}
+
+func BenchmarkDecodeAll(b *testing.B) {
+	data := strings.Repeat("{\"id\":1,\"name\":\"a\"}\n{\"id\":null}\n", 1000)
+	s := optional.NewSlab[event](0)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		s.Reset()
+
+		if err := optional.DecodeAll(strings.NewReader(data), s, func(*event) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithCanonical makes [Marshal] and [WriteJSON] produce the canonical JSON: the keys of all objects are sorted,
+// numbers with fractions or exponents are formatted like encoding/json formats float64 values, strings are not
+// HTML escaped and there is no insignificant whitespace. The same values always produce the same bytes
+// regardless of the current marshaller, so the output can be hashed and signed reproducibly.
+func WithCanonical() Option {
+	return func(o *options) {
+		o.canonical = true
+	}
+}
+
+// canonicalize appends the canonical form of the JSON value data to dst.
+func canonicalize(dst []byte, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("optional: canonicalize: %w", err)
+	}
+
+	return appendCanonical(dst, v)
+}
+
+func appendCanonical(dst []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case string:
+		return appendString(dst, v, false), nil
+	case json.Number:
+		return appendCanonicalNumber(dst, v)
+	case []any:
+		dst = append(dst, '[')
+
+		for i, e := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			var err error
+
+			if dst, err = appendCanonical(dst, e); err != nil {
+				return nil, err
+			}
+		}
+
+		return append(dst, ']'), nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		dst = append(dst, '{')
+
+		for i, k := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			dst = append(appendString(dst, k, false), ':')
+
+			var err error
+
+			if dst, err = appendCanonical(dst, v[k]); err != nil {
+				return nil, err
+			}
+		}
+
+		return append(dst, '}'), nil
+	}
+
+	return nil, fmt.Errorf("optional: canonicalize: unexpected value %T", v)
+}
+
+// appendCanonicalNumber keeps integers as they are, so large ones do not lose precision,
+// and formats other numbers like encoding/json formats float64 values.
+func appendCanonicalNumber(dst []byte, n json.Number) ([]byte, error) {
+	if !strings.ContainsAny(string(n), ".eE") {
+		if n == "-0" {
+			n = "0"
+		}
+
+		return append(dst, n...), nil
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, fmt.Errorf("optional: canonicalize: %w", err)
+	}
+
+	return appendFloat(dst, f, 64)
+}
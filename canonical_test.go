@@ -0,0 +1,62 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestWithCanonical(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Zeta  optional.Type[string]         `json:"zeta"`
+		Alpha optional.Type[float64]        `json:"alpha"`
+		Meta  map[string]any                `json:"meta"`
+		Raw   json.RawMessage               `json:"raw"`
+		Items []optional.Type[float64]      `json:"items"`
+		Null  optional.Type[int]            `json:"null"`
+		Unset optional.Type[int]            `json:"unset"`
+		Map   map[string]optional.Type[int] `json:"map"`
+	}
+
+	tests := [...]struct {
+		name  string
+		input any
+		want  string
+	}{
+		{
+			"struct",
+			payload{
+				Zeta:  optional.Some("a&b<c>"),
+				Alpha: optional.Some(1.0),
+				Meta:  map[string]any{"b": 2, "a": []any{1.5, "x"}},
+				Raw:   json.RawMessage(`{ "y" : 1.50, "x" : 1E2, "big": 12345678901234567890, "z": -0 }`),
+				Items: []optional.Type[float64]{optional.Some(1e21), optional.Some(0.000001), {}},
+				Null:  optional.Null[int](),
+				Map:   map[string]optional.Type[int]{"b": optional.Some(1), "a": {}},
+			},
+			`{"alpha":1,"items":[1e+21,0.000001,null],"map":{"b":1},"meta":{"a":[1.5,"x"],"b":2},"null":null,` +
+				`"raw":{"big":12345678901234567890,"x":100,"y":1.5,"z":0},"zeta":"a&b<c>"}`,
+		},
+		{"string", "\u2028<>", `"\u2028<>"`},
+		{"nil", nil, `null`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := optional.Marshal(tt.input, optional.WithCanonical())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const optionalPath = "github.com/micronull/optional"
+
+// diagnostic is a problem found in the code.
+type diagnostic struct {
+	pos     token.Pos
+	message string
+}
+
+// pass is a run of the checks over a type-checked package.
+type pass struct {
+	pkg         *types.Package
+	files       []*ast.File
+	info        *types.Info
+	diagnostics []diagnostic
+}
+
+func (p *pass) report(pos token.Pos, message string) {
+	p.diagnostics = append(p.diagnostics, diagnostic{pos: pos, message: message})
+}
+
+// checks are run for every package.
+var checks = []func(*pass){
+	checkValueReads,
+	checkNew,
+	checkOmitEmpty,
+}
+
+// check runs the checks over the package and returns the problems found, ordered by position.
+func check(pkg *types.Package, files []*ast.File, info *types.Info) []diagnostic {
+	// The package itself manages the fields of its values.
+	if pkg.Path() == optionalPath {
+		return nil
+	}
+
+	p := &pass{pkg: pkg, files: files, info: info}
+
+	for _, c := range checks {
+		c(p)
+	}
+
+	sort.SliceStable(p.diagnostics, func(i, j int) bool {
+		return p.diagnostics[i].pos < p.diagnostics[j].pos
+	})
+
+	return p.diagnostics
+}
+
+// checkValueReads reports the reads of the V field of the optional values which are not guarded by a check
+// of the presence: inside the body of if v.IsSet(), after an early return on !v.IsSet(), on the right of
+// v.IsSet() && or in the case optional.StateValue of switch v.State().
+func checkValueReads(p *pass) {
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			checkFuncValueReads(p, fn.Body)
+		}
+	}
+}
+
+func checkFuncValueReads(p *pass, body *ast.BlockStmt) {
+	w := &readWalker{p: p, writes: map[*ast.SelectorExpr]bool{}}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.ASSIGN || n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if sel, ok := unparen(lhs).(*ast.SelectorExpr); ok {
+						w.writes[sel] = true
+					}
+				}
+			}
+		case *ast.UnaryExpr:
+			if sel, ok := unparen(n.X).(*ast.SelectorExpr); ok && n.Op == token.AND {
+				w.writes[sel] = true
+			}
+		}
+
+		return true
+	})
+
+	w.walk(body, guards{})
+}
+
+// guards are the values known to be present, by their expressions.
+type guards map[string]bool
+
+// with returns the guards extended with the values.
+func (g guards) with(values []string) guards {
+	if len(values) == 0 {
+		return g
+	}
+
+	ng := make(guards, len(g)+len(values))
+	for v := range g {
+		ng[v] = true
+	}
+
+	for _, v := range values {
+		ng[v] = true
+	}
+
+	return ng
+}
+
+// readWalker walks a function body tracking the values guarded by the presence checks.
+type readWalker struct {
+	p      *pass
+	writes map[*ast.SelectorExpr]bool
+}
+
+func (w *readWalker) walk(n ast.Node, g guards) {
+	if n == nil {
+		return
+	}
+
+	switch n := n.(type) {
+	case *ast.BlockStmt:
+		w.walkStmts(n.List, g)
+	case *ast.CaseClause:
+		for _, e := range n.List {
+			w.walk(e, g)
+		}
+
+		w.walkStmts(n.Body, g)
+	case *ast.IfStmt:
+		w.walk(n.Init, g)
+		w.walk(n.Cond, g)
+		w.walk(n.Body, g.with(w.presentWhen(n.Cond, true)))
+		w.walk(n.Else, g.with(w.presentWhen(n.Cond, false)))
+	case *ast.SwitchStmt:
+		w.walk(n.Init, g)
+		w.walk(n.Tag, g)
+
+		value := w.stateOf(n.Tag)
+
+		for _, stmt := range n.Body.List {
+			clause := stmt.(*ast.CaseClause)
+
+			if value != "" && hasStateValue(clause.List) {
+				w.walk(clause, g.with([]string{value}))
+			} else {
+				w.walk(clause, g)
+			}
+		}
+	case *ast.BinaryExpr:
+		w.walk(n.X, g)
+
+		switch n.Op {
+		case token.LAND:
+			w.walk(n.Y, g.with(w.presentWhen(n.X, true)))
+		case token.LOR:
+			w.walk(n.Y, g.with(w.presentWhen(n.X, false)))
+		default:
+			w.walk(n.Y, g)
+		}
+	case *ast.SelectorExpr:
+		if isValueField(w.p, n) && !w.writes[n] && !g[types.ExprString(n.X)] {
+			w.p.report(n.Sel.Pos(), types.ExprString(n)+" is read without checking "+types.ExprString(n.X)+
+				".IsSet(), the value may be unset or null")
+		}
+
+		w.walk(n.X, g)
+	default:
+		ast.Inspect(n, func(c ast.Node) bool {
+			if c == n {
+				return true
+			}
+
+			if c != nil {
+				w.walk(c, g)
+			}
+
+			return false
+		})
+	}
+}
+
+// walkStmts walks the statements of a block, guarding the values after the early returns on their absence.
+func (w *readWalker) walkStmts(list []ast.Stmt, g guards) {
+	for _, stmt := range list {
+		w.walk(stmt, g)
+
+		if is, ok := stmt.(*ast.IfStmt); ok && is.Else == nil && terminates(is.Body) {
+			g = g.with(w.presentWhen(is.Cond, false))
+		}
+	}
+}
+
+// presentWhen returns the values known to be present when the condition evaluates to the result.
+func (w *readWalker) presentWhen(cond ast.Expr, result bool) []string {
+	switch e := unparen(cond).(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return w.presentWhen(e.X, !result)
+		}
+	case *ast.BinaryExpr:
+		switch {
+		case e.Op == token.LAND && result, e.Op == token.LOR && !result:
+			return append(w.presentWhen(e.X, result), w.presentWhen(e.Y, result)...)
+		case e.Op == token.EQL || e.Op == token.NEQ:
+			value := w.stateOf(e.X)
+			if value == "" {
+				value = w.stateOf(e.Y)
+			}
+
+			if value != "" && hasStateValue([]ast.Expr{e.X, e.Y}) && (e.Op == token.EQL) == result {
+				return []string{value}
+			}
+		}
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && result && sel.Sel.Name == "IsSet" && isOptionalMethod(w.p, sel) {
+			return []string{types.ExprString(sel.X)}
+		}
+	}
+
+	return nil
+}
+
+// stateOf returns the value of the call of its State method, or an empty string for other expressions.
+func (w *readWalker) stateOf(e ast.Expr) string {
+	call, ok := unparen(e).(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "State" || !isOptionalMethod(w.p, sel) {
+		return ""
+	}
+
+	return types.ExprString(sel.X)
+}
+
+// hasStateValue reports whether one of the expressions refers to optional.StateValue.
+func hasStateValue(list []ast.Expr) bool {
+	for _, e := range list {
+		switch e := unparen(e).(type) {
+		case *ast.Ident:
+			if e.Name == "StateValue" {
+				return true
+			}
+		case *ast.SelectorExpr:
+			if e.Sel.Name == "StateValue" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// terminates reports whether the block ends with a return, a branch or a panic.
+func terminates(b *ast.BlockStmt) bool {
+	if len(b.List) == 0 {
+		return false
+	}
+
+	switch s := b.List[len(b.List)-1].(type) {
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+
+		id, ok := call.Fun.(*ast.Ident)
+
+		return ok && id.Name == "panic"
+	}
+
+	return false
+}
+
+// isValueField reports whether the selector selects the V field of an optional value.
+func isValueField(p *pass, sel *ast.SelectorExpr) bool {
+	s, ok := p.info.Selections[sel]
+	if !ok || s.Kind() != types.FieldVal {
+		return false
+	}
+
+	v, ok := s.Obj().(*types.Var)
+
+	return ok && v.IsField() && v.Name() == "V" && v.Pkg() != nil && v.Pkg().Path() == optionalPath
+}
+
+// isOptionalMethod reports whether the selector selects a method of the optional package.
+func isOptionalMethod(p *pass, sel *ast.SelectorExpr) bool {
+	s, ok := p.info.Selections[sel]
+	if !ok || s.Kind() != types.MethodVal {
+		return false
+	}
+
+	return s.Obj().Pkg() != nil && s.Obj().Pkg().Path() == optionalPath
+}
+
+// checkNew reports the calls of New with false for null, as the result reads as unset.
+func checkNew(p *pass) {
+	for _, f := range p.files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 || !isOptionalFunc(p, call.Fun, "New") {
+				return true
+			}
+
+			if tv := p.info.Types[call.Args[1]]; tv.Value != nil && tv.Value.Kind() == constant.Bool &&
+				!constant.BoolVal(tv.Value) {
+				p.report(call.Pos(), "optional.New does not mark the value as set, IsSet reports false: "+
+					"use optional.Some")
+			}
+
+			return true
+		})
+	}
+}
+
+// isOptionalFunc reports whether the expression refers to the function of the optional package with the name,
+// possibly instantiated.
+func isOptionalFunc(p *pass, fun ast.Expr, name string) bool {
+	switch e := unparen(fun).(type) {
+	case *ast.IndexExpr:
+		fun = e.X
+	case *ast.IndexListExpr:
+		fun = e.X
+	}
+
+	var id *ast.Ident
+
+	switch e := unparen(fun).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return false
+	}
+
+	obj, ok := p.info.Uses[id].(*types.Func)
+
+	return ok && obj.Name() == name && obj.Pkg() != nil && obj.Pkg().Path() == optionalPath
+}
+
+// checkOmitEmpty reports the omitempty option in the json tags of the optional fields. The structs are never
+// empty for encoding/json, so the option has no effect.
+func checkOmitEmpty(p *pass) {
+	for _, f := range p.files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+
+			for _, field := range st.Fields.List {
+				if field.Tag == nil || !isOptionalType(p.info.Types[field.Type].Type) {
+					continue
+				}
+
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					continue
+				}
+
+				_, opts, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+
+				for _, opt := range strings.Split(opts, ",") {
+					if opt == "omitempty" {
+						p.report(field.Tag.Pos(), "omitempty has no effect on optional fields: "+
+							"encode with optional.Marshal to omit the unset fields")
+
+						break
+					}
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+// isOptionalType reports whether t is an optional.Type or an optional.Tracked.
+func isOptionalType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == optionalPath && (obj.Name() == "Type" || obj.Name() == "Tracked")
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+
+		e = p.X
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// optionalSource is a stub of the optional package for the checked code to import.
+const optionalSource = `package optional
+
+type Type[T any] struct {
+	V T
+	n bool
+	s bool
+}
+
+func New[T any](value T, null bool) Type[T] { return Type[T]{V: value, n: null} }
+
+func Some[T any](value T) Type[T] { return Type[T]{V: value, s: true} }
+
+func (t Type[T]) IsSet() bool { return t.s }
+
+func (t Type[T]) IsSetNull() bool { return t.n }
+
+type State int
+
+const (
+	StateUnset State = iota
+	StateNull
+	StateValue
+)
+
+func (t Type[T]) State() State { return StateUnset }
+`
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "unchecked read",
+			src: `func f(u user) string {
+	return u.Name.V
+}`,
+			want: []string{"8:16: u.Name.V is read without checking u.Name.IsSet(), the value may be unset or null"},
+		},
+		{
+			name: "checked read",
+			src: `func f(u user) string {
+	if !u.Name.IsSet() {
+		return ""
+	}
+
+	return u.Name.V
+}`,
+		},
+		{
+			name: "other value checked",
+			src: `func f(u, v user) string {
+	if v.Name.IsSet() {
+		return u.Name.V
+	}
+
+	return ""
+}`,
+			want: []string{"9:17: u.Name.V is read without checking u.Name.IsSet(), the value may be unset or null"},
+		},
+		{
+			name: "read before check",
+			src: `func f(u user) string {
+	name := u.Name.V
+
+	if !u.Name.IsSet() {
+		return ""
+	}
+
+	return name + u.Name.V
+}`,
+			want: []string{"8:17: u.Name.V is read without checking u.Name.IsSet(), the value may be unset or null"},
+		},
+		{
+			name: "not null check",
+			src: `func f(u user) string {
+	if !u.Name.IsSetNull() {
+		return u.Name.V
+	}
+
+	return ""
+}`,
+			want: []string{"9:17: u.Name.V is read without checking u.Name.IsSet(), the value may be unset or null"},
+		},
+		{
+			name: "check without return",
+			src: `func f(u user) string {
+	if !u.Name.IsSet() {
+		println("unset")
+	}
+
+	return u.Name.V
+}`,
+			want: []string{"12:16: u.Name.V is read without checking u.Name.IsSet(), the value may be unset or null"},
+		},
+		{
+			name: "guarded reads",
+			src: `func f(u user) string {
+	if u.Name.IsSet() && u.Name.V != "" {
+		return u.Name.V
+	}
+
+	if !u.Name.IsSet() || u.Name.V == "" {
+		return ""
+	}
+
+	switch u.Name.State() {
+	case optional.StateValue:
+		return u.Name.V
+	}
+
+	if u.Name.State() != optional.StateValue {
+		return ""
+	} else {
+		return u.Name.V
+	}
+}`,
+		},
+		{
+			name: "writes",
+			src: `func f(u *user) *string {
+	u.Name.V = "name"
+
+	return &u.Name.V
+}`,
+		},
+		{
+			name: "new not null",
+			src: `func f() optional.Type[int] {
+	return optional.New(1, false)
+}`,
+			want: []string{"8:9: optional.New does not mark the value as set, IsSet reports false: use optional.Some"},
+		},
+		{
+			name: "new null and some",
+			src: `func f(null bool) []optional.Type[int] {
+	return []optional.Type[int]{optional.New[int](0, true), optional.New(1, null), optional.Some(1)}
+}`,
+		},
+		{
+			name: "omitempty",
+			src: `type request struct {
+	Name  optional.Type[string] ` + "`json:\"name,omitempty\"`" + `
+	Email optional.Type[string] ` + "`json:\"email\" xml:\",omitempty\"`" + `
+	Tags  []string              ` + "`json:\"tags,omitempty\"`" + `
+}`,
+			want: []string{"8:30: omitempty has no effect on optional fields: encode with optional.Marshal to omit the unset fields"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, checkSource(t, tt.src))
+		})
+	}
+}
+
+// checkSource checks the declarations in a file importing the stub of the optional package and returns
+// the problems found as "line:column: message".
+func checkSource(t *testing.T, decls string) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+
+	optFile, err := parser.ParseFile(fset, "optional.go", optionalSource, 0)
+	require.NoError(t, err)
+
+	optPkg, _, err := typeCheck(fset, optionalPath, []*ast.File{optFile}, &types.Config{})
+	require.NoError(t, err)
+
+	src := fmt.Sprintf("package p\n\nimport \"%s\"\n\ntype user struct{ Name optional.Type[string] }\n\n%s\n",
+		optionalPath, decls)
+
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	require.NoError(t, err)
+
+	tc := &types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		return optPkg, nil
+	})}
+
+	pkg, info, err := typeCheck(fset, "p", []*ast.File{file}, tc)
+	require.NoError(t, err)
+
+	var got []string
+
+	for _, d := range check(pkg, []*ast.File{file}, info) {
+		pos := fset.Position(d.pos)
+		got = append(got, fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, d.message))
+	}
+
+	return got
+}
@@ -0,0 +1,257 @@
+// Command optionalcheck reports misuses of the [optional] package.
+//
+// It checks that:
+//
+//   - the V field of an [optional.Type] is read only where the presence of the value is checked:
+//     in the body of if v.IsSet(), after an early return on !v.IsSet(), on the right of v.IsSet() &&,
+//     or where v.State() is optional.StateValue;
+//   - [optional.New] is not called with false for null, the result is not marked as set, use [optional.Some];
+//   - the json tags of the optional fields have no omitempty option, which has no effect on structs,
+//     use [optional.Marshal] to omit the unset fields.
+//
+// The command is run by go vet:
+//
+//	go install github.com/micronull/optional/cmd/optionalcheck
+//	go vet -vettool=$(which optionalcheck) ./...
+//
+// [optional]: https://pkg.go.dev/github.com/micronull/optional
+// [optional.Type]: https://pkg.go.dev/github.com/micronull/optional#Type
+// [optional.New]: https://pkg.go.dev/github.com/micronull/optional#New
+// [optional.Some]: https://pkg.go.dev/github.com/micronull/optional#Some
+// [optional.Marshal]: https://pkg.go.dev/github.com/micronull/optional#Marshal
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	code, err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "optionalcheck:", err)
+	}
+
+	os.Exit(code)
+}
+
+// run implements the protocol go vet uses to run the tool: it describes the flags with -flags, reports
+// its version with -V=full and checks the package described by the configuration file.
+func run(args []string, stdout, stderr io.Writer) (int, error) {
+	fs := flag.NewFlagSet("optionalcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	describe := fs.Bool("flags", false, "print the flags as JSON")
+	ver := fs.String("V", "", "print the version and exit")
+	asJSON := fs.Bool("json", false, "print the problems as JSON")
+	_ = fs.Int("c", -1, "lines of context to print, ignored")
+
+	if err := fs.Parse(args); err != nil {
+		return 1, err
+	}
+
+	switch {
+	case *describe:
+		fmt.Fprintln(stdout, `[{"Name":"json","Bool":true,"Usage":"print the problems as JSON"}]`)
+
+		return 0, nil
+	case *ver != "":
+		return version(stdout)
+	case fs.NArg() != 1 || !strings.HasSuffix(fs.Arg(0), ".cfg"):
+		return 1, errors.New("run the command with go vet -vettool")
+	}
+
+	return checkConfig(fs.Arg(0), *asJSON, stdout, stderr)
+}
+
+// version prints the version of the executable, go vet uses it as the key of the cached results.
+func version(stdout io.Writer) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 1, err
+	}
+
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return 1, err
+	}
+
+	fmt.Fprintf(stdout, "%s version devel comments-go-here buildID=%x\n",
+		strings.TrimSuffix(filepath.Base(exe), ".exe"), sha256.Sum256(data))
+
+	return 0, nil
+}
+
+// config describes the package to check, go vet writes it to a file.
+type config struct {
+	ID                        string
+	Compiler                  string
+	ImportPath                string
+	GoFiles                   []string
+	ImportMap                 map[string]string
+	PackageFile               map[string]string
+	VetxOnly                  bool
+	VetxOutput                string
+	Stdout                    string
+	SucceedOnTypecheckFailure bool
+}
+
+// checkConfig checks the package of the configuration file and prints the problems found.
+// The exit code is 1 if any problem is found.
+func checkConfig(path string, asJSON bool, stdout, stderr io.Writer) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 1, err
+	}
+
+	var cfg config
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return 1, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	// The checks produce no facts for the dependent packages, but go vet expects the file.
+	if cfg.VetxOutput != "" {
+		if err := os.WriteFile(cfg.VetxOutput, nil, 0o666); err != nil {
+			return 1, err
+		}
+	}
+
+	if cfg.VetxOnly {
+		return 0, nil
+	}
+
+	fset := token.NewFileSet()
+
+	files := make([]*ast.File, 0, len(cfg.GoFiles))
+
+	for _, name := range cfg.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			if cfg.SucceedOnTypecheckFailure {
+				return 0, nil
+			}
+
+			return 1, err
+		}
+
+		files = append(files, f)
+	}
+
+	compiled := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
+		file, ok := cfg.PackageFile[path]
+		if !ok {
+			return nil, fmt.Errorf("no package file for %q", path)
+		}
+
+		return os.Open(file)
+	})
+
+	tc := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if mapped, ok := cfg.ImportMap[path]; ok {
+				path = mapped
+			}
+
+			return compiled.Import(path)
+		}),
+		Sizes: types.SizesFor(cfg.Compiler, build.Default.GOARCH),
+	}
+
+	pkg, info, err := typeCheck(fset, cfg.ImportPath, files, &tc)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			return 0, nil
+		}
+
+		return 1, err
+	}
+
+	diagnostics := check(pkg, files, info)
+
+	if asJSON {
+		// Newer versions of go vet read the output from the file and print it themselves.
+		if cfg.Stdout != "" {
+			f, err := os.Create(cfg.Stdout)
+			if err != nil {
+				return 1, err
+			}
+
+			defer f.Close()
+
+			stdout = f
+		}
+
+		return 0, printJSON(stdout, fset, cfg.ID, diagnostics)
+	}
+
+	for _, d := range diagnostics {
+		fmt.Fprintf(stderr, "%s: %s\n", fset.Position(d.pos), d.message)
+	}
+
+	if len(diagnostics) != 0 {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// printJSON prints the problems in the JSON format of go vet, grouped by the package and the name of the tool.
+func printJSON(w io.Writer, fset *token.FileSet, id string, diagnostics []diagnostic) error {
+	type jsonDiagnostic struct {
+		Posn    string `json:"posn"`
+		Message string `json:"message"`
+	}
+
+	if len(diagnostics) == 0 {
+		_, err := fmt.Fprintln(w, "{}")
+
+		return err
+	}
+
+	list := make([]jsonDiagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		list = append(list, jsonDiagnostic{Posn: fset.Position(d.pos).String(), Message: d.message})
+	}
+
+	data, err := json.MarshalIndent(map[string]any{id: map[string]any{"optionalcheck": list}}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+
+	return err
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// typeCheck type checks the files of the package.
+func typeCheck(fset *token.FileSet, path string, files []*ast.File, tc *types.Config) (*types.Package, *types.Info, error) {
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+
+	pkg, err := tc.Check(path, fset, files, info)
+
+	return pkg, info, err
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+
+	code, err := run([]string{"-flags"}, &stdout, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[{"Name":"json","Bool":true,"Usage":"print the problems as JSON"}]`, stdout.String())
+
+	stdout.Reset()
+
+	code, err = run([]string{"-V=full"}, &stdout, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, `^\S+ version devel comments-go-here buildID=[0-9a-f]{64}\n$`, stdout.String())
+
+	code, err = run([]string{"./..."}, &stdout, io.Discard)
+	assert.EqualError(t, err, "run the command with go vet -vettool")
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"io"
+)
+
+// runAccessors runs the accessors command.
+func runAccessors(args []string, stderr io.Writer) error {
+	return runTypeCommand("accessors", args, stderr, generateAccessors)
+}
+
+// generateAccessors generates the accessors of the optional fields of the struct type.
+func generateAccessors(src *source, typeName string) ([]byte, error) {
+	fields, err := src.fields(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		optionals []field
+		elems     []ast.Expr
+	)
+
+	for _, f := range fields {
+		if f.elem != nil {
+			optionals = append(optionals, f)
+			elems = append(elems, f.elem)
+		}
+	}
+
+	if len(optionals) == 0 {
+		return nil, fmt.Errorf("type %s has no optional fields", typeName)
+	}
+
+	var buf bytes.Buffer
+
+	src.header(&buf, "accessors", src.imports(elems...))
+
+	r := receiverName(typeName)
+
+	for _, f := range optionals {
+		elem := src.expr(f.elem)
+
+		fmt.Fprintf(&buf, "\n// Get%[1]s returns the value of %[1]s and whether it is set to a value, rather than unset or null.\n", f.name)
+		fmt.Fprintf(&buf, "func (%[1]s *%[2]s) Get%[3]s() (%[4]s, bool) {\n", r, typeName, f.name, elem)
+		fmt.Fprintf(&buf, "\treturn %[1]s.%[2]s.V, %[1]s.%[2]s.IsSet() && !%[1]s.%[2]s.IsSetNull()\n}\n", r, f.name)
+
+		fmt.Fprintf(&buf, "\n// Set%[1]s sets %[1]s to the value.\n", f.name)
+		fmt.Fprintf(&buf, "func (%[1]s *%[2]s) Set%[3]s(value %[4]s) {\n", r, typeName, f.name, elem)
+		fmt.Fprintf(&buf, "\t%s.%s.SetValue(value)\n}\n", r, f.name)
+
+		fmt.Fprintf(&buf, "\n// Clear%[1]s marks %[1]s as not set.\n", f.name)
+		fmt.Fprintf(&buf, "func (%[1]s *%[2]s) Clear%[3]s() {\n", r, typeName, f.name)
+		fmt.Fprintf(&buf, "\t%s.%s.Unset()\n}\n", r, f.name)
+	}
+
+	fmt.Fprintf(&buf, "\n// SetFields returns the names of the set fields of %s, including the fields set to null.\n", typeName)
+	fmt.Fprintf(&buf, "func (%s *%s) SetFields() []string {\n", r, typeName)
+	fmt.Fprintf(&buf, "\tfields := make([]string, 0, %d)\n", len(optionals))
+
+	for _, f := range optionals {
+		fmt.Fprintf(&buf, "\n\tif %s.%s.IsSet() {\n\t\tfields = append(fields, %q)\n\t}\n", r, f.name, f.name)
+	}
+
+	buf.WriteString("\n\treturn fields\n}\n")
+
+	return buf.Bytes(), nil
+}
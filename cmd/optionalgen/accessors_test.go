@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestAccessors(t *testing.T) {
+	t.Parallel()
+
+	assertGenerated(t, "accessors", "User", "testdata/user.go", "testdata/user_accessors.golden")
+}
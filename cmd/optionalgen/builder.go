@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"io"
+)
+
+// runBuilder runs the builder command.
+func runBuilder(args []string, stderr io.Writer) error {
+	return runTypeCommand("builder", args, stderr, generateBuilder)
+}
+
+// generateBuilder generates the fluent builder of the struct type, with a method setting every exported field
+// and a method setting every optional field to null.
+func generateBuilder(src *source, typeName string) ([]byte, error) {
+	fields, err := src.fields(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exported []field
+		types    []ast.Expr
+	)
+
+	for _, f := range fields {
+		if !ast.IsExported(f.name) {
+			continue
+		}
+
+		exported = append(exported, f)
+
+		if f.elem != nil {
+			types = append(types, f.elem)
+		} else {
+			types = append(types, f.typ)
+		}
+	}
+
+	if len(exported) == 0 {
+		return nil, fmt.Errorf("type %s has no exported fields", typeName)
+	}
+
+	var buf bytes.Buffer
+
+	src.header(&buf, "builder", src.imports(types...))
+
+	builderName := typeName + "Builder"
+
+	fmt.Fprintf(&buf, "\n// %s builds %s field by field.\n", builderName, typeName)
+	fmt.Fprintf(&buf, "type %s struct {\n\tv %s\n}\n", builderName, typeName)
+
+	fmt.Fprintf(&buf, "\n// New%[1]s returns a builder of %[1]s with no fields set.\n", typeName)
+	fmt.Fprintf(&buf, "func New%s() *%s {\n\treturn &%[2]s{}\n}\n", typeName, builderName)
+
+	for i, f := range exported {
+		typ := src.expr(types[i])
+
+		fmt.Fprintf(&buf, "\n// %[1]s sets %[1]s to the value.\n", f.name)
+		fmt.Fprintf(&buf, "func (b *%s) %s(value %s) *%[1]s {\n", builderName, f.name, typ)
+
+		if f.elem != nil {
+			fmt.Fprintf(&buf, "\tb.v.%s.SetValue(value)\n\n\treturn b\n}\n", f.name)
+
+			fmt.Fprintf(&buf, "\n// %[1]sNull sets %[1]s to null.\n", f.name)
+			fmt.Fprintf(&buf, "func (b *%s) %sNull() *%[1]s {\n", builderName, f.name)
+			fmt.Fprintf(&buf, "\tb.v.%s.SetNull()\n\n\treturn b\n}\n", f.name)
+		} else {
+			fmt.Fprintf(&buf, "\tb.v.%s = value\n\n\treturn b\n}\n", f.name)
+		}
+	}
+
+	fmt.Fprintf(&buf, "\n// Build returns the built %s.\n", typeName)
+	fmt.Fprintf(&buf, "func (b *%s) Build() %s {\n\treturn b.v\n}\n", builderName, typeName)
+
+	return buf.Bytes(), nil
+}
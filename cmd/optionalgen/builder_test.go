@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	assertGenerated(t, "builder", "User", "testdata/user.go", "testdata/user_builder.golden")
+}
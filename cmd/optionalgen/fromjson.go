@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// runFromJSON runs the fromjson command.
+func runFromJSON(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("fromjson", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	typeName := fs.String("type", "", "name of the struct type (required)")
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package name (default $GOPACKAGE or main)")
+	output := fs.String("output", "", "output file name (default standard output)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *typeName == "" {
+		return errors.New("fromjson: -type is required")
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("fromjson: no sample files given")
+	}
+
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	root := &shape{}
+
+	for _, path := range fs.Args() {
+		if err := sampleFile(root, path); err != nil {
+			return fmt.Errorf("fromjson: %w", err)
+		}
+	}
+
+	if root.kind != shapeObject {
+		return errors.New("fromjson: samples are not JSON objects")
+	}
+
+	code, err := generateFromJSON(root, *pkg, *typeName)
+	if err != nil {
+		return fmt.Errorf("fromjson: %w", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(code)
+
+		return err
+	}
+
+	return os.WriteFile(*output, code, 0o644)
+}
+
+// sampleFile adds the JSON documents of the file, one or more, to the shape.
+func sampleFile(root *shape, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+
+	for {
+		var v any
+
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		root.add(v)
+	}
+}
+
+type shapeKind int
+
+const (
+	shapeNone shapeKind = iota // shapeNone is the kind of a value seen only as null.
+	shapeBool
+	shapeInt
+	shapeFloat
+	shapeString
+	shapeObject
+	shapeArray
+	shapeMixed // shapeMixed is the kind of a value seen with incompatible types.
+)
+
+// shape is the type of a JSON value inferred from the samples.
+type shape struct {
+	kind    shapeKind
+	null    bool // null reports whether the value was seen as null.
+	objects int  // objects counts the objects seen, to tell the members absent from some of them.
+	members map[string]*member
+	elem    *shape // elem is the shape of the elements of an array.
+}
+
+type member struct {
+	shape *shape
+	seen  int
+}
+
+// add merges the value into the shape.
+func (s *shape) add(v any) {
+	if v == nil {
+		s.null = true
+
+		return
+	}
+
+	var kind shapeKind
+
+	switch v := v.(type) {
+	case bool:
+		kind = shapeBool
+	case json.Number:
+		kind = shapeInt
+		if strings.ContainsAny(v.String(), ".eE") {
+			kind = shapeFloat
+		}
+	case string:
+		kind = shapeString
+	case map[string]any:
+		kind = shapeObject
+	case []any:
+		kind = shapeArray
+	}
+
+	switch {
+	case s.kind == shapeNone:
+		s.kind = kind
+	case s.kind == shapeInt && kind == shapeFloat, s.kind == shapeFloat && kind == shapeInt:
+		s.kind = shapeFloat
+	case s.kind != kind:
+		s.kind = shapeMixed
+	}
+
+	if s.kind == shapeMixed {
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		s.addObject(v)
+	case []any:
+		if s.elem == nil {
+			s.elem = &shape{}
+		}
+
+		for _, e := range v {
+			s.elem.add(e)
+		}
+	}
+}
+
+func (s *shape) addObject(obj map[string]any) {
+	if s.members == nil {
+		s.members = map[string]*member{}
+	}
+
+	s.objects++
+
+	for key, v := range obj {
+		m, ok := s.members[key]
+		if !ok {
+			m = &member{shape: &shape{}}
+			s.members[key] = m
+		}
+
+		m.seen++
+		m.shape.add(v)
+	}
+}
+
+// keys returns the sorted names of the members of the object shape.
+func (s *shape) keys() []string {
+	keys := make([]string, 0, len(s.members))
+	for key := range s.members {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// structDef is a struct type to emit.
+type structDef struct {
+	name  string
+	shape *shape
+}
+
+// generateFromJSON generates the struct types of the object shape.
+func generateFromJSON(root *shape, pkg, typeName string) ([]byte, error) {
+	var (
+		body         bytes.Buffer
+		usesOptional bool
+	)
+
+	queue := []structDef{{name: typeName, shape: root}}
+
+	for len(queue) > 0 {
+		def := queue[0]
+		queue = queue[1:]
+
+		fmt.Fprintf(&body, "\ntype %s struct {\n", def.name)
+
+		names := map[string]int{}
+
+		for _, key := range def.shape.keys() {
+			m := def.shape.members[key]
+
+			name := goName(key)
+			if names[name]++; names[name] > 1 {
+				name += strconv.Itoa(names[name])
+			}
+
+			typ, nested := goType(m.shape, def.name+name)
+			queue = append(queue, nested...)
+
+			if m.shape.null || m.seen < def.shape.objects {
+				typ = "optional.Type[" + typ + "]"
+				usesOptional = true
+			}
+
+			fmt.Fprintf(&body, "\t%s %s `json:%q`\n", name, typ, key)
+		}
+
+		body.WriteString("}\n")
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by optionalgen fromjson.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+
+	if usesOptional {
+		fmt.Fprintf(&buf, "\nimport %q\n", optionalPath)
+	}
+
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// goType returns the Go type of the shape and the struct types to emit for it. Nested objects become struct
+// types with the name.
+func goType(s *shape, name string) (string, []structDef) {
+	switch s.kind {
+	case shapeBool:
+		return "bool", nil
+	case shapeInt:
+		return "int64", nil
+	case shapeFloat:
+		return "float64", nil
+	case shapeString:
+		return "string", nil
+	case shapeObject:
+		return name, []structDef{{name: name, shape: s}}
+	case shapeArray:
+		if s.elem == nil {
+			return "[]any", nil
+		}
+
+		elem, nested := goType(s.elem, name)
+		if s.elem.null && elem != "any" {
+			elem = "*" + elem
+		}
+
+		return "[]" + elem, nested
+	}
+
+	return "any", nil
+}
+
+// initialisms are spelled in upper case in Go names, as golint suggests.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName returns the exported Go name of the JSON key.
+func goName(key string) string {
+	var b strings.Builder
+
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+
+			continue
+		}
+
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	name := b.String()
+
+	switch {
+	case name == "":
+		return "Field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "F" + name
+	}
+
+	return name
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "partner.go")
+
+	args := []string{"fromjson", "-type", "Partner", "-package", "partner", "-output", output,
+		"testdata/partner1.json", "testdata/partner2.json"}
+
+	require.NoError(t, run(args, os.Stderr))
+
+	got, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	want, err := os.ReadFile("testdata/partner.golden")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGoName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"name":        "Name",
+		"website_url": "WebsiteURL",
+		"user-id":     "UserID",
+		"createdAt":   "CreatedAt",
+		"2fa":         "F2fa",
+		"_":           "Field",
+	}
+
+	for key, want := range tests {
+		assert.Equal(t, want, goName(key), key)
+	}
+}
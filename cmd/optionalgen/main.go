@@ -0,0 +1,67 @@
+// Command optionalgen generates code for structs with [optional.Type] fields.
+//
+// Usage:
+//
+//	optionalgen <command> [flags] [files]
+//
+// The commands are:
+//
+//	accessors   generate getters, setters and clearers of the optional fields of a struct
+//	builder     generate a fluent builder of a struct of optional fields
+//	fromjson    generate struct types from sample JSON documents
+//	patch       generate a patch struct of optional fields mirroring a domain struct
+//
+// The file defaults to $GOFILE, so the command can be run with go:generate:
+//
+//	//go:generate go run github.com/micronull/optional/cmd/optionalgen accessors -type User
+//
+// [optional.Type]: https://pkg.go.dev/github.com/micronull/optional#Type
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// commands maps the names of the commands to their implementations.
+var commands = map[string]func(args []string, stderr io.Writer) error{
+	"accessors": runAccessors,
+	"builder":   runBuilder,
+	"fromjson":  runFromJSON,
+	"patch":     runPatch,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "optionalgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("no command, want one of: " + commandNames())
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, want one of: %s", args[0], commandNames())
+	}
+
+	return cmd(args[1:], stderr)
+}
+
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "no command",
+			args: nil,
+			err:  "no command, want one of: accessors, builder, fromjson, patch",
+		},
+		{
+			name: "unknown command",
+			args: []string{"getters"},
+			err:  `unknown command "getters", want one of: accessors, builder, fromjson, patch`,
+		},
+		{
+			name: "no type",
+			args: []string{"accessors", "testdata/user.go"},
+			err:  "accessors: -type is required",
+		},
+		{
+			name: "unknown type",
+			args: []string{"patch", "-type", "Order", "testdata/user.go"},
+			err:  "patch: type Order is not found in testdata/user.go",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualError(t, run(tt.args, io.Discard), tt.err)
+		})
+	}
+}
+
+// assertGenerated runs the command for the type from the source file and compares the generated code
+// with the golden file.
+func assertGenerated(t *testing.T, command, typeName, source, golden string) {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "generated.go")
+
+	require.NoError(t, run([]string{command, "-type", typeName, "-output", output, source}, os.Stderr))
+
+	got, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(want), string(got))
+}
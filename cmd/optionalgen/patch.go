@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// runPatch runs the patch command.
+func runPatch(args []string, stderr io.Writer) error {
+	return runTypeCommand("patch", args, stderr, generatePatch)
+}
+
+// generatePatch generates the patch struct of the struct type, with the optional fields matching the exported
+// fields of the type, and the functions applying and computing the patch with the patch package.
+func generatePatch(src *source, typeName string) ([]byte, error) {
+	fields, err := src.fields(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exported []field
+		elems    []ast.Expr
+	)
+
+	for _, f := range fields {
+		if !ast.IsExported(f.name) {
+			continue
+		}
+
+		if f.elem == nil {
+			f.elem = f.typ
+		}
+
+		exported = append(exported, f)
+		elems = append(elems, f.elem)
+	}
+
+	if len(exported) == 0 {
+		return nil, fmt.Errorf("type %s has no exported fields", typeName)
+	}
+
+	imports := append(src.imports(elems...), strconv.Quote(optionalPath), strconv.Quote(optionalPath+"/patch"))
+	sort.Strings(imports)
+
+	var buf bytes.Buffer
+
+	src.header(&buf, "patch", imports)
+
+	patchName := typeName + "Patch"
+
+	fmt.Fprintf(&buf, "\n// %s is a patch of %s. Only the set fields are applied, the fields set to null are cleared.\n", patchName, typeName)
+	fmt.Fprintf(&buf, "type %s struct {\n", patchName)
+
+	for _, f := range exported {
+		fmt.Fprintf(&buf, "\t%s optional.Type[%s] %s\n", f.name, src.expr(f.elem), patchTag(f.tag))
+	}
+
+	buf.WriteString("}\n")
+
+	fmt.Fprintf(&buf, "\n// Apply applies the set fields of the patch onto dst.\n")
+	fmt.Fprintf(&buf, "func (p *%s) Apply(dst *%s) error {\n\treturn patch.Apply(dst, p)\n}\n", patchName, typeName)
+
+	fmt.Fprintf(&buf, "\n// Diff%[1]s returns the patch turning from into to, with the fields that differ set.\n", typeName)
+	fmt.Fprintf(&buf, "func Diff%[1]s(from, to *%[1]s) (%[2]s, error) {\n", typeName, patchName)
+	fmt.Fprintf(&buf, "\tvar p %s\n\n\terr := patch.Diff(from, to, &p)\n\n\treturn p, err\n}\n", patchName)
+
+	return buf.Bytes(), nil
+}
+
+// patchTag returns the tag literal of the field for the optional field of the patch, without the omitempty option
+// of the json tag: it has no effect on the optional fields and is reported by optionalcheck.
+func patchTag(lit string) string {
+	tag, err := strconv.Unquote(lit)
+	if err != nil {
+		return lit
+	}
+
+	value, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok {
+		return lit
+	}
+
+	name, opts, _ := strings.Cut(value, ",")
+
+	var kept []string
+
+	for _, opt := range strings.Split(opts, ",") {
+		if opt != "" && opt != "omitempty" {
+			kept = append(kept, opt)
+		}
+	}
+
+	trimmed := strings.Join(append([]string{name}, kept...), ",")
+	if trimmed == value {
+		return lit
+	}
+
+	tag = strings.Replace(tag, "json:"+strconv.Quote(value), "json:"+strconv.Quote(trimmed), 1)
+
+	return "`" + tag + "`"
+}
@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+
+	assertGenerated(t, "patch", "Account", "testdata/account.go", "testdata/account_patch.golden")
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const optionalPath = "github.com/micronull/optional"
+
+// source is a parsed Go file holding the structs to generate code for.
+type source struct {
+	path string
+	fset *token.FileSet
+	file *ast.File
+}
+
+// field is a named field of a struct.
+type field struct {
+	name string
+	typ  ast.Expr
+	elem ast.Expr // elem is the type argument of optional.Type, or nil for other fields.
+	tag  string
+}
+
+// runTypeCommand runs the command generating code for a struct type with the generate function.
+// The generated code is written next to the source file, unless the -output flag is given.
+func runTypeCommand(name string, args []string, stderr io.Writer, generate func(*source, string) ([]byte, error)) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	typeName := fs.String("type", "", "name of the struct type (required)")
+	output := fs.String("output", "", "output file name (default <type>_"+name+".go)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *typeName == "" {
+		return fmt.Errorf("%s: -type is required", name)
+	}
+
+	path, err := sourcePath(fs.Args())
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	src, err := parseSource(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	code, err := generate(src, *typeName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return writeSource(src.outputPath(*output, *typeName, name), code)
+}
+
+// sourcePath returns the file from the arguments, falling back to $GOFILE set by go generate.
+func sourcePath(args []string) (string, error) {
+	switch {
+	case len(args) > 1:
+		return "", fmt.Errorf("too many arguments: %s", strings.Join(args, " "))
+	case len(args) == 1:
+		return args[0], nil
+	case os.Getenv("GOFILE") != "":
+		return os.Getenv("GOFILE"), nil
+	}
+
+	return "", errors.New("no file given and $GOFILE is not set")
+}
+
+func parseSource(path string) (*source, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &source{path: path, fset: fset, file: file}, nil
+}
+
+// optionalName returns the name the file imports the optional package under.
+func (s *source) optionalName() (string, bool) {
+	for _, spec := range s.file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path != optionalPath {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name, spec.Name.Name != "_"
+		}
+
+		return "optional", true
+	}
+
+	return "", false
+}
+
+// fields returns the named fields of the struct type with the name.
+func (s *source) fields(name string) ([]field, error) {
+	var spec *ast.TypeSpec
+
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			spec = ts
+		}
+
+		return spec == nil
+	})
+
+	if spec == nil {
+		return nil, fmt.Errorf("type %s is not found in %s", name, s.path)
+	}
+
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	if spec.TypeParams != nil && len(spec.TypeParams.List) != 0 {
+		return nil, fmt.Errorf("type %s is generic, generic structs are not supported", name)
+	}
+
+	pkg, _ := s.optionalName()
+
+	var fields []field
+
+	for _, f := range st.Fields.List {
+		elem := optionalElem(f.Type, pkg)
+
+		var tag string
+		if f.Tag != nil {
+			tag = f.Tag.Value
+		}
+
+		for _, n := range f.Names {
+			fields = append(fields, field{name: n.Name, typ: f.Type, elem: elem, tag: tag})
+		}
+	}
+
+	return fields, nil
+}
+
+// optionalElem returns T of the expression optional.Type[T], or nil if the expression is another type.
+func optionalElem(e ast.Expr, pkg string) ast.Expr {
+	if pkg == "" {
+		return nil
+	}
+
+	idx, ok := e.(*ast.IndexExpr)
+	if !ok {
+		return nil
+	}
+
+	sel, ok := idx.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Type" {
+		return nil
+	}
+
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != pkg {
+		return nil
+	}
+
+	return idx.Index
+}
+
+// expr returns the source text of the expression.
+func (s *source) expr(e ast.Expr) string {
+	var buf bytes.Buffer
+
+	_ = printer.Fprint(&buf, s.fset, e)
+
+	return buf.String()
+}
+
+// imports returns the import declarations of the file used by the expressions.
+func (s *source) imports(exprs ...ast.Expr) []string {
+	used := map[string]bool{}
+
+	for _, e := range exprs {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := sel.X.(*ast.Ident); ok {
+					used[x.Name] = true
+				}
+			}
+
+			return true
+		})
+	}
+
+	var decls []string
+
+	for _, spec := range s.file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+
+		name := importName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		if !used[name] {
+			continue
+		}
+
+		if spec.Name != nil {
+			decls = append(decls, spec.Name.Name+" "+spec.Path.Value)
+		} else {
+			decls = append(decls, spec.Path.Value)
+		}
+	}
+
+	sort.Strings(decls)
+
+	return decls
+}
+
+// importName guesses the name of the package from the import path, like goimports does.
+func importName(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	if strings.HasPrefix(name, "v") {
+		if _, err := strconv.Atoi(name[1:]); err == nil && strings.Contains(path, "/") {
+			name = strings.TrimSuffix(path, "/"+name)
+			name = name[strings.LastIndex(name, "/")+1:]
+		}
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+	name, _, _ = strings.Cut(name, ".")
+
+	return name
+}
+
+// header writes the beginning of a generated file.
+func (s *source) header(buf *bytes.Buffer, command string, imports []string) {
+	fmt.Fprintf(buf, "// Code generated by optionalgen %s; DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(buf, "package %s\n", s.file.Name.Name)
+
+	if len(imports) != 0 {
+		buf.WriteString("\nimport (\n")
+
+		// The standard library is grouped apart from the other packages, like goimports does.
+		var std, other []string
+
+		for _, decl := range imports {
+			path := decl[strings.Index(decl, `"`)+1:]
+			if elem, _, _ := strings.Cut(path, "/"); strings.Contains(elem, ".") {
+				other = append(other, decl)
+			} else {
+				std = append(std, decl)
+			}
+		}
+
+		for i, group := range [][]string{std, other} {
+			if i > 0 && len(std) != 0 && len(other) != 0 {
+				buf.WriteString("\n")
+			}
+
+			for _, decl := range group {
+				fmt.Fprintf(buf, "\t%s\n", decl)
+			}
+		}
+
+		buf.WriteString(")\n")
+	}
+}
+
+// outputPath returns the path of the generated file next to the source, unless the output is given.
+func (s *source) outputPath(output, typeName, suffix string) string {
+	if output != "" {
+		return output
+	}
+
+	return filepath.Join(filepath.Dir(s.path), strings.ToLower(typeName)+"_"+suffix+".go")
+}
+
+// writeSource formats the generated code and writes it to the path.
+func writeSource(path string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("format generated code: %w\n%s", err, src)
+	}
+
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// receiverName returns the name of the receiver of the methods of the type.
+func receiverName(typeName string) string {
+	return strings.ToLower(typeName[:1])
+}
@@ -0,0 +1,14 @@
+package example
+
+import (
+	"net/mail"
+	"time"
+)
+
+type Account struct {
+	ID        int64         `json:"id"`
+	Email     *mail.Address `json:"email"`
+	Tags      []string      `json:"tags,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	password  string
+}
@@ -0,0 +1,16 @@
+package example
+
+import (
+	"io"
+	"time"
+
+	opt "github.com/micronull/optional"
+)
+
+type User struct {
+	ID          int
+	Name, Email opt.Type[string]
+	Birthday    opt.Type[time.Time]
+	Tags        opt.Type[[]string] `json:"tags"`
+	Avatar      io.Reader
+}
@@ -0,0 +1,149 @@
+package optional
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Codec is a pair of functions marshalling and unmarshalling values in some data format.
+type Codec struct {
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+}
+
+var contentTypes = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{
+	m: map[string]Codec{},
+}
+
+// RegisterContentType registers the codec for the media type, replacing the previously registered one.
+// The codec is used by [EncodeAs], [DecodeAs] and [DecodeRequest].
+//
+// The application/json media type is handled by [Marshal] and the current unmarshaller unless
+// it is registered explicitly.
+func RegisterContentType(mediaType string, c Codec) {
+	contentTypes.Lock()
+	defer contentTypes.Unlock()
+
+	contentTypes.m[strings.ToLower(mediaType)] = c
+}
+
+// EncodeAs encodes v in the format of the media type.
+// The media type may contain parameters, such as charset, which are ignored.
+func EncodeAs(mediaType string, v any) ([]byte, error) {
+	c, err := lookupContentType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Marshal(v)
+}
+
+// DecodeAs decodes the data in the format of the media type into v.
+// The media type may contain parameters, such as charset, which are ignored.
+func DecodeAs(mediaType string, data []byte, v any) error {
+	c, err := lookupContentType(mediaType)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(data, v)
+}
+
+// lookupContentType returns the codec of the media type. Structured syntax suffixes, such as +json,
+// fall back to the codec of the base format.
+func lookupContentType(mediaType string) (Codec, error) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return Codec{}, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+	}
+
+	contentTypes.RLock()
+	c, ok := contentTypes.m[mt]
+	contentTypes.RUnlock()
+
+	switch {
+	case ok:
+		return c, nil
+	case mt == "application/json":
+		return jsonCodec(), nil
+	}
+
+	if i := strings.LastIndexByte(mt, '+'); i != -1 {
+		return lookupContentType("application/" + mt[i+1:])
+	}
+
+	return Codec{}, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mt)
+}
+
+// jsonCodec returns the codec encoding with [Marshal] and decoding with the current unmarshaller.
+func jsonCodec() Codec {
+	return Codec{
+		Marshal:   func(v any) ([]byte, error) { return Marshal(v) },
+		Unmarshal: func(data []byte, v any) error { return unmarshaller(data, v) },
+	}
+}
+
+// ErrUnsupportedFormat is returned by [MarshalFormat] and [UnmarshalFormat] for the format without a registered codec.
+var ErrUnsupportedFormat = errors.New("optional: unsupported format")
+
+var formats = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{
+	m: map[string]Codec{},
+}
+
+// RegisterFormat registers the codec for the format name, such as "yaml" or "csv", replacing the previously
+// registered one, so the same optional structs can be serialized in the formats chosen at runtime with
+// [MarshalFormat] and [UnmarshalFormat]. The names are case-insensitive.
+//
+// The "json" format is handled by [Marshal] and the current unmarshaller unless it is registered explicitly.
+func RegisterFormat(name string, c Codec) {
+	formats.Lock()
+	defer formats.Unlock()
+
+	formats.m[strings.ToLower(name)] = c
+}
+
+// MarshalFormat encodes v in the format registered with [RegisterFormat].
+func MarshalFormat(format string, v any) ([]byte, error) {
+	c, err := lookupFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Marshal(v)
+}
+
+// UnmarshalFormat decodes the data in the format registered with [RegisterFormat] into v.
+func UnmarshalFormat(format string, data []byte, v any) error {
+	c, err := lookupFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(data, v)
+}
+
+func lookupFormat(name string) (Codec, error) {
+	name = strings.ToLower(name)
+
+	formats.RLock()
+	c, ok := formats.m[name]
+	formats.RUnlock()
+
+	switch {
+	case ok:
+		return c, nil
+	case name == "json":
+		return jsonCodec(), nil
+	}
+
+	return Codec{}, fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+}
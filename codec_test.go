@@ -0,0 +1,153 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestEncodeAs(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+	}
+
+	tests := [...]struct {
+		name      string
+		mediaType string
+		want      string
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{"json", "application/json", `{"name":"some"}`, require.NoError},
+		{"json charset", "application/json; charset=utf-8", `{"name":"some"}`, require.NoError},
+		{"json suffix", "application/problem+json", `{"name":"some"}`, require.NoError},
+		{"unknown", "application/unknown", ``, require.Error},
+		{"invalid", "", ``, require.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := optional.EncodeAs(tt.mediaType, some{Name: optional.Some("some")})
+			tt.wantErr(t, err)
+
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRegisterContentType(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		Name optional.Type[string] `json:"name"`
+	}
+
+	// A fake format marking the JSON payload with a prefix.
+	optional.RegisterContentType("application/x-test", optional.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			b, err := json.Marshal(v)
+
+			return append([]byte("test:"), b...), err
+		},
+		Unmarshal: func(data []byte, v any) error {
+			return json.Unmarshal([]byte(strings.TrimPrefix(string(data), "test:")), v)
+		},
+	})
+
+	b, err := optional.EncodeAs("application/x-test", some{Name: optional.Some("some")})
+	require.NoError(t, err)
+	assert.Equal(t, `test:{"name":"some"}`, string(b))
+
+	var got some
+
+	require.NoError(t, optional.DecodeAs("application/x-test", b, &got))
+	assert.Equal(t, "some", got.Name.V)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`test:{"name":"other"}`))
+	r.Header.Set("Content-Type", "application/x-test")
+
+	got = some{}
+
+	require.NoError(t, optional.DecodeRequest(r, &got))
+	assert.True(t, got.Name.IsSet())
+	assert.Equal(t, "other", got.Name.V)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+	}
+
+	// A fake format writing the set fields as lines of key=value.
+	optional.RegisterFormat("Lines", optional.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			var b strings.Builder
+
+			for _, name := range optional.SetFieldNames(v) {
+				value, _, err := optional.GetPointer(v, "/"+name)
+				if err != nil {
+					return nil, err
+				}
+
+				b.WriteString(name + "=" + value.(string) + "\n")
+			}
+
+			return []byte(b.String()), nil
+		},
+		Unmarshal: func(data []byte, v any) error {
+			for _, line := range strings.Fields(string(data)) {
+				name, value, _ := strings.Cut(line, "=")
+
+				if err := optional.SetPointer(v, "/"+name, value); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+
+	v := some{Name: optional.Some("some")}
+
+	tests := [...]struct {
+		format string
+		want   string
+	}{
+		{"json", `{"name":"some"}`},
+		{"JSON", `{"name":"some"}`},
+		{"lines", "name=some\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := optional.MarshalFormat(tt.format, v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+
+			var got some
+
+			require.NoError(t, optional.UnmarshalFormat(tt.format, b, &got))
+			assert.Equal(t, v, got)
+		})
+	}
+
+	_, err := optional.MarshalFormat("yaml", v)
+	require.ErrorIs(t, err, optional.ErrUnsupportedFormat)
+	require.ErrorIs(t, optional.UnmarshalFormat("yaml", nil, &v), optional.ErrUnsupportedFormat)
+}
@@ -0,0 +1,213 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Column is the column of a field of a slice of structs in the columnar layout of Apache Arrow, such as for
+// the array builders of https://github.com/apache/arrow-go taking the values and their validity.
+type Column struct {
+	Name string // Name is the JSON name of the field.
+	// Values is the slice of the values of the field, []T for the fields of type T, [Type] of T and pointers
+	// to T. The invalid values are zero.
+	Values any
+	// Valid is the validity bitmap, false for the null and the unset [Type] values and nil pointers.
+	Valid []bool
+	// Set is the presence bitmap, false for the unset [Type] values only, so the unset and the null values
+	// are told apart in a column of its own. It is nil for the fields other than [Type].
+	Set []bool
+}
+
+// ToColumns returns the columns of the fields of the slice of structs, or pointers to structs, rows,
+// in the order of the fields.
+func ToColumns(rows any) ([]Column, error) {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice || indirectType(rv.Type().Elem()).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optional: ToColumns expects a slice of structs, got %T", rows)
+	}
+
+	et := indirectType(rv.Type().Elem())
+	fields := jsonFields(et)
+	columns := make([]Column, len(fields))
+
+	for i, f := range fields {
+		ft := et.FieldByIndex(f.index).Type
+		values := reflect.MakeSlice(reflect.SliceOf(columnElem(ft)), rv.Len(), rv.Len())
+
+		c := Column{Name: f.name, Valid: make([]bool, rv.Len())}
+		if isPresenceType(ft) {
+			c.Set = make([]bool, rv.Len())
+		}
+
+		for r := 0; r < rv.Len(); r++ {
+			row := indirect(rv.Index(r))
+			if !row.IsValid() {
+				return nil, fmt.Errorf("optional: ToColumns: row %d is nil", r)
+			}
+
+			fv, ok := fieldByIndex(row, f.index, false)
+			if !ok {
+				continue
+			}
+
+			if isPresenceType(ft) {
+				state := fieldState(fv)
+				c.Set[r] = state != StateUnset
+
+				if state != StateValue {
+					continue
+				}
+
+				fv = presenceValue(fv)
+			}
+
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+
+				fv = fv.Elem()
+			}
+
+			values.Index(r).Set(fv)
+			c.Valid[r] = true
+		}
+
+		c.Values = values.Interface()
+		columns[i] = c
+	}
+
+	return columns, nil
+}
+
+// FromColumns sets the slice of structs pointed to by v to the rows of the columns, such as read from
+// the arrays of an Arrow record batch, matching the columns with the fields by the JSON names. The columns
+// without a matching field are ignored.
+//
+// The invalid values set the [Type] fields to null and the pointers to nil, unless the presence bitmap of
+// the column marks them unset. The values are converted to the types of the fields when possible, such as
+// the int64 values of an Arrow array to int fields, failing on the numbers overflowing the fields and
+// the fractions converted to integers. All the matching columns must have the same number of rows.
+func FromColumns(columns []Column, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice ||
+		indirectType(rv.Elem().Type().Elem()).Kind() != reflect.Struct {
+		return fmt.Errorf("optional: FromColumns expects a non-nil pointer to a slice of structs, got %T", v)
+	}
+
+	sv := rv.Elem()
+	et := sv.Type().Elem()
+	fields := jsonFields(indirectType(et))
+
+	n := -1
+
+	for _, c := range columns {
+		if _, ok := lookupField(fields, c.Name, false); !ok {
+			continue
+		}
+
+		if n < 0 {
+			n = len(c.Valid)
+		}
+
+		values := reflect.ValueOf(c.Values)
+		if values.Kind() != reflect.Slice || values.Len() != n || len(c.Valid) != n || c.Set != nil && len(c.Set) != n {
+			return fmt.Errorf("optional: FromColumns: column %q must have %d values", c.Name, n)
+		}
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	rows := reflect.MakeSlice(sv.Type(), n, n)
+
+	for r := 0; r < n; r++ {
+		if et.Kind() == reflect.Ptr {
+			rows.Index(r).Set(reflect.New(et.Elem()))
+		}
+	}
+
+	for _, c := range columns {
+		f, ok := lookupField(fields, c.Name, false)
+		if !ok {
+			continue
+		}
+
+		values := reflect.ValueOf(c.Values)
+		ft := indirectType(et).FieldByIndex(f.index).Type
+		elem := columnElem(ft)
+
+		if !columnConvertible(values.Type().Elem(), elem) {
+			return fmt.Errorf("optional: FromColumns: column %q holds %s, expected []%s", c.Name, values.Type(), elem)
+		}
+
+		numeric := isNumber(values.Type().Elem().Kind()) && isNumber(elem.Kind())
+
+		for r := 0; r < n; r++ {
+			fv, _ := fieldByIndex(indirect(rows.Index(r)), f.index, true)
+
+			switch {
+			case c.Set != nil && !c.Set[r]:
+			case !c.Valid[r] && isPresenceType(ft):
+				a, _ := asAccessor(fv)
+				a.mark(true, true)
+			case !c.Valid[r]:
+				fv.Set(reflect.Zero(ft))
+			default:
+				val := values.Index(r)
+
+				if numeric {
+					var err error
+
+					if val, err = convertNumber(val, elem); err != nil {
+						return fmt.Errorf("optional: FromColumns: column %q, row %d: %w", c.Name, r, err)
+					}
+				} else {
+					val = val.Convert(elem)
+				}
+
+				if isPresenceType(ft) {
+					a, _ := asAccessor(fv)
+					a.mark(true, false)
+					fv = presenceValue(fv)
+				}
+
+				if fv.Kind() == reflect.Ptr {
+					fv.Set(reflect.New(elem))
+					fv = fv.Elem()
+				}
+
+				fv.Set(val)
+			}
+		}
+	}
+
+	sv.Set(rows)
+
+	return nil
+}
+
+// columnElem returns the type of the values of the column of the field of type t.
+func columnElem(t reflect.Type) reflect.Type {
+	if isPresenceType(t) {
+		t = optionalElem(t)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// columnConvertible reports whether the values of the type from are converted to the type to, unlike Go
+// converting the integers to the strings of the runes.
+func columnConvertible(from, to reflect.Type) bool {
+	if to.Kind() == reflect.String && from.Kind() != reflect.String {
+		return false
+	}
+
+	return from.ConvertibleTo(to)
+}
@@ -0,0 +1,167 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type columnOrder struct {
+	ID       int                       `json:"id"`
+	Customer optional.Type[string]     `json:"customer"`
+	Total    optional.Tracked[float64] `json:"total"`
+	Note     *string                   `json:"note"`
+	PaidAt   optional.Type[time.Time]  `json:"paid_at"`
+	Ignored  string                    `json:"-"`
+}
+
+func TestToColumns(t *testing.T) {
+	t.Parallel()
+
+	note := "gift"
+	paid := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	var total optional.Tracked[float64]
+	total.SetValue(9.5)
+
+	orders := []*columnOrder{
+		{ID: 1, Customer: optional.Some("John"), Total: total, Note: &note, PaidAt: optional.Some(paid)},
+		{ID: 2, Customer: optional.Null[string]()},
+	}
+
+	columns, err := optional.ToColumns(orders)
+	require.NoError(t, err)
+
+	assert.Equal(t, []optional.Column{
+		{Name: "id", Values: []int{1, 2}, Valid: []bool{true, true}},
+		{Name: "customer", Values: []string{"John", ""}, Valid: []bool{true, false}, Set: []bool{true, true}},
+		{Name: "total", Values: []float64{9.5, 0}, Valid: []bool{true, false}, Set: []bool{true, false}},
+		{Name: "note", Values: []string{"gift", ""}, Valid: []bool{true, false}},
+		{Name: "paid_at", Values: []time.Time{paid, {}}, Valid: []bool{true, false}, Set: []bool{true, false}},
+	}, columns)
+
+	var got []*columnOrder
+
+	require.NoError(t, optional.FromColumns(columns, &got))
+	assert.Equal(t, orders, got)
+}
+
+func TestFromColumns(t *testing.T) {
+	t.Parallel()
+
+	// The columns as read from the arrays of an Arrow record batch without the presence bitmaps.
+	columns := []optional.Column{
+		{Name: "id", Values: []int64{1, 2}, Valid: []bool{true, true}},
+		{Name: "customer", Values: []string{"John", ""}, Valid: []bool{true, false}},
+		{Name: "note", Values: []string{"", ""}, Valid: []bool{false, false}},
+		{Name: "extra", Values: []bool{true, false}, Valid: []bool{true, true}},
+	}
+
+	var got []columnOrder
+
+	require.NoError(t, optional.FromColumns(columns, &got))
+
+	assert.Equal(t, []columnOrder{
+		{ID: 1, Customer: optional.Some("John")},
+		{ID: 2, Customer: optional.Null[string]()},
+	}, got)
+
+	require.NoError(t, optional.FromColumns(nil, &got))
+	assert.Empty(t, got)
+}
+
+func TestColumns_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.ToColumns([]int{1})
+	require.EqualError(t, err, "optional: ToColumns expects a slice of structs, got []int")
+
+	_, err = optional.ToColumns([]*columnOrder{nil})
+	require.EqualError(t, err, "optional: ToColumns: row 0 is nil")
+
+	var got []columnOrder
+
+	err = optional.FromColumns(nil, got)
+	require.EqualError(t, err,
+		"optional: FromColumns expects a non-nil pointer to a slice of structs, got []optional_test.columnOrder")
+
+	err = optional.FromColumns([]optional.Column{
+		{Name: "id", Values: []int{1}, Valid: []bool{true}},
+		{Name: "customer", Values: []string{}, Valid: []bool{true}},
+	}, &got)
+	require.EqualError(t, err, `optional: FromColumns: column "customer" must have 1 values`)
+
+	err = optional.FromColumns([]optional.Column{{Name: "customer", Values: []int{1}, Valid: []bool{true}}}, &got)
+	require.EqualError(t, err, `optional: FromColumns: column "customer" holds []int, expected []string`)
+
+	err = optional.FromColumns([]optional.Column{
+		{Name: "extra", Values: []int{1, 2, 3}, Valid: []bool{true, true, true}},
+		{Name: "id", Values: []int{1}, Valid: []bool{true}},
+		{Name: "customer", Values: []string{"John", "Jane"}, Valid: []bool{true, true}},
+	}, &got)
+	require.EqualError(t, err, `optional: FromColumns: column "customer" must have 1 values`)
+}
+
+func TestFromColumns_Numbers(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Small optional.Type[int8] `json:"small"`
+		Count *uint16             `json:"count"`
+		Ratio float32             `json:"ratio"`
+	}
+
+	tests := []struct {
+		name    string
+		columns []optional.Column
+		err     string
+	}{
+		{
+			name:    "int overflow",
+			columns: []optional.Column{{Name: "small", Values: []int64{1, 300}, Valid: []bool{true, true}}},
+			err:     `optional: FromColumns: column "small", row 1: value 300 overflows int8`,
+		},
+		{
+			name:    "negative to unsigned",
+			columns: []optional.Column{{Name: "count", Values: []int64{-1}, Valid: []bool{true}}},
+			err:     `optional: FromColumns: column "count", row 0: value -1 overflows uint16`,
+		},
+		{
+			name:    "fraction",
+			columns: []optional.Column{{Name: "small", Values: []float64{1.5}, Valid: []bool{true}}},
+			err:     `optional: FromColumns: column "small", row 0: value 1.5 is not an integer, cannot convert to int8`,
+		},
+		{
+			name:    "float overflow",
+			columns: []optional.Column{{Name: "ratio", Values: []float64{1e39}, Valid: []bool{true}}},
+			err:     `optional: FromColumns: column "ratio", row 0: value 1e+39 overflows float32`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []row
+
+			require.EqualError(t, optional.FromColumns(tt.columns, &got), tt.err)
+		})
+	}
+
+	var got []row
+
+	require.NoError(t, optional.FromColumns([]optional.Column{
+		{Name: "small", Values: []int64{-128}, Valid: []bool{true}},
+		{Name: "count", Values: []float64{65535}, Valid: []bool{true}},
+		{Name: "ratio", Values: []float64{0.5}, Valid: []bool{true}},
+	}, &got))
+
+	count := uint16(65535)
+	assert.Equal(t, []row{{Small: optional.Some(int8(-128)), Count: &count, Ratio: 0.5}}, got)
+}
@@ -0,0 +1,291 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BreakingChange is a change of a schema rejecting the documents accepted by its previous version.
+type BreakingChange struct {
+	// Path is the dotted path of the JSON names of the changed field, such as "address.city", "tags[]"
+	// for the elements of arrays and "labels.*" for the values of maps. It is empty for the document itself.
+	Path    string
+	Message string
+}
+
+func (c BreakingChange) String() string {
+	if c.Path == "" {
+		return c.Message
+	}
+
+	return fmt.Sprintf("field %q %s", c.Path, c.Message)
+}
+
+// CheckCompatibility compares the old and the new versions of a struct, such as the request types of two versions
+// of an API, by their [JSONSchema] and reports the breaking changes like [CompareSchemas] does.
+func CheckCompatibility(old, new any) ([]BreakingChange, error) {
+	o, err := JSONSchema(old)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := JSONSchema(new)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareSchemas(o, n)
+}
+
+// CompareSchemas compares the old and the new versions of a JSON Schema, such as the ones generated by [JSONSchema]
+// and kept in the repository, and reports the changes of the new version rejecting the documents accepted by
+// the old one, sorted by the paths: the fields that became required, the fields that are no longer nullable,
+// the changed types and the raised minimums. The references to "$defs" are followed, the removed
+// and the added optional fields are compatible.
+//
+// It is meant for the CI gates on the compatibility of APIs, an empty result means the new version is compatible.
+func CompareSchemas(old, new []byte) ([]BreakingChange, error) {
+	var o, n map[string]any
+
+	if err := json.Unmarshal(old, &o); err != nil {
+		return nil, fmt.Errorf("optional: old schema: %w", err)
+	}
+
+	if err := json.Unmarshal(new, &n); err != nil {
+		return nil, fmt.Errorf("optional: new schema: %w", err)
+	}
+
+	c := &schemaComparer{oldRoot: o, newRoot: n, seen: map[[2]string]bool{}}
+	c.compare("", o, n)
+
+	sort.SliceStable(c.changes, func(i, j int) bool { return c.changes[i].Path < c.changes[j].Path })
+
+	return c.changes, nil
+}
+
+// schemaComparer compares the schemas of the old and the new documents.
+type schemaComparer struct {
+	oldRoot, newRoot map[string]any
+	seen             map[[2]string]bool // seen holds the pairs of the references being compared, for recursive schemas.
+	changes          []BreakingChange
+}
+
+func (c *schemaComparer) report(path, format string, args ...any) {
+	c.changes = append(c.changes, BreakingChange{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *schemaComparer) compare(path string, o, n map[string]any) {
+	o, onull := splitNullable(o)
+	n, nnull := splitNullable(n)
+
+	if onull && !nnull {
+		c.report(path, "is no longer nullable")
+	}
+
+	oref, _ := o["$ref"].(string)
+	nref, _ := n["$ref"].(string)
+
+	if oref != "" || nref != "" {
+		key := [2]string{oref, nref}
+		if c.seen[key] {
+			return
+		}
+
+		c.seen[key] = true
+		defer delete(c.seen, key)
+
+		o, n = resolveRef(c.oldRoot, o), resolveRef(c.newRoot, n)
+	}
+
+	otypes, ntypes := schemaTypes(o), schemaTypes(n)
+
+	if !typesAccepted(otypes, ntypes) {
+		c.report(path, "changed type from %s to %s", typesString(otypes), typesString(ntypes))
+
+		return
+	}
+
+	if nmin, ok := n["minimum"].(float64); ok {
+		if omin, ok := o["minimum"].(float64); !ok || omin < nmin {
+			c.report(path, "raised minimum to %v", nmin)
+		}
+	}
+
+	if items, ok := n["items"].(map[string]any); ok {
+		if oitems, ok := o["items"].(map[string]any); ok {
+			c.compare(path+"[]", oitems, items)
+		}
+	}
+
+	if values, ok := n["additionalProperties"].(map[string]any); ok {
+		if ovalues, ok := o["additionalProperties"].(map[string]any); ok {
+			c.compare(joinPath(path, "*"), ovalues, values)
+		}
+	}
+
+	orequired := stringSet(o["required"])
+
+	for name := range stringSet(n["required"]) {
+		if !orequired[name] {
+			c.report(joinPath(path, name), "became required")
+		}
+	}
+
+	oprops, _ := o["properties"].(map[string]any)
+	nprops, _ := n["properties"].(map[string]any)
+
+	for name, np := range nprops {
+		ops, _ := oprops[name].(map[string]any)
+		nps, _ := np.(map[string]any)
+
+		if ops != nil && nps != nil {
+			c.compare(joinPath(path, name), ops, nps)
+		}
+	}
+}
+
+// resolveRef returns the schema referenced by the schema s within the root document, or s if it is not a reference.
+func resolveRef(root, s map[string]any) map[string]any {
+	ref, ok := s["$ref"].(string)
+	if !ok {
+		return s
+	}
+
+	if ref == "#" {
+		return root
+	}
+
+	var defs map[string]any
+
+	switch {
+	case strings.HasPrefix(ref, "#/$defs/"):
+		defs, _ = root["$defs"].(map[string]any)
+	case strings.HasPrefix(ref, "#/components/schemas/"):
+		components, _ := root["components"].(map[string]any)
+		defs, _ = components["schemas"].(map[string]any)
+	}
+
+	if d, ok := defs[ref[strings.LastIndexByte(ref, '/')+1:]].(map[string]any); ok {
+		return d
+	}
+
+	return s
+}
+
+// splitNullable returns the schema s without its null alternative and whether it has one.
+func splitNullable(s map[string]any) (map[string]any, bool) {
+	if anyOf, ok := s["anyOf"].([]any); ok && len(anyOf) == 2 {
+		for i, alt := range anyOf {
+			if a, ok := alt.(map[string]any); ok && a["type"] == "null" {
+				base, _ := anyOf[1-i].(map[string]any)
+
+				return base, true
+			}
+		}
+	}
+
+	types, ok := s["type"].([]any)
+	if !ok {
+		return s, s["type"] == "null"
+	}
+
+	rest := make([]any, 0, len(types))
+	for _, t := range types {
+		if t != "null" {
+			rest = append(rest, t)
+		}
+	}
+
+	if len(rest) == len(types) {
+		return s, false
+	}
+
+	base := make(map[string]any, len(s))
+	for k, v := range s {
+		base[k] = v
+	}
+
+	base["type"] = rest
+	if len(rest) == 1 {
+		base["type"] = rest[0]
+	}
+
+	return base, true
+}
+
+// schemaTypes returns the types of the schema s other than null, none for the schemas accepting any value.
+func schemaTypes(s map[string]any) []string {
+	switch t := s["type"].(type) {
+	case string:
+		if t != "null" {
+			return []string{t}
+		}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if v, ok := v.(string); ok && v != "null" {
+				types = append(types, v)
+			}
+		}
+
+		return types
+	}
+
+	if _, ok := s["properties"]; ok {
+		return []string{"object"}
+	}
+
+	return nil
+}
+
+// typesAccepted reports whether the values of the old types are accepted by the new types.
+func typesAccepted(old, new []string) bool {
+	if len(new) == 0 {
+		return true
+	}
+
+	if len(old) == 0 {
+		return false
+	}
+
+	for _, o := range old {
+		accepted := false
+
+		for _, n := range new {
+			if n == o || n == "number" && o == "integer" {
+				accepted = true
+
+				break
+			}
+		}
+
+		if !accepted {
+			return false
+		}
+	}
+
+	return true
+}
+
+func typesString(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+
+	return strings.Join(types, " or ")
+}
+
+func stringSet(v any) map[string]bool {
+	set := map[string]bool{}
+
+	list, _ := v.([]any)
+	for _, s := range list {
+		if s, ok := s.(string); ok {
+			set[s] = true
+		}
+	}
+
+	return set
+}
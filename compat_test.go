@@ -0,0 +1,144 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type compatAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type compatNode struct {
+	Value    int           `json:"value"`
+	Children []*compatNode `json:"children,omitempty"`
+}
+
+type compatUserV1 struct {
+	ID      int                          `json:"id"`
+	Name    optional.Type[string]        `json:"name"`
+	Email   optional.Type[string]        `json:"email"`
+	Age     optional.Type[int]           `json:"age"`
+	Score   int                          `json:"score"`
+	Tags    []string                     `json:"tags"`
+	Address optional.Type[compatAddress] `json:"address"`
+	Billing *compatAddress               `json:"billing"`
+	Tree    compatNode                   `json:"tree"`
+	Removed string                       `json:"removed"`
+}
+
+type compatAddressV2 struct {
+	City string `json:"city"`
+}
+
+type compatUserV2 struct {
+	ID      int                          `json:"id"`
+	Name    string                       `json:"name"`
+	Email   optional.Type[string]        `json:"email"`
+	Age     optional.Type[string]        `json:"age"`
+	Score   uint                         `json:"score"`
+	Tags    []int                        `json:"tags"`
+	Address optional.Type[compatAddress] `json:"address"`
+	Billing *compatAddressV2             `json:"billing"`
+	Tree    compatNode                   `json:"tree"`
+	Added   optional.Type[time.Time]     `json:"added"`
+	Ratio   optional.Type[float64]       `json:"ratio"`
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+
+	changes, err := optional.CheckCompatibility(compatUserV1{}, compatUserV2{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []optional.BreakingChange{
+		{Path: "age", Message: "changed type from integer to string"},
+		{Path: "billing.city", Message: "became required"},
+		{Path: "billing.city", Message: "is no longer nullable"},
+		{Path: "name", Message: "became required"},
+		{Path: "name", Message: "is no longer nullable"},
+		{Path: "score", Message: "raised minimum to 0"},
+		{Path: "tags[]", Message: "changed type from string to integer"},
+	}, changes)
+
+	assert.Equal(t, `field "name" became required`, changes[3].String())
+
+	changes, err = optional.CheckCompatibility(compatUserV1{}, compatUserV1{})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestCompareSchemas(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		old  string
+		new  string
+		want []optional.BreakingChange
+	}{
+		{
+			"widened",
+			`{"type":"object","properties":{"n":{"type":"integer"},"id":{"type":"string"}},"required":["id"]}`,
+			`{"type":"object","properties":{"n":{"type":["number","null"]}}}`,
+			nil,
+		},
+		{
+			"document",
+			`{"type":["object","null"]}`,
+			`{"type":"array"}`,
+			[]optional.BreakingChange{
+				{Message: "is no longer nullable"},
+				{Message: "changed type from object to array"},
+			},
+		},
+		{
+			"maps",
+			`{"type":"object","additionalProperties":{"type":["string","null"]}}`,
+			`{"type":"object","additionalProperties":{"type":"string"}}`,
+			[]optional.BreakingChange{{Path: "*", Message: "is no longer nullable"}},
+		},
+		{
+			"components",
+			`{"$ref":"#/components/schemas/User","components":{"schemas":{"User":{"type":"object"}}}}`,
+			`{"$ref":"#/components/schemas/User","components":{"schemas":{"User":{"type":"object","required":["id"]}}}}`,
+			[]optional.BreakingChange{{Path: "id", Message: "became required"}},
+		},
+		{
+			"any",
+			`{}`,
+			`{"type":"string"}`,
+			[]optional.BreakingChange{{Message: "changed type from any to string"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := optional.CompareSchemas([]byte(tt.old), []byte(tt.new))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompareSchemas_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.CompareSchemas([]byte(`{`), []byte(`{}`))
+	require.ErrorContains(t, err, "optional: old schema: ")
+
+	_, err = optional.CompareSchemas([]byte(`{}`), []byte(`[]`))
+	require.ErrorContains(t, err, "optional: new schema: ")
+
+	_, err = optional.CheckCompatibility(1, compatUserV1{})
+	require.EqualError(t, err, "optional: JSONSchema expects a struct, got int")
+}
@@ -0,0 +1,57 @@
+package optional
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+type codecKey struct{}
+
+// ContextWithCodec returns the copy of ctx carrying the codec used by [DecodeRequest] and [DecodePatch] to decode
+// the values of JSON bodies of the requests with the context, and by [Decoder] with [WithContext], instead of
+// the current unmarshaller. It allows multi-tenant gateways to apply tenant-specific decoding rules, such as
+// date formats or leniency, per request without changing the global state.
+//
+// The codec decodes the values of the fields, such as the inner values of [Type] fields, while the presence
+// is handled as usual, so it does not need to know about the optional values. Only Unmarshal of the codec is used.
+func ContextWithCodec(ctx context.Context, c Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, c)
+}
+
+// CodecFromContext returns the codec set by [ContextWithCodec].
+func CodecFromContext(ctx context.Context) (Codec, bool) {
+	c, ok := ctx.Value(codecKey{}).(Codec)
+
+	return c, ok
+}
+
+// WithContext makes [Decoder] and [LinesDecoder] decode the values with the codec set on ctx by [ContextWithCodec],
+// if any.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		if c, ok := CodecFromContext(ctx); ok {
+			o.codec = &c
+		}
+	}
+}
+
+// unmarshalValue decodes the raw value into v with the codec of the options or the current unmarshaller.
+func unmarshalValue(raw json.RawMessage, v reflect.Value, o options) error {
+	if o.codec != nil {
+		return decodeWithCodec(raw, v, *o.codec)
+	}
+
+	return unmarshaller(raw, v.Addr().Interface())
+}
+
+// codecUnmarshal returns the function decoding the documents with [Decoder] using the codec of the options.
+// The values are normalized by the caller.
+func codecUnmarshal(o options) func(data []byte, v any) error {
+	o.normalize = false
+
+	return func(data []byte, v any) error {
+		return (&Decoder{dec: json.NewDecoder(bytes.NewReader(data)), o: o}).Decode(v)
+	}
+}
@@ -0,0 +1,102 @@
+package optional_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// europeanDates decodes the times in the "02.01.2006" format and everything else like encoding/json.
+var europeanDates = optional.Codec{
+	Unmarshal: func(data []byte, v any) error {
+		if p, ok := v.(*time.Time); ok {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+
+			t, err := time.Parse("02.01.2006", s)
+			*p = t
+
+			return err
+		}
+
+		return json.Unmarshal(data, v)
+	},
+}
+
+type tenantOrder struct {
+	ID      int                      `json:"id"`
+	Due     optional.Type[time.Time] `json:"due"`
+	Shipped time.Time                `json:"shipped"`
+	Note    optional.Type[string]    `json:"note"`
+}
+
+func TestContextWithCodec(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"id":1,"due":"31.12.2024","shipped":"01.02.2024","note":null}`
+
+	want := tenantOrder{
+		ID:      1,
+		Due:     optional.Some(time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)),
+		Shipped: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Note:    optional.Null[string](),
+	}
+
+	ctx := optional.ContextWithCodec(context.Background(), europeanDates)
+
+	c, ok := optional.CodecFromContext(ctx)
+	require.True(t, ok)
+	assert.NotNil(t, c.Unmarshal)
+
+	t.Run("request", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(ctx)
+
+		var got tenantOrder
+
+		require.NoError(t, optional.DecodeRequest(r, &got))
+		assert.Equal(t, want, got)
+
+		r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		require.Error(t, optional.DecodeRequest(r, &tenantOrder{}))
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"due":"01.01.2025","note":"x"}`)).
+			WithContext(ctx)
+
+		got, changed, err := optional.DecodePatch(r, want)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"due", "note"}, changed)
+		assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), got.(tenantOrder).Due.V)
+		assert.Equal(t, optional.Some("x"), got.(tenantOrder).Note)
+	})
+
+	t.Run("decoder", func(t *testing.T) {
+		t.Parallel()
+
+		var got tenantOrder
+
+		require.NoError(t, optional.NewDecoder(strings.NewReader(body), optional.WithContext(ctx)).Decode(&got))
+		assert.Equal(t, want, got)
+
+		dec := optional.NewDecoder(strings.NewReader(body), optional.WithContext(context.Background()))
+		require.Error(t, dec.Decode(&tenantOrder{}))
+	})
+}
@@ -0,0 +1,143 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// assign stores the generic value src, such as decoded by a third-party library, into the addressable dst.
+// Values of other types are converted when possible, composite values are converted through JSON.
+func assign(dst reflect.Value, src any) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+
+	switch {
+	case sv.Type().AssignableTo(dst.Type()):
+		dst.Set(sv)
+
+		return nil
+	case isNumber(sv.Kind()) && isNumber(dst.Kind()):
+		nv, err := convertNumber(sv, dst.Type())
+		if err != nil {
+			return fmt.Errorf("optional: %w", err)
+		}
+
+		dst.Set(nv)
+
+		return nil
+	case sv.Kind() == dst.Kind() && sv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(sv.Convert(dst.Type()))
+
+		return nil
+	case dst.Kind() == reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		return assign(dst.Elem(), src)
+	}
+
+	if n, ok := src.(json.Number); ok && isNumber(dst.Kind()) {
+		return unmarshaller([]byte(n), dst.Addr().Interface())
+	}
+
+	b, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("optional: cannot convert %T to %s: %w", src, dst.Type(), err)
+	}
+
+	if err := unmarshaller(b, dst.Addr().Interface()); err != nil {
+		return fmt.Errorf("optional: cannot convert %T to %s: %w", src, dst.Type(), err)
+	}
+
+	return nil
+}
+
+// convertNumber converts the number v to the numeric type t, failing instead of truncating the values
+// out of the range of t and the values with fractions converted to integers.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	nv := reflect.New(t).Elem()
+
+	switch {
+	case isInteger(v.Kind()) && isInteger(t.Kind()):
+		if !integerFits(v, nv) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+		}
+	case isInteger(t.Kind()):
+		f := v.Float()
+		if f != math.Trunc(f) {
+			return reflect.Value{}, fmt.Errorf("value %v is not an integer, cannot convert to %s", v, t)
+		}
+
+		// 2^63 and 2^64 are exact in float64, the values below them convert to the integers exactly.
+		if f < -(1<<63) || f >= 1<<64 || isSigned(t.Kind()) && f >= 1<<63 ||
+			!integerFits(reflect.ValueOf(f).Convert(integerOf(f)), nv) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+		}
+	case t.Kind() == reflect.Float32 && v.Kind() == reflect.Float64:
+		if f := v.Float(); !math.IsInf(f, 0) && nv.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+		}
+	}
+
+	return v.Convert(t), nil
+}
+
+// integerFits reports whether the integer v fits into the integer value dst.
+func integerFits(v, dst reflect.Value) bool {
+	if isSigned(v.Kind()) {
+		x := v.Int()
+		if isSigned(dst.Kind()) {
+			return !dst.OverflowInt(x)
+		}
+
+		return x >= 0 && !dst.OverflowUint(uint64(x))
+	}
+
+	x := v.Uint()
+	if isSigned(dst.Kind()) {
+		return x <= math.MaxInt64 && !dst.OverflowInt(int64(x))
+	}
+
+	return !dst.OverflowUint(x)
+}
+
+// integerOf returns the integer type holding the whole float f within the range of uint64.
+func integerOf(f float64) reflect.Type {
+	if f < 0 {
+		return reflect.TypeOf(int64(0))
+	}
+
+	return reflect.TypeOf(uint64(0))
+}
+
+func isInteger(k reflect.Kind) bool {
+	return isNumber(k) && k != reflect.Float32 && k != reflect.Float64
+}
+
+func isSigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
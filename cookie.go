@@ -0,0 +1,33 @@
+package optional
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// DecodeCookies decodes the request cookies into the fields of the struct pointed to by v
+// with the `cookie` tag, such as `cookie:"consent"`.
+//
+// Fields of absent cookies are left untouched, so [Type] fields stay unset.
+// Cookies with an empty value are handled according to [WithEmpty].
+func DecodeCookies(r *http.Request, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeCookies expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return decodeFields(cookieValues(r), nil, rv.Elem(), "cookie", newOptions(opts))
+}
+
+// cookieValues returns the lookup of the values of the request cookies by name.
+func cookieValues(r *http.Request) func(name string) []string {
+	return func(name string) []string {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return nil
+		}
+
+		return []string{c.Value}
+	}
+}
@@ -0,0 +1,48 @@
+package optional_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecodeCookies(t *testing.T) {
+	t.Parallel()
+
+	type cookies struct {
+		Consent optional.Type[bool]   `cookie:"consent"`
+		Theme   optional.Type[string] `cookie:"theme"`
+		Lang    optional.Type[string] `cookie:"lang"`
+	}
+
+	tests := [...]struct {
+		name  string
+		mode  optional.EmptyMode
+		theme optional.Type[string]
+	}{
+		{"empty value", optional.EmptyValue, optional.Some("")},
+		{"empty null", optional.EmptyNull, optional.Null[string]()},
+		{"empty unset", optional.EmptyUnset, optional.Type[string]{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: "consent", Value: "true"})
+			r.AddCookie(&http.Cookie{Name: "theme", Value: ""})
+
+			var got cookies
+
+			require.NoError(t, optional.DecodeCookies(r, &got, optional.WithEmpty(tt.mode)))
+
+			assert.Equal(t, optional.Some(true), got.Consent)
+			assert.Equal(t, tt.theme, got.Theme)
+			assert.False(t, got.Lang.IsSet())
+		})
+	}
+}
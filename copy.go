@@ -0,0 +1,219 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// converterKey is the pair of the source and destination types of a converter set by [WithConverter].
+type converterKey struct {
+	src, dst reflect.Type
+}
+
+// WithConverter sets the function converting the values of S into the values of D for [CopyTo] and [CopyFrom],
+// such as the IDs of the domain into the strings of the API.
+func WithConverter[S, D any](fn func(S) (D, error)) Option {
+	return func(o *options) {
+		if o.converters == nil {
+			o.converters = map[converterKey]reflect.Value{}
+		}
+
+		key := converterKey{reflect.TypeOf((*S)(nil)).Elem(), reflect.TypeOf((*D)(nil)).Elem()}
+		o.converters[key] = reflect.ValueOf(fn)
+	}
+}
+
+// CopyTo copies the fields of the struct src with [Type] fields, such as an API request, onto the struct
+// pointed to by dst with plain fields, such as a domain entity, applying only the set fields: the unset fields
+// leave the fields of dst untouched and the fields set to null reset them to zero.
+//
+// The fields are matched by the Go names, falling back to the names from the `json` tags, the fields without
+// a match are skipped. Nested structs and the elements of slices are copied field by field, other values
+// are converted by the functions set by [WithConverter], to the types of the same kind or to numbers
+// of other sizes, composite values are converted through JSON.
+func CopyTo(src, dst any, opts ...Option) error {
+	return copyStructs("CopyTo", dst, src, opts)
+}
+
+// CopyFrom copies the fields of the struct src with plain fields, such as a domain entity, onto the struct
+// pointed to by dst with [Type] fields, such as an API response, wrapping all the values: the [Type] fields
+// are set to the values, or to null for nil pointers, slices and maps. The fields are matched and the values
+// are converted like [CopyTo] does.
+func CopyFrom(dst, src any, opts ...Option) error {
+	return copyStructs("CopyFrom", dst, src, opts)
+}
+
+func copyStructs(name string, dst, src any, opts []Option) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: %s expects a non-nil pointer to a struct destination, got %T", name, dst)
+	}
+
+	sv := derefPointer(reflect.ValueOf(src))
+	if sv.Kind() != reflect.Struct || isPresenceType(sv.Type()) {
+		return fmt.Errorf("optional: %s expects a struct source, got %T", name, src)
+	}
+
+	o := newOptions(opts)
+
+	return copyStruct(dv.Elem(), sv, "", &o)
+}
+
+// copyStruct copies the fields of the struct src onto the matching fields of the addressable struct dst.
+func copyStruct(dst, src reflect.Value, prefix string, o *options) error {
+	st := src.Type()
+	dfields := jsonFields(dst.Type())
+
+	for _, f := range jsonFields(st) {
+		sf, ok := fieldByIndex(src, f.index, false)
+		if !ok {
+			continue
+		}
+
+		index, ok := copyTarget(dst.Type(), dfields, st.FieldByIndex(f.index).Name, f.name)
+		if !ok {
+			continue
+		}
+
+		df, _ := fieldByIndex(dst, index, true)
+		path := joinPath(prefix, f.name)
+
+		if err := copyField(df, sf, path, o); err != nil {
+			return fmt.Errorf("optional: field %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// copyTarget returns the index of the field of the struct type t matching the Go name or the JSON name.
+func copyTarget(t reflect.Type, fields []jsonField, goName, name string) ([]int, bool) {
+	if f, ok := t.FieldByName(goName); ok && f.IsExported() {
+		return f.Index, true
+	}
+
+	for _, f := range fields {
+		if f.name == name {
+			return f.index, true
+		}
+	}
+
+	return nil, false
+}
+
+// copyField copies the field src onto the field dst, either or both of them may be [Type] or [Tracked].
+func copyField(dst, src reflect.Value, path string, o *options) error {
+	if isPresenceType(src.Type()) {
+		switch fieldState(src) {
+		case StateUnset:
+			return nil
+		case StateNull:
+			return copyNull(dst)
+		}
+
+		src = presenceValue(src)
+	} else if isNilValue(src) {
+		return copyNull(dst)
+	}
+
+	if a, ok := asAccessor(dst); ok && isPresenceType(dst.Type()) {
+		// The struct values are copied onto the current value, like the nested patches are applied.
+		if fieldState(dst) != StateValue {
+			a.value().Set(reflect.Zero(a.value().Type()))
+		}
+
+		if err := copyValue(a.value(), src, path, o); err != nil {
+			return err
+		}
+
+		a.mark(true, false)
+
+		return nil
+	}
+
+	return copyValue(dst, src, path, o)
+}
+
+// copyNull sets the [Type] dst to null, or other dst to zero.
+func copyNull(dst reflect.Value) error {
+	if a, ok := asAccessor(dst); ok && isPresenceType(dst.Type()) {
+		a.mark(true, true)
+
+		return nil
+	}
+
+	dst.Set(reflect.Zero(dst.Type()))
+
+	return nil
+}
+
+// copyValue converts the value src into the addressable dst.
+func copyValue(dst, src reflect.Value, path string, o *options) error {
+	if fn, ok := o.converters[converterKey{src.Type(), dst.Type()}]; ok {
+		out := fn.Call([]reflect.Value{src})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return err
+		}
+
+		dst.Set(out[0])
+
+		return nil
+	}
+
+	if isPresenceType(dst.Type()) || isPresenceType(src.Type()) {
+		return copyField(dst, src, path, o)
+	}
+
+	switch {
+	case src.Type().AssignableTo(dst.Type()) && !hasPresence(src.Type()) && !hasPresence(dst.Type()):
+		dst.Set(src)
+
+		return nil
+	case src.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+
+			return nil
+		}
+
+		return copyValue(dst, src.Elem(), path, o)
+	case dst.Kind() == reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		return copyValue(dst.Elem(), src, path, o)
+	case isPlainStruct(src.Type()) && isPlainStruct(dst.Type()):
+		return copyStruct(dst, src, path, o)
+	case src.Kind() == reflect.Slice && dst.Kind() == reflect.Slice && isPlainStruct(indirectType(src.Type().Elem())):
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+
+			return nil
+		}
+
+		s := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+
+		for i := 0; i < src.Len(); i++ {
+			if err := copyValue(s.Index(i), src.Index(i), fmt.Sprintf("%s.%d", path, i), o); err != nil {
+				return err
+			}
+		}
+
+		dst.Set(s)
+
+		return nil
+	}
+
+	return assign(dst, src.Interface())
+}
+
+// isNilValue reports whether v is a nil pointer, slice, map or interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
@@ -0,0 +1,128 @@
+package optional_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type copyAddress struct {
+	City   string
+	Street string
+}
+
+type copyItem struct {
+	SKU string
+	Qty int
+}
+
+type copyEntity struct {
+	ID      int64
+	Name    string
+	Email   *string
+	Age     int
+	Tags    []string
+	Address copyAddress
+	Items   []copyItem
+	Secret  string
+}
+
+type copyAddressDTO struct {
+	City   optional.Type[string] `json:"city"`
+	Street optional.Type[string] `json:"street"`
+}
+
+type copyItemDTO struct {
+	SKU optional.Type[string] `json:"sku"`
+	Qty optional.Type[int32]  `json:"qty"`
+}
+
+type copyDTO struct {
+	ID       optional.Type[string]         `json:"id"`
+	FullName optional.Type[string]         `json:"Name"`
+	Email    optional.Type[string]         `json:"email"`
+	Age      optional.Type[int]            `json:"age"`
+	Tags     optional.Type[[]string]       `json:"tags"`
+	Address  optional.Type[copyAddressDTO] `json:"address"`
+	Items    []copyItemDTO                 `json:"items"`
+}
+
+func TestCopyTo(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+
+	dst := copyEntity{
+		ID: 1, Name: "some", Email: &email, Age: 42, Tags: []string{"a"},
+		Address: copyAddress{City: "Moscow", Street: "Tverskaya"}, Secret: "secret",
+	}
+
+	src := copyDTO{
+		ID:       optional.Some("2"),
+		FullName: optional.Some("other"),
+		Email:    optional.Null[string](),
+		Address:  optional.Some(copyAddressDTO{City: optional.Some("Paris")}),
+		Items:    []copyItemDTO{{SKU: optional.Some("pen"), Qty: optional.Some[int32](3)}},
+	}
+
+	parseID := optional.WithConverter(func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+
+	require.NoError(t, optional.CopyTo(src, &dst, parseID))
+
+	assert.Equal(t, copyEntity{
+		ID: 2, Name: "other", Age: 42, Tags: []string{"a"},
+		Address: copyAddress{City: "Paris", Street: "Tverskaya"},
+		Items:   []copyItem{{SKU: "pen", Qty: 3}},
+		Secret:  "secret",
+	}, dst)
+}
+
+func TestCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	src := copyEntity{
+		ID: 7, Name: "some", Age: 42,
+		Address: copyAddress{City: "Moscow"},
+		Items:   []copyItem{{SKU: "pen", Qty: 3}},
+		Secret:  "secret",
+	}
+
+	formatID := optional.WithConverter(func(id int64) (string, error) { return strconv.FormatInt(id, 10), nil })
+
+	var dst copyDTO
+
+	require.NoError(t, optional.CopyFrom(&dst, &src, formatID))
+
+	assert.Equal(t, copyDTO{
+		ID:       optional.Some("7"),
+		FullName: optional.Some("some"),
+		Email:    optional.Null[string](),
+		Age:      optional.Some(42),
+		Tags:     optional.Null[[]string](),
+		Address:  optional.Some(copyAddressDTO{City: optional.Some("Moscow"), Street: optional.Some("")}),
+		Items:    []copyItemDTO{{SKU: optional.Some("pen"), Qty: optional.Some[int32](3)}},
+	}, dst)
+}
+
+func TestCopyTo_Error(t *testing.T) {
+	t.Parallel()
+
+	errInvalid := errors.New("invalid id")
+	failing := optional.WithConverter(func(string) (int64, error) { return 0, errInvalid })
+
+	var dst copyEntity
+
+	err := optional.CopyTo(copyDTO{ID: optional.Some("x")}, &dst, failing)
+	require.ErrorIs(t, err, errInvalid)
+	assert.EqualError(t, err, `optional: field "id": invalid id`)
+
+	require.EqualError(t, optional.CopyTo(copyDTO{}, dst),
+		"optional: CopyTo expects a non-nil pointer to a struct destination, got optional_test.copyEntity")
+	require.EqualError(t, optional.CopyFrom(&dst, 1),
+		"optional: CopyFrom expects a struct source, got int")
+}
@@ -0,0 +1,216 @@
+package optional
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CUEDefinitions returns the CUE definitions of the named structs of values as encoded by encoding/json,
+// such as "#User: {...}", so CUE based validation stays in sync with the Go types. The nested named structs
+// are defined too and referenced by their definitions.
+//
+// The [Type] fields may be absent or null, so they are optional fields, marked with "?", of the disjunctions
+// with null. Other fields are required unless tagged with omitempty, pointers are disjunctions with null.
+// The output has no package clause, so it can be written to a file of any package.
+func CUEDefinitions(values ...any) ([]byte, error) {
+	b := &cueBuilder{names: map[reflect.Type]string{}, taken: map[string]bool{}}
+
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() != reflect.Struct || isOptionalType(t) || t.Name() == "" {
+			return nil, fmt.Errorf("optional: CUEDefinitions expects named structs, got %T", v)
+		}
+
+		b.define(t)
+	}
+
+	var buf bytes.Buffer
+
+	for i, d := range b.defs {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		fmt.Fprintf(&buf, "%s: %s\n", d.name, d.body)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// cueBuilder builds the CUE expressions of types, collecting the named structs into definitions.
+type cueBuilder struct {
+	names map[reflect.Type]string // names holds the definitions of the structs being defined or defined.
+	taken map[string]bool
+	defs  []cueDef
+}
+
+type cueDef struct {
+	name string
+	body string
+}
+
+// expr returns the CUE expression of the values of the type t, indented by depth tabs.
+func (b *cueBuilder) expr(t reflect.Type, depth int) string {
+	switch {
+	case t == timeType:
+		return "string"
+	case t == rawMessageType:
+		return "_"
+	case t == numberType:
+		return "number"
+	case isOptionalType(t), isTrackedType(t):
+		return cueNullable(b.expr(optionalElem(t), depth))
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return "_"
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t.Kind().String()
+	case reflect.Uintptr:
+		return "uint"
+	case reflect.String:
+		return "string"
+	case reflect.Ptr:
+		return cueNullable(b.expr(t.Elem(), depth))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+
+		return "[..." + b.expr(t.Elem(), depth) + "]"
+	case reflect.Array:
+		return "[..." + b.expr(t.Elem(), depth) + "]"
+	case reflect.Map:
+		return "{[string]: " + b.expr(t.Elem(), depth) + "}"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structExpr(t, depth)
+		}
+
+		return b.define(t)
+	}
+
+	return "_"
+}
+
+// define adds the definition of the named struct type t unless it is defined, and returns its name.
+func (b *cueBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := "#" + schemaName(t)
+	for i := 2; b.taken[name]; i++ {
+		name = "#" + schemaName(t) + strconv.Itoa(i)
+	}
+
+	b.names[t] = name
+	b.taken[name] = true
+
+	i := len(b.defs)
+	b.defs = append(b.defs, cueDef{name: name}) // reserves the position while the struct is being defined
+
+	body := b.structExpr(t, 0)
+	b.defs[i].body = body
+
+	return name
+}
+
+// structExpr returns the CUE struct of the fields of the struct type t.
+func (b *cueBuilder) structExpr(t reflect.Type, depth int) string {
+	fields := jsonFields(t)
+	if len(fields) == 0 {
+		return "{}"
+	}
+
+	indent := strings.Repeat("\t", depth+1)
+
+	var sb strings.Builder
+
+	sb.WriteString("{\n")
+
+	for _, f := range fields {
+		sf := t.FieldByIndex(f.index)
+
+		e := b.expr(sf.Type, depth+1)
+		if f.quoted && isQuotable(indirectType(optionalOrSelf(sf.Type)).Kind()) {
+			e = "string"
+			if isOptionalType(sf.Type) || isTrackedType(sf.Type) || sf.Type.Kind() == reflect.Ptr {
+				e = cueNullable(e)
+			}
+		}
+
+		marker := ""
+		if f.omitEmpty || isOptionalType(sf.Type) || isTrackedType(sf.Type) {
+			marker = "?"
+		}
+
+		sb.WriteString(indent + cueLabel(f.name) + marker + ": " + e + "\n")
+	}
+
+	sb.WriteString(strings.Repeat("\t", depth) + "}")
+
+	return sb.String()
+}
+
+// optionalOrSelf returns the type T of the [Type] or [Tracked] type t, or t itself.
+func optionalOrSelf(t reflect.Type) reflect.Type {
+	if isOptionalType(t) || isTrackedType(t) {
+		return optionalElem(t)
+	}
+
+	return t
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// cueNullable returns the disjunction of the expression e with null.
+func cueNullable(e string) string {
+	if strings.HasSuffix(e, " | null") {
+		return e
+	}
+
+	return e + " | null"
+}
+
+var cueKeywords = map[string]bool{
+	"null": true, "true": true, "false": true, "for": true, "in": true, "if": true, "let": true,
+}
+
+// cueLabel returns the field name as the CUE label, quoting the names that are not plain identifiers.
+func cueLabel(name string) string {
+	if name == "" || cueKeywords[name] {
+		return strconv.Quote(name)
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '$':
+		case (r >= '0' && r <= '9' || r == '_') && i > 0:
+		default:
+			return strconv.Quote(name)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,77 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type CueAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type CueNode struct {
+	Value    int        `json:"value"`
+	Children []*CueNode `json:"children,omitempty"`
+}
+
+type CueUser struct {
+	ID        uint                      `json:"id"`
+	Name      optional.Type[string]     `json:"name"`
+	Age       optional.Type[int]        `json:"age,string"`
+	Tags      []string                  `json:"tags,omitempty"`
+	Labels    map[string]string         `json:"labels"`
+	Address   optional.Type[CueAddress] `json:"address"`
+	Billing   *CueAddress               `json:"billing"`
+	Tree      CueNode                   `json:"tree"`
+	Geo       struct{ Lat float64 }     `json:"geo"`
+	CreatedAt time.Time                 `json:"created_at"`
+	Score     optional.Tracked[float64] `json:"score"`
+	Private   bool                      `json:"_private"`
+	Ignored   string                    `json:"-"`
+}
+
+func TestCUEDefinitions(t *testing.T) {
+	t.Parallel()
+
+	got, err := optional.CUEDefinitions(&CueUser{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `#CueUser: {
+	id: uint
+	name?: string | null
+	age?: string | null
+	tags?: [...string]
+	labels: {[string]: string}
+	address?: #CueAddress | null
+	billing: #CueAddress | null
+	tree: #CueNode
+	geo: {
+		Lat: float64
+	}
+	created_at: string
+	score?: float64 | null
+	"_private": bool
+}
+
+#CueAddress: {
+	city?: string | null
+}
+
+#CueNode: {
+	value: int
+	children?: [...#CueNode | null]
+}
+`, string(got))
+}
+
+func TestCUEDefinitions_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.CUEDefinitions(struct{}{})
+	require.EqualError(t, err, "optional: CUEDefinitions expects named structs, got struct {}")
+}
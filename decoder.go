@@ -0,0 +1,445 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ErrDuplicateKey is returned by [Decoder] when the key of an optional field appears twice in the object
+// and [DuplicateError] is set.
+var ErrDuplicateKey = errors.New("optional: duplicate key")
+
+// Decoder reads and decodes JSON values from an input stream like [json.Decoder] does, using
+// the current unmarshaller for the values of the fields.
+//
+// Unlike decoding with [json.Unmarshal], the *[json.UnmarshalTypeError] and *[json.SyntaxError] errors
+// returned by the [Type] fields have the offsets relative to the input stream and the dotted paths of
+// the fields, so precise errors such as "field address.city expected string" can be reported.
+//
+// Besides the names of the fields, the keys listed in the `optional` tag are accepted,
+// such as `optional:"alias=user_name,userName"`, for ingesting payloads of different API versions.
+// The fields marked `optional:"deprecated"` are reported to the hook set by [WithDeprecatedHook], and
+// the fields tagged `optional:"oneof=key"` are decoded into the variant named by the sibling member
+// of the key, see [RegisterOneOf].
+type Decoder struct {
+	dec *json.Decoder
+	o   options
+}
+
+// NewDecoder returns the decoder reading from r.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	o := newOptions(opts)
+
+	return &Decoder{dec: json.NewDecoder(limitJSON(r, o)), o: o}
+}
+
+// More reports whether there is another value in the input stream.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Decode reads the next JSON value from the input and stores it in the value pointed to by v.
+func (d *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("optional: Decode expects a non-nil pointer, got %T", v)
+	}
+
+	var raw json.RawMessage
+
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	offset := d.dec.InputOffset() - int64(len(raw))
+
+	if d.o.hasLimits() {
+		if err := checkLimits(raw, offset, d.o); err != nil {
+			return err
+		}
+	}
+
+	if d.o.schema != nil {
+		if err := checkSchema(raw, d.o); err != nil {
+			return err
+		}
+	}
+
+	if err := d.decodeAt(raw, rv.Elem(), offset, "", ""); err != nil {
+		return err
+	}
+
+	if d.o.normalize {
+		return normalizeValue(rv, "", nil)
+	}
+
+	return nil
+}
+
+// decodeAt decodes the raw value at the offset of the input into the addressable value v.
+// Plain structs, slices and the values of [Type] are walked member by member, so the errors
+// of the values are located relative to the input.
+func (d *Decoder) decodeAt(raw json.RawMessage, v reflect.Value, offset int64, path, structName string) error {
+	switch c := firstByte(raw); {
+	case c == '{' && v.Kind() == reflect.Ptr && isWalkableStruct(v.Type().Elem()):
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.decodeStructAt(raw, v.Elem(), offset, path)
+	case (c == '{' || c == '[') && isOptionalType(v.Type()) && v.Field(0).Kind() != reflect.Interface &&
+		v.Field(0).Type() != rawMessageType:
+		a, _ := asAccessor(v)
+
+		if c == '[' || !d.o.merge || fieldState(v) != StateValue {
+			a.mark(false, false)
+		}
+
+		a.mark(true, false)
+
+		return d.decodeAt(raw, a.value(), offset, path, structName)
+	case c == '{' && isWalkableStruct(v.Type()):
+		return d.decodeStructAt(raw, v, offset, path)
+	case c == '[' && v.Kind() == reflect.Slice && !v.Type().Implements(jsonUnmarshalerType) &&
+		!reflect.PtrTo(v.Type()).Implements(jsonUnmarshalerType) && v.Type().Elem().Kind() != reflect.Uint8:
+		return d.decodeSliceAt(raw, v, offset, path, structName)
+	}
+
+	return locate(unmarshalValue(raw, v, d.o), offset, path, structName)
+}
+
+func (d *Decoder) decodeStructAt(raw json.RawMessage, v reflect.Value, offset int64, path string) error {
+	fields := jsonFields(v.Type())
+	seen := map[string]bool{}
+
+	var (
+		pending  []pendingMigration
+		siblings []siblingOneOf
+		members  map[string]json.RawMessage // members holds the discriminators of the siblings.
+	)
+
+	for _, f := range fields {
+		if f.oneOf != "" {
+			members = map[string]json.RawMessage{}
+
+			break
+		}
+	}
+
+	err := walkJSON(raw, '{', func(key string, member json.RawMessage, start int64) error {
+		if members != nil {
+			members[key] = member
+		}
+
+		if m, ok := lookupMigration(v.Type(), key, d.o.caseFold); ok {
+			pending = append(pending, pendingMigration{m, member, key})
+
+			return nil
+		}
+
+		f, ok := lookupField(fields, key, d.o.caseFold)
+		if !ok {
+			return nil
+		}
+
+		if err := d.checkDuplicate(v, f, seen, joinPath(path, f.name), offset+start); err != nil {
+			return err
+		}
+
+		if f.deprecate && d.o.deprecated != nil {
+			d.o.deprecated(joinPath(path, f.name))
+		}
+
+		if f.oneOf != "" {
+			siblings = append(siblings, siblingOneOf{f, member, start})
+
+			return nil
+		}
+
+		fv, _ := fieldByIndex(v, f.index, true)
+
+		var err error
+
+		switch {
+		case f.codec != "":
+			if err = decodeCodecAt(member, fv, f.codec); err != nil {
+				err = fmt.Errorf("optional: field %q: %w", joinPath(path, f.name), err)
+			}
+		case f.quoted && isQuotable(fv.Kind()):
+			err = d.decodeQuotedAt(member, fv, offset+start, joinPath(path, f.name), v.Type().Name())
+		default:
+			err = d.decodeAt(member, fv, offset+start, joinPath(path, f.name), v.Type().Name())
+		}
+
+		if d.o.hook != nil && isPresenceType(fv.Type()) {
+			d.o.hook.OnUnmarshal(joinPath(path, f.name), fieldState(fv), err)
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range siblings {
+		fv, _ := fieldByIndex(v, s.f.index, true)
+		disc, found := members[s.f.oneOf]
+
+		err := d.decodeSiblingOneOfAt(s.raw, disc, found, fv, s.f.oneOf, offset+s.start, joinPath(path, s.f.name),
+			v.Type().Name())
+
+		if d.o.hook != nil && isPresenceType(fv.Type()) {
+			d.o.hook.OnUnmarshal(joinPath(path, s.f.name), fieldState(fv), err)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range pending {
+		if err := p.fn(p.raw, v); err != nil {
+			return fmt.Errorf("optional: migrating field %q: %w", joinPath(path, p.key), err)
+		}
+	}
+
+	if d.o.hook == nil {
+		return nil
+	}
+
+	for _, f := range fields {
+		if !seen[f.name] && isPresenceType(v.Type().FieldByIndex(f.index).Type) {
+			d.o.hook.OnUnmarshal(joinPath(path, f.name), StateUnset, nil)
+		}
+	}
+
+	return nil
+}
+
+// isPresenceType reports whether t is an instantiation of [Type] or [Tracked].
+func isPresenceType(t reflect.Type) bool {
+	return isOptionalType(t) || isTrackedType(t)
+}
+
+// checkDuplicate reports the optional field decoded more than once from the same object.
+func (d *Decoder) checkDuplicate(v reflect.Value, f jsonField, seen map[string]bool, path string, offset int64) error {
+	if !isPresenceType(v.Type().FieldByIndex(f.index).Type) {
+		return nil
+	}
+
+	if !seen[f.name] {
+		seen[f.name] = true
+
+		return nil
+	}
+
+	if d.o.duplicate != nil {
+		d.o.duplicate(path)
+	}
+
+	if d.o.duplicates == DuplicateError {
+		return fmt.Errorf("%w %q at offset %d", ErrDuplicateKey, path, offset)
+	}
+
+	return nil
+}
+
+// decodeQuotedAt decodes the scalar quoted with the `string` tag option like encoding/json does.
+func (d *Decoder) decodeQuotedAt(raw json.RawMessage, v reflect.Value, offset int64, path, structName string) error {
+	if string(raw) == "null" {
+		return nil
+	}
+
+	typeError := func(value string) error {
+		return &json.UnmarshalTypeError{Value: value, Type: v.Type(), Offset: offset, Struct: structName, Field: path}
+	}
+
+	var s string
+
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return typeError(jsonKind(raw))
+	}
+
+	err := unmarshaller([]byte(s), v.Addr().Interface())
+
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		return typeError("string")
+	}
+
+	return locate(err, offset, path, structName)
+}
+
+// jsonKind returns the kind of the JSON value as encoding/json names it in the errors.
+func jsonKind(raw json.RawMessage) string {
+	switch firstByte(raw) {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	}
+
+	return "number"
+}
+
+func isQuotable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+
+	return false
+}
+
+func (d *Decoder) decodeSliceAt(raw json.RawMessage, v reflect.Value, offset int64, path, structName string) error {
+	s := reflect.MakeSlice(v.Type(), 0, 0)
+
+	err := walkJSON(raw, '[', func(_ string, elem json.RawMessage, start int64) error {
+		ev := reflect.New(v.Type().Elem()).Elem()
+
+		if err := d.decodeAt(elem, ev, offset+start, path, structName); err != nil {
+			return err
+		}
+
+		s = reflect.Append(s, ev)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	v.Set(s)
+
+	return nil
+}
+
+// walkJSON calls fn for the members of the JSON object or the elements of the JSON array with their
+// offsets within the raw value.
+func walkJSON(raw json.RawMessage, delim json.Delim, fn func(key string, value json.RawMessage, start int64) error) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var key string
+
+		if delim == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			key, _ = tok.(string)
+		}
+
+		var value json.RawMessage
+
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		if err := fn(key, value, dec.InputOffset()-int64(len(value))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// locate makes the offset of the *json.UnmarshalTypeError or *json.SyntaxError relative to the input
+// and prefixes the field of the type error with the path.
+func locate(err error, offset int64, path, structName string) error {
+	if err == nil {
+		return nil
+	}
+
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		te.Offset += offset
+
+		if path != "" {
+			te.Field = joinPath(path, te.Field)
+
+			if te.Struct == "" {
+				te.Struct = structName
+			}
+		}
+
+		return err
+	}
+
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		se.Offset += offset
+	}
+
+	return err
+}
+
+// isWalkableStruct reports whether t is a struct decoded by encoding/json member by member.
+func isWalkableStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		!reflect.PtrTo(t).Implements(jsonUnmarshalerType) && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// lookupField finds the field by the exact name or alias, falling back to the case-insensitive match
+// like encoding/json when caseFold is true.
+func lookupField(fields []jsonField, name string, caseFold bool) (jsonField, bool) {
+	match := func(eq func(a, b string) bool) (jsonField, bool) {
+		for _, f := range fields {
+			if eq(f.name, name) {
+				return f, true
+			}
+
+			for _, alias := range f.aliases {
+				if eq(alias, name) {
+					return f, true
+				}
+			}
+		}
+
+		return jsonField{}, false
+	}
+
+	if f, ok := match(func(a, b string) bool { return a == b }); ok || !caseFold {
+		return f, ok
+	}
+
+	return match(strings.EqualFold)
+}
+
+func firstByte(raw json.RawMessage) byte {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return 0
+	}
+
+	return raw[0]
+}
+
+func joinPath(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
+	}
+
+	return prefix + "." + name
+}
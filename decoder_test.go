@@ -0,0 +1,385 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type decoderAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type decoderUser struct {
+	Name    optional.Type[string]          `json:"name"`
+	Age     int                            `json:"age"`
+	Address decoderAddress                 `json:"address"`
+	Home    *decoderAddress                `json:"home"`
+	Extra   optional.Type[decoderAddress]  `json:"extra"`
+	Tags    []optional.Type[string]        `json:"tags"`
+	Others  []decoderAddress               `json:"others"`
+	Meta    map[string]optional.Type[bool] `json:"meta"`
+}
+
+type decoderQuoted struct {
+	Count optional.Type[int] `json:"count"`
+	Size  int                `json:"size,string"`
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	t.Parallel()
+
+	dec := optional.NewDecoder(strings.NewReader(`{
+		"NAME": "John",
+		"age": 30,
+		"address": {"city": null},
+		"home": {"city": "Paris"},
+		"extra": {"city": "Rome"},
+		"tags": ["a", null],
+		"others": [{"city": "Oslo"}, {}],
+		"meta": {"admin": true},
+		"unknown": 1
+	} {"age": 31}`))
+
+	var got decoderUser
+
+	require.NoError(t, dec.Decode(&got))
+
+	assert.Equal(t, decoderUser{
+		Name:    optional.Some("John"),
+		Age:     30,
+		Address: decoderAddress{City: optional.Null[string]()},
+		Home:    &decoderAddress{City: optional.Some("Paris")},
+		Extra:   optional.Some(decoderAddress{City: optional.Some("Rome")}),
+		Tags:    []optional.Type[string]{optional.Some("a"), optional.Null[string]()},
+		Others:  []decoderAddress{{City: optional.Some("Oslo")}, {}},
+		Meta:    map[string]optional.Type[bool]{"admin": optional.Some(true)},
+	}, got)
+
+	require.True(t, dec.More())
+	require.NoError(t, dec.Decode(&got))
+	assert.Equal(t, 31, got.Age)
+	assert.Equal(t, optional.Some("John"), got.Name)
+
+	require.False(t, dec.More())
+	require.ErrorIs(t, dec.Decode(&got), io.EOF)
+}
+
+func TestDecoder_Decode_TypeError(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name   string
+		input  string
+		field  string
+		offset int64
+	}{
+		{"top level", `{"name": 1}`, "name", 10},
+		{"plain", `{"age": "x"}`, "age", 11},
+		{"nested", `{"address": {"city": 1}}`, "address.city", 22},
+		{"pointer", `{"home": {"city": 1}}`, "home.city", 19},
+		{"inside optional", `{"extra": {"city": 1}}`, "extra.city", 20},
+		{"slice", `{"tags": ["a", 1]}`, "tags", 16},
+		{"slice of structs", `{"others": [{}, {"city": 1}]}`, "others.city", 26},
+		{"stream", ` {} {"name": true}`, "name", 17},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dec := optional.NewDecoder(strings.NewReader(tt.input))
+
+			var (
+				got decoderUser
+				err error
+			)
+
+			for err == nil {
+				err = dec.Decode(&got)
+			}
+
+			var te *json.UnmarshalTypeError
+
+			require.True(t, errors.As(err, &te), err)
+			assert.Equal(t, tt.field, te.Field)
+			assert.Equal(t, tt.offset, te.Offset)
+		})
+	}
+}
+
+func TestDecoder_Decode_Quoted(t *testing.T) {
+	t.Parallel()
+
+	var got decoderQuoted
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(`{"count": 1, "size": "2"}`)).Decode(&got))
+	assert.Equal(t, decoderQuoted{Count: optional.Some(1), Size: 2}, got)
+
+	err := optional.NewDecoder(strings.NewReader(`{"q": {"size": "\"x\""}}`)).Decode(&struct {
+		Q decoderQuoted `json:"q"`
+	}{})
+
+	var te *json.UnmarshalTypeError
+
+	require.True(t, errors.As(err, &te), err)
+	assert.Equal(t, "q.size", te.Field)
+	assert.GreaterOrEqual(t, te.Offset, int64(15))
+
+	for _, input := range [...]string{`{"size": "x"}`, `{"size": true}`} {
+		err = optional.NewDecoder(strings.NewReader(input)).Decode(&decoderQuoted{})
+
+		require.True(t, errors.As(err, &te), err)
+		assert.Equal(t, "size", te.Field)
+		assert.Equal(t, int64(9), te.Offset)
+	}
+}
+
+func TestDecoder_Decode_SyntaxError(t *testing.T) {
+	t.Parallel()
+
+	var se *json.SyntaxError
+
+	err := optional.NewDecoder(strings.NewReader(`{"name": "x",}`)).Decode(&decoderUser{})
+	require.True(t, errors.As(err, &se), err)
+
+	require.ErrorContains(t, optional.NewDecoder(strings.NewReader(`{}`)).Decode(decoderUser{}),
+		"optional: Decode expects a non-nil pointer")
+}
+
+func TestDecoder_Decode_Aliases(t *testing.T) {
+	t.Parallel()
+
+	type partner struct {
+		UserName optional.Type[string] `json:"user_name" optional:"alias=userName,login"`
+		Email    optional.Type[string] `json:"email" optional:"deprecated,alias=mail"`
+		Age      optional.Type[int]    `json:"age"`
+	}
+
+	tests := [...]struct {
+		name  string
+		input string
+		opts  []optional.Option
+		want  partner
+	}{
+		{"name", `{"user_name":"a"}`, nil, partner{UserName: optional.Some("a")}},
+		{"alias", `{"userName":"a","mail":"m"}`, nil, partner{UserName: optional.Some("a"), Email: optional.Some("m")}},
+		{"second alias", `{"login":"a"}`, nil, partner{UserName: optional.Some("a")}},
+		{"case-insensitive alias", `{"USERNAME":"a","AGE":1}`, nil, partner{UserName: optional.Some("a"), Age: optional.Some(1)}},
+		{
+			"case-sensitive",
+			`{"USERNAME":"a","AGE":1,"age":2}`,
+			[]optional.Option{optional.WithCaseInsensitive(false)},
+			partner{Age: optional.Some(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got partner
+
+			require.NoError(t, optional.NewDecoder(strings.NewReader(tt.input), tt.opts...).Decode(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecoder_Decode_Deprecated(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		Zip optional.Type[string] `json:"zip" optional:"deprecated"`
+	}
+
+	type partner struct {
+		Name    optional.Type[string] `json:"name"`
+		Email   optional.Type[string] `json:"email" optional:"alias=mail,deprecated"`
+		Address address               `json:"address"`
+	}
+
+	var got []string
+
+	dec := optional.NewDecoder(
+		strings.NewReader(`{"name":"a","mail":null,"address":{"zip":"1"}} {"name":"b"}`),
+		optional.WithDeprecatedHook(func(field string) { got = append(got, field) }),
+	)
+
+	var p partner
+
+	require.NoError(t, dec.Decode(&p))
+	require.NoError(t, dec.Decode(&p))
+
+	assert.Equal(t, []string{"email", "address.zip"}, got)
+	assert.Equal(t, optional.Null[string](), p.Email)
+}
+
+func TestDecoder_Decode_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	type account struct {
+		Role  optional.Type[string] `json:"role" optional:"alias=userRole"`
+		Plain string                `json:"plain"`
+	}
+
+	tests := [...]struct {
+		name    string
+		input   string
+		opts    []optional.Option
+		want    account
+		wantErr bool
+		dups    []string
+	}{
+		{"last wins", `{"role":"user","role":"admin"}`, nil, account{Role: optional.Some("admin")}, false, []string{"role"}},
+		{
+			"recorded",
+			`{"role":"user","ROLE":"admin","plain":"a","plain":"b"}`,
+			nil,
+			account{Role: optional.Some("admin"), Plain: "b"},
+			false,
+			[]string{"role"},
+		},
+		{
+			"error",
+			`{"role":"user","userRole":"admin"}`,
+			[]optional.Option{optional.WithDuplicateKeys(optional.DuplicateError)},
+			account{Role: optional.Some("user")},
+			true,
+			[]string{"role"},
+		},
+		{
+			"plain fields",
+			`{"plain":"a","plain":"b"}`,
+			[]optional.Option{optional.WithDuplicateKeys(optional.DuplicateError)},
+			account{Plain: "b"},
+			false,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				got  account
+				dups []string
+			)
+
+			opts := append([]optional.Option{
+				optional.WithDuplicateKeyHook(func(field string) { dups = append(dups, field) }),
+			}, tt.opts...)
+
+			err := optional.NewDecoder(strings.NewReader(tt.input), opts...).Decode(&got)
+			if tt.wantErr {
+				require.ErrorIs(t, err, optional.ErrDuplicateKey)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.dups, dups)
+		})
+	}
+}
+
+func TestDecoder_Decode_Merge(t *testing.T) {
+	t.Parallel()
+
+	type profile struct {
+		Name  optional.Type[string]         `json:"name"`
+		Email optional.Type[string]         `json:"email"`
+		Home  optional.Type[decoderAddress] `json:"home"`
+		Tags  optional.Type[[]string]       `json:"tags"`
+	}
+
+	type account struct {
+		Profile optional.Type[profile] `json:"profile"`
+		Backup  optional.Type[profile] `json:"backup"`
+	}
+
+	loaded := func() account {
+		return account{
+			Profile: optional.Some(profile{
+				Name:  optional.Some("John"),
+				Email: optional.Some("john@example.com"),
+				Home:  optional.Some(decoderAddress{City: optional.Some("Paris")}),
+				Tags:  optional.Some([]string{"a", "b"}),
+			}),
+			Backup: optional.Null[profile](),
+		}
+	}
+
+	input := `{"profile":{"email":null,"home":{},"tags":["c"]},"backup":{"name":"Jane"}}`
+
+	t.Run("merge", func(t *testing.T) {
+		t.Parallel()
+
+		got := loaded()
+
+		require.NoError(t, optional.NewDecoder(strings.NewReader(input), optional.WithMerge()).Decode(&got))
+
+		assert.Equal(t, account{
+			Profile: optional.Some(profile{
+				Name:  optional.Some("John"),
+				Email: optional.Null[string](),
+				Home:  optional.Some(decoderAddress{City: optional.Some("Paris")}),
+				Tags:  optional.Some([]string{"c"}),
+			}),
+			Backup: optional.Some(profile{Name: optional.Some("Jane")}),
+		}, got)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		got := loaded()
+
+		require.NoError(t, optional.NewDecoder(strings.NewReader(input)).Decode(&got))
+
+		assert.Equal(t, account{
+			Profile: optional.Some(profile{
+				Email: optional.Null[string](),
+				Home:  optional.Some(decoderAddress{}),
+				Tags:  optional.Some([]string{"c"}),
+			}),
+			Backup: optional.Some(profile{Name: optional.Some("Jane")}),
+		}, got)
+	})
+}
+
+func TestDecoder_Decode_Embedded(t *testing.T) {
+	t.Parallel()
+
+	data := `{"source": "crm", "note": "audit", "name": "tagged", "id": 1, "Note": "top"}`
+
+	var got embeddedRecord
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(data)).Decode(&got))
+
+	var want embeddedRecord
+
+	require.NoError(t, json.Unmarshal([]byte(data), &want))
+
+	assert.Equal(t, embeddedRecord{
+		embeddedAudit:  embeddedAudit{Source: optional.Some("crm"), Note: optional.Some("audit")},
+		EmbeddedTagged: EmbeddedTagged{Name: optional.Some("tagged")},
+		ID:             1,
+		Note:           optional.Some("top"),
+	}, got)
+	assert.Equal(t, want, got)
+}
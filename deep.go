@@ -0,0 +1,77 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetDeep sets the field of the struct held by the value found by the dotted path of the JSON names,
+// such as "address.city", marking the value set. The [Type] values on the way are marked set as well,
+// so the nested field is not lost when the value is marshalled. The unset or null values on the way
+// are set to the zero values first, nil pointers and maps are allocated.
+//
+// The field of [Type] is set to null when value is nil. Values of other types are converted when possible,
+// composite values are converted through JSON.
+func (t *Type[T]) SetDeep(path string, value any) error {
+	var tokens []string
+	if path != "" {
+		tokens = strings.Split(path, ".")
+	}
+
+	if t.f != flagSet {
+		t.mark(true, false)
+	}
+
+	if err := setPointer(t.value(), tokens, 0, value); err != nil {
+		return fmt.Errorf("optional: SetDeep %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// FieldStates returns the states of the [Type] fields of the struct v by their dotted paths of the JSON names,
+// such as "address.city". The fields of nested structs, including the structs held by the set [Type] values,
+// are reported recursively, while the fields under the unset or null [Type] values are not reported.
+func FieldStates(v any) map[string]State {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: FieldStates expects a struct, got %T", v))
+	}
+
+	states := map[string]State{}
+
+	collectFieldStates(states, rv, "")
+
+	return states
+}
+
+func collectFieldStates(states map[string]State, v reflect.Value, prefix string) {
+	for _, f := range jsonFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		if isTrackedType(fv.Type()) {
+			fv = fv.Field(0)
+		}
+
+		name := prefix + f.name
+
+		if !isOptionalType(fv.Type()) {
+			if sv := indirect(fv); sv.IsValid() && isPlainStruct(sv.Type()) {
+				collectFieldStates(states, sv, name+".")
+			}
+
+			continue
+		}
+
+		state := fieldState(fv)
+		states[name] = state
+
+		if sv := indirect(fv.FieldByName("V")); state == StateValue && sv.IsValid() && isPlainStruct(sv.Type()) {
+			collectFieldStates(states, sv, name+".")
+		}
+	}
+}
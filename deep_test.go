@@ -0,0 +1,107 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type deepGeo struct {
+	Lat optional.Type[float64] `json:"lat"`
+	Lng optional.Type[float64] `json:"lng"`
+}
+
+type deepAddress struct {
+	City   optional.Type[string]  `json:"city"`
+	Street optional.Type[string]  `json:"street"`
+	Geo    optional.Type[deepGeo] `json:"geo"`
+}
+
+type deepUser struct {
+	Name    optional.Type[string]      `json:"name"`
+	Address optional.Type[deepAddress] `json:"address"`
+}
+
+func TestType_SetDeep(t *testing.T) {
+	t.Parallel()
+
+	var addr optional.Type[deepAddress]
+
+	require.NoError(t, addr.SetDeep("city", "Paris"))
+	require.NoError(t, addr.SetDeep("street", nil))
+	require.NoError(t, addr.SetDeep("geo.lat", 48.85))
+
+	assert.Equal(t, optional.Some(deepAddress{
+		City:   optional.Some("Paris"),
+		Street: optional.Null[string](),
+		Geo:    optional.Some(deepGeo{Lat: optional.Some(48.85)}),
+	}), addr)
+
+	null := optional.Null[deepAddress]()
+
+	require.NoError(t, null.SetDeep("city", "Rome"))
+	assert.Equal(t, optional.Some(deepAddress{City: optional.Some("Rome")}), null)
+
+	require.EqualError(t, addr.SetDeep("zip", "75001"), `optional: SetDeep "zip": optional: pointer "/zip": no field "zip" in optional_test.deepAddress`)
+}
+
+func TestFieldStates(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		v    deepUser
+		want map[string]optional.State
+	}{
+		{"unset", deepUser{}, map[string]optional.State{"name": optional.StateUnset, "address": optional.StateUnset}},
+		{
+			"null",
+			deepUser{Name: optional.Some("John"), Address: optional.Null[deepAddress]()},
+			map[string]optional.State{"name": optional.StateValue, "address": optional.StateNull},
+		},
+		{
+			"nested",
+			deepUser{Address: optional.Some(deepAddress{City: optional.Some("Paris"), Geo: optional.Some(deepGeo{Lng: optional.Null[float64]()})})},
+			map[string]optional.State{
+				"name":            optional.StateUnset,
+				"address":         optional.StateValue,
+				"address.city":    optional.StateValue,
+				"address.street":  optional.StateUnset,
+				"address.geo":     optional.StateValue,
+				"address.geo.lat": optional.StateUnset,
+				"address.geo.lng": optional.StateNull,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, optional.FieldStates(&tt.v))
+		})
+	}
+
+	assert.PanicsWithValue(t, "optional: FieldStates expects a struct, got int", func() { optional.FieldStates(1) })
+}
+
+func TestType_MarshalJSON_Nested(t *testing.T) {
+	t.Parallel()
+
+	addr := optional.Some(deepAddress{City: optional.Some("Paris"), Geo: optional.Some(deepGeo{Lat: optional.Null[float64]()})})
+
+	got, err := json.Marshal(addr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"city":"Paris","geo":{"lat":null}}`, string(got))
+
+	var back optional.Type[deepAddress]
+
+	require.NoError(t, json.Unmarshal(got, &back))
+	assert.Equal(t, addr, back)
+}
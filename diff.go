@@ -0,0 +1,80 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Change describes a change of a [Type] field reported by [DiffConfig].
+type Change struct {
+	Path     string // Path is the dotted path of the Go field names, such as "Database.Port".
+	OldState State
+	NewState State
+	Old      any // Old is the previous value, nil unless OldState is StateValue.
+	New      any // New is the current value, nil unless NewState is StateValue.
+}
+
+// DiffConfig reports the [Type] fields of the structs that differ in the state or the value,
+// including transitions to and from null and unset, so services can react to the reloaded
+// configuration selectively. Nested structs are compared field by field.
+//
+// Both arguments must be structs, or pointers to structs, of the same type.
+func DiffConfig(before, after any) ([]Change, error) {
+	ov, nv := indirect(reflect.ValueOf(before)), indirect(reflect.ValueOf(after))
+
+	if ov.Kind() != reflect.Struct || nv.Kind() != reflect.Struct || ov.Type() != nv.Type() {
+		return nil, fmt.Errorf("optional: DiffConfig expects structs of the same type, got %T and %T", before, after)
+	}
+
+	return diffStruct(nil, "", ov, nv), nil
+}
+
+func diffStruct(changes []Change, prefix string, ov, nv reflect.Value) []Change {
+	for i := 0; i < ov.NumField(); i++ {
+		f := ov.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		path := prefix + f.Name
+		of, nf := ov.Field(i), nv.Field(i)
+
+		switch {
+		case isOptionalType(f.Type):
+			c := Change{
+				Path:     path,
+				OldState: stateOf(of.Interface().(presence)),
+				NewState: stateOf(nf.Interface().(presence)),
+			}
+
+			if c.OldState == StateValue {
+				c.Old = of.FieldByName("V").Interface()
+			}
+
+			if c.NewState == StateValue {
+				c.New = nf.FieldByName("V").Interface()
+			}
+
+			if c.OldState != c.NewState || !reflect.DeepEqual(c.Old, c.New) {
+				changes = append(changes, c)
+			}
+		case f.Type.Kind() == reflect.Struct:
+			changes = diffStruct(changes, path+".", of, nf)
+		}
+	}
+
+	return changes
+}
+
+// indirect dereferences the pointers, returning the zero Value for nil ones.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
@@ -0,0 +1,58 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDiffConfig(t *testing.T) {
+	t.Parallel()
+
+	type database struct {
+		Host optional.Type[string]
+		Port optional.Type[int]
+	}
+
+	type config struct {
+		Name     optional.Type[string]
+		Level    optional.Type[string]
+		Proxy    optional.Type[string]
+		Timeout  optional.Type[int]
+		Database database
+	}
+
+	old := config{
+		Name:     optional.Some("some"),
+		Level:    optional.Some("info"),
+		Proxy:    optional.Null[string](),
+		Database: database{Host: optional.Some("localhost"), Port: optional.Some(5432)},
+	}
+
+	cur := config{
+		Name:     optional.Some("some"),
+		Level:    optional.Null[string](),
+		Timeout:  optional.Some(5),
+		Database: database{Host: optional.Some("localhost"), Port: optional.Some(6432)},
+	}
+
+	got, err := optional.DiffConfig(old, &cur)
+	require.NoError(t, err)
+
+	assert.Equal(t, []optional.Change{
+		{Path: "Level", OldState: optional.StateValue, NewState: optional.StateNull, Old: "info"},
+		{Path: "Proxy", OldState: optional.StateNull, NewState: optional.StateUnset},
+		{Path: "Timeout", OldState: optional.StateUnset, NewState: optional.StateValue, New: 5},
+		{Path: "Database.Port", OldState: optional.StateValue, NewState: optional.StateValue, Old: 5432, New: 6432},
+	}, got)
+
+	got, err = optional.DiffConfig(old, old)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = optional.DiffConfig(old, database{})
+	require.EqualError(t, err, "optional: DiffConfig expects structs of the same type, got optional_test.config and optional_test.database")
+}
@@ -0,0 +1,151 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Redacted replaces the values of the fields redacted by [Dump].
+const Redacted = "[REDACTED]"
+
+// WithRedact makes [Dump] print [Redacted] instead of the values of the fields for which fn returns true,
+// given the dotted paths of their JSON names, such as "credentials.password".
+func WithRedact(fn func(path string) bool) Option {
+	return func(o *options) {
+		o.redact = fn
+	}
+}
+
+// Dump returns the human-readable rendering of the struct v for debug endpoints and error reports, one field per
+// line by its JSON name, with the values aligned and the state of every [Type] field marked, so it shows what was
+// actually provided where JSON or %+v hide the unset and null fields:
+//
+//	main.User {
+//		id:      1
+//		name:    [value] "John"
+//		email:   [null]
+//		phone:   [unset]
+//		address: [value] main.Address {
+//			city: [value] "Berlin"
+//		}
+//		token:   [REDACTED]
+//	}
+//
+// The nested structs, including the ones held by the set [Type] fields, are rendered recursively. The values
+// of the fields tagged with `optional:"redact"` or selected by [WithRedact] are printed as [Redacted].
+func Dump(v any, opts ...Option) string {
+	o := newOptions(opts)
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return "nil"
+	}
+
+	var b strings.Builder
+
+	dumpValue(&b, rv, "", 0, false, o)
+
+	return b.String()
+}
+
+// dumpValue writes the value v at the path, indented by depth tabs, marking the state of the [Type] values.
+func dumpValue(b *strings.Builder, v reflect.Value, path string, depth int, redact bool, o options) {
+	if isPresenceType(v.Type()) {
+		state := fieldState(v)
+
+		b.WriteString("[" + state.String() + "]")
+
+		if state != StateValue {
+			return
+		}
+
+		b.WriteByte(' ')
+
+		v = presenceValue(v)
+	}
+
+	if redact {
+		b.WriteString(Redacted)
+
+		return
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteString("nil")
+
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	if !isPlainStruct(v.Type()) {
+		b.WriteString(dumpScalar(v))
+
+		return
+	}
+
+	fields := jsonFields(v.Type())
+
+	b.WriteString(v.Type().String())
+
+	if len(fields) == 0 {
+		b.WriteString(" {}")
+
+		return
+	}
+
+	width := 0
+	for _, f := range fields {
+		if len(f.name) > width {
+			width = len(f.name)
+		}
+	}
+
+	indent := strings.Repeat("\t", depth+1)
+
+	b.WriteString(" {\n")
+
+	for _, f := range fields {
+		b.WriteString(indent + f.name + ":" + strings.Repeat(" ", width-len(f.name)+1))
+
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			b.WriteString("nil\n")
+
+			continue
+		}
+
+		fieldPath := joinPath(path, f.name)
+		redact := hasTagFlag(v.Type().FieldByIndex(f.index).Tag.Get("optional"), "redact") ||
+			o.redact != nil && o.redact(fieldPath)
+
+		dumpValue(b, fv, fieldPath, depth+1, redact, o)
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(strings.Repeat("\t", depth) + "}")
+}
+
+// dumpScalar returns the rendering of the value v other than a plain struct.
+func dumpScalar(v reflect.Value) string {
+	if !v.CanInterface() {
+		return v.Type().String()
+	}
+
+	switch x := v.Interface().(type) {
+	case fmt.Stringer:
+		return x.String()
+	case error:
+		return x.Error()
+	}
+
+	if v.Kind() == reflect.String {
+		return strconv.Quote(v.String())
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
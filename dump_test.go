@@ -0,0 +1,96 @@
+package optional_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+type dumpAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type dumpCredentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type dumpUser struct {
+	ID          int                        `json:"id"`
+	Name        optional.Type[string]      `json:"name"`
+	Email       optional.Type[string]      `json:"email"`
+	Phone       optional.Tracked[string]   `json:"phone"`
+	Tags        []string                   `json:"tags"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	Address     optional.Type[dumpAddress] `json:"address"`
+	Billing     *dumpAddress               `json:"billing"`
+	Token       optional.Type[string]      `json:"token" optional:"redact"`
+	Credentials dumpCredentials            `json:"credentials"`
+}
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	u := dumpUser{
+		ID:          1,
+		Name:        optional.Some("John"),
+		Email:       optional.Null[string](),
+		Tags:        []string{"a"},
+		CreatedAt:   time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Address:     optional.Some(dumpAddress{City: optional.Some("Berlin")}),
+		Token:       optional.Some("secret"),
+		Credentials: dumpCredentials{Login: "john", Password: "qwerty"},
+	}
+
+	got := optional.Dump(&u, optional.WithRedact(func(path string) bool {
+		return strings.HasSuffix(path, ".password")
+	}))
+
+	assert.Equal(t, `optional_test.dumpUser {
+	id:          1
+	name:        [value] "John"
+	email:       [null]
+	phone:       [unset]
+	tags:        []string{"a"}
+	created_at:  2024-03-01 00:00:00 +0000 UTC
+	address:     [value] optional_test.dumpAddress {
+		city: [value] "Berlin"
+	}
+	billing:     nil
+	token:       [value] [REDACTED]
+	credentials: optional_test.dumpCredentials {
+		login:    "john"
+		password: [REDACTED]
+	}
+}`, got)
+}
+
+func TestDump_Values(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		v    any
+		want string
+	}{
+		{"nil", nil, "nil"},
+		{"scalar", 1, "1"},
+		{"value", optional.Some(1), "[value] 1"},
+		{"unset", optional.Type[int]{}, "[unset]"},
+		{"empty struct", struct{}{}, "struct {} {}"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, optional.Dump(tt.v))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Either holds one of two alternatives, such as of the API fields which are either a string or an object.
+// Wrap it into [Type] to represent the unset and null states as well:
+//
+//	type Order struct {
+//		Customer optional.Type[optional.Either[string, Customer]] `json:"customer"` // ID or expanded object
+//	}
+//
+// The zero value holds neither of the alternatives and is encoded as null.
+type Either[L, R any] struct {
+	l    L
+	r    R
+	side side
+}
+
+type side uint8
+
+const (
+	sideNone side = iota
+	sideLeft
+	sideRight
+)
+
+// Left returns the [Either] holding the left alternative.
+func Left[L, R any](value L) Either[L, R] {
+	return Either[L, R]{l: value, side: sideLeft}
+}
+
+// Right returns the [Either] holding the right alternative.
+func Right[L, R any](value R) Either[L, R] {
+	return Either[L, R]{r: value, side: sideRight}
+}
+
+// IsLeft reports whether the left alternative is held.
+func (e Either[L, R]) IsLeft() bool {
+	return e.side == sideLeft
+}
+
+// IsRight reports whether the right alternative is held.
+func (e Either[L, R]) IsRight() bool {
+	return e.side == sideRight
+}
+
+// GetLeft returns the left alternative and whether it is held.
+func (e Either[L, R]) GetLeft() (L, bool) {
+	return e.l, e.side == sideLeft
+}
+
+// GetRight returns the right alternative and whether it is held.
+func (e Either[L, R]) GetRight() (R, bool) {
+	return e.r, e.side == sideRight
+}
+
+// MarshalJSON implements the [json.Marshaler] interface, encoding the held alternative with the current marshaller.
+func (e Either[L, R]) MarshalJSON() ([]byte, error) {
+	switch e.side {
+	case sideLeft:
+		return marshaller(e.l)
+	case sideRight:
+		return marshaller(e.r)
+	}
+
+	return []byte(`null`), nil
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the left alternative or, if it fails,
+// the right one with the current unmarshaller. Since the first alternative decoded without an error wins,
+// the stricter alternative should be the left one, for example a string rather than a struct accepting any object.
+// JSON null resets the value to hold neither of the alternatives.
+func (e *Either[L, R]) UnmarshalJSON(data []byte) error {
+	*e = Either[L, R]{}
+
+	if isNull(data) {
+		return nil
+	}
+
+	errL := unmarshaller(data, &e.l)
+	if errL == nil {
+		e.side = sideLeft
+
+		return nil
+	}
+
+	e.l = *new(L)
+
+	errR := unmarshaller(data, &e.r)
+	if errR == nil {
+		e.side = sideRight
+
+		return nil
+	}
+
+	e.r = *new(R)
+
+	return fmt.Errorf("optional: %s is neither %s (%v) nor %s: %w", jsonKind(data), typeName[L](), errL, typeName[R](), errR)
+}
+
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
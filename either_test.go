@@ -0,0 +1,101 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type eitherCustomer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestEither(t *testing.T) {
+	t.Parallel()
+
+	left := optional.Left[string, eitherCustomer]("cus_1")
+
+	v, ok := left.GetLeft()
+	assert.True(t, ok)
+	assert.True(t, left.IsLeft())
+	assert.False(t, left.IsRight())
+	assert.Equal(t, "cus_1", v)
+
+	_, ok = left.GetRight()
+	assert.False(t, ok)
+
+	right := optional.Right[string](eitherCustomer{ID: "cus_1"})
+
+	c, ok := right.GetRight()
+	assert.True(t, ok)
+	assert.True(t, right.IsRight())
+	assert.Equal(t, eitherCustomer{ID: "cus_1"}, c)
+
+	var zero optional.Either[string, int]
+
+	assert.False(t, zero.IsLeft())
+	assert.False(t, zero.IsRight())
+}
+
+func TestEither_JSON(t *testing.T) {
+	t.Parallel()
+
+	type order struct {
+		Customer optional.Type[optional.Either[string, eitherCustomer]] `json:"customer"`
+	}
+
+	tests := [...]struct {
+		name  string
+		input string
+		want  order
+	}{
+		{"unset", `{}`, order{}},
+		{"null", `{"customer":null}`, order{Customer: optional.Null[optional.Either[string, eitherCustomer]]()}},
+		{"left", `{"customer":"cus_1"}`, order{Customer: optional.Some(optional.Left[string, eitherCustomer]("cus_1"))}},
+		{
+			"right",
+			`{"customer":{"id":"cus_1","name":"John"}}`,
+			order{Customer: optional.Some(optional.Right[string](eitherCustomer{ID: "cus_1", Name: "John"}))},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got order
+
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &got))
+			assert.Equal(t, tt.want, got)
+
+			out, err := optional.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.input, string(out))
+		})
+	}
+}
+
+func TestEither_UnmarshalJSON_Error(t *testing.T) {
+	t.Parallel()
+
+	var got optional.Either[string, eitherCustomer]
+
+	err := json.Unmarshal([]byte(`42`), &got)
+	require.ErrorContains(t, err, "optional: number is neither string")
+	require.ErrorContains(t, err, "nor optional_test.eitherCustomer")
+	assert.False(t, got.IsLeft())
+	assert.False(t, got.IsRight())
+
+	var none optional.Either[string, int]
+
+	out, err := json.Marshal(none)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(out))
+}
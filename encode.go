@@ -0,0 +1,558 @@
+package optional
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// presence is implemented by every [Type] value.
+type presence interface {
+	IsSet() bool
+	IsSetNull() bool
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Marshal returns the JSON encoding of v respecting the presence of the [Type] fields:
+// unset fields are omitted, fields set to null are encoded as null and the rest
+// are encoded using the current marshaller.
+//
+// The presence is respected through nested and embedded structs, pointers, slices, arrays and maps,
+// including the values of [Type]: unset map values are omitted, while unset elements of slices and arrays
+// are encoded as null. Values implementing [json.Marshaler] or [encoding.TextMarshaler] and values
+// without [Type] inside are encoded by the current marshaller as is. Cyclic values result
+// in the *[json.UnsupportedValueError].
+//
+// The fields tagged `optional:"nullas=empty"` are encoded as the empty values of their types, such as "" or [],
+// instead of null, and the fields tagged `optional:"nullas=omit"` are omitted, for the APIs clearing the fields so.
+//
+// [WithCanonical] makes the output deterministic for hashing and signing,
+// [WithEscapeHTML] controls the escaping of HTML characters and [WithUnsetSentinel] emits the unset fields.
+func Marshal(v any, opts ...Option) ([]byte, error) {
+	e := encoder{o: newOptions(opts)}
+
+	if e.o.unset != nil && !json.Valid(e.o.unset) {
+		return nil, fmt.Errorf("optional: invalid unset sentinel %q", e.o.unset)
+	}
+
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	if e.o.canonical {
+		return canonicalize(nil, e.buf.Bytes())
+	}
+
+	if !e.o.escapeHTML {
+		return unescapeHTML(e.buf.Bytes()), nil
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// unescapeHTML replaces the escape sequences of the <, > and & characters in the JSON strings of data
+// with the characters, leaving other escape sequences as they are.
+func unescapeHTML(data []byte) []byte {
+	out := data[:0]
+
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' || i+1 == len(data) {
+			out = append(out, data[i])
+
+			continue
+		}
+
+		if data[i+1] == 'u' && i+6 <= len(data) {
+			switch string(data[i+2 : i+6]) {
+			case "003c", "003C":
+				out = append(out, '<')
+				i += 5
+
+				continue
+			case "003e", "003E":
+				out = append(out, '>')
+				i += 5
+
+				continue
+			case "0026":
+				out = append(out, '&')
+				i += 5
+
+				continue
+			}
+		}
+
+		out = append(out, data[i], data[i+1])
+		i++
+	}
+
+	return out
+}
+
+// encoder encodes the values respecting the presence of the [Type] values inside.
+type encoder struct {
+	buf bytes.Buffer
+	o   options
+
+	// seen holds the pointers, maps and slices being encoded to detect cycles.
+	seen map[visit]struct{}
+	// path is the dotted path of the field being encoded.
+	path string
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	// The values without Type inside are walked only to select the requested fields.
+	if (!hasPresence(v.Type()) && (e.o.fields == nil || !hasFields(v.Type()))) || hasMarshaler(v) {
+		return encodeValue(&e.buf, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		if v.Kind() == reflect.Interface {
+			return e.encode(v.Elem())
+		}
+
+		return e.visit(v, func() error { return e.encode(v.Elem()) })
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		return e.visit(v, func() error { return e.encodeMap(v) })
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		return e.visit(v, func() error { return e.encodeArray(v) })
+	case reflect.Array:
+		return e.encodeArray(v)
+	}
+
+	return encodeValue(&e.buf, v)
+}
+
+// visit calls fn detecting the cycles through the pointer, map or slice v.
+func (e *encoder) visit(v reflect.Value, fn func() error) error {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	if _, ok := e.seen[key]; ok {
+		return &json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+	}
+
+	if e.seen == nil {
+		e.seen = map[visit]struct{}{}
+	}
+
+	e.seen[key] = struct{}{}
+	defer delete(e.seen, key)
+
+	return fn()
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	if isTrackedType(v.Type()) {
+		v = v.Field(0)
+	}
+
+	if isOptionalType(v.Type()) {
+		// The values of Type outside of structs and maps, such as the elements of slices, cannot be omitted.
+		if p := v.Interface().(presence); !p.IsSet() || p.IsSetNull() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		return e.encode(v.FieldByName("V"))
+	}
+
+	e.buf.WriteByte('{')
+
+	first := true
+
+	for _, f := range jsonFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		parent := e.path
+		e.path = joinPath(parent, f.name)
+
+		if e.o.fields != nil && !e.o.fields.allows(e.path) {
+			e.path = parent
+
+			continue
+		}
+
+		err := e.encodeField(fv, f, &first)
+
+		e.path = parent
+
+		if err != nil {
+			return fmt.Errorf("optional: field %q: %w", f.name, err)
+		}
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+func (e *encoder) encodeField(fv reflect.Value, f jsonField, first *bool) error {
+	if isTrackedType(fv.Type()) {
+		fv = fv.Field(0)
+	}
+
+	if !isOptionalType(fv.Type()) {
+		if f.omitEmpty && isEmptyValue(fv) {
+			return nil
+		}
+
+		writeKey(&e.buf, f.name, first)
+
+		return e.encodeFieldValue(fv, f)
+	}
+
+	state := fieldState(fv)
+
+	var err error
+
+	switch {
+	case e.o.omitCompat && state != StateValue:
+		// The unset and null fields are the nil pointers of the legacy struct.
+		if !f.omitEmpty {
+			writeKey(&e.buf, f.name, first)
+			e.buf.WriteString("null")
+		}
+	case state == StateUnset:
+		if e.o.unset != nil {
+			writeKey(&e.buf, f.name, first)
+			e.buf.Write(e.o.unset)
+		}
+	case state == StateNull:
+		err = e.encodeNull(fv.FieldByName("V").Type(), f, first)
+	default:
+		writeKey(&e.buf, f.name, first)
+
+		err = e.encodeOptionalValue(fv.FieldByName("V"), f)
+	}
+
+	if e.o.hook != nil {
+		e.o.hook.OnMarshal(e.path, state, err)
+	}
+
+	return err
+}
+
+// encodeOptionalValue encodes the value of the set optional field, formatting the numbers as the options say.
+func (e *encoder) encodeOptionalValue(v reflect.Value, f jsonField) error {
+	if f.codec == "" && !f.quoted && e.hasNumberFormat(f) {
+		if ok, err := e.encodeNumber(v, f); ok || err != nil {
+			return err
+		}
+	}
+
+	return e.encodeFieldValue(v, f)
+}
+
+// Null representations of the fields set to null, from the `optional:"nullas=..."` tag.
+const (
+	nullAsEmpty = "empty"
+	nullAsOmit  = "omit"
+)
+
+// encodeNull encodes the field of the type t set to null as the `optional:"nullas=..."` tag says:
+// as null by default, as the empty value of the type with "empty", or not at all with "omit".
+func (e *encoder) encodeNull(t reflect.Type, f jsonField, first *bool) error {
+	switch f.nullAs {
+	case nullAsOmit:
+		return nil
+	case nullAsEmpty:
+		writeKey(&e.buf, f.name, first)
+
+		switch t.Kind() {
+		case reflect.Slice:
+			e.buf.WriteString("[]")
+		case reflect.Map:
+			e.buf.WriteString("{}")
+		default:
+			return e.encodeMember(reflect.Zero(t), f.quoted)
+		}
+
+		return nil
+	}
+
+	writeKey(&e.buf, f.name, first)
+	e.buf.WriteString("null")
+
+	return nil
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.buf.WriteByte('[')
+
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte(']')
+
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+
+		mv := iter.Value()
+		if isTrackedType(mv.Type()) {
+			mv = mv.Field(0)
+		}
+
+		if isOptionalType(mv.Type()) && !e.o.omitCompat {
+			if p := mv.Interface().(presence); !p.IsSet() && !p.IsSetNull() {
+				continue
+			}
+		}
+
+		keys = append(keys, key)
+		values[key] = mv
+	}
+
+	sort.Strings(keys)
+
+	e.buf.WriteByte('{')
+
+	first := true
+
+	for _, key := range keys {
+		writeKey(&e.buf, key, &first)
+
+		if err := e.encode(values[key]); err != nil {
+			return fmt.Errorf("optional: key %q: %w", key, err)
+		}
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// mapKey returns the name of the map key like encoding/json does.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+
+		b, err := tm.MarshalText()
+
+		return string(b), err
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("optional: unsupported map key type %s", k.Type())
+}
+
+// hasMarshaler reports whether v is encoded by its own MarshalJSON or MarshalText method.
+// The [Type] and [Tracked] values are encoded respecting the presence instead.
+func hasMarshaler(v reflect.Value) bool {
+	t := v.Type()
+	if isOptionalType(t) || isTrackedType(t) || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	return isMarshaler(t) || (v.CanAddr() && isMarshaler(reflect.PtrTo(t)))
+}
+
+// isMarshaler reports whether t implements [json.Marshaler] or [encoding.TextMarshaler].
+func isMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+var presenceTypes sync.Map // map[reflect.Type]bool
+
+// hasPresence reports whether the values of t may contain the [Type] values, which have to be encoded
+// respecting the presence, or the fields encoded by the codecs registered with [RegisterFieldCodec].
+// Interfaces may hold any values, so they are inspected when encoded.
+func hasPresence(t reflect.Type) bool {
+	if ok, found := presenceTypes.Load(t); found {
+		return ok.(bool)
+	}
+
+	ok := typeHasPresence(t, map[reflect.Type]bool{})
+
+	presenceTypes.Store(t, ok)
+
+	return ok
+}
+
+// hasNestedPresence reports whether the values of T may contain the [Type] values. Interfaces are not inspected,
+// so the values of [Type] of an interface are encoded by the current marshaller.
+func hasNestedPresence[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	return t.Kind() != reflect.Interface && hasPresence(t)
+}
+
+func typeHasPresence(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return typeHasPresence(t.Elem(), visiting)
+	case reflect.Map:
+		return typeHasPresence(t.Elem(), visiting)
+	case reflect.Struct:
+		if isOptionalType(t) || isTrackedType(t) {
+			return true
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			if typeHasPresence(t.Field(i).Type, visiting) || tagValue(t.Field(i).Tag.Get("optional"), "codec") != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeKey(buf *bytes.Buffer, name string, first *bool) {
+	if !*first {
+		buf.WriteByte(',')
+	}
+
+	*first = false
+
+	key, _ := json.Marshal(name)
+
+	buf.Write(key)
+	buf.WriteByte(':')
+}
+
+// encodeMember encodes the struct member, quoting scalars for fields with the `string` tag option.
+func (e *encoder) encodeMember(v reflect.Value, quoted bool) error {
+	if !quoted {
+		return e.encode(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		var tmp bytes.Buffer
+
+		if err := encodeValue(&tmp, v); err != nil {
+			return err
+		}
+
+		b, _ := json.Marshal(tmp.String())
+		e.buf.Write(b)
+
+		return nil
+	}
+
+	return e.encode(v)
+}
+
+// encodeValue encodes v with the marshaller. The addressable values are encoded through their pointers
+// when the pointer type is a marshaler, agreeing with [hasMarshaler], so the pointer receiver methods
+// are called as encoding/json calls them.
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	if v.CanAddr() && !isMarshaler(v.Type()) && isMarshaler(reflect.PtrTo(v.Type())) {
+		v = v.Addr()
+	}
+
+	b, err := marshaller(v.Interface())
+	if err != nil {
+		return err
+	}
+
+	buf.Write(b)
+
+	return nil
+}
+
+// isEmptyValue reports whether the value is empty in terms of the `omitempty` tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
@@ -0,0 +1,470 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		ID optional.Type[int] `json:"id"`
+	}
+
+	type some struct {
+		Base
+
+		Name    optional.Type[string] `json:"name"`
+		Email   optional.Type[string] `json:"email"`
+		Age     optional.Type[int]    `json:"age,string"`
+		Plain   string                `json:"plain"`
+		Omitted string                `json:"omitted,omitempty"`
+		Skipped string                `json:"-"`
+		NoTag   int
+	}
+
+	tests := [...]struct {
+		name  string
+		input any
+		want  string
+	}{
+		{"unset", some{}, `{"plain":"","NoTag":0}`},
+		{
+			"set",
+			some{
+				Base:  Base{ID: optional.Some(1)},
+				Name:  optional.Some("some"),
+				Email: optional.Null[string](),
+				Age:   optional.Some(42),
+				Plain: "plain",
+			},
+			`{"id":1,"name":"some","email":null,"age":"42","plain":"plain","NoTag":0}`,
+		},
+		{"pointer", &some{Name: optional.Some("some")}, `{"name":"some","plain":"","NoTag":0}`},
+		{"nil", (*some)(nil), `null`},
+		{"not a struct", []int{1}, `[1]`},
+		{"optional", optional.Some("some"), `"some"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := optional.Marshal(tt.input)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+type marshalNode struct {
+	Name optional.Type[string] `json:"name"`
+	Next *marshalNode          `json:"next,omitempty"`
+}
+
+type marshalText struct {
+	Name optional.Type[string]
+}
+
+func (marshalText) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+func TestMarshal_Nested(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+	}
+
+	type Embedded struct {
+		Note optional.Type[string] `json:"note"`
+	}
+
+	type some struct {
+		*Embedded
+
+		Address  address                            `json:"address"`
+		Home     *address                           `json:"home"`
+		Optional optional.Type[address]             `json:"optional"`
+		List     []address                          `json:"list"`
+		Values   []optional.Type[int]               `json:"values"`
+		Array    [2]optional.Type[int]              `json:"array"`
+		Map      map[string]optional.Type[int]      `json:"map"`
+		IntMap   map[int]address                    `json:"int_map"`
+		Any      any                                `json:"any"`
+		Text     marshalText                        `json:"text"`
+		Tracked  optional.Tracked[address]          `json:"tracked"`
+		Nested   map[string][]optional.Type[string] `json:"nested"`
+	}
+
+	got, err := optional.Marshal(some{
+		Embedded: &Embedded{Note: optional.Some("note")},
+		Address:  address{City: optional.Some("Paris")},
+		Home:     &address{Street: optional.Null[string]()},
+		Optional: optional.Some(address{City: optional.Some("Rome")}),
+		List:     []address{{City: optional.Some("Oslo")}, {}},
+		Values:   []optional.Type[int]{optional.Some(1), optional.Null[int](), {}},
+		Array:    [2]optional.Type[int]{optional.Some(2)},
+		Map:      map[string]optional.Type[int]{"b": optional.Some(1), "a": optional.Null[int](), "c": {}},
+		IntMap:   map[int]address{2: {}, 1: {City: optional.Some("Kyiv")}},
+		Any:      address{City: optional.Some("Lima")},
+		Tracked:  optional.Tracked[address]{Type: optional.Some(address{})},
+		Nested:   map[string][]optional.Type[string]{"x": {optional.Some("y")}},
+	})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"note": "note",
+		"address": {"city": "Paris"},
+		"home": {"street": null},
+		"optional": {"city": "Rome"},
+		"list": [{"city": "Oslo"}, {}],
+		"values": [1, null, null],
+		"array": [2, null],
+		"map": {"a": null, "b": 1},
+		"int_map": {"1": {"city": "Kyiv"}, "2": {}},
+		"any": {"city": "Lima"},
+		"text": "text",
+		"tracked": {},
+		"nested": {"x": ["y"]}
+	}`, string(got))
+}
+
+func TestMarshal_Cycle(t *testing.T) {
+	t.Parallel()
+
+	list := &marshalNode{Name: optional.Some("a"), Next: &marshalNode{}}
+
+	got, err := optional.Marshal(list)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"a","next":{}}`, string(got))
+
+	list.Next.Next = list
+
+	_, err = optional.Marshal(list)
+
+	var ue *json.UnsupportedValueError
+
+	require.ErrorAs(t, err, &ue)
+	assert.Contains(t, ue.Str, "encountered a cycle")
+}
+
+func TestMarshal_EscapeHTML(t *testing.T) {
+	t.Parallel()
+
+	type link struct {
+		URL  optional.Type[string] `json:"url"`
+		Note string                `json:"note"`
+	}
+
+	v := link{URL: optional.Some("https://example.com/?a=1&b=<2>"), Note: `\u0026 & "x"`}
+
+	got, err := optional.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"https://example.com/?a=1\u0026b=\u003c2\u003e","note":"\\u0026 \u0026 \"x\""}`, string(got))
+
+	got, err = optional.Marshal(v, optional.WithEscapeHTML(false))
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"https://example.com/?a=1&b=<2>","note":"\\u0026 & \"x\""}`, string(got))
+}
+
+func TestMarshal_NullAs(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City optional.Type[string] `json:"city"`
+	}
+
+	type profile struct {
+		Name    optional.Type[string]            `json:"name"`
+		Nick    optional.Type[string]            `json:"nick" optional:"nullas=empty"`
+		Age     optional.Type[int]               `json:"age,string" optional:"nullas=empty"`
+		Tags    optional.Type[[]string]          `json:"tags" optional:"nullas=empty"`
+		Attrs   optional.Type[map[string]string] `json:"attrs" optional:"alias=attributes,nullas=empty"`
+		Address optional.Type[address]           `json:"address" optional:"nullas=empty"`
+		Phone   optional.Type[string]            `json:"phone" optional:"nullas=omit"`
+		Email   optional.Type[string]            `json:"email" optional:"nullas=omit"`
+	}
+
+	v := profile{
+		Name:    optional.Null[string](),
+		Nick:    optional.Null[string](),
+		Age:     optional.Null[int](),
+		Tags:    optional.Null[[]string](),
+		Attrs:   optional.Null[map[string]string](),
+		Address: optional.Null[address](),
+		Phone:   optional.Null[string](),
+		Email:   optional.Some("some@example.com"),
+	}
+
+	states := map[string]optional.State{}
+
+	got, err := optional.Marshal(v, optional.WithHook(optional.HookFuncs{
+		Marshal: func(field string, state optional.State, _ error) { states[field] = state },
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":null,"nick":"","age":"0","tags":[],"attrs":{},"address":{},"email":"some@example.com"}`, string(got))
+	assert.Equal(t, optional.StateNull, states["nick"])
+	assert.Equal(t, optional.StateNull, states["phone"])
+}
+
+func TestMarshal_UnsetSentinel(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City optional.Type[string] `json:"city"`
+	}
+
+	type partner struct {
+		Name    optional.Type[string]            `json:"name"`
+		Email   optional.Type[string]            `json:"email"`
+		Address optional.Type[address]           `json:"address"`
+		Meta    map[string]optional.Type[string] `json:"meta"`
+	}
+
+	v := partner{
+		Name:    optional.Some("John"),
+		Address: optional.Some(address{}),
+		Meta:    map[string]optional.Type[string]{"a": {}},
+	}
+
+	got, err := optional.Marshal(v, optional.WithUnsetSentinel(`"__UNSET__"`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"John","email":"__UNSET__","address":{"city":"__UNSET__"},"meta":{}}`, string(got))
+
+	got, err = optional.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"John","address":{},"meta":{}}`, string(got))
+
+	_, err = optional.Marshal(v, optional.WithUnsetSentinel(`__UNSET__`))
+	require.EqualError(t, err, `optional: invalid unset sentinel "__UNSET__"`)
+}
+
+func TestMarshal_OmitEmptyCompat(t *testing.T) {
+	t.Parallel()
+
+	type legacyAddress struct {
+		City *string `json:"city,omitempty"`
+	}
+
+	type legacyUser struct {
+		ID      int             `json:"id"`
+		Name    *string         `json:"name,omitempty"`
+		Email   *string         `json:"email"`
+		Age     *int            `json:"age,omitempty,string"`
+		Bio     *string         `json:"bio,omitempty"`
+		Address *legacyAddress  `json:"address,omitempty"`
+		Scores  map[string]*int `json:"scores,omitempty"`
+		Tags    []string        `json:"tags,omitempty"`
+		Phone   *string         `json:"phone,omitempty"`
+	}
+
+	type address struct {
+		City optional.Type[string] `json:"city,omitempty"`
+	}
+
+	type user struct {
+		ID      int                           `json:"id"`
+		Name    optional.Type[string]         `json:"name,omitempty" optional:"nullas=empty"`
+		Email   optional.Type[string]         `json:"email"`
+		Age     optional.Type[int]            `json:"age,omitempty,string"`
+		Bio     optional.Type[string]         `json:"bio,omitempty"`
+		Address optional.Type[address]        `json:"address,omitempty"`
+		Scores  map[string]optional.Type[int] `json:"scores,omitempty"`
+		Tags    []string                      `json:"tags,omitempty"`
+		Phone   optional.Tracked[string]      `json:"phone,omitempty"`
+	}
+
+	name, bio, city, score := "", "<b>Tom & Jerry</b>", "Berlin", 7
+	age := 30
+
+	var phone optional.Tracked[string]
+	phone.SetValue("+1")
+
+	phoneValue := "+1"
+
+	tests := [...]struct {
+		name   string
+		legacy legacyUser
+		user   user
+	}{
+		{"unset", legacyUser{ID: 1}, user{ID: 1}},
+		{
+			"null",
+			legacyUser{ID: 1, Scores: map[string]*int{"a": nil}},
+			user{
+				ID:      1,
+				Name:    optional.Null[string](),
+				Email:   optional.Null[string](),
+				Age:     optional.Null[int](),
+				Address: optional.Null[address](),
+				Scores:  map[string]optional.Type[int]{"a": optional.Null[int]()},
+			},
+		},
+		{
+			"values",
+			legacyUser{
+				ID:      1,
+				Name:    &name,
+				Email:   &name,
+				Age:     &age,
+				Bio:     &bio,
+				Address: &legacyAddress{City: &city},
+				Scores:  map[string]*int{"a": &score, "b": nil},
+				Tags:    []string{"x"},
+				Phone:   &phoneValue,
+			},
+			user{
+				ID:      1,
+				Name:    optional.Some(name),
+				Email:   optional.Some(name),
+				Age:     optional.Some(age),
+				Bio:     optional.Some(bio),
+				Address: optional.Some(address{City: optional.Some(city)}),
+				Scores:  map[string]optional.Type[int]{"a": optional.Some(score), "b": {}},
+				Tags:    []string{"x"},
+				Phone:   phone,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			want, err := json.Marshal(tt.legacy)
+			require.NoError(t, err)
+
+			got, err := optional.Marshal(tt.user, optional.WithOmitEmptyCompat(), optional.WithUnsetSentinel(`"x"`))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+type embeddedAudit struct {
+	Source optional.Type[string] `json:"source"`
+	Note   optional.Type[string] `json:"note"`
+	Name   optional.Type[string]
+}
+
+type EmbeddedTagged struct {
+	Name optional.Type[string] `json:"name"`
+	Note optional.Type[string]
+}
+
+type EmbeddedOther struct {
+	Note optional.Type[string]
+}
+
+type embeddedRecord struct {
+	embeddedAudit
+	EmbeddedTagged
+	EmbeddedOther
+
+	ID   int `json:"id"`
+	Note optional.Type[string]
+}
+
+func TestMarshal_Embedded(t *testing.T) {
+	t.Parallel()
+
+	v := embeddedRecord{
+		embeddedAudit:  embeddedAudit{Source: optional.Some("crm"), Note: optional.Some("audit"), Name: optional.Some("a")},
+		EmbeddedTagged: EmbeddedTagged{Name: optional.Some("tagged"), Note: optional.Some("tagged")},
+		EmbeddedOther:  EmbeddedOther{Note: optional.Some("other")},
+		ID:             1,
+		Note:           optional.Some("top"),
+	}
+
+	got, err := optional.Marshal(v)
+	require.NoError(t, err)
+
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	// The fields of the unexported embedded struct are promoted and the shallowest "Note" wins over the embedded ones.
+	assert.JSONEq(t, `{"source": "crm", "note": "audit", "Name": "a", "name": "tagged", "id": 1, "Note": "top"}`,
+		string(got))
+	assert.JSONEq(t, string(want), string(got))
+
+	type ambiguous struct {
+		EmbeddedTagged
+		EmbeddedOther
+	}
+
+	got, err = optional.Marshal(ambiguous{EmbeddedTagged{Note: optional.Some("a")}, EmbeddedOther{optional.Some("b")}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(got), "two untagged fields of the same depth are dropped")
+}
+
+type pointerMarshaler int
+
+func (*pointerMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"custom"`), nil
+}
+
+func TestMarshal_PointerMarshaler(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		X    pointerMarshaler      `json:"X"`
+		Name optional.Type[string] `json:"name"`
+	}
+
+	v := &some{Name: optional.Some("some")}
+
+	got, err := optional.Marshal(v)
+	require.NoError(t, err)
+
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"X": "custom", "name": "some"}`, string(got))
+	assert.JSONEq(t, string(want), string(got))
+}
+
+// textPatch carries a Type field, but is encoded by its pointer receiver MarshalText.
+type textPatch struct {
+	Name optional.Type[string]
+}
+
+func (p *textPatch) MarshalText() ([]byte, error) {
+	return []byte("name=" + p.Name.V), nil
+}
+
+func TestMarshal_PointerMarshaler_Nested(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		List []textPatch          `json:"list"`
+		Map  map[string]textPatch `json:"map"`
+		Home *textPatch           `json:"home"`
+	}
+
+	v := some{
+		List: []textPatch{{Name: optional.Some("a")}},
+		Map:  map[string]textPatch{"b": {Name: optional.Some("b")}},
+		Home: &textPatch{Name: optional.Some("c")},
+	}
+
+	got, err := optional.Marshal(v)
+	require.NoError(t, err)
+
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	// The map values are not addressable, so their MarshalText is not called, like in encoding/json.
+	assert.JSONEq(t, `{"list": ["name=a"], "map": {"b": {"Name": "b"}}, "home": "name=c"}`, string(got))
+	assert.JSONEq(t, string(want), string(got))
+}
@@ -0,0 +1,90 @@
+package optional
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// DecodeEnv decodes the environment variables into the fields of the struct pointed to by v
+// with the `env` tag, such as `env:"PORT"`. If the prefix is not empty, the names of the variables
+// are prefixed with it and an underscore, such as APP_PORT.
+//
+// Fields of unset variables are left untouched, so [Type] fields stay unset. Variables set to an empty
+// string are handled according to [WithEmpty], the sentinel set by [WithNullValue] sets the field to null.
+// Values of slice fields are split by comma unless changed by [WithSeparator].
+func DecodeEnv(prefix string, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeEnv expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	get := func(name string) []string {
+		if s, ok := os.LookupEnv(prefix + name); ok {
+			return []string{s}
+		}
+
+		return nil
+	}
+
+	o := newOptions(append([]Option{WithSeparator(",")}, opts...))
+
+	return decodeFields(get, nil, rv.Elem(), "env", o)
+}
+
+// Parser returns the type of [Type] of T and the function parsing the textual value into it,
+// suitable for the FuncMap of https://github.com/caarlos0/env, so optional fields of configuration
+// structs are set only when the variable is provided:
+//
+//	t, parse := optional.Parser[int]()
+//	opts := env.Options{FuncMap: map[reflect.Type]env.ParserFunc{t: parse}}
+func Parser[T any]() (reflect.Type, func(v string) (any, error)) {
+	return reflect.TypeOf(Type[T]{}), func(v string) (any, error) {
+		var t Type[T]
+
+		if err := setText(t.value(), v); err != nil {
+			return nil, err
+		}
+
+		t.mark(true, false)
+
+		return t, nil
+	}
+}
+
+// Parsers returns the parsers of [Type] of the basic types and [time.Duration] created by [Parser].
+//
+//	opts := env.Options{FuncMap: map[reflect.Type]env.ParserFunc{}}
+//	for t, parse := range optional.Parsers() {
+//		opts.FuncMap[t] = parse
+//	}
+func Parsers() map[reflect.Type]func(v string) (any, error) {
+	m := make(map[reflect.Type]func(v string) (any, error))
+
+	add := func(t reflect.Type, parse func(v string) (any, error)) {
+		m[t] = parse
+	}
+
+	add(Parser[string]())
+	add(Parser[bool]())
+	add(Parser[int]())
+	add(Parser[int8]())
+	add(Parser[int16]())
+	add(Parser[int32]())
+	add(Parser[int64]())
+	add(Parser[uint]())
+	add(Parser[uint8]())
+	add(Parser[uint16]())
+	add(Parser[uint32]())
+	add(Parser[uint64]())
+	add(Parser[float32]())
+	add(Parser[float64]())
+	add(Parser[time.Duration]())
+
+	return m
+}
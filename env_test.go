@@ -0,0 +1,88 @@
+package optional_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type envConfig struct {
+	Port    optional.Type[int]      `env:"PORT"`
+	Host    optional.Type[string]   `env:"HOST"`
+	Proxy   optional.Type[string]   `env:"PROXY"`
+	Hosts   optional.Type[[]string] `env:"HOSTS"`
+	Timeout optional.Type[string]   `env:"TIMEOUT"`
+	Debug   bool                    `env:"DEBUG"`
+}
+
+func TestDecodeEnv(t *testing.T) {
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_HOST", "")
+	t.Setenv("APP_PROXY", "null")
+	t.Setenv("APP_HOSTS", "a,b")
+	t.Setenv("APP_DEBUG", "true")
+
+	var got envConfig
+
+	require.NoError(t, optional.DecodeEnv("APP", &got, optional.WithNullValue("null")))
+
+	assert.Equal(t, optional.Some(8080), got.Port)
+	assert.Equal(t, optional.Some(""), got.Host)
+	assert.Equal(t, optional.Null[string](), got.Proxy)
+	assert.Equal(t, optional.Some([]string{"a", "b"}), got.Hosts)
+	assert.False(t, got.Timeout.IsSet())
+	assert.True(t, got.Debug)
+}
+
+func TestDecodeEnv_Empty(t *testing.T) {
+	t.Setenv("HOST", "")
+
+	var got envConfig
+
+	require.NoError(t, optional.DecodeEnv("", &got, optional.WithEmpty(optional.EmptyUnset)))
+	assert.False(t, got.Host.IsSet())
+
+	require.NoError(t, optional.DecodeEnv("", &got, optional.WithEmpty(optional.EmptyNull)))
+	assert.True(t, got.Host.IsSetNull())
+}
+
+func TestDecodeEnv_Error(t *testing.T) {
+	t.Setenv("PORT", "port")
+
+	var got envConfig
+
+	require.Error(t, optional.DecodeEnv("", &got))
+	require.Error(t, optional.DecodeEnv("", got))
+}
+
+func TestParser(t *testing.T) {
+	t.Parallel()
+
+	typ, parse := optional.Parser[time.Duration]()
+	assert.Equal(t, reflect.TypeOf(optional.Type[time.Duration]{}), typ)
+
+	got, err := parse("5s")
+	require.NoError(t, err)
+	assert.Equal(t, optional.Some(5*time.Second), got)
+
+	_, err = parse("some")
+	require.Error(t, err)
+}
+
+func TestParsers(t *testing.T) {
+	t.Parallel()
+
+	parsers := optional.Parsers()
+
+	parse, ok := parsers[reflect.TypeOf(optional.Type[int64]{})]
+	require.True(t, ok)
+
+	got, err := parse("42")
+	require.NoError(t, err)
+	assert.Equal(t, optional.Some(int64(42)), got)
+}
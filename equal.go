@@ -0,0 +1,202 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Equal reports whether the values are in the same state and, if set to values, the values are deeply equal.
+//
+// The method is used by github.com/google/go-cmp to compare the values, as cmp.Equal calls the methods
+// of the form (T) Equal(T) bool instead of comparing the fields, so cmp.Equal and cmp.Diff work without
+// options or an exporter of the unexported fields.
+func (t Type[T]) Equal(o Type[T]) bool {
+	s := t.State()
+	if s != o.State() {
+		return false
+	}
+
+	return s != StateValue || reflect.DeepEqual(t.V, o.V)
+}
+
+// Equal reports whether the current values are equal like [Type.Equal] does, the snapshots taken by
+// [Tracked.MarkClean] and the callbacks are ignored.
+func (t Tracked[T]) Equal(o Tracked[T]) bool {
+	return t.Type.Equal(o.Type)
+}
+
+// StructEqual reports whether the structs a and b are equal by presence and values: the [Type] and [Tracked]
+// fields are equal like [Type.Equal] reports, comparing the values holding optional fields recursively, and
+// other fields are deeply equal. Only the fields seen by encoding/json are compared, so a patch can be checked
+// to be a no-op against the current state without serializing both.
+//
+// Both arguments must be structs, or pointers to structs, of the same type.
+func StructEqual(a, b any) bool {
+	av, bv := structPair("StructEqual", a, b)
+
+	d := differ{first: true}
+	d.diff("", av, bv)
+
+	return len(d.paths) == 0
+}
+
+// StructDiff returns the dotted paths of the JSON names of the fields of the structs a and b differing
+// by presence or values, such as "address.city", as [StructEqual] compares them. The elements of slices
+// and the values of maps are reported by their indexes and keys, such as "items.0.price".
+//
+// Both arguments must be structs, or pointers to structs, of the same type.
+func StructDiff(a, b any) []string {
+	av, bv := structPair("StructDiff", a, b)
+
+	var d differ
+	d.diff("", av, bv)
+
+	return d.paths
+}
+
+func structPair(fn string, a, b any) (reflect.Value, reflect.Value) {
+	av, bv := indirect(reflect.ValueOf(a)), indirect(reflect.ValueOf(b))
+
+	if av.Kind() != reflect.Struct || bv.Kind() != reflect.Struct || av.Type() != bv.Type() {
+		panic(fmt.Sprintf("optional: %s expects structs of the same type, got %T and %T", fn, a, b))
+	}
+
+	return av, bv
+}
+
+// differ collects the paths of the differing values, stopping at the first one if first is true.
+type differ struct {
+	paths []string
+	first bool
+	seen  map[[2]uintptr]bool
+}
+
+func (d *differ) report(path string) {
+	d.paths = append(d.paths, path)
+}
+
+func (d *differ) done() bool {
+	return d.first && len(d.paths) != 0
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if isTrackedType(a.Type()) {
+		a, b = a.Field(0), b.Field(0)
+	}
+
+	switch {
+	case isOptionalType(a.Type()):
+		sa, sb := fieldState(a), fieldState(b)
+
+		switch {
+		case sa != sb:
+			d.report(path)
+		case sa == StateValue:
+			d.diff(path, a.FieldByName("V"), b.FieldByName("V"))
+		}
+	case a.Kind() == reflect.Ptr:
+		switch {
+		case a.IsNil() || b.IsNil():
+			if a.IsNil() != b.IsNil() {
+				d.report(path)
+			}
+		case a.Pointer() != b.Pointer():
+			key := [2]uintptr{a.Pointer(), b.Pointer()}
+			if d.seen[key] {
+				return
+			}
+
+			if d.seen == nil {
+				d.seen = map[[2]uintptr]bool{}
+			}
+
+			d.seen[key] = true
+
+			d.diff(path, a.Elem(), b.Elem())
+		}
+	case a.Kind() == reflect.Interface:
+		switch {
+		case a.IsNil() || b.IsNil():
+			if a.IsNil() != b.IsNil() {
+				d.report(path)
+			}
+		case a.Elem().Type() != b.Elem().Type():
+			d.report(path)
+		default:
+			d.diff(path, a.Elem(), b.Elem())
+		}
+	case isPlainStruct(a.Type()):
+		for _, f := range jsonFields(a.Type()) {
+			af, aok := fieldByIndex(a, f.index, false)
+			bf, bok := fieldByIndex(b, f.index, false)
+
+			switch {
+			case !aok || !bok:
+				if aok != bok {
+					d.report(joinPath(path, f.name))
+				}
+			default:
+				d.diff(joinPath(path, f.name), af, bf)
+			}
+
+			if d.done() {
+				return
+			}
+		}
+	case (a.Kind() == reflect.Slice || a.Kind() == reflect.Array) && isComparedByField(a.Type().Elem()):
+		if a.Kind() == reflect.Slice && (a.IsNil() != b.IsNil() || a.Len() != b.Len()) {
+			d.report(path)
+
+			return
+		}
+
+		for i := 0; i < a.Len() && !d.done(); i++ {
+			d.diff(joinPath(path, strconv.Itoa(i)), a.Index(i), b.Index(i))
+		}
+	case a.Kind() == reflect.Map && isComparedByField(a.Type().Elem()):
+		d.diffMap(path, a, b)
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.report(path)
+		}
+	}
+}
+
+// isComparedByField reports whether the values of t are compared field by field rather than deeply,
+// holding optional values or plain structs.
+func isComparedByField(t reflect.Type) bool {
+	return hasPresence(t) || isPlainStruct(indirectType(t))
+}
+
+// diffMap compares the values of the maps by their keys, in the order of the formatted keys.
+func (d *differ) diffMap(path string, a, b reflect.Value) {
+	if a.IsNil() != b.IsNil() {
+		d.report(path)
+
+		return
+	}
+
+	keys := append(a.MapKeys(), b.MapKeys()...)
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+	for i, k := range keys {
+		if i > 0 && fmt.Sprint(keys[i-1]) == fmt.Sprint(k) {
+			continue
+		}
+
+		av, bv := a.MapIndex(k), b.MapIndex(k)
+
+		switch {
+		case !av.IsValid() || !bv.IsValid():
+			d.report(joinPath(path, fmt.Sprint(k)))
+		default:
+			d.diff(joinPath(path, fmt.Sprint(k)), av, bv)
+		}
+
+		if d.done() {
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package optional_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_Equal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b optional.Type[[]int]
+		want bool
+	}{
+		{name: "unset", want: true},
+		{name: "null", a: optional.Null[[]int](), b: optional.Null[[]int](), want: true},
+		{name: "values", a: optional.Some([]int{1}), b: optional.Some([]int{1}), want: true},
+		{name: "different values", a: optional.Some([]int{1}), b: optional.Some([]int{2})},
+		{name: "null and unset", a: optional.Null[[]int]()},
+		{name: "value and null", a: optional.Some([]int(nil)), b: optional.Null[[]int]()},
+		{name: "zero value and unset", a: optional.Some([]int(nil))},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.a.Equal(tt.b))
+			assert.Equal(t, tt.want, tt.b.Equal(tt.a))
+		})
+	}
+}
+
+func TestTracked_Equal(t *testing.T) {
+	t.Parallel()
+
+	a := optional.Tracked[string]{Type: optional.Some("x")}
+	a.MarkClean()
+
+	b := optional.Tracked[string]{}
+	b.SetValue("x")
+
+	assert.True(t, a.Equal(b))
+
+	b.SetNull()
+
+	assert.False(t, a.Equal(b))
+}
+
+type equalAddress struct {
+	City optional.Type[string] `json:"city"`
+	Zip  string                `json:"zip"`
+}
+
+type equalUser struct {
+	Name    optional.Type[string]            `json:"name"`
+	Age     optional.Tracked[int]            `json:"age"`
+	Address optional.Type[equalAddress]      `json:"address"`
+	Items   []equalAddress                   `json:"items"`
+	Labels  map[string]optional.Type[string] `json:"labels"`
+	Manager *equalUser                       `json:"manager"`
+	Created time.Time                        `json:"created"`
+	cache   string
+}
+
+func TestStructEqual(t *testing.T) {
+	t.Parallel()
+
+	base := func() equalUser {
+		return equalUser{
+			Name:    optional.Some("John"),
+			Address: optional.Some(equalAddress{City: optional.Null[string](), Zip: "101"}),
+			Items:   []equalAddress{{Zip: "1"}, {City: optional.Some("Paris")}},
+			Labels:  map[string]optional.Type[string]{"a": optional.Some("x"), "b": optional.Null[string]()},
+			Manager: &equalUser{Name: optional.Some("Jane")},
+			Created: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := [...]struct {
+		name   string
+		change func(u *equalUser)
+		want   []string
+	}{
+		{"same", func(*equalUser) {}, nil},
+		{"unexported", func(u *equalUser) { u.cache = "x" }, nil},
+		{"tracked snapshot", func(u *equalUser) { u.Age.MarkClean() }, nil},
+		{"unset value", func(u *equalUser) { u.Name = optional.Type[string]{} }, []string{"name"}},
+		{"null value", func(u *equalUser) { u.Name = optional.Null[string]() }, []string{"name"}},
+		{"value", func(u *equalUser) { u.Name = optional.Some("Bob") }, []string{"name"}},
+		{"tracked", func(u *equalUser) { u.Age.SetValue(1) }, []string{"age"}},
+		{
+			"nested",
+			func(u *equalUser) { u.Address.V.City = optional.Some("Rome"); u.Address.V.Zip = "102" },
+			[]string{"address.city", "address.zip"},
+		},
+		{"slice element", func(u *equalUser) { u.Items[1].City = optional.Type[string]{} }, []string{"items.1.city"}},
+		{"slice length", func(u *equalUser) { u.Items = u.Items[:1] }, []string{"items"}},
+		{"map value", func(u *equalUser) { u.Labels["b"] = optional.Type[string]{} }, []string{"labels.b"}},
+		{"map key", func(u *equalUser) { u.Labels["c"] = optional.Some("z") }, []string{"labels.c"}},
+		{"pointer", func(u *equalUser) { u.Manager.Name = optional.Null[string]() }, []string{"manager.name"}},
+		{"nil pointer", func(u *equalUser) { u.Manager = nil }, []string{"manager"}},
+		{"time", func(u *equalUser) { u.Created = u.Created.Add(time.Second) }, []string{"created"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := base(), base()
+			tt.change(&b)
+
+			assert.Equal(t, tt.want, optional.StructDiff(a, &b))
+			assert.Equal(t, tt.want == nil, optional.StructEqual(&a, b))
+		})
+	}
+}
+
+func TestStructEqual_Cycle(t *testing.T) {
+	t.Parallel()
+
+	a := &equalUser{Name: optional.Some("John")}
+	a.Manager = a
+
+	b := &equalUser{Name: optional.Some("John")}
+	b.Manager = b
+
+	assert.True(t, optional.StructEqual(a, b))
+}
+
+func TestStructEqual_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "optional: StructEqual expects structs of the same type, got int and int", func() {
+		optional.StructEqual(1, 2)
+	})
+}
+
+// cmpEqualMethod reports whether go-cmp compares the values of t with their Equal method:
+// cmp.Equal calls the method (T) Equal(I) bool, where T is assignable to I, before recursing into the fields,
+// see the tryMethod of github.com/google/go-cmp/cmp. It returns the result of the method for a and b.
+func cmpEqualMethod(a, b any) (equal, ok bool) {
+	t := reflect.TypeOf(a)
+
+	m, found := t.MethodByName("Equal")
+	if !found {
+		return false, false
+	}
+
+	ft := m.Type
+	if ft.NumIn() != 2 || ft.NumOut() != 1 || ft.Out(0).Kind() != reflect.Bool || !t.AssignableTo(ft.In(1)) {
+		return false, false
+	}
+
+	return m.Func.Call([]reflect.Value{reflect.ValueOf(a), reflect.ValueOf(b)})[0].Bool(), true
+}
+
+func TestType_Equal_Cmp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b any
+		want bool
+	}{
+		{"values", optional.Some([]int{1}), optional.Some([]int{1}), true},
+		{"null and unset", optional.Null[int](), optional.Type[int]{}, false},
+		{"zero value and unset", optional.Some(0), optional.Type[int]{}, false},
+		{"tracked", optional.Tracked[string]{Type: optional.Some("a")}, optional.Tracked[string]{Type: optional.Some("a")}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			equal, ok := cmpEqualMethod(tt.a, tt.b)
+			require.True(t, ok, "go-cmp uses the Equal method")
+			assert.Equal(t, tt.want, equal)
+		})
+	}
+}
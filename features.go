@@ -0,0 +1,116 @@
+package optional
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// FeatureResolver resolves the values of feature flags, such as by the client of an OpenFeature
+// (https://openfeature.dev) provider, for [Feature] and [ResolveFeatures]. The state tells whether the flag
+// has no value, such as when the flag is not found or the default value is returned, or is explicitly disabled.
+type FeatureResolver interface {
+	ResolveFeature(ctx context.Context, flag string) (value any, state State, err error)
+}
+
+// FeatureResolverFunc is the function implementing [FeatureResolver], adapting the client of a flag provider:
+//
+//	r := optional.FeatureResolverFunc(func(ctx context.Context, flag string) (any, optional.State, error) {
+//		d, err := client.ObjectValueDetails(ctx, flag, nil, openfeature.EvaluationContext{})
+//		switch {
+//		case d.Reason == openfeature.DisabledReason:
+//			return nil, optional.StateNull, nil
+//		case d.ErrorCode == openfeature.FlagNotFoundCode, d.Reason == openfeature.DefaultReason:
+//			return nil, optional.StateUnset, nil
+//		}
+//		return d.Value, optional.StateValue, err
+//	})
+type FeatureResolverFunc func(ctx context.Context, flag string) (any, State, error)
+
+// ResolveFeature calls f(ctx, flag).
+func (f FeatureResolverFunc) ResolveFeature(ctx context.Context, flag string) (any, State, error) {
+	return f(ctx, flag)
+}
+
+// Feature resolves the feature flag into [Type]: unset when the flag has no value, null when it is disabled
+// or resolved to nil, and set to the value converted to T otherwise.
+func Feature[T any](ctx context.Context, r FeatureResolver, flag string) (Type[T], error) {
+	var t Type[T]
+
+	if err := resolveFeature(ctx, r, flag, reflect.ValueOf(&t).Elem()); err != nil {
+		return Type[T]{}, err
+	}
+
+	return t, nil
+}
+
+// ResolveFeatures resolves the fields of the struct pointed to by v tagged with the names of the feature flags,
+// such as `feature:"new-checkout"`, like [Feature] does, so the presence-aware configuration structs are filled
+// from the flags at read time. The fields of embedded structs are resolved too. The fields other than [Type]
+// are left untouched by the flags without a value and set to the zero values by the disabled flags.
+func ResolveFeatures(ctx context.Context, r FeatureResolver, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: ResolveFeatures expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return resolveFeatures(ctx, r, rv.Elem())
+}
+
+func resolveFeatures(ctx context.Context, r FeatureResolver, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if _, ok := f.Tag.Lookup("feature"); !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct && !isPresenceType(f.Type) {
+				if err := resolveFeatures(ctx, r, v.Field(i)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		name, ok := tagName(f, "feature")
+		if !ok || !f.IsExported() {
+			continue
+		}
+
+		if err := resolveFeature(ctx, r, name, v.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveFeature stores the resolved flag into the addressable value v.
+func resolveFeature(ctx context.Context, r FeatureResolver, flag string, v reflect.Value) error {
+	value, state, err := r.ResolveFeature(ctx, flag)
+	if err != nil {
+		return fmt.Errorf("optional: feature flag %q: %w", flag, err)
+	}
+
+	if state == StateValue && value == nil {
+		state = StateNull
+	}
+
+	switch state {
+	case StateUnset:
+		if a, ok := asAccessor(v); ok && isPresenceType(v.Type()) {
+			a.mark(false, false)
+		}
+
+		return nil
+	case StateNull:
+		value = nil
+	}
+
+	if err := setPointerValue(v, value); err != nil {
+		return fmt.Errorf("optional: feature flag %q: %w", flag, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package optional_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// fakeFeatures resolves the flags from the map, the absent flags have no value and the nil ones are disabled.
+type fakeFeatures map[string]any
+
+func (f fakeFeatures) ResolveFeature(_ context.Context, flag string) (any, optional.State, error) {
+	v, ok := f[flag]
+
+	switch {
+	case !ok:
+		return nil, optional.StateUnset, nil
+	case v == nil:
+		return nil, optional.StateNull, nil
+	}
+
+	if err, ok := v.(error); ok {
+		return nil, optional.StateUnset, err
+	}
+
+	return v, optional.StateValue, nil
+}
+
+func TestResolveFeatures(t *testing.T) {
+	t.Parallel()
+
+	type limits struct {
+		Burst optional.Type[int] `feature:"burst"`
+	}
+
+	type config struct {
+		limits
+
+		Checkout optional.Type[bool]     `feature:"new-checkout"`
+		Rate     optional.Type[int]      `feature:"rate"`
+		Banner   optional.Type[string]   `feature:"banner"`
+		Regions  optional.Type[[]string] `feature:"regions"`
+		Timeout  int                     `feature:"timeout"`
+		Retries  int                     `feature:"retries"`
+		Name     string
+	}
+
+	features := fakeFeatures{
+		"new-checkout": true,
+		"rate":         float64(10),
+		"banner":       nil,
+		"regions":      []any{"eu", "us"},
+		"timeout":      nil,
+		"burst":        float64(5),
+	}
+
+	got := config{
+		Banner:  optional.Some("old"),
+		Timeout: 30,
+		Retries: 3,
+		Name:    "api",
+	}
+	got.Checkout.SetValue(false)
+
+	require.NoError(t, optional.ResolveFeatures(context.Background(), features, &got))
+
+	assert.Equal(t, config{
+		limits:   limits{Burst: optional.Some(5)},
+		Checkout: optional.Some(true),
+		Rate:     optional.Some(10),
+		Banner:   optional.Null[string](),
+		Regions:  optional.Some([]string{"eu", "us"}),
+		Retries:  3,
+		Name:     "api",
+	}, got)
+
+	delete(features, "new-checkout")
+
+	require.NoError(t, optional.ResolveFeatures(context.Background(), features, &got))
+	assert.False(t, got.Checkout.IsSet())
+}
+
+func TestFeature(t *testing.T) {
+	t.Parallel()
+
+	errProvider := errors.New("provider is down")
+
+	features := fakeFeatures{"rate": float64(10), "off": nil, "broken": errProvider, "bad": "ten"}
+
+	tests := [...]struct {
+		name    string
+		flag    string
+		want    optional.Type[int]
+		wantErr string
+	}{
+		{"value", "rate", optional.Some(10), ""},
+		{"no value", "missing", optional.Type[int]{}, ""},
+		{"disabled", "off", optional.Null[int](), ""},
+		{"provider error", "broken", optional.Type[int]{}, `optional: feature flag "broken": provider is down`},
+		{
+			"conversion error", "bad", optional.Type[int]{},
+			`optional: feature flag "bad": optional: cannot convert string to int: ` +
+				`json: cannot unmarshal string into Go value of type int`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := optional.Feature[int](context.Background(), features, tt.flag)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var fieldCodecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{
+	m: map[string]Codec{},
+}
+
+// RegisterFieldCodec registers the codec for the fields tagged with `optional:"codec=<name>"`, replacing
+// the previously registered one, so a single field can use the encoding quirks of a partner API, such as
+// the dates in RFC 1123, without a named type for each quirk:
+//
+//	optional.RegisterFieldCodec("rfc1123time", optional.Codec{
+//		Marshal: func(v any) ([]byte, error) {
+//			return json.Marshal(v.(time.Time).Format(time.RFC1123))
+//		},
+//		Unmarshal: func(data []byte, v any) error {
+//			var s string
+//			if err := json.Unmarshal(data, &s); err != nil {
+//				return err
+//			}
+//
+//			t, err := time.Parse(time.RFC1123, s)
+//			*v.(*time.Time) = t
+//
+//			return err
+//		},
+//	})
+//
+// The codec is used by [Marshal] and [Decoder] for the values of the fields, such as the values of the set
+// [Type] fields: Marshal receives the value and returns its JSON encoding, Unmarshal receives the JSON
+// encoding of the value, never null, and the pointer to the value. The presence is handled as usual:
+// unset fields are omitted, null sets [Type] fields to null and other fields to zero.
+func RegisterFieldCodec(name string, c Codec) {
+	fieldCodecs.Lock()
+	defer fieldCodecs.Unlock()
+
+	fieldCodecs.m[name] = c
+}
+
+func lookupFieldCodec(name string) (Codec, error) {
+	fieldCodecs.RLock()
+	defer fieldCodecs.RUnlock()
+
+	c, ok := fieldCodecs.m[name]
+	if !ok {
+		return Codec{}, fmt.Errorf("unknown codec %q", name)
+	}
+
+	return c, nil
+}
+
+// encodeFieldValue encodes the value of the field with the codec of the field, if any.
+func (e *encoder) encodeFieldValue(v reflect.Value, f jsonField) error {
+	if f.codec == "" {
+		return e.encodeMember(v, f.quoted)
+	}
+
+	c, err := lookupFieldCodec(f.codec)
+	if err != nil {
+		return err
+	}
+
+	b, err := c.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+
+	if !json.Valid(b) {
+		return fmt.Errorf("codec %q returned invalid JSON", f.codec)
+	}
+
+	e.buf.Write(b)
+
+	return nil
+}
+
+// decodeCodecAt decodes the raw value into the field v with the codec named by the tag of the field.
+func decodeCodecAt(raw json.RawMessage, v reflect.Value, codec string) error {
+	c, err := lookupFieldCodec(codec)
+	if err != nil {
+		return err
+	}
+
+	return decodeWithCodec(raw, v, c)
+}
+
+// decodeWithCodec decodes the raw value into v with the codec, setting the presence of [Type] and [Tracked]
+// values itself, so the codec decodes only the inner values.
+func decodeWithCodec(raw json.RawMessage, v reflect.Value, c Codec) error {
+	if isTrackedType(v.Type()) {
+		v = v.Field(0)
+	}
+
+	if isOptionalType(v.Type()) {
+		a, _ := asAccessor(v)
+
+		if isNull(raw) {
+			a.mark(true, true)
+
+			return nil
+		}
+
+		a.mark(false, false)
+		a.mark(true, false)
+
+		v = a.value()
+	}
+
+	if isNull(raw) {
+		v.Set(reflect.Zero(v.Type()))
+
+		return nil
+	}
+
+	return c.Unmarshal(raw, v.Addr().Interface())
+}
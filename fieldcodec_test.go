@@ -0,0 +1,117 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func init() {
+	optional.RegisterFieldCodec("rfc1123time", optional.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			return json.Marshal(v.(time.Time).Format(time.RFC1123))
+		},
+		Unmarshal: func(data []byte, v any) error {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+
+			t, err := time.Parse(time.RFC1123, s)
+			*v.(*time.Time) = t
+
+			return err
+		},
+	})
+
+	optional.RegisterFieldCodec("broken", optional.Codec{
+		Marshal:   func(any) ([]byte, error) { return []byte("{"), nil },
+		Unmarshal: func([]byte, any) error { return nil },
+	})
+}
+
+type partnerOrder struct {
+	ID        int                      `json:"id"`
+	CreatedAt time.Time                `json:"created_at" optional:"codec=rfc1123time"`
+	ShippedAt optional.Type[time.Time] `json:"shipped_at" optional:"codec=rfc1123time"`
+}
+
+func TestRegisterFieldCodec(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	shipped := time.Date(2024, time.March, 2, 12, 30, 0, 0, time.UTC)
+
+	tests := [...]struct {
+		name  string
+		order partnerOrder
+		json  string
+	}{
+		{
+			"value",
+			partnerOrder{ID: 1, CreatedAt: created, ShippedAt: optional.Some(shipped)},
+			`{"id":1,"created_at":"Fri, 01 Mar 2024 10:00:00 UTC","shipped_at":"Sat, 02 Mar 2024 12:30:00 UTC"}`,
+		},
+		{
+			"null",
+			partnerOrder{ID: 1, CreatedAt: created, ShippedAt: optional.Null[time.Time]()},
+			`{"id":1,"created_at":"Fri, 01 Mar 2024 10:00:00 UTC","shipped_at":null}`,
+		},
+		{
+			"unset",
+			partnerOrder{ID: 1, CreatedAt: created},
+			`{"id":1,"created_at":"Fri, 01 Mar 2024 10:00:00 UTC"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := optional.Marshal(tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, tt.json, string(data))
+
+			var got partnerOrder
+
+			require.NoError(t, optional.NewDecoder(strings.NewReader(tt.json)).Decode(&got))
+			assert.Equal(t, tt.order, got)
+		})
+	}
+}
+
+func TestRegisterFieldCodec_Error(t *testing.T) {
+	t.Parallel()
+
+	type unknown struct {
+		At optional.Type[time.Time] `json:"at" optional:"codec=unix"`
+	}
+
+	type broken struct {
+		At time.Time `json:"at" optional:"codec=broken"`
+	}
+
+	_, err := optional.Marshal(unknown{At: optional.Some(time.Time{})})
+	require.ErrorContains(t, err, `unknown codec "unix"`)
+
+	_, err = optional.Marshal(broken{})
+	require.ErrorContains(t, err, `codec "broken" returned invalid JSON`)
+
+	var u unknown
+
+	err = optional.NewDecoder(strings.NewReader(`{"at":1}`)).Decode(&u)
+	require.EqualError(t, err, `optional: field "at": unknown codec "unix"`)
+
+	var o partnerOrder
+
+	err = optional.NewDecoder(strings.NewReader(`{"shipped_at":"yesterday"}`)).Decode(&o)
+	require.ErrorContains(t, err, `optional: field "shipped_at": parsing time "yesterday"`)
+}
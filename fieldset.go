@@ -0,0 +1,94 @@
+package optional
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithFields makes [Marshal], [MarshalJSONAPI] and [WriteJSON] emit only the requested fields, such as the sparse fieldsets
+// of JSON:API requested with `fields[user]=name,email`. The unset fields are omitted even when requested.
+// The fields are the dotted paths of the JSON names: "address" requests the whole address, while "address.city"
+// requests only its city. The fields of the elements of slices, arrays and maps are requested by the same paths
+// as the fields of the struct values, so the fieldsets apply to collections too. Without the option, or with no
+// fields, all the fields are emitted.
+func WithFields(fields ...string) Option {
+	return func(o *options) {
+		if len(fields) == 0 {
+			o.fields = nil
+
+			return
+		}
+
+		o.fields = newFieldset(fields)
+	}
+}
+
+// fieldset is the set of the requested fields with their ancestors.
+type fieldset struct {
+	fields  map[string]bool
+	parents map[string]bool
+}
+
+func newFieldset(fields []string) *fieldset {
+	fs := &fieldset{fields: map[string]bool{}, parents: map[string]bool{}}
+
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+
+		fs.fields[f] = true
+
+		for i := strings.IndexByte(f, '.'); i != -1; i = nextDot(f, i) {
+			fs.parents[f[:i]] = true
+		}
+	}
+
+	return fs
+}
+
+// allows reports whether the field of the dotted path is requested, is inside a requested field
+// or contains a requested field.
+func (fs *fieldset) allows(path string) bool {
+	if fs.fields[path] || fs.parents[path] {
+		return true
+	}
+
+	for i := strings.IndexByte(path, '.'); i != -1; i = nextDot(path, i) {
+		if fs.fields[path[:i]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextDot returns the index of the dot following the one at i, or -1.
+func nextDot(s string, i int) int {
+	if j := strings.IndexByte(s[i+1:], '.'); j != -1 {
+		return i + 1 + j
+	}
+
+	return -1
+}
+
+// hasFields reports whether the values of t may contain structs, whose fields are selected by [WithFields].
+func hasFields(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Struct, reflect.Interface:
+			return true
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				return false
+			}
+
+			t = t.Elem()
+		case reflect.Ptr, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestWithFields(t *testing.T) {
+	t.Parallel()
+
+	type geo struct {
+		Lat float64 `json:"lat"`
+		Lng float64 `json:"lng"`
+	}
+
+	type address struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+		Geo    geo                   `json:"geo"`
+	}
+
+	type user struct {
+		ID      int                    `json:"id"`
+		Name    optional.Type[string]  `json:"name"`
+		Email   optional.Type[string]  `json:"email"`
+		Phone   optional.Type[string]  `json:"phone"`
+		Avatar  []byte                 `json:"avatar"`
+		Address optional.Type[address] `json:"address"`
+		Friends []user                 `json:"friends"`
+	}
+
+	v := user{
+		ID:     1,
+		Name:   optional.Some("John"),
+		Email:  optional.Null[string](),
+		Avatar: []byte("png"),
+		Address: optional.Some(address{
+			City:   optional.Some("Paris"),
+			Street: optional.Some("Rivoli"),
+			Geo:    geo{Lat: 48.8, Lng: 2.3},
+		}),
+		Friends: []user{{ID: 2, Name: optional.Some("Jane"), Email: optional.Some("jane@example.com")}},
+	}
+
+	tests := [...]struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{
+			"all",
+			nil,
+			`{"id":1,"name":"John","email":null,"avatar":"cG5n",` +
+				`"address":{"city":"Paris","street":"Rivoli","geo":{"lat":48.8,"lng":2.3}},` +
+				`"friends":[{"id":2,"name":"Jane","email":"jane@example.com","avatar":null,"friends":null}]}`,
+		},
+		{"requested and set", []string{"name", "email", "phone"}, `{"name":"John","email":null}`},
+		{"plain", []string{"id", "avatar"}, `{"id":1,"avatar":"cG5n"}`},
+		{"whole nested", []string{"address"}, `{"address":{"city":"Paris","street":"Rivoli","geo":{"lat":48.8,"lng":2.3}}}`},
+		{"nested", []string{"address.city", " address.geo.lat "}, `{"address":{"city":"Paris","geo":{"lat":48.8}}}`},
+		{"collection", []string{"id", "friends.name"}, `{"id":1,"friends":[{"name":"Jane"}]}`},
+		{"unknown", []string{"unknown"}, `{}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := optional.Marshal(v, optional.WithFields(tt.fields...))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package optional
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PFlagValue is the value of a command line flag, it satisfies both [flag.Value] and the Value of pflag.
+type PFlagValue interface {
+	flag.Value
+	Type() string
+	IsBoolFlag() bool
+}
+
+// FlagValue returns the command line flag value bound to t, so [Type] can be used with [flag.Var]
+// to distinguish a flag that was not passed from a flag passed with the zero value:
+//
+//	var limit optional.Type[int]
+//
+//	flag.Var(optional.FlagValue(&limit), "limit", "maximum number of items")
+func FlagValue[T any](t *Type[T]) PFlagValue {
+	return &flagValue[T]{t: t}
+}
+
+// flagValue adapts a [Type] to [PFlagValue].
+type flagValue[T any] struct {
+	t *Type[T]
+}
+
+// Set parses the value from its textual representation, the flag becomes set.
+func (v *flagValue[T]) Set(s string) error {
+	v.t.mark(false, false)
+
+	if err := setText(v.t.value(), s); err != nil {
+		return err
+	}
+
+	v.t.mark(true, false)
+
+	return nil
+}
+
+// String returns the textual representation of the value, or an empty string if the value is unset or null.
+func (v *flagValue[T]) String() string {
+	if v == nil || v.t == nil || v.t.f != flagSet {
+		return ""
+	}
+
+	return fmt.Sprint(v.t.V)
+}
+
+// IsBoolFlag allows passing boolean flags without a value, such as -verbose.
+func (v *flagValue[T]) IsBoolFlag() bool {
+	return reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Bool
+}
+
+// Type returns the name of the value type shown in the usage of pflag, such as "int" or "duration".
+func (v *flagValue[T]) Type() string {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if rt == durationType {
+		return "duration"
+	}
+
+	return rt.String()
+}
+
+// flagValue lets [Flags] bind the fields without knowing T.
+func (t *Type[T]) flagValue() PFlagValue {
+	return FlagValue(t)
+}
+
+// flagBinder is implemented by *[Type].
+type flagBinder interface {
+	flagValue() PFlagValue
+}
+
+// Flag describes a command line flag bound to a [Type] field by [Flags].
+type Flag struct {
+	Name      string
+	Shorthand string
+	Usage     string
+	Value     PFlagValue
+}
+
+// Flags returns the flags bound to the [Type] fields of the struct pointed to by v with the `flag` tag,
+// such as `flag:"limit,l" usage:"maximum number of items"`, where the optional part after the comma
+// is the shorthand. The fields are set only when the flags are actually passed, which is what the
+// Changed method of pflag reports, so the struct can be used as a patch directly.
+//
+// Register the flags with https://github.com/spf13/cobra:
+//
+//	flags, err := optional.Flags(&opts)
+//	if err != nil {
+//		return err
+//	}
+//
+//	for _, f := range flags {
+//		cmd.Flags().VarP(f.Value, f.Name, f.Shorthand, f.Usage)
+//
+//		if f.Value.IsBoolFlag() {
+//			cmd.Flags().Lookup(f.Name).NoOptDefVal = "true"
+//		}
+//	}
+func Flags(v any) ([]Flag, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optional: Flags expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return appendFlags(nil, rv.Elem()), nil
+}
+
+func appendFlags(flags []Flag, v reflect.Value) []Flag {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+
+		tag, ok := f.Tag.Lookup("flag")
+		if !ok || tag == "-" {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				flags = appendFlags(flags, fv)
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		b, ok := fv.Addr().Interface().(flagBinder)
+		if !ok {
+			continue
+		}
+
+		name, short, _ := strings.Cut(tag, ",")
+
+		flags = append(flags, Flag{
+			Name:      name,
+			Shorthand: short,
+			Usage:     f.Tag.Get("usage"),
+			Value:     b.flagValue(),
+		})
+	}
+
+	return flags
+}
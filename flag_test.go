@@ -0,0 +1,93 @@
+package optional_test
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_Flag(t *testing.T) {
+	t.Parallel()
+
+	var (
+		name    optional.Type[string]
+		limit   optional.Type[int]
+		verbose optional.Type[bool]
+		timeout optional.Type[time.Duration]
+	)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(optional.FlagValue(&name), "name", "")
+	fs.Var(optional.FlagValue(&limit), "limit", "")
+	fs.Var(optional.FlagValue(&verbose), "verbose", "")
+	fs.Var(optional.FlagValue(&timeout), "timeout", "")
+
+	require.NoError(t, fs.Parse([]string{"-limit", "0", "-verbose", "-timeout=5s"}))
+
+	assert.False(t, name.IsSet())
+	assert.Equal(t, optional.Some(0), limit)
+	assert.Equal(t, optional.Some(true), verbose)
+	assert.Equal(t, optional.Some(5*time.Second), timeout)
+
+	assert.Equal(t, "", optional.FlagValue(&name).String())
+	assert.Equal(t, "0", optional.FlagValue(&limit).String())
+	assert.Equal(t, "5s", optional.FlagValue(&timeout).String())
+	assert.Equal(t, "{true 1}", fmt.Sprint(verbose), "Type keeps the default format")
+
+	require.Error(t, fs.Parse([]string{"-limit", "some"}))
+}
+
+func TestFlagValue_Type(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "int", optional.FlagValue(&optional.Type[int]{}).Type())
+	assert.Equal(t, "string", optional.FlagValue(&optional.Type[string]{}).Type())
+	assert.Equal(t, "duration", optional.FlagValue(&optional.Type[time.Duration]{}).Type())
+}
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+
+	type options struct {
+		Name    optional.Type[string] `flag:"name,n" usage:"user name"`
+		Limit   optional.Type[int]    `flag:"limit"`
+		Verbose optional.Type[bool]   `flag:"verbose,v"`
+		Skipped optional.Type[int]
+		Plain   string `flag:"plain"`
+	}
+
+	var opts options
+
+	flags, err := optional.Flags(&opts)
+	require.NoError(t, err)
+	require.Len(t, flags, 3)
+
+	assert.Equal(t, "name", flags[0].Name)
+	assert.Equal(t, "n", flags[0].Shorthand)
+	assert.Equal(t, "user name", flags[0].Usage)
+	assert.Equal(t, "limit", flags[1].Name)
+	assert.Equal(t, "", flags[1].Shorthand)
+	assert.True(t, flags[2].Value.IsBoolFlag())
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range flags {
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+
+	require.NoError(t, fs.Parse([]string{"-limit=5"}))
+
+	assert.False(t, opts.Name.IsSet())
+	assert.Equal(t, optional.Some(5), opts.Limit)
+	assert.False(t, opts.Verbose.IsSet())
+
+	_, err = optional.Flags(opts)
+	require.EqualError(t, err, "optional: Flags expects a non-nil pointer to a struct, got optional_test.options")
+}
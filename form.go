@@ -0,0 +1,153 @@
+package optional
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// decodeValues fills the fields of the struct v tagged with key from the textual values.
+// Fields absent from values are left untouched, so optional fields stay unset.
+func decodeValues(values map[string][]string, v reflect.Value, key string, o options) error {
+	return decodeFields(func(name string) []string { return values[name] }, nil, v, key, o)
+}
+
+// decodeForm is like decodeValues, but also fills the fields of type *multipart.FileHeader
+// and []*multipart.FileHeader, including optional ones, from the uploaded files.
+func decodeForm(values map[string][]string, files map[string][]*multipart.FileHeader, v reflect.Value, key string, o options) error {
+	return decodeFields(func(name string) []string { return values[name] }, files, v, key, o)
+}
+
+// decodeFields fills the fields of the struct v tagged with key from the values returned by get.
+func decodeFields(get func(name string) []string, files map[string][]*multipart.FileHeader, v reflect.Value, key string, o options) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+
+		name, ok := tagName(f, key)
+		if !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if err := decodeFields(get, files, fv, key, o); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if fhs := files[name]; len(fhs) != 0 && isFileField(fv) {
+			decodeFiles(fhs, fv)
+
+			continue
+		}
+
+		vals := get(name)
+		if len(vals) == 0 {
+			continue
+		}
+
+		if err := decodeValue(vals, fv, o); err != nil {
+			return fmt.Errorf("optional: field %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(vals []string, fv reflect.Value, o options) error {
+	empty := len(vals) == 1 && vals[0] == ""
+	null := o.null != "" && len(vals) == 1 && vals[0] == o.null
+
+	// A file input without a selected file is sent as an empty textual part.
+	file := isFileField(fv)
+	if file && !empty {
+		return errors.New("optional: expected a file, got a value")
+	}
+
+	a, isOptional := asAccessor(fv)
+	if !isOptional {
+		switch {
+		case null:
+			fv.Set(reflect.Zero(fv.Type()))
+
+			return nil
+		case file || empty && o.empty != EmptyValue:
+			return nil
+		}
+
+		return setTexts(fv, vals, o.separator)
+	}
+
+	if null {
+		a.mark(true, true)
+
+		return nil
+	}
+
+	if empty {
+		switch o.empty {
+		case EmptyUnset:
+			return nil
+		case EmptyNull:
+			a.mark(true, true)
+
+			return nil
+		}
+	}
+
+	a.mark(false, false)
+
+	if !file {
+		if err := setTexts(a.value(), vals, o.separator); err != nil {
+			return err
+		}
+	}
+
+	a.mark(true, false)
+
+	return nil
+}
+
+func isFileType(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeadersType
+}
+
+func isFileField(fv reflect.Value) bool {
+	if a, ok := asAccessor(fv); ok {
+		return isFileType(a.value().Type())
+	}
+
+	return isFileType(fv.Type())
+}
+
+// decodeFiles stores the uploaded files into the file field fv.
+func decodeFiles(fhs []*multipart.FileHeader, fv reflect.Value) {
+	dst := fv
+
+	a, isOptional := asAccessor(fv)
+	if isOptional {
+		dst = a.value()
+	}
+
+	if dst.Type() == fileHeadersType {
+		dst.Set(reflect.ValueOf(fhs))
+	} else {
+		dst.Set(reflect.ValueOf(fhs[0]))
+	}
+
+	if isOptional {
+		a.mark(true, false)
+	}
+}
@@ -0,0 +1,18 @@
+package optional
+
+import "fmt"
+
+var _ fmt.GoStringer = Type[int]{}
+
+// GoString implements the [fmt.GoStringer] interface, so the values are printed with the %#v verb, such as
+// in the failure messages of tests, as Some(value), Null or Unset instead of the fields of the struct.
+func (t Type[T]) GoString() string {
+	switch t.State() {
+	case StateNull:
+		return "Null"
+	case StateValue:
+		return fmt.Sprintf("Some(%#v)", t.V)
+	}
+
+	return "Unset"
+}
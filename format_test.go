@@ -0,0 +1,27 @@
+package optional_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_GoString(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name  optional.Type[string]
+		Age   optional.Type[int]
+		Email optional.Type[string]
+		Score optional.Tracked[float64]
+	}
+
+	u := user{Name: optional.Some("a"), Age: optional.Null[int]()}
+	u.Score.SetValue(1.5)
+
+	assert.Equal(t, `optional_test.user{Name:Some("a"), Age:Null, Email:Unset, Score:Some(1.5)}`, fmt.Sprintf("%#v", u))
+	assert.Equal(t, `Some([]int{1})`, fmt.Sprintf("%#v", optional.Some([]int{1})))
+}
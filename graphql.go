@@ -0,0 +1,136 @@
+package optional
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface of https://github.com/99designs/gqlgen,
+// so [Type] can be used as a scalar or a field of an input object. A null input sets the value to null,
+// absent inputs are never unmarshalled, so the value stays unset.
+func (t *Type[T]) UnmarshalGQL(v any) error {
+	t.mark(false, false)
+
+	if v == nil {
+		t.mark(true, true)
+
+		return nil
+	}
+
+	if u, ok := any(&t.V).(interface{ UnmarshalGQL(v any) error }); ok {
+		if err := u.UnmarshalGQL(v); err != nil {
+			return err
+		}
+	} else if err := assign(reflect.ValueOf(&t.V).Elem(), v); err != nil {
+		return err
+	}
+
+	t.mark(true, false)
+
+	return nil
+}
+
+// MarshalGQL implements the graphql.Marshaler interface of https://github.com/99designs/gqlgen.
+// Unset and null values are written as null. It panics if T cannot be marshalled, as the marshallers
+// of gqlgen do, the errors are returned by [Type.MarshalGQLContext] preferred by gqlgen.
+func (t Type[T]) MarshalGQL(w io.Writer) {
+	if err := t.MarshalGQLContext(context.Background(), w); err != nil {
+		panic(err)
+	}
+}
+
+// MarshalGQLContext implements the graphql.ContextMarshaler interface of https://github.com/99designs/gqlgen.
+// Unset and null values are written as null.
+func (t Type[T]) MarshalGQLContext(ctx context.Context, w io.Writer) error {
+	if t.f != flagSet {
+		_, err := io.WriteString(w, "null")
+
+		return err
+	}
+
+	switch m := any(t.V).(type) {
+	case interface {
+		MarshalGQLContext(ctx context.Context, w io.Writer) error
+	}:
+		return m.MarshalGQLContext(ctx, w)
+	case interface{ MarshalGQL(w io.Writer) }:
+		m.MarshalGQL(w)
+
+		return nil
+	}
+
+	b, err := marshaller(t.V)
+	if err != nil {
+		return fmt.Errorf("optional: MarshalGQL: %w", err)
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// FromOmittable converts the graphql.Omittable of a pointer of https://github.com/99designs/gqlgen into [Type]:
+// an omitted value is unset and a nil pointer is null.
+//
+//	name := optional.FromOmittable[string](input.Name) // input.Name is graphql.Omittable[*string]
+func FromOmittable[T any](o interface{ ValueOK() (*T, bool) }) Type[T] {
+	v, ok := o.ValueOK()
+
+	switch {
+	case !ok:
+		return Type[T]{}
+	case v == nil:
+		return Null[T]()
+	}
+
+	return Some(*v)
+}
+
+// ToOmittable converts [Type] into the graphql.Omittable of a pointer of https://github.com/99designs/gqlgen
+// created by of, usually graphql.OmittableOf: an unset value is omitted and a null value is a nil pointer.
+//
+//	input.Name = optional.ToOmittable(name, graphql.OmittableOf[*string])
+func ToOmittable[T, O any](t Type[T], of func(*T) O) O {
+	switch t.f {
+	case 0:
+		var omitted O
+
+		return omitted
+	case flagSet | flagNull:
+		return of(nil)
+	}
+
+	v := t.V
+
+	return of(&v)
+}
+
+var graphQLScalars sync.Map // map[reflect.Type]string
+
+// RegisterGraphQLScalar sets the name of the custom scalar of the schema of
+// https://github.com/graph-gophers/graphql-go implemented by [Type] of T.
+// Unregistered types implement the scalar with the name of T, such as "Time" for [time.Time].
+func RegisterGraphQLScalar[T any](name string) {
+	graphQLScalars.Store(reflect.TypeOf((*T)(nil)).Elem(), name)
+}
+
+// ImplementsGraphQLType implements the custom scalar interface of https://github.com/graph-gophers/graphql-go.
+// It reports whether [Type] of T implements the scalar registered by [RegisterGraphQLScalar].
+func (Type[T]) ImplementsGraphQLType(name string) bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	if n, ok := graphQLScalars.Load(t); ok {
+		return n == name
+	}
+
+	return t.Name() == name
+}
+
+// UnmarshalGraphQL implements the custom scalar interface of https://github.com/graph-gophers/graphql-go.
+// A null input sets the value to null.
+func (t *Type[T]) UnmarshalGraphQL(input any) error {
+	return t.UnmarshalGQL(input)
+}
@@ -0,0 +1,146 @@
+package optional_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_UnmarshalGQL(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `json:"city"`
+	}
+
+	var s optional.Type[string]
+
+	require.NoError(t, s.UnmarshalGQL("some"))
+	assert.Equal(t, optional.Some("some"), s)
+
+	require.NoError(t, s.UnmarshalGQL(nil))
+	assert.Equal(t, optional.Null[string](), s)
+
+	var n optional.Type[int64]
+
+	require.NoError(t, n.UnmarshalGQL(json.Number("42")))
+	assert.Equal(t, optional.Some(int64(42)), n)
+
+	require.NoError(t, n.UnmarshalGQL(7))
+	assert.Equal(t, optional.Some(int64(7)), n)
+
+	var a optional.Type[address]
+
+	require.NoError(t, a.UnmarshalGQL(map[string]any{"city": "Moscow"}))
+	assert.Equal(t, optional.Some(address{City: "Moscow"}), a)
+
+	require.Error(t, n.UnmarshalGQL("some"))
+
+	var small optional.Type[int8]
+
+	require.EqualError(t, small.UnmarshalGQL(int64(1000)), "optional: value 1000 overflows int8")
+	require.EqualError(t, small.UnmarshalGQL(1.5), "optional: value 1.5 is not an integer, cannot convert to int8")
+}
+
+func TestType_MarshalGQL(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input optional.Type[string]
+		want  string
+	}{
+		{"unset", optional.Type[string]{}, `null`},
+		{"null", optional.Null[string](), `null`},
+		{"value", optional.Some("some"), `"some"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.input.MarshalGQL(&buf)
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// omittable mimics graphql.Omittable of gqlgen.
+type omittable[T any] struct {
+	value T
+	set   bool
+}
+
+func (o omittable[T]) ValueOK() (T, bool) {
+	return o.value, o.set
+}
+
+func TestType_MarshalGQL_Error(t *testing.T) {
+	t.Parallel()
+
+	v := optional.Some(func() {})
+
+	require.ErrorContains(t, v.MarshalGQLContext(context.Background(), io.Discard), "optional: MarshalGQL: json: unsupported type")
+	assert.Panics(t, func() { v.MarshalGQL(io.Discard) })
+}
+
+func TestFromOmittable(t *testing.T) {
+	t.Parallel()
+
+	s := "some"
+
+	assert.Equal(t, optional.Type[string]{}, optional.FromOmittable[string](omittable[*string]{}))
+	assert.Equal(t, optional.Null[string](), optional.FromOmittable[string](omittable[*string]{set: true}))
+	assert.Equal(t, optional.Some("some"), optional.FromOmittable[string](omittable[*string]{value: &s, set: true}))
+}
+
+func TestToOmittable(t *testing.T) {
+	t.Parallel()
+
+	of := func(v *string) omittable[*string] { return omittable[*string]{value: v, set: true} }
+
+	assert.Equal(t, omittable[*string]{}, optional.ToOmittable(optional.Type[string]{}, of))
+	assert.Equal(t, omittable[*string]{set: true}, optional.ToOmittable(optional.Null[string](), of))
+
+	got := optional.ToOmittable(optional.Some("some"), of)
+	assert.Equal(t, optional.Some("some"), optional.FromOmittable[string](got))
+}
+
+type scalar struct {
+	Value string
+}
+
+func TestType_ImplementsGraphQLType(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, optional.Type[time.Time]{}.ImplementsGraphQLType("Time"))
+	assert.False(t, optional.Type[time.Time]{}.ImplementsGraphQLType("DateTime"))
+
+	optional.RegisterGraphQLScalar[scalar]("Custom")
+
+	assert.True(t, optional.Type[scalar]{}.ImplementsGraphQLType("Custom"))
+	assert.False(t, optional.Type[scalar]{}.ImplementsGraphQLType("scalar"))
+}
+
+func TestType_UnmarshalGraphQL(t *testing.T) {
+	t.Parallel()
+
+	var got optional.Type[int32]
+
+	require.NoError(t, got.UnmarshalGraphQL(int32(5)))
+	assert.Equal(t, optional.Some(int32(5)), got)
+
+	require.NoError(t, got.UnmarshalGraphQL(nil))
+	assert.Equal(t, optional.Null[int32](), got)
+
+	require.EqualError(t, got.UnmarshalGraphQL(int64(1<<40)), "optional: value 1099511627776 overflows int32")
+}
@@ -0,0 +1,105 @@
+package optional
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Link is a link object of the HAL format (https://stateless.group/hal_specification.html).
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// HAL wraps the resource of a hypermedia response of the HAL format, adding the "_links" and "_embedded"
+// members to the members of the resource only when they are set, so the links are included conditionally,
+// such as by the permissions of the user, without the post-processing of the JSON:
+//
+//	res := optional.HAL[User]{Resource: user}
+//	res.AddLink("self", optional.Link{Href: "/users/1"})
+//	if canEdit {
+//		res.AddLink("edit", optional.Link{Href: "/users/1/edit"})
+//	}
+type HAL[T any] struct {
+	Resource T
+	Links    Type[map[string]Link]
+	Embedded Type[map[string]any]
+}
+
+// AddLink sets the link of the relation, setting the links.
+func (h *HAL[T]) AddLink(rel string, link Link) {
+	if !h.Links.IsSet() || h.Links.V == nil {
+		h.Links.SetValue(map[string]Link{})
+	}
+
+	h.Links.V[rel] = link
+}
+
+// Embed sets the resource or the slice of the resources embedded under the relation, setting the embedded resources.
+func (h *HAL[T]) Embed(rel string, v any) {
+	if !h.Embedded.IsSet() || h.Embedded.V == nil {
+		h.Embedded.SetValue(map[string]any{})
+	}
+
+	h.Embedded.V[rel] = v
+}
+
+// MarshalJSON encodes the resource like [Marshal] does, followed by the "_links" and "_embedded" members
+// when they are set, null when they are set to null. The resource must be encoded as a JSON object.
+func (h HAL[T]) MarshalJSON() ([]byte, error) {
+	data, err := Marshal(h.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return nil, fmt.Errorf("optional: HAL resource %T is not encoded as a JSON object", h.Resource)
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(data[:len(data)-1])
+
+	first := len(bytes.TrimSpace(data[1:len(data)-1])) == 0
+
+	if h.Links.IsSet() {
+		writeKey(&buf, "_links", &first)
+
+		if err := encodeHALMember(&buf, h.Links); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.Embedded.IsSet() {
+		writeKey(&buf, "_embedded", &first)
+
+		if err := encodeHALMember(&buf, h.Embedded); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func encodeHALMember[V any](buf *bytes.Buffer, v Type[V]) error {
+	if v.IsSetNull() {
+		buf.WriteString("null")
+
+		return nil
+	}
+
+	data, err := Marshal(v.V)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(data)
+
+	return nil
+}
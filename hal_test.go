@@ -0,0 +1,78 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestHAL_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	type order struct {
+		ID int `json:"id"`
+	}
+
+	type user struct {
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+	}
+
+	linked := optional.HAL[user]{Resource: user{Name: optional.Some("John")}}
+	linked.AddLink("self", optional.Link{Href: "/users/1"})
+	linked.AddLink("orders", optional.Link{Href: "/users/1/orders{?page}", Templated: true})
+	linked.Embed("orders", []order{{ID: 7}})
+
+	tests := [...]struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			"links and embedded",
+			linked,
+			`{"name":"John","_links":{"orders":{"href":"/users/1/orders{?page}","templated":true},` +
+				`"self":{"href":"/users/1"}},"_embedded":{"orders":[{"id":7}]}}`,
+		},
+		{
+			"unset",
+			optional.HAL[user]{Resource: user{Name: optional.Some("John")}},
+			`{"name":"John"}`,
+		},
+		{
+			"null",
+			optional.HAL[user]{Links: optional.Null[map[string]optional.Link]()},
+			`{"_links":null}`,
+		},
+		{
+			"nested embedded",
+			optional.HAL[user]{Embedded: optional.Some(map[string]any{
+				"friend": optional.HAL[user]{Resource: user{Email: optional.Some("jane@example.com")}},
+			})},
+			`{"_embedded":{"friend":{"email":"jane@example.com"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := json.Marshal(tt.v)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestHAL_MarshalJSON_NotObject(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.HAL[[]int]{Resource: []int{1}}.MarshalJSON()
+	require.EqualError(t, err, "optional: HAL resource []int is not encoded as a JSON object")
+}
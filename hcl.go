@@ -0,0 +1,65 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeHCLAttributes decodes the attributes of an HCL block of https://github.com/hashicorp/hcl into the fields
+// of the struct pointed to by v with the `hcl` tag, such as `hcl:"port,optional"`. The attributes are the values
+// evaluated from the expressions of the block by their names, encoded to JSON, such as the SimpleJSONValue of
+// the ctyjson package wrapping the values, which keeps the package free of the HCL dependency:
+//
+//	attrs, diags := body.JustAttributes()
+//	values := map[string]json.Marshaler{}
+//
+//	for name, attr := range attrs {
+//		val, diags := attr.Expr.Value(ctx)
+//		// Handle the diagnostics.
+//		values[name] = ctyjson.SimpleJSONValue{Value: val}
+//	}
+//
+//	err := optional.DecodeHCLAttributes(values, &cfg)
+//
+// Unlike gohcl, which fills the absent optional attributes with null, the fields of the absent attributes are
+// left untouched, so [Type] fields stay unset, and the attributes set to null set the fields to null.
+// The fields of labels, blocks and the remaining body are skipped, the attributes without a field are ignored.
+func DecodeHCLAttributes(attrs map[string]json.Marshaler, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeHCLAttributes expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	st := rv.Elem().Type()
+
+	for _, f := range taggedFields(st, "hcl") {
+		tag, ok := st.FieldByIndex(f.index).Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+
+		if _, kind, _ := strings.Cut(tag, ","); kind != "" && kind != "attr" && kind != "optional" {
+			continue
+		}
+
+		attr, ok := attrs[f.name]
+		if !ok {
+			continue
+		}
+
+		data, err := attr.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("optional: DecodeHCLAttributes: attribute %q: %w", f.name, err)
+		}
+
+		fv, _ := fieldByIndex(rv.Elem(), f.index, true)
+
+		if err := unmarshaller(data, fv.Addr().Interface()); err != nil {
+			return fmt.Errorf("optional: DecodeHCLAttributes: attribute %q: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
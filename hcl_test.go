@@ -0,0 +1,56 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecodeHCLAttributes(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Name    string                  `hcl:"name,label"`
+		Host    optional.Type[string]   `hcl:"host"`
+		Port    optional.Type[int]      `hcl:"port,optional"`
+		Proxy   optional.Type[string]   `hcl:"proxy,optional"`
+		Aliases optional.Type[[]string] `hcl:"aliases,attr"`
+		Timeout optional.Type[int]      `hcl:"timeout,optional"`
+		Plain   *string                 `hcl:"plain,optional"`
+		Skipped optional.Type[string]
+	}
+
+	// The attributes as encoded by ctyjson.SimpleJSONValue.
+	attrs := map[string]json.Marshaler{
+		"name":    json.RawMessage(`"label"`),
+		"host":    json.RawMessage(`"localhost"`),
+		"port":    json.RawMessage(`8080`),
+		"proxy":   json.RawMessage(`null`),
+		"aliases": json.RawMessage(`["a","b"]`),
+		"plain":   json.RawMessage(`null`),
+		"unknown": json.RawMessage(`1`),
+		"Skipped": json.RawMessage(`"x"`),
+	}
+
+	var got server
+
+	require.NoError(t, optional.DecodeHCLAttributes(attrs, &got))
+
+	assert.Equal(t, server{
+		Host:    optional.Some("localhost"),
+		Port:    optional.Some(8080),
+		Proxy:   optional.Null[string](),
+		Aliases: optional.Some([]string{"a", "b"}),
+	}, got)
+	assert.False(t, got.Timeout.IsSet(), "the absent attribute is unset")
+
+	err := optional.DecodeHCLAttributes(map[string]json.Marshaler{"port": json.RawMessage(`"port"`)}, &got)
+	require.ErrorContains(t, err, `optional: DecodeHCLAttributes: attribute "port": `)
+
+	require.EqualError(t, optional.DecodeHCLAttributes(attrs, got),
+		"optional: DecodeHCLAttributes expects a non-nil pointer to a struct, got optional_test.server")
+}
@@ -0,0 +1,21 @@
+package optional
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// DecodeHeader decodes the request headers into the fields of the struct pointed to by v
+// with the `header` tag, such as `header:"X-Request-Priority"`.
+//
+// Header names are case-insensitive. Fields of missing headers are left untouched, so [Type] fields stay unset.
+// Fields of a slice type receive all the values of the header.
+func DecodeHeader(h http.Header, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeHeader expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return decodeFields(h.Values, nil, rv.Elem(), "header", newOptions(opts))
+}
@@ -0,0 +1,53 @@
+package optional_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecodeHeader(t *testing.T) {
+	t.Parallel()
+
+	type headers struct {
+		Priority optional.Type[int]      `header:"X-Request-Priority"`
+		Tenant   optional.Type[string]   `header:"x-tenant"`
+		Features optional.Type[[]string] `header:"X-Feature"`
+		Missing  optional.Type[string]   `header:"X-Missing"`
+	}
+
+	h := http.Header{}
+	h.Set("X-Request-Priority", "5")
+	h.Set("X-Tenant", "some")
+	h.Add("X-Feature", "a")
+	h.Add("X-Feature", "b")
+
+	var got headers
+
+	require.NoError(t, optional.DecodeHeader(h, &got))
+
+	assert.Equal(t, optional.Some(5), got.Priority)
+	assert.Equal(t, optional.Some("some"), got.Tenant)
+	assert.Equal(t, optional.Some([]string{"a", "b"}), got.Features)
+	assert.False(t, got.Missing.IsSet())
+}
+
+func TestDecodeHeader_Error(t *testing.T) {
+	t.Parallel()
+
+	type headers struct {
+		Priority optional.Type[int] `header:"X-Request-Priority"`
+	}
+
+	h := http.Header{}
+	h.Set("X-Request-Priority", "high")
+
+	var got headers
+
+	require.Error(t, optional.DecodeHeader(h, &got))
+	require.Error(t, optional.DecodeHeader(h, got))
+}
@@ -0,0 +1,41 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DecodeHook returns the decode hook of https://github.com/go-viper/mapstructure converting
+// map values into [Type]. A nil value sets the field to null, any other value sets the field
+// to the value converted to T. Keys absent from the map leave the fields unset.
+//
+// Nil values reach the hook only when the DecodeNil option of the decoder is enabled.
+// Composite values are converted to T through JSON, so T should use the `json` tags.
+func DecodeHook() func(from, to reflect.Type, data any) (any, error) {
+	return func(_, to reflect.Type, data any) (any, error) {
+		if !isOptionalType(to) {
+			return data, nil
+		}
+
+		if data != nil && reflect.TypeOf(data) == to {
+			return data, nil
+		}
+
+		v := reflect.New(to)
+		a := v.Interface().(accessor)
+
+		if data == nil {
+			a.mark(true, true)
+
+			return v.Elem().Interface(), nil
+		}
+
+		if err := assign(a.value(), data); err != nil {
+			return nil, fmt.Errorf("optional: decode hook: %w", err)
+		}
+
+		a.mark(true, false)
+
+		return v.Elem().Interface(), nil
+	}
+}
@@ -0,0 +1,60 @@
+package optional_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecodeHook(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `json:"city"`
+	}
+
+	tests := [...]struct {
+		name    string
+		to      reflect.Type
+		data    any
+		want    any
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"not optional", reflect.TypeOf(""), "some", "some", require.NoError},
+		{"value", reflect.TypeOf(optional.Type[string]{}), "some", optional.Some("some"), require.NoError},
+		{"null", reflect.TypeOf(optional.Type[string]{}), nil, optional.Null[string](), require.NoError},
+		{"convert", reflect.TypeOf(optional.Type[int64]{}), 42, optional.Some(int64(42)), require.NoError},
+		{"optional", reflect.TypeOf(optional.Type[int]{}), optional.Some(1), optional.Some(1), require.NoError},
+		{
+			"struct",
+			reflect.TypeOf(optional.Type[address]{}),
+			map[string]any{"city": "Moscow"},
+			optional.Some(address{City: "Moscow"}),
+			require.NoError,
+		},
+		{"invalid", reflect.TypeOf(optional.Type[int]{}), "some", nil, require.Error},
+		{"overflow", reflect.TypeOf(optional.Type[int8]{}), 300, nil, require.Error},
+		{"fraction", reflect.TypeOf(optional.Type[int]{}), 1.5, nil, require.Error},
+		{"negative to uint", reflect.TypeOf(optional.Type[uint]{}), -1, nil, require.Error},
+	}
+
+	hook := optional.DecodeHook()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from reflect.Type
+			if tt.data != nil {
+				from = reflect.TypeOf(tt.data)
+			}
+
+			got, err := hook(from, tt.to, tt.data)
+			tt.wantErr(t, err)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ErrUnsupportedContentType is returned when the request body has a content type that cannot be decoded.
+var ErrUnsupportedContentType = errors.New("optional: unsupported content type")
+
+// ErrEmptyBody is returned by [DecodeRequest] and [DecodePatch] for the missing or blank body
+// when it is required by [WithRequiredBody].
+var ErrEmptyBody = errors.New("optional: empty request body")
+
+// DecodeRequest decodes the HTTP request into the struct pointed to by v.
+//
+// Query parameters are decoded into fields with the `query` tag, headers and cookies into fields
+// with the `header` and `cookie` tags as [DecodeHeader] and [DecodeCookies] do. The body is decoded depending on
+// the Content-Type header: urlencoded and multipart forms are decoded into fields with the `form` tag,
+// uploaded files are decoded into fields of type *multipart.FileHeader or []*multipart.FileHeader,
+// which may be wrapped into [Type]. Other bodies are decoded by the codec registered for the content
+// type with [RegisterContentType], JSON bodies use the current unmarshaller by default, or the codec set
+// on the context of the request by [ContextWithCodec] for the values.
+// Parameters that are not present in the request leave the corresponding [Type] fields unset.
+//
+// A missing, empty or whitespace-only body is decoded as an empty patch leaving the fields unset, like
+// UnmarshalJSON of [Type] tolerates empty input, unless the body is required by [WithRequiredBody].
+func DecodeRequest(r *http.Request, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeRequest expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	o := newOptions(opts)
+
+	if c, ok := CodecFromContext(r.Context()); ok && o.codec == nil {
+		o.codec = &c
+	}
+
+	if err := decodeRequest(r, rv, o); err != nil {
+		return err
+	}
+
+	if o.normalize {
+		return normalizeValue(rv, "", nil)
+	}
+
+	return nil
+}
+
+// RequestValidator validates the HTTP request, such as the ValidateRequest function of the openapi3filter package
+// of https://github.com/getkin/kin-openapi bound to the route of the request.
+type RequestValidator func(r *http.Request) error
+
+// DecodeValidatedRequest validates the HTTP request with validate and decodes it into the struct pointed to by v
+// like [DecodeRequest] does. The error of validate is returned as is, so the errors of the validator can be
+// inspected with [errors.As].
+//
+// The body is read once and handed to validate and to the decoding as sent by the client, so the defaults
+// the validator fills into the body do not mark the fields as set: absent properties stay unset and properties
+// sent as null are set to null. Limit the size of the body with [http.MaxBytesReader].
+//
+//	err := optional.DecodeValidatedRequest(r, &req, func(r *http.Request) error {
+//		return openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+//			Request:    r,
+//			PathParams: pathParams,
+//			Route:      route,
+//		})
+//	})
+func DecodeValidatedRequest(r *http.Request, v any, validate RequestValidator, opts ...Option) error {
+	var body []byte
+
+	if r.Body != nil && r.Body != http.NoBody {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("optional: read body: %w", err)
+		}
+
+		body = b
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := validate(r); err != nil {
+		return err
+	}
+
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return DecodeRequest(r, v, opts...)
+}
+
+func decodeRequest(r *http.Request, rv reflect.Value, o options) error {
+	if err := decodeValues(r.URL.Query(), rv.Elem(), "query", o); err != nil {
+		return err
+	}
+
+	if err := decodeFields(r.Header.Values, nil, rv.Elem(), "header", o); err != nil {
+		return err
+	}
+
+	if err := decodeFields(cookieValues(r), nil, rv.Elem(), "cookie", o); err != nil {
+		return err
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return checkEmptyBody(o)
+	}
+
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		ct = "application/json"
+	}
+
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, ct)
+	}
+
+	switch mt {
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return fmt.Errorf("optional: parse form: %w", err)
+		}
+
+		return decodeValues(r.PostForm, rv.Elem(), "form", o)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(o.maxMemory); err != nil {
+			return fmt.Errorf("optional: parse multipart form: %w", err)
+		}
+
+		return decodeForm(r.MultipartForm.Value, r.MultipartForm.File, rv.Elem(), "form", o)
+	}
+
+	isJSON := mt == "application/json" || strings.HasSuffix(mt, "+json")
+
+	if o.codec != nil && isJSON {
+		return decodeBody(limitJSON(r.Body, o), rv.Interface(), codecUnmarshal(o), o)
+	}
+
+	c, err := lookupContentType(mt)
+	if err != nil {
+		return err
+	}
+
+	body := io.Reader(r.Body)
+	if isJSON {
+		body = limitJSON(body, o)
+	}
+
+	return decodeBody(body, rv.Interface(), c.Unmarshal, o)
+}
+
+// DecodePatch decodes the JSON body of the request as a patch and applies it onto a copy of the current entity.
+//
+// The current entity must be a struct or a pointer to a struct, the result has the same type.
+// Only the fields present in the body are applied: plain fields are replaced by the sent values
+// or reset to zero by null, [Type] fields are decoded with their presence. The names of the fields
+// whose values were changed by the patch are returned in changed. A missing or blank body is an empty patch
+// returning the copy unchanged, unless the body is required by [WithRequiredBody].
+// The values are decoded by the codec set on the context of the request by [ContextWithCodec], if any.
+func DecodePatch(r *http.Request, current any, opts ...Option) (patched any, changed []string, err error) {
+	rv := reflect.ValueOf(current)
+
+	isPtr := rv.Kind() == reflect.Ptr
+	if isPtr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("optional: DecodePatch expects a struct or a pointer to a struct, got %T", current)
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || mt != "application/json" && !strings.HasSuffix(mt, "+json") {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, ct)
+		}
+	}
+
+	cp := reflect.New(rv.Type())
+	cp.Elem().Set(rv)
+
+	o := newOptions(opts)
+
+	if c, ok := CodecFromContext(r.Context()); ok && o.codec == nil {
+		o.codec = &c
+	}
+
+	var doc map[string]json.RawMessage
+
+	if r.Body != nil {
+		err = decodeBody(limitJSON(r.Body, o), &doc, unmarshaller, o)
+	} else {
+		err = checkEmptyBody(o)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed, err = applyJSON(doc, cp.Elem(), o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isPtr {
+		return cp.Interface(), changed, nil
+	}
+
+	return cp.Elem().Interface(), changed, nil
+}
+
+// applyJSON decodes the members of the document into the matching fields of the struct v
+// and returns the names of the changed fields.
+func applyJSON(doc map[string]json.RawMessage, v reflect.Value, o options) ([]string, error) {
+	var changed []string
+
+	for _, f := range jsonFields(v.Type()) {
+		raw, ok := lookupMember(doc, f.name)
+		if !ok {
+			continue
+		}
+
+		fv, _ := fieldByIndex(v, f.index, true)
+		old := fv.Interface()
+
+		if err := applyMember(raw, fv, o); err != nil {
+			return nil, fmt.Errorf("optional: field %q: %w", f.name, err)
+		}
+
+		if !reflect.DeepEqual(old, fv.Interface()) {
+			changed = append(changed, f.name)
+		}
+	}
+
+	return changed, nil
+}
+
+func applyMember(raw json.RawMessage, fv reflect.Value, o options) error {
+	if o.codec != nil {
+		return decodeWithCodec(raw, fv, *o.codec)
+	}
+
+	if u, ok := fv.Addr().Interface().(json.Unmarshaler); ok && isOptionalType(fv.Type()) {
+		return u.UnmarshalJSON(raw)
+	}
+
+	fv.Set(reflect.Zero(fv.Type()))
+
+	if string(raw) == "null" {
+		return nil
+	}
+
+	return unmarshaller(raw, fv.Addr().Interface())
+}
+
+// lookupMember finds the member by the exact name, falling back to the case-insensitive match like encoding/json.
+func lookupMember(doc map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := doc[name]; ok {
+		return raw, true
+	}
+
+	for k, raw := range doc {
+		if strings.EqualFold(k, name) {
+			return raw, true
+		}
+	}
+
+	return nil, false
+}
+
+// WriteJSON writes v to the response as JSON encoded by [Marshal], so unset fields are omitted
+// and fields set to null are written as null.
+//
+// The Content-Type header is set to application/json unless it is already set.
+// The status code is 200 unless changed by [WithStatus]. Nothing is written if encoding fails.
+func WriteJSON(w http.ResponseWriter, v any, opts ...Option) error {
+	o := newOptions(opts)
+
+	b, err := Marshal(v, opts...)
+	if err != nil {
+		return err
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	w.WriteHeader(o.status)
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+func decodeBody(body io.Reader, v any, unmarshal func([]byte, any) error, o options) error {
+	data, err := io.ReadAll(body)
+
+	var le *LimitError
+	if errors.As(err, &le) {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("optional: read body: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return checkEmptyBody(o) // Treat empty body as not setting any value
+	}
+
+	// The strings with escapes are measured exactly only once decoded.
+	if _, ok := body.(*limitReader); ok && json.Valid(data) {
+		if err := checkLimits(data, 0, o); err != nil {
+			return err
+		}
+	}
+
+	return unmarshal(data, v)
+}
+
+// checkEmptyBody reports the missing body when it is required.
+func checkEmptyBody(o options) error {
+	if o.requireBody {
+		return ErrEmptyBody
+	}
+
+	return nil
+}
@@ -0,0 +1,370 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type request struct {
+	Limit optional.Type[int]      `query:"limit"`
+	Tags  optional.Type[[]string] `query:"tag"`
+	Name  optional.Type[string]   `json:"name" form:"name"`
+	Age   optional.Type[int]      `json:"age" form:"age"`
+}
+
+func TestDecodeRequest_Query(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/?limit=10&tag=a&tag=b", nil)
+
+	var got request
+
+	require.NoError(t, optional.DecodeRequest(r, &got))
+
+	assert.True(t, got.Limit.IsSet())
+	assert.Equal(t, 10, got.Limit.V)
+	assert.Equal(t, []string{"a", "b"}, got.Tags.V)
+	assert.False(t, got.Name.IsSet())
+}
+
+func TestDecodeRequest_Body(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json", `{"name":"some","age":null}`},
+		{"json charset", "application/json; charset=utf-8", `{"name":"some","age":null}`},
+		{"no content type", "", `{"name":"some","age":null}`},
+		{"form", "application/x-www-form-urlencoded", `name=some&age=`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var got request
+
+			require.NoError(t, optional.DecodeRequest(r, &got, optional.WithEmpty(optional.EmptyNull)))
+
+			assert.True(t, got.Name.IsSet())
+			assert.Equal(t, "some", got.Name.V)
+			assert.True(t, got.Age.IsSet())
+			assert.True(t, got.Age.IsSetNull())
+			assert.False(t, got.Limit.IsSet())
+		})
+	}
+}
+
+func TestDecodeRequest_Empty(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name    string
+		mode    optional.EmptyMode
+		set     assert.BoolAssertionFunc
+		null    assert.BoolAssertionFunc
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"value", optional.EmptyValue, assert.False, assert.False, require.Error},
+		{"null", optional.EmptyNull, assert.True, assert.True, require.NoError},
+		{"unset", optional.EmptyUnset, assert.False, assert.False, require.NoError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?limit=", nil)
+
+			var got request
+
+			tt.wantErr(t, optional.DecodeRequest(r, &got, optional.WithEmpty(tt.mode)))
+			tt.set(t, got.Limit.IsSet())
+			tt.null(t, got.Limit.IsSetNull())
+		})
+	}
+}
+
+func TestDecodeRequest_EmptyBody(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		body string
+		opts []optional.Option
+		want error
+	}{
+		{"no body", "", nil, nil},
+		{"whitespace", " \n\t", nil, nil},
+		{"required no body", "", []optional.Option{optional.WithRequiredBody()}, optional.ErrEmptyBody},
+		{"required whitespace", " \n", []optional.Option{optional.WithRequiredBody()}, optional.ErrEmptyBody},
+		{"required", `{"name":"some"}`, []optional.Option{optional.WithRequiredBody()}, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/?limit=10", nil)
+			if tt.body != "" {
+				r = httptest.NewRequest(http.MethodPost, "/?limit=10", strings.NewReader(tt.body))
+			}
+
+			var got request
+
+			err := optional.DecodeRequest(r, &got, tt.opts...)
+			if tt.want != nil {
+				require.ErrorIs(t, err, tt.want)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, optional.Some(10), got.Limit)
+			assert.Equal(t, tt.body != "" && strings.TrimSpace(tt.body) != "", got.Name.IsSet())
+		})
+	}
+}
+
+func TestDecodeRequest_UnsupportedContentType(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("some"))
+	r.Header.Set("Content-Type", "text/plain")
+
+	var got request
+
+	err := optional.DecodeRequest(r, &got)
+	require.ErrorIs(t, err, optional.ErrUnsupportedContentType)
+}
+
+func TestDecodeRequest_Multipart(t *testing.T) {
+	t.Parallel()
+
+	type upload struct {
+		Name   optional.Type[string]                  `form:"name"`
+		Avatar optional.Type[*multipart.FileHeader]   `form:"avatar"`
+		Docs   optional.Type[[]*multipart.FileHeader] `form:"docs"`
+		Cover  optional.Type[*multipart.FileHeader]   `form:"cover"`
+		Banner optional.Type[*multipart.FileHeader]   `form:"banner"`
+	}
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+
+	require.NoError(t, mw.WriteField("name", "some"))
+
+	fw, err := mw.CreateFormFile("avatar", "avatar.png")
+	require.NoError(t, err)
+
+	_, err = fw.Write([]byte("png"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		_, err = mw.CreateFormFile("docs", name)
+		require.NoError(t, err)
+	}
+
+	// A file input without a selected file.
+	_, err = mw.CreateFormFile("cover", "")
+	require.NoError(t, err)
+
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var got upload
+
+	require.NoError(t, optional.DecodeRequest(r, &got, optional.WithEmpty(optional.EmptyNull)))
+
+	assert.Equal(t, "some", got.Name.V)
+	require.True(t, got.Avatar.IsSet())
+	assert.Equal(t, "avatar.png", got.Avatar.V.Filename)
+	assert.Equal(t, int64(3), got.Avatar.V.Size)
+	require.True(t, got.Docs.IsSet())
+	assert.Len(t, got.Docs.V, 2)
+	assert.True(t, got.Cover.IsSetNull())
+	assert.False(t, got.Banner.IsSet())
+}
+
+func TestDecodePatch(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `json:"city"`
+	}
+
+	type user struct {
+		ID      int                   `json:"id"`
+		Name    string                `json:"name"`
+		Email   *string               `json:"email"`
+		Tags    []string              `json:"tags"`
+		Address address               `json:"address"`
+		Note    optional.Type[string] `json:"note"`
+	}
+
+	email := "some@example.com"
+	current := user{ID: 1, Name: "some", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow"}}
+
+	tests := [...]struct {
+		name        string
+		body        string
+		want        user
+		wantChanged []string
+	}{
+		{"empty", ``, current, nil},
+		{"whitespace", " \r\n\t", current, nil},
+		{"no fields", `{}`, current, nil},
+		{"same value", `{"name":"some"}`, current, nil},
+		{
+			"values",
+			`{"name":"other","tags":["b"],"address":{"city":"Paris"}}`,
+			user{ID: 1, Name: "other", Email: &email, Tags: []string{"b"}, Address: address{City: "Paris"}},
+			[]string{"name", "tags", "address"},
+		},
+		{
+			"null",
+			`{"email":null}`,
+			user{ID: 1, Name: "some", Tags: []string{"a"}, Address: address{City: "Moscow"}},
+			[]string{"email"},
+		},
+		{"case insensitive", `{"NAME":"other"}`, user{ID: 1, Name: "other", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow"}}, []string{"name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.body))
+
+			got, changed, err := optional.DecodePatch(r, current)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
+func TestDecodePatch_Pointer(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name string                `json:"name"`
+		Note optional.Type[string] `json:"note"`
+	}
+
+	current := &user{Name: "some"}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"other","note":null}`))
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+
+	got, changed, err := optional.DecodePatch(r, current)
+	require.NoError(t, err)
+
+	require.IsType(t, &user{}, got)
+	assert.Equal(t, "other", got.(*user).Name)
+	assert.True(t, got.(*user).Note.IsSetNull())
+	assert.Equal(t, []string{"name", "note"}, changed)
+	assert.Equal(t, "some", current.Name, "current entity must not be modified")
+}
+
+func TestDecodePatch_RequiredBody(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader("\n"))
+
+	_, _, err := optional.DecodePatch(r, user{}, optional.WithRequiredBody())
+	require.ErrorIs(t, err, optional.ErrEmptyBody)
+
+	r = &http.Request{Method: http.MethodPatch, Header: http.Header{}}
+
+	_, _, err = optional.DecodePatch(r, user{}, optional.WithRequiredBody())
+	require.ErrorIs(t, err, optional.ErrEmptyBody)
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	type response struct {
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+		Age   optional.Type[int]    `json:"age"`
+	}
+
+	w := httptest.NewRecorder()
+
+	err := optional.WriteJSON(w, response{Name: optional.Some("some"), Email: optional.Null[string]()},
+		optional.WithStatus(http.StatusCreated))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"name":"some","email":null}`, w.Body.String())
+}
+
+// fillDefaults mimics the request validation of kin-openapi: the body must be an object
+// and the default of the age property is written into the body.
+func fillDefaults(r *http.Request) error {
+	var body map[string]any
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return fmt.Errorf("request body has an error: %w", err)
+	}
+
+	if _, ok := body["age"]; !ok {
+		body["age"] = 18
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	return nil
+}
+
+func TestDecodeValidatedRequest(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPatch, "/?limit=10", strings.NewReader(`{"name":null}`))
+
+	var got request
+
+	require.NoError(t, optional.DecodeValidatedRequest(r, &got, fillDefaults))
+
+	assert.Equal(t, request{Limit: optional.Some(10), Name: optional.Null[string]()}, got,
+		"the default filled by the validator is not decoded as set")
+
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[1]`))
+
+	err := optional.DecodeValidatedRequest(r, &got, fillDefaults)
+
+	var typeErr *json.UnmarshalTypeError
+
+	require.ErrorAs(t, err, &typeErr)
+	require.EqualError(t, err, "request body has an error: json: cannot unmarshal array into Go value of type map[string]interface {}")
+}
@@ -0,0 +1,39 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// INISection is the part of *ini.Section of https://github.com/go-ini/ini used by [DecodeINISection].
+type INISection interface {
+	KeysHash() map[string]string
+}
+
+// DecodeINISection decodes the keys of the INI section into the fields of the struct pointed to by v
+// with the `ini` tag, such as `ini:"port"`.
+//
+// Fields of keys absent from the section are left untouched, so [Type] fields stay unset and migration
+// tooling can tell which keys were actually specified. Keys with an empty value are handled according
+// to [WithEmpty], the sentinel set by [WithNullValue] sets the field to null. Values of slice fields
+// are split by comma unless changed by [WithSeparator].
+func DecodeINISection(s INISection, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeINISection expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	keys := s.KeysHash()
+
+	get := func(name string) []string {
+		if s, ok := keys[name]; ok {
+			return []string{s}
+		}
+
+		return nil
+	}
+
+	o := newOptions(append([]Option{WithSeparator(",")}, opts...))
+
+	return decodeFields(get, nil, rv.Elem(), "ini", o)
+}
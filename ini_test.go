@@ -0,0 +1,52 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// fakeSection mimics *ini.Section.
+type fakeSection map[string]string
+
+func (s fakeSection) KeysHash() map[string]string {
+	return s
+}
+
+func TestDecodeINISection(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Host    optional.Type[string]   `ini:"host"`
+		Port    optional.Type[int]      `ini:"port"`
+		Proxy   optional.Type[string]   `ini:"proxy"`
+		Aliases optional.Type[[]string] `ini:"aliases"`
+		Timeout optional.Type[int]      `ini:"timeout"`
+		Mode    optional.Type[string]   `ini:"mode"`
+	}
+
+	s := fakeSection{
+		"host":    "localhost",
+		"port":    "8080",
+		"proxy":   "none",
+		"aliases": "a,b",
+		"mode":    "",
+	}
+
+	var got server
+
+	require.NoError(t, optional.DecodeINISection(s, &got,
+		optional.WithNullValue("none"), optional.WithEmpty(optional.EmptyNull)))
+
+	assert.Equal(t, optional.Some("localhost"), got.Host)
+	assert.Equal(t, optional.Some(8080), got.Port)
+	assert.Equal(t, optional.Null[string](), got.Proxy)
+	assert.Equal(t, optional.Some([]string{"a", "b"}), got.Aliases)
+	assert.False(t, got.Timeout.IsSet())
+	assert.Equal(t, optional.Null[string](), got.Mode)
+
+	require.Error(t, optional.DecodeINISection(fakeSection{"port": "port"}, &got))
+}
@@ -0,0 +1,106 @@
+package optional
+
+import (
+	"bytes"
+	"sync"
+)
+
+var interner *Interner
+
+// ChangeInterner sets the interner deduplicating the strings decoded by [Type.UnmarshalJSON], so payloads
+// with highly repetitive enum-like strings share a single copy of each of them. By default, there is no
+// interner and every decode allocates a fresh copy. Pass nil to disable interning.
+func ChangeInterner(in *Interner) {
+	interner = in
+}
+
+// Interner is a bounded cache of strings. Once the cache holds the maximum number of strings it is emptied,
+// so the memory stays bounded when the strings are not as repetitive as expected. Interner is safe for
+// concurrent use.
+type Interner struct {
+	mu   sync.Mutex
+	m    map[string]string
+	size int
+}
+
+// NewInterner returns the interner caching up to size strings.
+func NewInterner(size int) *Interner {
+	return &Interner{m: make(map[string]string), size: size}
+}
+
+// Intern returns the cached copy of the string s, caching s if there is none.
+func (in *Interner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if v, ok := in.m[s]; ok {
+		return v
+	}
+
+	in.store(s)
+
+	return s
+}
+
+// internBytes returns the cached copy of the string b without allocating when there is one.
+func (in *Interner) internBytes(b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if v, ok := in.m[string(b)]; ok {
+		return v
+	}
+
+	s := string(b)
+
+	in.store(s)
+
+	return s
+}
+
+func (in *Interner) store(s string) {
+	if len(in.m) >= in.size {
+		in.m = make(map[string]string, len(in.m))
+	}
+
+	if in.size > 0 {
+		in.m[s] = s
+	}
+}
+
+// unmarshalInterned decodes the JSON string data into the string pointed to by p using the interner.
+// Strings without escape sequences are looked up without decoding, other strings are decoded by
+// the current unmarshaller first.
+func unmarshalInterned(in *Interner, data []byte, p *string) error {
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' && isPlainString(data[1:len(data)-1]) {
+		*p = in.internBytes(data[1 : len(data)-1])
+
+		return nil
+	}
+
+	var s string
+
+	if err := unmarshaller(data, &s); err != nil {
+		return err
+	}
+
+	*p = in.Intern(s)
+
+	return nil
+}
+
+// isPlainString reports whether the content of the JSON string is the same as its value,
+// that is there are no escape sequences, control characters and the content is ASCII.
+func isPlainString(b []byte) bool {
+	if bytes.IndexByte(b, '\\') != -1 || bytes.IndexByte(b, '"') != -1 {
+		return false
+	}
+
+	for _, c := range b {
+		if c < 0x20 || c >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
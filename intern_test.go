@@ -0,0 +1,75 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestInterner_Intern(t *testing.T) {
+	t.Parallel()
+
+	in := optional.NewInterner(2)
+
+	assert.Equal(t, "a", in.Intern("a"))
+	assert.Equal(t, "b", in.Intern("b"))
+	assert.Equal(t, "a", in.Intern("a"))
+	assert.Equal(t, "c", in.Intern("c"))
+	assert.Equal(t, "", optional.NewInterner(0).Intern(""))
+}
+
+func TestChangeInterner(t *testing.T) {
+	type some struct {
+		Status optional.Type[string] `json:"status"`
+		Name   optional.Type[string] `json:"name"`
+		Count  optional.Type[int]    `json:"count"`
+	}
+
+	optional.ChangeInterner(optional.NewInterner(16))
+	defer optional.ChangeInterner(nil)
+
+	tests := [...]struct {
+		name  string
+		input string
+		want  some
+	}{
+		{"plain", `{"status":"active","count":1}`, some{Status: optional.Some("active"), Count: optional.Some(1)}},
+		{"escaped", `{"status":"a\"b","name":"é"}`, some{Status: optional.Some(`a"b`), Name: optional.Some("é")}},
+		{"null", `{"status":null}`, some{Status: optional.Null[string]()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got some
+
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	var got some
+
+	require.Error(t, json.Unmarshal([]byte(`{"status":1}`), &got))
+}
+
+func TestChangeInterner_Allocs(t *testing.T) {
+	optional.ChangeInterner(optional.NewInterner(16))
+	defer optional.ChangeInterner(nil)
+
+	data := []byte(`"active"`)
+
+	var v optional.Type[string]
+
+	require.NoError(t, v.UnmarshalJSON(data))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = v.UnmarshalJSON(data)
+	})
+
+	assert.Zero(t, allocs)
+	assert.Equal(t, optional.Some("active"), v)
+}
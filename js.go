@@ -0,0 +1,41 @@
+//go:build js && wasm
+
+package optional
+
+import "syscall/js"
+
+// ToJSValue converts the value into a JS value: unset into undefined, null into null and values into
+// the JS values of their JSON encoding by the current marshaller, so objects and arrays become native
+// JS objects and arrays.
+//
+// Structs with optional fields are converted with [Marshal] and JSON.parse, the unset fields become
+// absent properties.
+func (t Type[T]) ToJSValue() (js.Value, error) {
+	switch {
+	case t.f&flagNull != 0:
+		return js.Null(), nil
+	case t.f&flagSet == 0:
+		return js.Undefined(), nil
+	}
+
+	data, err := marshaller(t.V)
+	if err != nil {
+		return js.Value{}, err
+	}
+
+	return js.Global().Get("JSON").Call("parse", string(data)), nil
+}
+
+// FromJSValue converts the JS value into a [Type]: undefined into an unset value, null into null
+// and other values like unmarshalling their JSON encoding does.
+func FromJSValue[T any](v js.Value) (Type[T], error) {
+	var t Type[T]
+
+	if v.IsUndefined() {
+		return t, nil
+	}
+
+	err := t.UnmarshalJSON([]byte(js.Global().Get("JSON").Call("stringify", v).String()))
+
+	return t, err
+}
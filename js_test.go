@@ -0,0 +1,48 @@
+//go:build js && wasm
+
+package optional_test
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_ToJSValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := optional.Type[string]{}.ToJSValue()
+	require.NoError(t, err)
+	assert.True(t, v.IsUndefined())
+
+	v, err = optional.Null[string]().ToJSValue()
+	require.NoError(t, err)
+	assert.True(t, v.IsNull())
+
+	v, err = optional.Some(map[string]int{"a": 1}).ToJSValue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Get("a").Int())
+}
+
+func TestFromJSValue(t *testing.T) {
+	t.Parallel()
+
+	got, err := optional.FromJSValue[string](js.Undefined())
+	require.NoError(t, err)
+	assert.Equal(t, optional.StateUnset, got.State())
+
+	got, err = optional.FromJSValue[string](js.Null())
+	require.NoError(t, err)
+	assert.Equal(t, optional.StateNull, got.State())
+
+	got, err = optional.FromJSValue[string](js.ValueOf("a"))
+	require.NoError(t, err)
+	assert.True(t, got.Equal(optional.Some("a")))
+
+	_, err = optional.FromJSValue[int](js.ValueOf("a"))
+	require.Error(t, err)
+}
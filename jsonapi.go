@@ -0,0 +1,307 @@
+package optional
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSONAPI returns the JSON:API document (https://jsonapi.org) with the resource object of the struct v,
+// or the resource objects of the slice v, as the primary data.
+//
+// The field tagged `jsonapi:"primary,users"` holds the id of the resource of the type "users". The fields tagged
+// `jsonapi:"relation"` are the relationships, holding the resources or the slices of the resources, which are
+// written as the resource identifiers, and the rest of the fields are the attributes. The members are named
+// by the `json` tags and encoded like [Marshal] does: the unset attributes and relationships are omitted,
+// the ones set to null are written as null. The unset id is omitted, such as of the resources created by the server.
+//
+// [WithFields] selects the attributes and relationships like the fields query parameter of JSON:API does.
+func MarshalJSONAPI(v any, opts ...Option) ([]byte, error) {
+	e := encoder{o: newOptions(opts)}
+
+	rv := derefPointer(reflect.ValueOf(v))
+
+	var err error
+
+	e.buf.WriteString(`{"data":`)
+
+	switch {
+	case !rv.IsValid():
+		e.buf.WriteString("null")
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		e.buf.WriteByte('[')
+
+		for i := 0; i < rv.Len() && err == nil; i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+
+			err = e.encodeResource(derefPointer(rv.Index(i)))
+		}
+
+		e.buf.WriteByte(']')
+	default:
+		err = e.encodeResource(rv)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.buf.WriteByte('}')
+
+	if !e.o.escapeHTML {
+		return unescapeHTML(e.buf.Bytes()), nil
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// encodeResource encodes the resource object of the struct v.
+func (e *encoder) encodeResource(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	typ, id, err := resourceIdentity(v)
+	if err != nil {
+		return err
+	}
+
+	first := true
+
+	e.buf.WriteByte('{')
+	writeKey(&e.buf, "type", &first)
+	encodeString(&e.buf, typ)
+
+	if id != nil {
+		writeKey(&e.buf, "id", &first)
+		encodeString(&e.buf, *id)
+	}
+
+	var attrs, rels []jsonField
+
+	for _, f := range jsonFields(v.Type()) {
+		kind, _, _ := strings.Cut(v.Type().FieldByIndex(f.index).Tag.Get("jsonapi"), ",")
+
+		switch {
+		case kind == "primary":
+		case e.o.fields != nil && !e.o.fields.allows(f.name):
+		case kind == "relation":
+			rels = append(rels, f)
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+
+	if err := e.encodeMembers(v, "attributes", attrs, e.encodeField, &first); err != nil {
+		return err
+	}
+
+	if err := e.encodeMembers(v, "relationships", rels, e.encodeRelationship, &first); err != nil {
+		return err
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// encodeMembers encodes the fields of the struct v as the object of the member of the resource object,
+// omitting the member without the fields written by fn.
+func (e *encoder) encodeMembers(v reflect.Value, name string, fields []jsonField,
+	fn func(fv reflect.Value, f jsonField, first *bool) error, first *bool,
+) error {
+	start := e.buf.Len()
+
+	writeKey(&e.buf, name, first)
+	e.buf.WriteByte('{')
+
+	empty := e.buf.Len()
+	inner := true
+
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		e.path = f.name
+
+		err := fn(fv, f, &inner)
+
+		e.path = ""
+
+		if err != nil {
+			return fmt.Errorf("optional: field %q: %w", f.name, err)
+		}
+	}
+
+	if e.buf.Len() == empty {
+		e.buf.Truncate(start) // the type member precedes, so the comma is removed along with the key
+
+		return nil
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// encodeRelationship encodes the relationship object of the field holding the resources,
+// omitting the unset [Type] values.
+func (e *encoder) encodeRelationship(fv reflect.Value, f jsonField, first *bool) error {
+	if isTrackedType(fv.Type()) {
+		fv = fv.Field(0)
+	}
+
+	if isOptionalType(fv.Type()) {
+		state := fieldState(fv)
+
+		if e.o.hook != nil {
+			e.o.hook.OnMarshal(e.path, state, nil)
+		}
+
+		switch state {
+		case StateUnset:
+			return nil
+		case StateNull:
+			writeKey(&e.buf, f.name, first)
+			e.buf.WriteString(`{"data":null}`)
+
+			return nil
+		}
+
+		fv = fv.FieldByName("V")
+	}
+
+	writeKey(&e.buf, f.name, first)
+	e.buf.WriteString(`{"data":`)
+
+	v := derefPointer(fv)
+
+	switch {
+	case !v.IsValid():
+		e.buf.WriteString("null")
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		e.buf.WriteByte('[')
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+
+			if err := e.encodeIdentifier(derefPointer(v.Index(i))); err != nil {
+				return err
+			}
+		}
+
+		e.buf.WriteByte(']')
+	default:
+		if err := e.encodeIdentifier(v); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// encodeIdentifier encodes the resource identifier object of the struct v.
+func (e *encoder) encodeIdentifier(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	typ, id, err := resourceIdentity(v)
+	if err != nil {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("optional: resource %s has no id", v.Type())
+	}
+
+	e.buf.WriteString(`{"type":`)
+	encodeString(&e.buf, typ)
+	e.buf.WriteString(`,"id":`)
+	encodeString(&e.buf, *id)
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// resourceIdentity returns the type and the id of the resource struct v from the field tagged
+// `jsonapi:"primary,..."`, the nil id for the unset [Type] id.
+func resourceIdentity(v reflect.Value) (string, *string, error) {
+	if v.Kind() != reflect.Struct || isPresenceType(v.Type()) {
+		return "", nil, fmt.Errorf("optional: JSON:API resource must be a struct, got %s", v.Type())
+	}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		kind, typ, _ := strings.Cut(t.Field(i).Tag.Get("jsonapi"), ",")
+		if kind != "primary" {
+			continue
+		}
+
+		if typ == "" {
+			return "", nil, fmt.Errorf("optional: field %s of %s has no resource type", t.Field(i).Name, t)
+		}
+
+		fv := v.Field(i)
+
+		if isPresenceType(fv.Type()) {
+			if fieldState(fv) != StateValue {
+				return typ, nil, nil
+			}
+
+			fv = presenceValue(fv)
+		}
+
+		id, err := resourceID(fv)
+		if err != nil {
+			return "", nil, fmt.Errorf("optional: id of %s: %w", t, err)
+		}
+
+		return typ, &id, nil
+	}
+
+	return "", nil, fmt.Errorf(`optional: %s has no field tagged jsonapi:"primary"`, t)
+}
+
+// resourceID formats the id of a resource, the ids are strings in JSON:API.
+func resourceID(v reflect.Value) (string, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func encodeString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+
+	buf.Write(b)
+}
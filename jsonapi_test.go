@@ -0,0 +1,132 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestMarshalJSONAPI(t *testing.T) {
+	t.Parallel()
+
+	type person struct {
+		ID   string `jsonapi:"primary,people"`
+		Name string `json:"name"`
+	}
+
+	type tag struct {
+		ID int `jsonapi:"primary,tags"`
+	}
+
+	type article struct {
+		ID       optional.Type[int]     `jsonapi:"primary,articles"`
+		Title    optional.Type[string]  `json:"title"`
+		Body     optional.Type[string]  `json:"body"`
+		Views    int                    `json:"views,omitempty"`
+		Author   optional.Type[*person] `json:"author" jsonapi:"relation"`
+		Editor   optional.Type[*person] `json:"editor" jsonapi:"relation"`
+		Reviewer *person                `json:"reviewer" jsonapi:"relation"`
+		Tags     []tag                  `json:"tags" jsonapi:"relation"`
+	}
+
+	tests := [...]struct {
+		name string
+		v    any
+		opts []optional.Option
+		want string
+	}{
+		{
+			"resource",
+			article{
+				ID:     optional.Some(1),
+				Title:  optional.Some("Rails is Omakase"),
+				Body:   optional.Null[string](),
+				Author: optional.Some(&person{ID: "9"}),
+				Editor: optional.Null[*person](),
+				Tags:   []tag{{ID: 2}, {ID: 3}},
+			},
+			nil,
+			`{"data":{"type":"articles","id":"1","attributes":{"title":"Rails is Omakase","body":null},` +
+				`"relationships":{"author":{"data":{"type":"people","id":"9"}},"editor":{"data":null},` +
+				`"reviewer":{"data":null},"tags":{"data":[{"type":"tags","id":"2"},{"type":"tags","id":"3"}]}}}}`,
+		},
+		{
+			"without id and attributes",
+			&article{Tags: []tag{}},
+			nil,
+			`{"data":{"type":"articles","relationships":{"reviewer":{"data":null},"tags":{"data":[]}}}}`,
+		},
+		{
+			"collection",
+			[]*person{{ID: "1", Name: "John"}, {ID: "2", Name: "Jane"}},
+			nil,
+			`{"data":[{"type":"people","id":"1","attributes":{"name":"John"}},` +
+				`{"type":"people","id":"2","attributes":{"name":"Jane"}}]}`,
+		},
+		{
+			"sparse fieldset",
+			article{ID: optional.Some(1), Title: optional.Some("Title"), Body: optional.Some("Body")},
+			[]optional.Option{optional.WithFields("title")},
+			`{"data":{"type":"articles","id":"1","attributes":{"title":"Title"}}}`,
+		},
+		{
+			"null",
+			(*person)(nil),
+			nil,
+			`{"data":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := optional.MarshalJSONAPI(tt.v, tt.opts...)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestMarshalJSONAPI_Error(t *testing.T) {
+	t.Parallel()
+
+	type noPrimary struct {
+		Name string `json:"name"`
+	}
+
+	type person struct {
+		ID optional.Type[string] `jsonapi:"primary,people"`
+	}
+
+	type article struct {
+		ID     int    `jsonapi:"primary,articles"`
+		Author person `json:"author" jsonapi:"relation"`
+	}
+
+	tests := [...]struct {
+		name string
+		v    any
+		want string
+	}{
+		{"no primary", noPrimary{}, `optional: optional_test.noPrimary has no field tagged jsonapi:"primary"`},
+		{"not a struct", 1, "optional: JSON:API resource must be a struct, got int"},
+		{"identifier without id", article{}, `optional: field "author": optional: resource optional_test.person has no id`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := optional.MarshalJSONAPI(tt.v)
+			require.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// KoanfMap returns the nested map of the fields of the struct v with the `koanf` tags suitable for
+// the confmap provider of https://github.com/knadh/koanf. Unset [Type] fields are omitted and fields
+// set to null are nil, so loading the map overrides only the keys set in v:
+//
+//	err := k.Load(confmap.Provider(optional.KoanfMap(flags), ""), nil)
+func KoanfMap(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optional: KoanfMap expects a struct, got %T", v)
+	}
+
+	return ToMap(rv.Interface(), "koanf"), nil
+}
@@ -0,0 +1,56 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestKoanfMap(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Host optional.Type[string] `koanf:"host"`
+		Port optional.Type[int]    `koanf:"port"`
+	}
+
+	type limits struct {
+		Rate optional.Type[int] `koanf:"rate"`
+	}
+
+	type config struct {
+		Name    optional.Type[string]    `koanf:"name"`
+		Proxy   optional.Type[string]    `koanf:"proxy"`
+		Timeout optional.Type[time.Time] `koanf:"timeout"`
+		Server  server                   `koanf:"server"`
+		Limits  limits                   `koanf:"limits"`
+		Backup  optional.Type[server]    `koanf:"backup"`
+		Level   string                   `koanf:"level"`
+	}
+
+	cfg := config{
+		Name:   optional.Some("some"),
+		Proxy:  optional.Null[string](),
+		Server: server{Port: optional.Some(8080)},
+		Backup: optional.Some(server{Host: optional.Some("backup")}),
+		Level:  "info",
+	}
+
+	got, err := optional.KoanfMap(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"name":   "some",
+		"proxy":  nil,
+		"server": map[string]any{"port": 8080},
+		"backup": map[string]any{"host": "backup"},
+		"level":  "info",
+	}, got)
+
+	_, err = optional.KoanfMap(1)
+	require.Error(t, err)
+}
@@ -0,0 +1,342 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LimitError is returned when the payload exceeds a limit set by [WithMaxDepth], [WithMaxStringLen]
+// or [WithMaxArrayLen]. The limits are honoured by [Decoder], [DecodeAll], [LinesDecoder], and by [DecodeRequest]
+// and [DecodePatch] for JSON bodies. They are checked while the input is read, so the payloads exceeding them are
+// rejected before they are buffered whole. [Unmarshal], [Type.UnmarshalJSON] and the other decoders ignore them.
+type LimitError struct {
+	Field  string // Field is the dotted path of the keys of the offending value, empty for the top-level value.
+	Offset int64  // Offset is the offset of the input after the byte exceeding the limit.
+	Limit  string // Limit is the name of the exceeded limit: "depth", "string length" or "array length".
+	Max    int    // Max is the value of the exceeded limit.
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("optional: field %q at offset %d exceeds the maximum %s of %d", e.Field, e.Offset, e.Limit, e.Max)
+}
+
+// WithMaxDepth sets the maximum nesting depth of the objects and arrays decoded by [Decoder], see [LimitError]
+// for the other entry points honouring the limits.
+// By default, the depth is not limited other than by encoding/json.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxStringLen sets the maximum length in bytes of the strings, including the keys, decoded by [Decoder].
+// By default, the length is not limited.
+func WithMaxStringLen(n int) Option {
+	return func(o *options) {
+		o.maxString = n
+	}
+}
+
+// WithMaxArrayLen sets the maximum number of the elements of the arrays decoded by [Decoder].
+// By default, the number is not limited.
+func WithMaxArrayLen(n int) Option {
+	return func(o *options) {
+		o.maxArray = n
+	}
+}
+
+func (o options) hasLimits() bool {
+	return o.maxDepth > 0 || o.maxString > 0 || o.maxArray > 0
+}
+
+// limitFrame is an object or array being checked.
+type limitFrame struct {
+	array bool
+	n     int    // n is the number of the elements of the array.
+	path  string // path is the path of the object or array.
+	key   string // key is the key of the current member of the object.
+	value bool   // value reports whether the next token of the object is the value of the member.
+}
+
+// limitReader checks the limits of the JSON input while it is read. After an exceeded limit, it returns
+// the bytes before the offending one and then the *[LimitError] for all the reads.
+type limitReader struct {
+	r   io.Reader
+	s   limitScanner
+	err error
+}
+
+// limitJSON returns the reader checking the limits of the options while r is read, or r if there are none.
+func limitJSON(r io.Reader, o options) io.Reader {
+	if !o.hasLimits() {
+		return r
+	}
+
+	return &limitReader{r: r, s: limitScanner{o: o}}
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.r.Read(p)
+
+	if i, lerr := r.s.scan(p[:n]); lerr != nil {
+		r.err = lerr
+
+		return i, lerr
+	}
+
+	return n, err
+}
+
+// limitScanner checks the limits on the bytes of a stream of JSON values as they come, following only
+// the lexical structure: the syntax errors are left to encoding/json. The escape sequences of the strings
+// count as single bytes, so only [checkLimits] tells the exact length of the strings with escapes.
+type limitScanner struct {
+	o      options
+	offset int64 // offset is the number of the scanned bytes.
+	stack  []limitFrame
+
+	str    bool   // str reports whether a string is being scanned.
+	esc    bool   // esc reports whether the previous byte of the string is a backslash.
+	hex    int    // hex is the number of the hex digits of the \u escape left to scan.
+	n      int    // n is the length of the string scanned so far.
+	inKey  bool   // inKey reports whether the string is the key of a member.
+	keyRaw []byte // keyRaw holds the key being scanned.
+}
+
+// scan scans the next bytes of the input, returning the number of the bytes before the one exceeding a limit.
+func (s *limitScanner) scan(p []byte) (int, error) {
+	for i, c := range p {
+		s.offset++
+
+		if err := s.scanByte(c); err != nil {
+			return i, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (s *limitScanner) scanByte(c byte) error {
+	var top *limitFrame
+	if len(s.stack) > 0 {
+		top = &s.stack[len(s.stack)-1]
+	}
+
+	if s.str {
+		return s.scanString(c, top)
+	}
+
+	switch c {
+	case ' ', '\t', '\r', '\n':
+		return nil
+	case ':':
+		if top != nil && !top.array {
+			top.value = true
+		}
+
+		return nil
+	case ',':
+		if top != nil {
+			top.value = false
+		}
+
+		return nil
+	case '}', ']':
+		if top != nil {
+			s.stack = s.stack[:len(s.stack)-1]
+		}
+
+		return nil
+	}
+
+	if top != nil && !top.array && !top.value {
+		// Only the keys start outside of the values of the members.
+		s.str, s.inKey, s.n, s.keyRaw = c == '"', true, 0, append(s.keyRaw[:0], c)
+
+		return nil
+	}
+
+	path := s.path(top)
+
+	if top != nil && top.array && !top.value {
+		top.value = true // The element continues up to the next comma.
+
+		if top.n++; s.o.maxArray > 0 && top.n > s.o.maxArray {
+			return s.limitError(top.path, "array length", s.o.maxArray)
+		}
+	}
+
+	switch c {
+	case '{', '[':
+		s.stack = append(s.stack, limitFrame{array: c == '[', path: path})
+
+		if s.o.maxDepth > 0 && len(s.stack) > s.o.maxDepth {
+			return s.limitError(path, "depth", s.o.maxDepth)
+		}
+	case '"':
+		s.str, s.inKey, s.n = true, false, 0
+	}
+
+	return nil
+}
+
+func (s *limitScanner) scanString(c byte, top *limitFrame) error {
+	if s.inKey {
+		s.keyRaw = append(s.keyRaw, c)
+	}
+
+	switch {
+	case s.hex > 0:
+		s.hex--
+
+		return nil
+	case s.esc:
+		s.esc = false
+
+		if c == 'u' {
+			s.hex = 4
+		}
+
+		return nil
+	case c == '"':
+		s.str = false
+
+		if s.inKey {
+			var key string
+			if json.Unmarshal(s.keyRaw, &key) == nil {
+				top.key = key
+			}
+		}
+
+		return nil
+	case c == '\\':
+		s.esc = true
+	}
+
+	if s.n++; s.o.maxString > 0 && s.n > s.o.maxString {
+		path := ""
+		if top != nil {
+			path = top.path
+		}
+
+		if !s.inKey {
+			path = s.path(top)
+		}
+
+		return s.limitError(path, "string length", s.o.maxString)
+	}
+
+	return nil
+}
+
+// path returns the path of the value starting in the top frame.
+func (s *limitScanner) path(top *limitFrame) string {
+	switch {
+	case top == nil:
+		return ""
+	case top.array:
+		return top.path
+	}
+
+	return joinPath(top.path, top.key)
+}
+
+func (s *limitScanner) limitError(path, limit string, max int) error {
+	return &LimitError{Field: path, Offset: s.offset, Limit: limit, Max: max}
+}
+
+// checkLimits checks the raw value located at the offset of the input against the limits of the options.
+func checkLimits(raw json.RawMessage, offset int64, o options) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var stack []*limitFrame
+
+	limitError := func(path, limit string, max int) error {
+		return &LimitError{Field: path, Offset: offset + dec.InputOffset(), Limit: limit, Max: max}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var top *limitFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if s, ok := tok.(string); ok && o.maxString > 0 && len(s) > o.maxString {
+			path := ""
+			if top != nil {
+				path = top.path
+			}
+
+			if top != nil && !top.array && top.value {
+				path = joinPath(top.path, top.key)
+			}
+
+			return limitError(path, "string length", o.maxString)
+		}
+
+		if top != nil && !top.array && !top.value {
+			if d, ok := tok.(json.Delim); !ok || d != '}' {
+				top.key, _ = tok.(string)
+				top.value = true
+
+				continue
+			}
+		}
+
+		path := ""
+
+		if top != nil {
+			path = top.path
+
+			if !top.array {
+				path = joinPath(top.path, top.key)
+			}
+		}
+
+		switch tok {
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+
+			if len(stack) > 0 {
+				stack[len(stack)-1].value = false
+			}
+
+			continue
+		}
+
+		if top != nil {
+			top.value = false
+
+			if top.array {
+				if top.n++; o.maxArray > 0 && top.n > o.maxArray {
+					return limitError(path, "array length", o.maxArray)
+				}
+			}
+		}
+
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, &limitFrame{array: d == '[', path: path})
+
+			if o.maxDepth > 0 && len(stack) > o.maxDepth {
+				return limitError(path, "depth", o.maxDepth)
+			}
+		}
+	}
+}
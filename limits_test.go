@@ -0,0 +1,198 @@
+package optional_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecoder_Decode_Limits(t *testing.T) {
+	t.Parallel()
+
+	type document struct {
+		Name  optional.Type[string]   `json:"name"`
+		Tags  optional.Type[[]string] `json:"tags"`
+		Extra optional.Type[any]      `json:"extra"`
+	}
+
+	tests := [...]struct {
+		name  string
+		input string
+		opt   optional.Option
+		want  *optional.LimitError
+	}{
+		{"within limits", `{"name":"abc","tags":["a","b"],"extra":{"a":[1]}}`, optional.WithMaxDepth(3), nil},
+		{
+			"depth",
+			`{"name":"a","extra":{"a":{"b":[1]}}}`,
+			optional.WithMaxDepth(3),
+			&optional.LimitError{Field: "extra.a.b", Offset: 31, Limit: "depth", Max: 3},
+		},
+		{
+			"string",
+			`{"tags":["a","bcdef"]}`,
+			optional.WithMaxStringLen(4),
+			&optional.LimitError{Field: "tags", Offset: 19, Limit: "string length", Max: 4},
+		},
+		{
+			"key",
+			`{"extra":{"longer":1}}`,
+			optional.WithMaxStringLen(5),
+			&optional.LimitError{Field: "extra", Offset: 17, Limit: "string length", Max: 5},
+		},
+		{
+			"member",
+			`{"name":"longer"}`,
+			optional.WithMaxStringLen(4),
+			&optional.LimitError{Field: "name", Offset: 14, Limit: "string length", Max: 4},
+		},
+		{
+			"array",
+			`{"extra":{"a":[1,[2],3]}}`,
+			optional.WithMaxArrayLen(2),
+			&optional.LimitError{Field: "extra.a", Offset: 22, Limit: "array length", Max: 2},
+		},
+		{
+			"escaped",
+			`{"name":"\u00e9\u00e9\u00e9"}`,
+			optional.WithMaxStringLen(4),
+			&optional.LimitError{Field: "name", Offset: 28, Limit: "string length", Max: 4},
+		},
+		{"escaped within limits", `{"name":"\u00e9\n\t"}`, optional.WithMaxStringLen(4), nil},
+		{
+			"after nested",
+			`{"extra":[[1,2],{"a":"b"},3]}`,
+			optional.WithMaxArrayLen(2),
+			&optional.LimitError{Field: "extra", Offset: 27, Limit: "array length", Max: 2},
+		},
+		{
+			"structural in strings",
+			`{"name":"[{,:\"]","extra":{"a\"b":[",]",2,3]}}`,
+			optional.WithMaxArrayLen(2),
+			&optional.LimitError{Field: `extra.a"b`, Offset: 43, Limit: "array length", Max: 2},
+		},
+		{
+			"top-level array",
+			`[1,2]`,
+			optional.WithMaxArrayLen(1),
+			&optional.LimitError{Offset: 4, Limit: "array length", Max: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got any = &document{}
+			if strings.HasPrefix(tt.input, "[") {
+				got = &[]int{}
+			}
+
+			err := optional.NewDecoder(strings.NewReader(tt.input), tt.opt).Decode(got)
+			if tt.want == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			var le *optional.LimitError
+
+			require.True(t, errors.As(err, &le), err)
+			assert.Equal(t, tt.want, le)
+		})
+	}
+}
+
+// hostileReader yields the start of an object followed by an endless string.
+type hostileReader struct {
+	n int
+}
+
+func (r *hostileReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		r.n += copy(p, `{"name":"`)
+
+		return len(`{"name":"`), nil
+	}
+
+	if r.n > 1<<20 {
+		return 0, errors.New("read past the limit")
+	}
+
+	for i := range p {
+		p[i] = 'a'
+	}
+
+	r.n += len(p)
+
+	return len(p), nil
+}
+
+func TestDecoder_Decode_Limits_Streaming(t *testing.T) {
+	t.Parallel()
+
+	var got struct {
+		Name optional.Type[string] `json:"name"`
+	}
+
+	err := optional.NewDecoder(&hostileReader{}, optional.WithMaxStringLen(1<<10)).Decode(&got)
+
+	var le *optional.LimitError
+
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, &optional.LimitError{Field: "name", Offset: 9 + 1<<10 + 1, Limit: "string length", Max: 1 << 10}, le)
+
+	dec := optional.NewDecoder(strings.NewReader(`{"name":"ok"} {"name":"longer"}`), optional.WithMaxStringLen(4))
+
+	require.NoError(t, dec.Decode(&got))
+	assert.Equal(t, optional.Some("ok"), got.Name)
+	require.ErrorAs(t, dec.Decode(&got), &le)
+	assert.Equal(t, int64(28), le.Offset)
+}
+
+func TestDecodeRequest_Limits(t *testing.T) {
+	t.Parallel()
+
+	type body struct {
+		Tags optional.Type[[]string] `json:"tags"`
+	}
+
+	for _, input := range []string{`{"tags":["a","b","c"]}`, `{"tags":["\u00e9\u00e9\u00e9"]}`} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(input))
+
+		var got body
+
+		err := optional.DecodeRequest(r, &got, optional.WithMaxArrayLen(2), optional.WithMaxStringLen(4))
+
+		var le *optional.LimitError
+
+		require.ErrorAs(t, err, &le, input)
+		assert.Equal(t, "tags", le.Field, input)
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"tags":[[[]]]}`))
+
+	_, _, err := optional.DecodePatch(r, body{}, optional.WithMaxDepth(2))
+
+	var le *optional.LimitError
+
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, &optional.LimitError{Field: "tags", Offset: 10, Limit: "depth", Max: 2}, le)
+}
+
+func TestLimitError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &optional.LimitError{Field: "tags", Offset: 10, Limit: "array length", Max: 2}
+
+	assert.EqualError(t, err, `optional: field "tags" at offset 10 exceeds the maximum array length of 2`)
+}
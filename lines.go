@@ -0,0 +1,196 @@
+package optional
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// LineError is returned by [LinesEncoder] and [LinesDecoder] for the record that cannot be encoded or decoded.
+// The stream stays usable: the next record is written or read after the error.
+type LineError struct {
+	Line int   // Line is the 1-based number of the line of the record.
+	Err  error // Err is the error of the record, with the offsets relative to the line.
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("optional: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LinesEncoder writes the records as newline delimited JSON (NDJSON, JSON Lines), each encoded by [Marshal]
+// on its own line, so the presence of the fields is preserved. The records are written to the writer
+// as they are encoded, wrap it into [bufio.Writer] to reduce the number of writes.
+type LinesEncoder struct {
+	w    io.Writer
+	opts []Option
+	line int
+}
+
+// NewLinesEncoder returns the encoder writing to w, passing the options to [Marshal].
+func NewLinesEncoder(w io.Writer, opts ...Option) *LinesEncoder {
+	return &LinesEncoder{w: w, opts: opts}
+}
+
+// Encode writes the record v followed by a newline. The record that cannot be marshalled is not written
+// and is reported by *[LineError], errors of the writer are returned as they are.
+func (e *LinesEncoder) Encode(v any) error {
+	data, err := Marshal(v, e.opts...)
+	if err == nil && bytes.IndexByte(data, '\n') != -1 { // the current marshaller may indent the values
+		var buf bytes.Buffer
+
+		err = json.Compact(&buf, data)
+		data = buf.Bytes()
+	}
+
+	if err != nil {
+		return &LineError{Line: e.line + 1, Err: err}
+	}
+
+	if _, err := e.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	e.line++
+
+	return nil
+}
+
+// LinesDecoder reads the records of newline delimited JSON (NDJSON, JSON Lines) one line at a time,
+// decoding each like [Decoder] does, so the presence of the fields is preserved and the options of [Decoder]
+// apply to each record. The lines are not limited in length, unless the limits of [WithMaxStringLen],
+// [WithMaxArrayLen] and [WithMaxDepth] reject them while they are read, and the blank lines are skipped.
+type LinesDecoder struct {
+	r      *bufio.Reader
+	d      Decoder
+	buf    []byte
+	line   int
+	limits *limitScanner // limits checks the limits of the options on each line, nil without limits.
+}
+
+// NewLinesDecoder returns the decoder reading from r.
+func NewLinesDecoder(r io.Reader, opts ...Option) *LinesDecoder {
+	d := &LinesDecoder{r: bufio.NewReader(r), d: Decoder{o: newOptions(opts)}}
+
+	if d.d.o.hasLimits() {
+		d.limits = &limitScanner{o: d.d.o}
+	}
+
+	return d
+}
+
+// Line returns the number of the line of the last record read.
+func (d *LinesDecoder) Line() int {
+	return d.line
+}
+
+// Decode reads the next record and stores it in the value pointed to by v. The invalid record is reported
+// by *[LineError] and the next call reads the next record, so the imports can skip or collect the broken records.
+// At the end of the input, [io.EOF] is returned.
+func (d *LinesDecoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("optional: Decode expects a non-nil pointer, got %T", v)
+	}
+
+	for {
+		line, err := d.readLine()
+
+		var le *LimitError
+		if errors.As(err, &le) {
+			d.line++
+
+			return &LineError{Line: d.line, Err: err}
+		}
+
+		if len(line) == 0 && err != nil {
+			return err
+		}
+
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		d.line++
+
+		raw := bytes.TrimSpace(line)
+		if len(raw) == 0 {
+			continue
+		}
+
+		if err := d.decodeLine(raw, rv.Elem()); err != nil {
+			return &LineError{Line: d.line, Err: err}
+		}
+
+		return nil
+	}
+}
+
+func (d *LinesDecoder) decodeLine(raw json.RawMessage, v reflect.Value) error {
+	if !json.Valid(raw) {
+		var tmp any
+
+		return json.Unmarshal(raw, &tmp) // reports the *json.SyntaxError
+	}
+
+	if d.d.o.hasLimits() {
+		if err := checkLimits(raw, 0, d.d.o); err != nil {
+			return err
+		}
+	}
+
+	if d.d.o.schema != nil {
+		if err := checkSchema(raw, d.d.o); err != nil {
+			return err
+		}
+	}
+
+	if err := d.d.decodeAt(raw, v, 0, "", ""); err != nil {
+		return err
+	}
+
+	if d.d.o.normalize {
+		return normalizeValue(v, "", nil)
+	}
+
+	return nil
+}
+
+// readLine reads the next line without the newline into the reused buffer. The line exceeding the limits
+// is skipped without buffering its rest and reported by the *[LimitError].
+func (d *LinesDecoder) readLine() ([]byte, error) {
+	d.buf = d.buf[:0]
+
+	var lerr error
+
+	if d.limits != nil {
+		*d.limits = limitScanner{o: d.d.o, stack: d.limits.stack[:0], keyRaw: d.limits.keyRaw[:0]}
+	}
+
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+
+		if lerr == nil && d.limits != nil {
+			_, lerr = d.limits.scan(chunk)
+		}
+
+		if lerr == nil {
+			d.buf = append(d.buf, chunk...)
+		}
+
+		if err != bufio.ErrBufferFull {
+			if lerr != nil {
+				return nil, lerr
+			}
+
+			return bytes.TrimSuffix(d.buf, []byte{'\n'}), err
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type lineRecord struct {
+	ID    int                   `json:"id"`
+	Name  optional.Type[string] `json:"name"`
+	Email optional.Type[string] `json:"email"`
+}
+
+func TestLinesEncoder_Encode(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	enc := optional.NewLinesEncoder(&buf)
+
+	require.NoError(t, enc.Encode(lineRecord{ID: 1, Name: optional.Some("John")}))
+	require.NoError(t, enc.Encode(lineRecord{ID: 2, Email: optional.Null[string]()}))
+
+	err := enc.Encode(map[string]any{"bad": func() {}})
+
+	var le *optional.LineError
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, 3, le.Line)
+
+	var ue *json.UnsupportedTypeError
+	assert.ErrorAs(t, err, &ue)
+
+	require.NoError(t, enc.Encode(json.RawMessage("{\n  \"id\": 3\n}")))
+
+	assert.Equal(t, "{\"id\":1,\"name\":\"John\"}\n{\"id\":2,\"email\":null}\n{\"id\":3}\n", buf.String())
+}
+
+func TestLinesDecoder_Decode(t *testing.T) {
+	t.Parallel()
+
+	input := "{\"id\":1,\"name\":\"John\"}\n" +
+		"\n" +
+		"{\"id\":2,\"email\":null}\r\n" +
+		"{\"id\":\"three\"}\n" +
+		"{\"id\":4,\n" +
+		"{\"id\":5,\"name\":\"" + strings.Repeat("x", 8192) + "\"}"
+
+	dec := optional.NewLinesDecoder(strings.NewReader(input))
+
+	type result struct {
+		line int
+		rec  lineRecord
+		err  string
+	}
+
+	var got []result
+
+	for {
+		var rec lineRecord
+
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		r := result{line: dec.Line(), rec: rec}
+		if err != nil {
+			r.err = err.Error()
+		}
+
+		got = append(got, r)
+	}
+
+	require.Len(t, got, 5)
+
+	assert.Equal(t, result{line: 1, rec: lineRecord{ID: 1, Name: optional.Some("John")}}, got[0])
+	assert.Equal(t, result{line: 3, rec: lineRecord{ID: 2, Email: optional.Null[string]()}}, got[1])
+	assert.Equal(t, 4, got[2].line)
+	assert.Equal(t, "optional: line 4: json: cannot unmarshal string into Go struct field lineRecord.id of type int", got[2].err)
+	assert.Equal(t, 5, got[3].line)
+	assert.Equal(t, "optional: line 5: unexpected end of JSON input", got[3].err)
+	assert.Equal(t, 6, got[4].line)
+	assert.Empty(t, got[4].err)
+	assert.Len(t, got[4].rec.Name.V, 8192)
+}
+
+func TestLinesDecoder_Decode_Limits(t *testing.T) {
+	t.Parallel()
+
+	dec := optional.NewLinesDecoder(strings.NewReader(`{"id":1,"name":"Johnny"}`), optional.WithMaxStringLen(4))
+
+	var rec lineRecord
+
+	err := dec.Decode(&rec)
+
+	var le *optional.LimitError
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, "name", le.Field)
+
+	input := `{"id":1,"name":"` + strings.Repeat("a", 1<<16) + `"}` + "\n" + `{"id":2,"name":"Jo"}` + "\n"
+	dec = optional.NewLinesDecoder(strings.NewReader(input), optional.WithMaxStringLen(4))
+
+	err = dec.Decode(&rec)
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, &optional.LimitError{Field: "name", Offset: 21, Limit: "string length", Max: 4}, le)
+	assert.EqualError(t, err, `optional: line 1: optional: field "name" at offset 21 exceeds the maximum string length of 4`)
+
+	require.NoError(t, dec.Decode(&rec))
+	assert.Equal(t, 2, dec.Line())
+	assert.Equal(t, optional.Some("Jo"), rec.Name)
+}
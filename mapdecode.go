@@ -0,0 +1,101 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FromMap fills the fields of the struct pointed to by v from the generic map, such as decoded from a message
+// queue payload, matching the keys with the names from the `json` tags case-insensitively.
+//
+// Keys present in the map set the [Type] fields, nil values set them to null and absent keys leave them
+// untouched. Nested maps are decoded into struct fields recursively, other values are converted to the
+// types of the fields when possible.
+func FromMap(m map[string]any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: FromMap expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return decodeMap(m, rv.Elem(), "json", newOptions(nil))
+}
+
+// decodeMap fills the fields of the struct v from the generic map, matching the names from the tags
+// of the key case-insensitively. Keys absent from the map leave the fields untouched and nil values
+// set [Type] fields to null.
+func decodeMap(m map[string]any, v reflect.Value, key string, o options) error {
+	for _, f := range taggedFields(v.Type(), key) {
+		val, ok := lookupKey(m, f.name)
+		if !ok {
+			continue
+		}
+
+		fv, _ := fieldByIndex(v, f.index, true)
+
+		if err := decodeMapValue(val, fv, key, o); err != nil {
+			return fmt.Errorf("optional: field %q: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeMapValue(val any, fv reflect.Value, key string, o options) error {
+	if s, ok := val.(string); ok && o.null != "" && s == o.null {
+		val = nil
+	}
+
+	a, isOptional := asAccessor(fv)
+	if !isOptional {
+		return assignLoose(fv, val, key, o)
+	}
+
+	a.mark(false, false)
+
+	if val == nil {
+		a.mark(true, true)
+
+		return nil
+	}
+
+	if err := assignLoose(a.value(), val, key, o); err != nil {
+		return err
+	}
+
+	a.mark(true, false)
+
+	return nil
+}
+
+// assignLoose is like assign, but decodes nested maps into structs by the tags of the key
+// and parses strings, such as environment variables, into values of other types.
+func assignLoose(dst reflect.Value, val any, key string, o options) error {
+	switch tv := val.(type) {
+	case map[string]any:
+		if dst.Kind() == reflect.Struct && !isOptionalType(dst.Type()) {
+			return decodeMap(tv, dst, key, o)
+		}
+	case string:
+		if dst.Kind() != reflect.String && !(dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8) {
+			return setTexts(dst, []string{tv}, o.separator)
+		}
+	}
+
+	return assign(dst, val)
+}
+
+// lookupKey finds the key by the exact name, falling back to the case-insensitive match.
+func lookupKey(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,125 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestFromMap(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+	}
+
+	type user struct {
+		ID      int64                   `json:"id"`
+		Name    optional.Type[string]   `json:"name"`
+		Email   optional.Type[string]   `json:"email"`
+		Phone   optional.Type[string]   `json:"phone"`
+		Age     optional.Type[int]      `json:"age"`
+		Tags    optional.Type[[]string] `json:"tags"`
+		Address optional.Type[address]  `json:"address"`
+	}
+
+	m := map[string]any{
+		"id":      float64(1),
+		"name":    "some",
+		"email":   nil,
+		"Age":     json.Number("42"),
+		"tags":    []any{"a", "b"},
+		"address": map[string]any{"city": "Paris"},
+		"unknown": "unknown",
+	}
+
+	var got user
+
+	require.NoError(t, optional.FromMap(m, &got))
+
+	assert.Equal(t, user{
+		ID:      1,
+		Name:    optional.Some("some"),
+		Email:   optional.Null[string](),
+		Age:     optional.Some(42),
+		Tags:    optional.Some([]string{"a", "b"}),
+		Address: optional.Some(address{City: optional.Some("Paris")}),
+	}, got)
+
+	// The round trip through ToMap keeps the presence.
+	var again user
+
+	require.NoError(t, optional.FromMap(optional.ToMap(got, "json"), &again))
+	assert.Equal(t, got, again)
+}
+
+func TestFromMap_Error(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Age optional.Type[int] `json:"age"`
+	}
+
+	var got user
+
+	require.Error(t, optional.FromMap(map[string]any{"age": []any{1}}, &got))
+	require.Error(t, optional.FromMap(nil, got))
+}
+
+func TestFromMap_Numbers(t *testing.T) {
+	t.Parallel()
+
+	type numbers struct {
+		Int     optional.Type[int]     `json:"int"`
+		Int8    optional.Type[int8]    `json:"int8"`
+		Uint    optional.Type[uint]    `json:"uint"`
+		Uint8   optional.Type[uint8]   `json:"uint8"`
+		Int64   optional.Type[int64]   `json:"int64"`
+		Float32 optional.Type[float32] `json:"float32"`
+	}
+
+	tests := [...]struct {
+		name string
+		in   map[string]any
+		want numbers
+		err  string
+	}{
+		{"whole float to int", map[string]any{"int": 2.0}, numbers{Int: optional.Some(2)}, ""},
+		{"fits int8", map[string]any{"int8": -128}, numbers{Int8: optional.Some(int8(-128))}, ""},
+		{"fits uint8", map[string]any{"uint8": uint64(255)}, numbers{Uint8: optional.Some(uint8(255))}, ""},
+		{"int to float", map[string]any{"float32": 3}, numbers{Float32: optional.Some(float32(3))}, ""},
+		{"min int64", map[string]any{"int64": -9223372036854775808.0}, numbers{Int64: optional.Some(int64(-1 << 63))}, ""},
+		{"fraction", map[string]any{"int": 1.5}, numbers{}, "value 1.5 is not an integer, cannot convert to int"},
+		{"int8 overflow", map[string]any{"int8": 300}, numbers{}, "value 300 overflows int8"},
+		{"negative uint", map[string]any{"uint": -1}, numbers{}, "value -1 overflows uint"},
+		{"uint64 overflows int", map[string]any{"int": uint64(1 << 63)}, numbers{}, "value 9223372036854775808 overflows int"},
+		{"float overflows int64", map[string]any{"int64": 1e19}, numbers{}, "value 1e+19 overflows int64"},
+		{"negative float to uint", map[string]any{"uint": -2.0}, numbers{}, "value -2 overflows uint"},
+		{"float32 overflow", map[string]any{"float32": 1e39}, numbers{}, "value 1e+39 overflows float32"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got numbers
+
+			err := optional.FromMap(tt.in, &got)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ToMap returns the map of the fields of the struct v named after the tags of the key, such as "json" or "db",
+// containing only the set [Type] fields, with nil values for the fields set to null. Fields without the tag
+// are named after the Go fields, fields with the "-" tag are skipped.
+//
+// Structs, including the values of [Type], become nested maps of their set fields, nested structs without
+// set fields are omitted. Other fields are always present.
+func ToMap(v any, tag string) map[string]any {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: ToMap expects a struct, got %T", v))
+	}
+
+	return encodeMap(rv, tag)
+}
+
+// encodeMap returns the generic map of the struct v with the names from the tags of the key.
+// Unset [Type] fields are omitted and fields set to null are nil. Structs, including the values
+// of [Type], are converted into nested maps, nested structs without set fields are omitted.
+func encodeMap(v reflect.Value, key string) map[string]any {
+	m := map[string]any{}
+
+	for _, f := range taggedFields(v.Type(), key) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		if isTrackedType(fv.Type()) {
+			fv = fv.Field(0)
+		}
+
+		if isOptionalType(fv.Type()) {
+			p := fv.Interface().(presence)
+
+			switch {
+			case p.IsSetNull():
+				m[f.name] = nil
+			case p.IsSet():
+				m[f.name] = encodeMapValue(fv.FieldByName("V"), key)
+			}
+
+			continue
+		}
+
+		if isPlainStruct(fv.Type()) {
+			if sub := encodeMap(fv, key); len(sub) != 0 {
+				m[f.name] = sub
+			}
+
+			continue
+		}
+
+		m[f.name] = fv.Interface()
+	}
+
+	return m
+}
+
+func encodeMapValue(v reflect.Value, key string) any {
+	if isPlainStruct(v.Type()) {
+		return encodeMap(v, key)
+	}
+
+	return v.Interface()
+}
+
+// isPlainStruct reports whether t is a struct without its own JSON or text encoding, other than [Type].
+func isPlainStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isOptionalType(t) &&
+		!reflect.PtrTo(t).Implements(jsonMarshalerType) && !reflect.PtrTo(t).Implements(textMarshalerType)
+}
@@ -0,0 +1,51 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+func TestToMap(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City   optional.Type[string] `db:"city"`
+		Street optional.Type[string] `db:"street"`
+	}
+
+	type user struct {
+		ID      int                      `db:"id"`
+		Name    optional.Type[string]    `db:"name"`
+		Email   optional.Type[string]    `db:"email"`
+		Phone   optional.Type[string]    `db:"phone"`
+		Address optional.Type[address]   `db:"address"`
+		Billing address                  `db:"billing"`
+		Note    optional.Tracked[string] `db:"note"`
+		Secret  optional.Type[string]    `db:"-"`
+		NoTag   optional.Type[int]
+	}
+
+	v := user{
+		ID:      1,
+		Name:    optional.Some("some"),
+		Email:   optional.Null[string](),
+		Address: optional.Some(address{City: optional.Some("Paris")}),
+		Secret:  optional.Some("secret"),
+		NoTag:   optional.Some(2),
+	}
+	v.Note.SetValue("note")
+
+	assert.Equal(t, map[string]any{
+		"id":      1,
+		"name":    "some",
+		"email":   nil,
+		"address": map[string]any{"city": "Paris"},
+		"note":    "note",
+		"NoTag":   2,
+	}, optional.ToMap(&v, "db"))
+
+	assert.Panics(t, func() { optional.ToMap("some", "db") })
+}
@@ -0,0 +1,130 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeConfig overlays the layers onto the struct pointed to by dst in order, so later layers take
+// precedence, e.g. the configuration file, then environment variables, then command line flags.
+//
+// Each layer overrides only the [Type] fields it sets. A field set to null resets the field to the value
+// dst held before the merge, which is meant to hold the defaults. Nested structs are merged field by field,
+// other fields are left untouched. The layers must be structs, or pointers to structs, of the type of dst,
+// the nil layers are skipped.
+func MergeConfig(dst any, layers ...any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: MergeConfig expects a non-nil pointer to a struct, got %T", dst)
+	}
+
+	rv = rv.Elem()
+
+	defaults := reflect.New(rv.Type()).Elem()
+	defaults.Set(rv)
+
+	for i, layer := range layers {
+		lv := reflect.ValueOf(layer)
+		if !lv.IsValid() {
+			continue
+		}
+
+		if lv.Kind() == reflect.Ptr {
+			if lv.IsNil() {
+				continue
+			}
+
+			lv = lv.Elem()
+		}
+
+		if lv.Type() != rv.Type() {
+			return fmt.Errorf("optional: MergeConfig layer %d has type %s, expected %s", i, lv.Type(), rv.Type())
+		}
+
+		mergeStruct(rv, lv, defaults)
+	}
+
+	return nil
+}
+
+func mergeStruct(dst, layer, defaults reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if !dst.Type().Field(i).IsExported() {
+			continue
+		}
+
+		df, lf := dst.Field(i), layer.Field(i)
+
+		switch {
+		case isOptionalType(df.Type()):
+			p := lf.Interface().(presence)
+
+			switch {
+			case p.IsSetNull():
+				df.Set(defaults.Field(i))
+			case p.IsSet():
+				df.Set(lf)
+			}
+		case df.Kind() == reflect.Struct:
+			mergeStruct(df, lf, defaults.Field(i))
+		}
+	}
+}
+
+// WithKeepNull makes [MergeMaps] store the null values of the source as nil values instead of deleting the keys,
+// for the documents where null is meaningful.
+func WithKeepNull() Option {
+	return func(o *options) {
+		o.keepNull = true
+	}
+}
+
+// MergeMaps merges the schemaless JSON document src into dst with the semantics of the typed patches, as JSON Merge
+// Patch (RFC 7386) does: the keys of src override the keys of dst, nil values delete the keys, the keys absent
+// from src are left untouched, and the objects, map[string]any, are merged recursively. Other values, such as
+// slices, replace the values of dst as they are.
+//
+// The values of src may be [Type] and [Tracked] values: unset ones are skipped, null ones delete the keys, and
+// set ones are merged as their values, so the maps built from optional fields merge presence-aware.
+//
+// The merged dst is returned, a new map if dst is nil. The nested objects of src are copied, not shared.
+func MergeMaps(dst, src map[string]any, opts ...Option) map[string]any {
+	o := newOptions(opts)
+
+	return mergeMaps(dst, src, o)
+}
+
+func mergeMaps(dst, src map[string]any, o options) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+
+	for k, v := range src {
+		if p, ok := v.(presence); ok {
+			switch stateOf(p) {
+			case StateUnset:
+				continue
+			case StateNull:
+				v = nil
+			case StateValue:
+				v = presenceValue(reflect.ValueOf(v)).Interface()
+			}
+		}
+
+		switch sv := v.(type) {
+		case nil:
+			if o.keepNull {
+				dst[k] = nil
+			} else {
+				delete(dst, k)
+			}
+		case map[string]any:
+			dv, _ := dst[k].(map[string]any)
+			dst[k] = mergeMaps(dv, sv, o)
+		default:
+			dst[k] = v
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,158 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestMergeConfig(t *testing.T) {
+	t.Parallel()
+
+	type database struct {
+		Host optional.Type[string]
+		Port optional.Type[int]
+	}
+
+	type config struct {
+		Name     optional.Type[string]
+		Level    optional.Type[string]
+		Proxy    optional.Type[string]
+		Database database
+	}
+
+	cfg := config{
+		Level:    optional.Some("info"),
+		Database: database{Host: optional.Some("localhost"), Port: optional.Some(5432)},
+	}
+
+	file := config{
+		Name:     optional.Some("file"),
+		Level:    optional.Some("debug"),
+		Proxy:    optional.Some("proxy"),
+		Database: database{Port: optional.Some(6432)},
+	}
+
+	env := config{
+		Level: optional.Null[string](),
+		Proxy: optional.Null[string](),
+	}
+
+	flags := &config{
+		Name: optional.Some("flags"),
+	}
+
+	require.NoError(t, optional.MergeConfig(&cfg, file, env, flags, (*config)(nil), nil))
+
+	assert.Equal(t, config{
+		Name:     optional.Some("flags"),
+		Level:    optional.Some("info"),
+		Database: database{Host: optional.Some("localhost"), Port: optional.Some(6432)},
+	}, cfg)
+}
+
+func TestMergeConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Name optional.Type[string]
+	}
+
+	var cfg config
+
+	require.Error(t, optional.MergeConfig(cfg))
+	require.Error(t, optional.MergeConfig(&cfg, struct{}{}))
+}
+
+func TestMergeMaps(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		dst  map[string]any
+		src  map[string]any
+		opts []optional.Option
+		want map[string]any
+	}{
+		{
+			"override and untouched",
+			map[string]any{"a": 1, "b": "x"},
+			map[string]any{"a": 2},
+			nil,
+			map[string]any{"a": 2, "b": "x"},
+		},
+		{
+			"nil deletes",
+			map[string]any{"a": 1, "b": "x"},
+			map[string]any{"b": nil, "c": nil},
+			nil,
+			map[string]any{"a": 1},
+		},
+		{
+			"nested",
+			map[string]any{"db": map[string]any{"host": "localhost", "port": 5432, "user": "root"}},
+			map[string]any{"db": map[string]any{"port": 6432, "user": nil}},
+			nil,
+			map[string]any{"db": map[string]any{"host": "localhost", "port": 6432}},
+		},
+		{
+			"object replaces value",
+			map[string]any{"db": "localhost"},
+			map[string]any{"db": map[string]any{"host": "db", "user": nil}},
+			nil,
+			map[string]any{"db": map[string]any{"host": "db"}},
+		},
+		{
+			"slices replace",
+			map[string]any{"tags": []any{"a", "b"}},
+			map[string]any{"tags": []any{"c"}},
+			nil,
+			map[string]any{"tags": []any{"c"}},
+		},
+		{
+			"presence",
+			map[string]any{"a": 1, "b": "x", "c": true},
+			map[string]any{"a": optional.Some(2), "b": optional.Null[string](), "c": optional.Type[bool]{}},
+			nil,
+			map[string]any{"a": 2, "c": true},
+		},
+		{
+			"keep null",
+			map[string]any{"a": 1, "db": map[string]any{"user": "root"}},
+			map[string]any{"a": nil, "db": map[string]any{"user": optional.Null[string]()}},
+			[]optional.Option{optional.WithKeepNull()},
+			map[string]any{"a": nil, "db": map[string]any{"user": nil}},
+		},
+		{
+			"nil dst",
+			nil,
+			map[string]any{"a": 1, "b": nil},
+			nil,
+			map[string]any{"a": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, optional.MergeMaps(tt.dst, tt.src, tt.opts...))
+		})
+	}
+}
+
+func TestMergeMaps_Copy(t *testing.T) {
+	t.Parallel()
+
+	nested := map[string]any{"host": "db"}
+
+	got := optional.MergeMaps(nil, map[string]any{"db": nested})
+	got["db"].(map[string]any)["host"] = "changed"
+
+	assert.Equal(t, "db", nested["host"])
+}
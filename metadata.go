@@ -0,0 +1,31 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeMetadata decodes the gRPC metadata, such as metadata.MD of https://pkg.go.dev/google.golang.org/grpc/metadata
+// returned by metadata.FromIncomingContext, into the fields of the struct pointed to by v with the `metadata` tag,
+// such as `metadata:"x-tenant-override"`, like [DecodeHeader] decodes the HTTP headers.
+//
+// Keys are case-insensitive. Fields of absent keys are left untouched, so [Type] fields stay unset.
+// Fields of a slice type receive all the values of the key. The values of binary keys, with the "-bin" suffix,
+// are the decoded bytes as gRPC provides them.
+func DecodeMetadata(md map[string][]string, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeMetadata expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	get := func(name string) []string {
+		if vals, ok := md[strings.ToLower(name)]; ok {
+			return vals
+		}
+
+		return md[name]
+	}
+
+	return decodeFields(get, nil, rv.Elem(), "metadata", newOptions(opts))
+}
@@ -0,0 +1,59 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type callMetadata struct {
+	Tenant   optional.Type[string]        `metadata:"X-Tenant-Override"`
+	Sampled  optional.Type[bool]          `metadata:"x-trace-sampled"`
+	Timeout  optional.Type[time.Duration] `metadata:"x-timeout"`
+	Features optional.Type[[]string]      `metadata:"x-feature"`
+	Priority optional.Type[int]           `metadata:"x-priority"`
+	Ignored  string
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	t.Parallel()
+
+	md := map[string][]string{
+		"x-tenant-override": {"acme"},
+		"x-trace-sampled":   {"true"},
+		"x-timeout":         {"1.5s"},
+		"x-feature":         {"a", "b"},
+		"x-priority":        {""},
+	}
+
+	var got callMetadata
+
+	require.NoError(t, optional.DecodeMetadata(md, &got, optional.WithEmpty(optional.EmptyNull)))
+
+	assert.Equal(t, optional.Some("acme"), got.Tenant)
+	assert.Equal(t, optional.Some(true), got.Sampled)
+	assert.Equal(t, optional.Some(1500*time.Millisecond), got.Timeout)
+	assert.Equal(t, optional.Some([]string{"a", "b"}), got.Features)
+	assert.Equal(t, optional.Null[int](), got.Priority)
+
+	got = callMetadata{}
+
+	require.NoError(t, optional.DecodeMetadata(nil, &got))
+	assert.Equal(t, callMetadata{}, got)
+}
+
+func TestDecodeMetadata_Error(t *testing.T) {
+	t.Parallel()
+
+	var got callMetadata
+
+	err := optional.DecodeMetadata(map[string][]string{"x-priority": {"high"}}, &got)
+	require.ErrorContains(t, err, `optional: field "x-priority"`)
+
+	err = optional.DecodeMetadata(nil, got)
+	require.EqualError(t, err, "optional: DecodeMetadata expects a non-nil pointer to a struct, got optional_test.callMetadata")
+}
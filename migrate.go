@@ -0,0 +1,86 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// migrations holds the registered migrations by the struct types.
+var migrations = struct {
+	sync.RWMutex
+	m map[reflect.Type][]migration
+}{m: map[reflect.Type][]migration{}}
+
+// migration transforms the value of the deprecated key into the fields of the struct.
+type migration struct {
+	key string
+	fn  func(raw json.RawMessage, v reflect.Value) error
+}
+
+// RegisterMigration registers the migration of the deprecated key of the JSON objects decoded by [Decoder]
+// into the struct T, so the shims of the previous versions of the API live next to the types rather than
+// inside the handlers:
+//
+//	optional.RegisterMigration("fullName", func(raw json.RawMessage, u *User) error {
+//		var name string
+//		if err := json.Unmarshal(raw, &name); err != nil {
+//			return err
+//		}
+//
+//		first, last, _ := strings.Cut(name, " ")
+//		u.First, u.Last = optional.Some(first), optional.Some(last)
+//
+//		return nil
+//	})
+//
+// The function is called with the raw value of the key, including null, after the other members of the object
+// are decoded, so it can keep the fields already sent under the new keys. The key is matched like the fields are,
+// case-insensitively unless disabled by [WithCaseInsensitive]. The errors of the function are returned by [Decoder]
+// with the dotted path of the key. Registering the key of T again replaces its migration.
+func RegisterMigration[T any](key string, fn func(raw json.RawMessage, v *T) error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: RegisterMigration expects a struct type, got %s", t))
+	}
+
+	m := migration{key: key, fn: func(raw json.RawMessage, v reflect.Value) error {
+		return fn(raw, v.Addr().Interface().(*T))
+	}}
+
+	migrations.Lock()
+	defer migrations.Unlock()
+
+	list := make([]migration, 0, len(migrations.m[t])+1)
+
+	for _, c := range migrations.m[t] {
+		if c.key != key {
+			list = append(list, c)
+		}
+	}
+
+	migrations.m[t] = append(list, m)
+}
+
+// lookupMigration finds the migration of the key of the objects decoded into the struct type t.
+func lookupMigration(t reflect.Type, key string, caseFold bool) (migration, bool) {
+	migrations.RLock()
+	defer migrations.RUnlock()
+
+	for _, m := range migrations.m[t] {
+		if m.key == key || caseFold && strings.EqualFold(m.key, key) {
+			return m, true
+		}
+	}
+
+	return migration{}, false
+}
+
+// pendingMigration is the migration of the key found in the object, applied after its members are decoded.
+type pendingMigration struct {
+	migration
+	raw json.RawMessage
+	key string
+}
@@ -0,0 +1,111 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type migratedUser struct {
+	First optional.Type[string] `json:"first"`
+	Last  optional.Type[string] `json:"last"`
+}
+
+type migratedAccount struct {
+	Owner *migratedUser `json:"owner"`
+}
+
+func init() {
+	optional.RegisterMigration("fullName", func(raw json.RawMessage, u *migratedUser) error {
+		var name *string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return err
+		}
+
+		if name == nil {
+			u.First.SetNull()
+			u.Last.SetNull()
+
+			return nil
+		}
+
+		if strings.TrimSpace(*name) == "" {
+			return errors.New("empty name")
+		}
+
+		first, last, _ := strings.Cut(*name, " ")
+
+		if !u.First.IsSet() {
+			u.First = optional.Some(first)
+		}
+
+		if !u.Last.IsSet() {
+			u.Last = optional.Some(last)
+		}
+
+		return nil
+	})
+}
+
+func TestRegisterMigration(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input string
+		want  migratedUser
+	}{
+		{"new keys", `{"first":"John","last":"Doe"}`, migratedUser{optional.Some("John"), optional.Some("Doe")}},
+		{"deprecated key", `{"fullName":"John Doe"}`, migratedUser{optional.Some("John"), optional.Some("Doe")}},
+		{"case folded", `{"FULLNAME":"John Doe"}`, migratedUser{optional.Some("John"), optional.Some("Doe")}},
+		{"new keys win", `{"fullName":"John Doe","last":"Smith"}`, migratedUser{optional.Some("John"), optional.Some("Smith")}},
+		{"null", `{"fullName":null}`, migratedUser{optional.Null[string](), optional.Null[string]()}},
+		{"absent", `{}`, migratedUser{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got migratedUser
+
+			require.NoError(t, optional.NewDecoder(strings.NewReader(tt.input)).Decode(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterMigration_Nested(t *testing.T) {
+	t.Parallel()
+
+	var got migratedAccount
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(`{"owner":{"fullName":"John Doe"}}`)).Decode(&got))
+	require.NotNil(t, got.Owner)
+	assert.Equal(t, migratedUser{optional.Some("John"), optional.Some("Doe")}, *got.Owner)
+
+	err := optional.NewDecoder(strings.NewReader(`{"owner":{"fullName":" "}}`)).Decode(&got)
+	require.EqualError(t, err, `optional: migrating field "owner.fullName": empty name`)
+
+	dec := optional.NewDecoder(strings.NewReader(`{"owner":{"FullName":"John Doe"}}`), optional.WithCaseInsensitive(false))
+
+	got = migratedAccount{}
+	require.NoError(t, dec.Decode(&got))
+	assert.Equal(t, migratedUser{}, *got.Owner)
+}
+
+func TestRegisterMigration_NotStruct(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "optional: RegisterMigration expects a struct type, got int", func() {
+		optional.RegisterMigration("x", func(json.RawMessage, *int) error { return nil })
+	})
+}
@@ -0,0 +1,56 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetFieldNames returns the names of the set [Type] fields of the struct v, including the fields set to null,
+// in declaration order. The names are taken from the `json` tags, fields of nested structs have dotted paths,
+// such as "address.city". A set [Type] of a struct is reported by the paths of its set fields, or by its own
+// name if none of them are set.
+func SetFieldNames(v any) []string {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: SetFieldNames expects a struct, got %T", v))
+	}
+
+	return appendSetFieldNames(nil, rv, "")
+}
+
+func appendSetFieldNames(names []string, v reflect.Value, prefix string) []string {
+	for _, f := range jsonFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		if isTrackedType(fv.Type()) {
+			fv = fv.Field(0)
+		}
+
+		name := prefix + f.name
+
+		switch {
+		case isOptionalType(fv.Type()):
+			p := fv.Interface().(presence)
+			if !p.IsSet() && !p.IsSetNull() {
+				continue
+			}
+
+			if val := fv.FieldByName("V"); !p.IsSetNull() && isPlainStruct(val.Type()) {
+				if sub := appendSetFieldNames(nil, val, name+"."); len(sub) != 0 {
+					names = append(names, sub...)
+
+					continue
+				}
+			}
+
+			names = append(names, name)
+		case isPlainStruct(fv.Type()):
+			names = appendSetFieldNames(names, fv, name+".")
+		}
+	}
+
+	return names
+}
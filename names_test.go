@@ -0,0 +1,42 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+func TestSetFieldNames(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+	}
+
+	type user struct {
+		ID       int                    `json:"id"`
+		Name     optional.Type[string]  `json:"name"`
+		Email    optional.Type[string]  `json:"email"`
+		Phone    optional.Type[string]  `json:"phone"`
+		Address  optional.Type[address] `json:"address"`
+		Shipping optional.Type[address] `json:"shipping"`
+		Billing  address                `json:"billing"`
+	}
+
+	v := user{
+		ID:       1,
+		Name:     optional.Some("some"),
+		Email:    optional.Null[string](),
+		Address:  optional.Some(address{City: optional.Some("Paris"), Street: optional.Null[string]()}),
+		Shipping: optional.Some(address{}),
+		Billing:  address{City: optional.Some("Moscow")},
+	}
+
+	assert.Equal(t, []string{"name", "email", "address.city", "address.street", "shipping", "billing.city"},
+		optional.SetFieldNames(v))
+	assert.Empty(t, optional.SetFieldNames(&user{}))
+	assert.Panics(t, func() { optional.SetFieldNames(1) })
+}
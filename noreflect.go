@@ -0,0 +1,10 @@
+//go:build tinygo || optional_noreflect
+
+package optional
+
+// noReflect makes the values of primitive types, such as strings, booleans and numbers, marshalled and
+// unmarshalled without encoding/json and reflection, for TinyGo and other environments with limited
+// support of reflection. The current marshaller and unmarshaller are used for other types, and for
+// the primitive ones too when they are changed with [ChangeMarshal] and [ChangeUnmarshal] or an interner
+// is set with [ChangeInterner].
+const noReflect = true
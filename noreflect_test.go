@@ -0,0 +1,165 @@
+//go:build tinygo || optional_noreflect
+
+package optional_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// TestNoReflect_Marshal checks that the primitive values are encoded exactly like encoding/json does.
+func TestNoReflect_Marshal(t *testing.T) {
+	t.Parallel()
+
+	values := []any{
+		"", "plain", `quote " and \ slash`, "<html> & 'script'", "line\nbreak\ttab\r\x01\x1f", "юникод 😀",
+		"invalid \xff utf-8", "separators    ",
+		true, false,
+		0, -1, math.MaxInt64, int8(-128), int16(300), int32(-70000), uint(7), uint8(255), uint64(math.MaxUint64),
+		0.0, 1.5, -2.25, 1e20, 1e21, 1e-6, 1e-7, 123456789.123, math.MaxFloat64, math.SmallestNonzeroFloat64,
+		float32(3.14), float32(1e-7), float32(1e21),
+	}
+
+	for _, v := range values {
+		want, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		got, err := marshalSome(v)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(want), string(got), "%#v", v)
+	}
+
+	_, err := optional.Some(math.NaN()).MarshalJSON()
+	require.Error(t, err)
+}
+
+func marshalSome(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return optional.Some(v).MarshalJSON()
+	case bool:
+		return optional.Some(v).MarshalJSON()
+	case int:
+		return optional.Some(v).MarshalJSON()
+	case int8:
+		return optional.Some(v).MarshalJSON()
+	case int16:
+		return optional.Some(v).MarshalJSON()
+	case int32:
+		return optional.Some(v).MarshalJSON()
+	case uint:
+		return optional.Some(v).MarshalJSON()
+	case uint8:
+		return optional.Some(v).MarshalJSON()
+	case uint64:
+		return optional.Some(v).MarshalJSON()
+	case float32:
+		return optional.Some(v).MarshalJSON()
+	case float64:
+		return optional.Some(v).MarshalJSON()
+	}
+
+	return nil, nil
+}
+
+// TestNoReflect_Unmarshal checks that the primitive values are decoded like encoding/json does.
+func TestNoReflect_Unmarshal(t *testing.T) {
+	t.Parallel()
+
+	strings := []string{
+		`""`, `"plain"`, `"esc \" \\ \/ \b \f \n \r \t"`, `"Aé中"`, `"😀"`, `"lone \ud83d surrogate"`,
+		`"\ude00 \ud83dA"`, "\"invalid \xff\"", `"юникод"`,
+	}
+
+	for _, data := range strings {
+		var want string
+
+		require.NoError(t, json.Unmarshal([]byte(data), &want), data)
+
+		var got optional.Type[string]
+
+		require.NoError(t, got.UnmarshalJSON([]byte(data)), data)
+		assert.Equal(t, want, got.V, data)
+	}
+
+	for _, data := range []string{`"\x"`, `"\u12"`, "\"\x01\"", `"a"b"`, `1`} {
+		var got optional.Type[string]
+
+		assert.Error(t, got.UnmarshalJSON([]byte(data)), data)
+	}
+
+	var i optional.Type[int8]
+
+	require.NoError(t, i.UnmarshalJSON([]byte(`-12`)))
+	assert.Equal(t, int8(-12), i.V)
+	assert.Error(t, i.UnmarshalJSON([]byte(`300`)))
+	assert.Error(t, i.UnmarshalJSON([]byte(`1.5`)))
+
+	var f optional.Type[float64]
+
+	require.NoError(t, f.UnmarshalJSON([]byte(`-1.5e3`)))
+	assert.Equal(t, -1500.0, f.V)
+
+	for _, data := range []string{`01`, `.5`, `1.`, `+1`, `1e`, `Inf`, `0x10`} {
+		assert.Error(t, f.UnmarshalJSON([]byte(data)), data)
+	}
+
+	var b optional.Type[bool]
+
+	require.NoError(t, b.UnmarshalJSON([]byte(`true`)))
+	assert.True(t, b.V)
+	assert.Error(t, b.UnmarshalJSON([]byte(`1`)))
+}
+
+func TestNoReflect_Errors(t *testing.T) {
+	t.Parallel()
+
+	var s optional.Type[string]
+
+	var te *json.UnmarshalTypeError
+
+	require.ErrorAs(t, s.UnmarshalJSON([]byte(`1`)), &te)
+	assert.Equal(t, "number", te.Value)
+	assert.Equal(t, "string", te.Type.String())
+
+	var i optional.Type[int8]
+
+	require.ErrorAs(t, i.UnmarshalJSON([]byte(`300`)), &te)
+	assert.Equal(t, "number 300", te.Value)
+	assert.Equal(t, "int8", te.Type.String())
+
+	for _, data := range []string{`300`, `1.5`, `"1"`, `true`, `[]`, `{}`} {
+		want := json.Unmarshal([]byte(data), new(int8))
+		require.Error(t, want, data)
+		assert.EqualError(t, i.UnmarshalJSON([]byte(data)), want.Error(), data)
+	}
+}
+
+// TestNoReflect_Custom checks that the changed marshaller and unmarshaller see the primitive values.
+func TestNoReflect_Custom(t *testing.T) {
+	optional.ChangeMarshal(func(any) ([]byte, error) { return []byte(`"custom"`), nil })
+	defer optional.ChangeMarshal(json.Marshal)
+
+	optional.ChangeUnmarshal(func(_ []byte, v any) error {
+		*v.(*int) = 42
+
+		return nil
+	})
+	defer optional.ChangeUnmarshal(json.Unmarshal)
+
+	got, err := optional.Some(1).MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `"custom"`, string(got))
+
+	var i optional.Type[int]
+
+	require.NoError(t, i.UnmarshalJSON([]byte(`1`)))
+	assert.Equal(t, 42, i.V)
+}
@@ -0,0 +1,135 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// normalizers holds the normalizers of the `optional:"norm=..."` tags by their names.
+var normalizers = struct {
+	sync.RWMutex
+	m map[string]func(string) string
+}{m: map[string]func(string) string{
+	"trim":     strings.TrimSpace,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"collapse": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}}
+
+// RegisterNormalizer registers the normalizer applied by [Normalize] to the strings of the fields listing
+// its name in the `optional:"norm=..."` tag, such as a slug normalizer. Registering a name again replaces
+// its normalizer, including the built-in ones.
+func RegisterNormalizer(name string, fn func(string) string) {
+	normalizers.Lock()
+	defer normalizers.Unlock()
+
+	normalizers.m[name] = fn
+}
+
+// WithNormalize makes [Decoder], [LinesDecoder] and [DecodeRequest] apply [Normalize] to each decoded value,
+// so the canonicalization of the values is declared by the tags rather than repeated in the handlers.
+func WithNormalize() Option {
+	return func(o *options) {
+		o.normalize = true
+	}
+}
+
+// Normalize applies the normalizers listed in the `optional` tags, such as `optional:"norm=trim,lower"`,
+// to the strings of the fields of the struct pointed to by v, in the order of the tag. The built-in
+// normalizers are trim, lower, upper and collapse, replacing the runs of whitespace by a single space,
+// others are registered with [RegisterNormalizer].
+//
+// Only the present values are normalized: the unset and null [Type] fields and nil pointers are left intact.
+// The normalizers of the slices and arrays apply to their elements, the nested structs, including the values
+// of the set [Type] fields, are normalized recursively. An error is returned if a normalizer is unknown
+// or the field does not hold strings.
+func Normalize(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || derefPointer(rv).Kind() != reflect.Struct {
+		return fmt.Errorf("optional: Normalize expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	return normalizeValue(rv, "", nil)
+}
+
+// normalizeValue applies the normalizers to the value v at the path and normalizes the values inside it.
+func normalizeValue(v reflect.Value, path string, norms []string) error {
+	if isPresenceType(v.Type()) {
+		if fieldState(v) != StateValue {
+			return nil
+		}
+
+		v = presenceValue(v)
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.String && len(norms) != 0:
+		s := v.String()
+
+		for _, name := range norms {
+			fn, err := lookupNormalizer(name)
+			if err != nil {
+				return fmt.Errorf("optional: field %q: %w", path, err)
+			}
+
+			s = fn(s)
+		}
+
+		v.SetString(s)
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := normalizeValue(v.Index(i), joinPath(path, strconv.Itoa(i)), norms); err != nil {
+				return err
+			}
+		}
+	case len(norms) != 0:
+		return fmt.Errorf("optional: field %q: norm=%s not applicable to %s", path, norms[0], v.Type())
+	case isPlainStruct(v.Type()) && !isTrackedType(v.Type()):
+		for _, f := range jsonFields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok {
+				continue
+			}
+
+			tag := v.Type().FieldByIndex(f.index).Tag.Get("optional")
+
+			if err := normalizeValue(fv, joinPath(path, f.name), tagNormalizers(tag)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// tagNormalizers returns the names of the normalizers of the `optional` tag, such as "norm=trim,lower".
+func tagNormalizers(tag string) []string {
+	if i := strings.Index(","+tag, ",pattern="); i != -1 {
+		tag = tag[:i]
+	}
+
+	return tagList(tag, "norm")
+}
+
+func lookupNormalizer(name string) (func(string) string, error) {
+	normalizers.RLock()
+	defer normalizers.RUnlock()
+
+	fn, ok := normalizers.m[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown normalizer %q", name)
+	}
+
+	return fn, nil
+}
@@ -0,0 +1,113 @@
+package optional_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func init() {
+	optional.RegisterNormalizer("slug", func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "-")
+	})
+}
+
+type normProfile struct {
+	Bio optional.Type[string] `json:"bio" optional:"norm=collapse"`
+}
+
+type normUser struct {
+	Email   optional.Type[string]      `json:"email" form:"email" optional:"norm=trim,lower"`
+	Slug    string                     `json:"slug" optional:"norm=slug"`
+	Login   optional.Type[string]      `json:"login" optional:"norm=trim,pattern=^[a-z, ]+$"`
+	Tags    optional.Type[[]string]    `json:"tags" optional:"norm=upper"`
+	Nick    *string                    `json:"nick" optional:"norm=trim"`
+	Name    optional.Type[string]      `json:"name" optional:"norm=trim"`
+	Profile optional.Type[normProfile] `json:"profile"`
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	nick := " neo "
+
+	u := normUser{
+		Email:   optional.Some("  John@Example.COM "),
+		Slug:    " Hello World",
+		Login:   optional.Some(" a, b "),
+		Tags:    optional.Some([]string{"a", "b"}),
+		Nick:    &nick,
+		Name:    optional.Null[string](),
+		Profile: optional.Some(normProfile{Bio: optional.Some(" too   many\tspaces ")}),
+	}
+
+	require.NoError(t, optional.Normalize(&u))
+
+	assert.Equal(t, optional.Some("john@example.com"), u.Email)
+	assert.Equal(t, "hello-world", u.Slug)
+	assert.Equal(t, optional.Some("a, b"), u.Login)
+	assert.Equal(t, optional.Some([]string{"A", "B"}), u.Tags)
+	assert.Equal(t, "neo", nick)
+	assert.Equal(t, optional.Null[string](), u.Name)
+	assert.Equal(t, optional.Some("too many spaces"), u.Profile.V.Bio)
+}
+
+func TestNormalize_Error(t *testing.T) {
+	t.Parallel()
+
+	type unknown struct {
+		Name optional.Type[string] `json:"name" optional:"norm=reverse"`
+	}
+
+	type notString struct {
+		Age optional.Type[int] `json:"age" optional:"norm=trim"`
+	}
+
+	err := optional.Normalize(&unknown{Name: optional.Some("a")})
+	require.EqualError(t, err, `optional: field "name": unknown normalizer "reverse"`)
+
+	err = optional.Normalize(&notString{Age: optional.Some(1)})
+	require.EqualError(t, err, `optional: field "age": norm=trim not applicable to int`)
+
+	require.NoError(t, optional.Normalize(&notString{}))
+
+	err = optional.Normalize(normUser{})
+	require.EqualError(t, err, "optional: Normalize expects a non-nil pointer to a struct, got optional_test.normUser")
+}
+
+func TestWithNormalize(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"email":" John@Example.COM","slug":"Hello World"}`
+
+	var got normUser
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(body), optional.WithNormalize()).Decode(&got))
+	assert.Equal(t, optional.Some("john@example.com"), got.Email)
+	assert.Equal(t, "hello-world", got.Slug)
+
+	got = normUser{}
+
+	dec := optional.NewLinesDecoder(strings.NewReader(body+"\n"), optional.WithNormalize())
+	require.NoError(t, dec.Decode(&got))
+	assert.Equal(t, optional.Some("john@example.com"), got.Email)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("email=+John%40Example.COM"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	got = normUser{}
+
+	require.NoError(t, optional.DecodeRequest(r, &got, optional.WithNormalize()))
+	assert.Equal(t, optional.Some("john@example.com"), got.Email)
+
+	got = normUser{}
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(body)).Decode(&got))
+	assert.Equal(t, optional.Some(" John@Example.COM"), got.Email)
+}
@@ -0,0 +1,110 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// WithFloatFormat makes [Marshal] and [WriteJSON] format the floats held by the set [Type] and [Tracked] fields
+// with [strconv.FormatFloat] using the format, 'f', 'e' or 'g', and the precision, such as 'f' and -1 to never use
+// the scientific notation or 'f' and 2 for two decimals. The `optional:"prec=N"` tag sets the precision of a single
+// field, with the 'f' format unless set by the option. The formatting is lost with [WithCanonical].
+func WithFloatFormat(format byte, prec int) Option {
+	return func(o *options) {
+		o.floatFormat = format
+		o.floatPrec = prec
+	}
+}
+
+// WithInt64AsString makes [Marshal] and [WriteJSON] encode the 64-bit integers held by the set [Type] and [Tracked]
+// fields, of the int64, uint64, int and uint types, as strings, such as "9007199254740993", so JavaScript consumers
+// do not lose the precision of the identifiers beyond 2^53.
+func WithInt64AsString() Option {
+	return func(o *options) {
+		o.int64String = true
+	}
+}
+
+// hasNumberFormat reports whether the numbers of the field are formatted by the options or the tag.
+func (e *encoder) hasNumberFormat(f jsonField) bool {
+	return e.o.floatFormat != 0 || e.o.int64String || f.prec != ""
+}
+
+// encodeNumber writes the number v formatted by the options and the tag of the field,
+// reporting false if v is not a formatted number.
+func (e *encoder) encodeNumber(v reflect.Value, f jsonField) (bool, error) {
+	if v = derefPointer(v); !v.IsValid() || hasMarshaler(v) {
+		return false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		format, prec := e.o.floatFormat, e.o.floatPrec
+
+		if f.prec != "" {
+			n, err := strconv.Atoi(f.prec)
+			if err != nil {
+				return false, fmt.Errorf("invalid precision %q: %w", f.prec, err)
+			}
+
+			prec = n
+
+			if format == 0 {
+				format = 'f'
+			}
+		}
+
+		if format == 0 {
+			return false, nil
+		}
+
+		return true, e.encodeFloat(v, format, prec)
+	case reflect.Int, reflect.Int64:
+		if !e.o.int64String {
+			return false, nil
+		}
+
+		e.buf.WriteByte('"')
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+		e.buf.WriteByte('"')
+
+		return true, nil
+	case reflect.Uint, reflect.Uint64:
+		if !e.o.int64String {
+			return false, nil
+		}
+
+		e.buf.WriteByte('"')
+		e.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+		e.buf.WriteByte('"')
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (e *encoder) encodeFloat(v reflect.Value, format byte, prec int) error {
+	switch format {
+	case 'f', 'e', 'g':
+	default:
+		return fmt.Errorf("invalid float format %q", format)
+	}
+
+	n := v.Float()
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(n, 'g', -1, 64)}
+	}
+
+	bits := 64
+	if v.Kind() == reflect.Float32 {
+		bits = 32
+	}
+
+	e.buf.WriteString(strconv.FormatFloat(n, format, prec, bits))
+
+	return nil
+}
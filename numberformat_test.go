@@ -0,0 +1,93 @@
+package optional_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type numbersPayload struct {
+	ID     optional.Type[int64]   `json:"id"`
+	Count  int64                  `json:"count"`
+	Serial optional.Type[*uint64] `json:"serial"`
+	Price  optional.Type[float64] `json:"price" optional:"prec=2"`
+	Ratio  optional.Type[float32] `json:"ratio"`
+	Size   optional.Type[int32]   `json:"size"`
+	Quoted optional.Type[float64] `json:"quoted,string"`
+	Null   optional.Type[int64]   `json:"null"`
+}
+
+func TestMarshal_NumberFormat(t *testing.T) {
+	t.Parallel()
+
+	serial := uint64(math.MaxUint64)
+
+	p := numbersPayload{
+		ID:     optional.Some(int64(9007199254740993)),
+		Count:  9007199254740993,
+		Serial: optional.Some(&serial),
+		Price:  optional.Some(10.0),
+		Ratio:  optional.Some(float32(1e21)),
+		Size:   optional.Some(int32(7)),
+		Quoted: optional.Some(0.5),
+		Null:   optional.Null[int64](),
+	}
+
+	tests := [...]struct {
+		name string
+		opts []optional.Option
+		want string
+	}{
+		{
+			"default",
+			nil,
+			`{"id":9007199254740993,"count":9007199254740993,"serial":18446744073709551615,"price":10.00,` +
+				`"ratio":1e+21,"size":7,"quoted":"0.5","null":null}`,
+		},
+		{
+			"int64 as string",
+			[]optional.Option{optional.WithInt64AsString()},
+			`{"id":"9007199254740993","count":9007199254740993,"serial":"18446744073709551615","price":10.00,` +
+				`"ratio":1e+21,"size":7,"quoted":"0.5","null":null}`,
+		},
+		{
+			"no scientific notation",
+			[]optional.Option{optional.WithFloatFormat('f', -1)},
+			`{"id":9007199254740993,"count":9007199254740993,"serial":18446744073709551615,"price":10.00,` +
+				`"ratio":1000000000000000000000,"size":7,"quoted":"0.5","null":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := optional.Marshal(p, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestMarshal_NumberFormat_Error(t *testing.T) {
+	t.Parallel()
+
+	type badPrec struct {
+		Price optional.Type[float64] `json:"price" optional:"prec=two"`
+	}
+
+	_, err := optional.Marshal(badPrec{Price: optional.Some(1.0)})
+	require.ErrorContains(t, err, `invalid precision "two"`)
+
+	_, err = optional.Marshal(numbersPayload{Ratio: optional.Some(float32(1))}, optional.WithFloatFormat('x', -1))
+	require.ErrorContains(t, err, `invalid float format 'x'`)
+
+	_, err = optional.Marshal(numbersPayload{Price: optional.Some(math.Inf(1))})
+	require.ErrorContains(t, err, "+Inf")
+}
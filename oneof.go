@@ -0,0 +1,176 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// oneOfs holds the registered variants by the interface types.
+var oneOfs sync.Map
+
+// oneOf is the set of the variants of an interface type selected by the discriminator key.
+type oneOf struct {
+	key      string
+	variants map[string]reflect.Type
+}
+
+// RegisterOneOf registers the variants of the interface type T, so the values of [Type] of T are decoded
+// into the variant named by the member key of the JSON object, such as "type" of the webhook payloads
+// like {"type":"card","number":"..."}. The variants are given by their zero values, either structs or
+// pointers to structs:
+//
+//	optional.RegisterOneOf[PaymentMethod]("type", map[string]PaymentMethod{"card": Card{}, "bank": &Bank{}})
+//
+// The unset and null states are decoded as usual. Values are encoded as the variants they hold, so the variants
+// should have the discriminator field themselves. Registering T again replaces its variants.
+//
+// When the discriminator is a sibling of the value rather than its member, such as {"kind":"card","method":{...}},
+// the field is tagged with the key of the sibling, which [Decoder] resolves while walking the object:
+//
+//	Kind   string                       `json:"kind"`
+//	Method optional.Type[PaymentMethod] `json:"method" optional:"oneof=kind"`
+func RegisterOneOf[T any](key string, variants map[string]T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("optional: RegisterOneOf expects an interface type, got %s", t))
+	}
+
+	o := &oneOf{key: key, variants: make(map[string]reflect.Type, len(variants))}
+
+	for name, v := range variants {
+		vt := reflect.TypeOf(v)
+		if vt == nil {
+			panic(fmt.Sprintf("optional: RegisterOneOf: variant %q of %s is nil", name, t))
+		}
+
+		o.variants[name] = vt
+	}
+
+	oneOfs.Store(t, o)
+}
+
+// unmarshalOneOf decodes the data into the variant of the interface pointed to by ptr, reporting false
+// if the interface type has no registered variants.
+func unmarshalOneOf(data []byte, ptr any) (bool, error) {
+	v := reflect.ValueOf(ptr)
+
+	o, ok := oneOfs.Load(v.Type().Elem())
+	if !ok {
+		return false, nil
+	}
+
+	return true, o.(*oneOf).unmarshal(data, v.Elem())
+}
+
+func (o *oneOf) unmarshal(data []byte, v reflect.Value) error {
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	raw, ok := members[o.key]
+
+	vt, err := o.variant(o.key, raw, ok, v.Type())
+	if err != nil {
+		return err
+	}
+
+	var variant reflect.Value
+
+	if vt.Kind() == reflect.Ptr {
+		variant = reflect.New(vt.Elem())
+
+		if err := unmarshaller(data, variant.Interface()); err != nil {
+			return err
+		}
+	} else {
+		p := reflect.New(vt)
+
+		if err := unmarshaller(data, p.Interface()); err != nil {
+			return err
+		}
+
+		variant = p.Elem()
+	}
+
+	v.Set(variant)
+
+	return nil
+}
+
+// variant returns the type of the variant named by the raw discriminator of the key, found reports whether
+// the discriminator is present, t is the interface type for the errors.
+func (o *oneOf) variant(key string, raw json.RawMessage, found bool, t reflect.Type) (reflect.Type, error) {
+	if !found {
+		return nil, fmt.Errorf("optional: missing discriminator %q of %s", key, t)
+	}
+
+	var name string
+
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return nil, fmt.Errorf("optional: discriminator %q of %s: %w", key, t, err)
+	}
+
+	vt, ok := o.variants[name]
+	if !ok {
+		return nil, fmt.Errorf("optional: unknown %q discriminator %q of %s", key, name, t)
+	}
+
+	return vt, nil
+}
+
+// siblingOneOf is the member of a field tagged `optional:"oneof=key"`, decoded after the other members
+// of the object, once its discriminator is known.
+type siblingOneOf struct {
+	f     jsonField
+	raw   json.RawMessage
+	start int64
+}
+
+// decodeSiblingOneOfAt decodes the raw value into the variant of the interface field v named by disc,
+// the raw value of the sibling member of the key, found reports whether the sibling is present.
+func (d *Decoder) decodeSiblingOneOfAt(raw, disc json.RawMessage, found bool, v reflect.Value, key string,
+	offset int64, path, structName string,
+) error {
+	iv := v
+	if isPresenceType(v.Type()) {
+		iv = presenceValue(v)
+	}
+
+	o, ok := oneOfs.Load(iv.Type())
+	if !ok {
+		return fmt.Errorf("optional: field %q: %s has no variants registered with RegisterOneOf", path, iv.Type())
+	}
+
+	if isNull(raw) {
+		return d.decodeAt(raw, v, offset, path, structName)
+	}
+
+	vt, err := o.(*oneOf).variant(key, disc, found, iv.Type())
+	if err != nil {
+		return err
+	}
+
+	variant := reflect.New(vt).Elem()
+	target := variant
+
+	if vt.Kind() == reflect.Ptr {
+		variant = reflect.New(vt.Elem())
+		target = variant.Elem()
+	}
+
+	if err := d.decodeAt(raw, target, offset, path, structName); err != nil {
+		return err
+	}
+
+	if a, ok := asAccessor(v); ok {
+		a.mark(true, false)
+	}
+
+	iv.Set(variant)
+
+	return nil
+}
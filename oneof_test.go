@@ -0,0 +1,178 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type paymentMethod interface {
+	isPaymentMethod()
+}
+
+type cardMethod struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+type bankMethod struct {
+	Type    string `json:"type"`
+	Account string `json:"account"`
+}
+
+func (cardMethod) isPaymentMethod()  {}
+func (*bankMethod) isPaymentMethod() {}
+
+func init() {
+	optional.RegisterOneOf[paymentMethod]("type", map[string]paymentMethod{
+		"card": cardMethod{},
+		"bank": &bankMethod{},
+	})
+}
+
+func TestRegisterOneOf(t *testing.T) {
+	t.Parallel()
+
+	type payment struct {
+		Method optional.Type[paymentMethod] `json:"method"`
+	}
+
+	tests := [...]struct {
+		name    string
+		input   string
+		want    optional.Type[paymentMethod]
+		wantErr string
+	}{
+		{"unset", `{}`, optional.Type[paymentMethod]{}, ""},
+		{"null", `{"method":null}`, optional.Null[paymentMethod](), ""},
+		{"value", `{"method":{"number":"4242","type":"card"}}`, optional.Some[paymentMethod](cardMethod{Type: "card", Number: "4242"}), ""},
+		{"pointer", `{"method":{"type":"bank","account":"DE89"}}`, optional.Some[paymentMethod](&bankMethod{Type: "bank", Account: "DE89"}), ""},
+		{"missing", `{"method":{"number":"4242"}}`, optional.Type[paymentMethod]{}, `optional: missing discriminator "type" of optional_test.paymentMethod`},
+		{"unknown", `{"method":{"type":"cash"}}`, optional.Type[paymentMethod]{}, `optional: unknown "type" discriminator "cash" of optional_test.paymentMethod`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for name, decode := range map[string]func(v any) error{
+				"unmarshal": func(v any) error { return json.Unmarshal([]byte(tt.input), v) },
+				"decoder":   func(v any) error { return optional.NewDecoder(strings.NewReader(tt.input)).Decode(v) },
+			} {
+				var got payment
+
+				err := decode(&got)
+				if tt.wantErr != "" {
+					require.ErrorContains(t, err, tt.wantErr, name)
+
+					continue
+				}
+
+				require.NoError(t, err, name)
+				assert.Equal(t, tt.want, got.Method, name)
+
+				out, err := optional.Marshal(got)
+				require.NoError(t, err, name)
+				assert.JSONEq(t, tt.input, string(out), name)
+			}
+		})
+	}
+}
+
+func TestRegisterOneOf_Sibling(t *testing.T) {
+	t.Parallel()
+
+	type payment struct {
+		Kind   string                       `json:"kind"`
+		Method optional.Type[paymentMethod] `json:"method" optional:"oneof=kind"`
+		Backup paymentMethod                `json:"backup" optional:"oneof=kind"`
+	}
+
+	tests := [...]struct {
+		name    string
+		input   string
+		want    payment
+		wantErr string
+	}{
+		{"unset", `{"kind":"card"}`, payment{Kind: "card"}, ""},
+		{"null", `{"method":null}`, payment{Method: optional.Null[paymentMethod]()}, ""},
+		{
+			"value", `{"kind":"card","method":{"number":"4242"}}`,
+			payment{Kind: "card", Method: optional.Some[paymentMethod](cardMethod{Number: "4242"})}, "",
+		},
+		{
+			"after value", `{"method":{"account":"DE89"},"kind":"bank","backup":{"account":"FR76"}}`,
+			payment{
+				Kind:   "bank",
+				Method: optional.Some[paymentMethod](&bankMethod{Account: "DE89"}),
+				Backup: &bankMethod{Account: "FR76"},
+			}, "",
+		},
+		{"missing", `{"method":{"number":"4242"}}`, payment{}, `optional: missing discriminator "kind" of optional_test.paymentMethod`},
+		{"unknown", `{"kind":"cash","method":{}}`, payment{}, `optional: unknown "kind" discriminator "cash" of optional_test.paymentMethod`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got payment
+
+			err := optional.NewDecoder(strings.NewReader(tt.input)).Decode(&got)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterOneOf_SiblingError(t *testing.T) {
+	t.Parallel()
+
+	var located struct {
+		Kind   string                       `json:"kind"`
+		Method optional.Type[paymentMethod] `json:"method" optional:"oneof=kind"`
+	}
+
+	err := optional.NewDecoder(strings.NewReader(`{"kind":"card","method":{"number":1}}`)).Decode(&located)
+
+	var te *json.UnmarshalTypeError
+
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "method.number", te.Field)
+	assert.Equal(t, int64(35), te.Offset)
+
+	var unregistered struct {
+		Kind  string             `json:"kind"`
+		Value optional.Type[any] `json:"value" optional:"oneof=kind"`
+	}
+
+	err = optional.NewDecoder(strings.NewReader(`{"kind":"a","value":1}`)).Decode(&unregistered)
+	require.EqualError(t, err, `optional: field "value": interface {} has no variants registered with RegisterOneOf`)
+}
+
+func TestRegisterOneOf_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "optional: RegisterOneOf expects an interface type, got int", func() {
+		optional.RegisterOneOf[int]("type", nil)
+	})
+
+	assert.PanicsWithValue(t, `optional: RegisterOneOf: variant "card" of optional_test.paymentMethod is nil`, func() {
+		optional.RegisterOneOf[paymentMethod]("type", map[string]paymentMethod{"card": nil})
+	})
+}
@@ -0,0 +1,130 @@
+// Package optbench provides the benchmarks of encoding and decoding the structs of optional values,
+// so the codecs, such as encoding/json, json-iterator or sonic, can be compared with the real payloads
+// before switching.
+package optbench
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/micronull/optional"
+)
+
+// mediaType is the media type of the codec benchmarked by [Run].
+const mediaType = "application/json"
+
+// Run benchmarks the sample, a struct or a pointer to a struct, with the configured JSON codec as sub-benchmarks
+// of b, reporting ns/op, B/op and allocs/op:
+//
+//   - "encode" encodes the sample with [optional.EncodeAs], that is with the codec registered for application/json
+//     by [optional.RegisterContentType] or with [optional.Marshal];
+//   - "decode" decodes the encoded sample with [optional.DecodeAs], using the current unmarshaller by default;
+//   - "decoder" decodes the encoded sample with [optional.Decoder].
+//
+// Call it from a benchmark after plugging the codec:
+//
+//	func BenchmarkUser(b *testing.B) {
+//		optional.ChangeUnmarshal(jsoniter.Unmarshal)
+//
+//		optbench.Run(b, sampleUser)
+//	}
+func Run(b *testing.B, sample any) {
+	b.Helper()
+
+	data := prepare(b, sample, func(v any) ([]byte, error) { return optional.EncodeAs(mediaType, v) })
+	if data == nil {
+		return
+	}
+
+	benchmark(b, "encode", data, func() error {
+		_, err := optional.EncodeAs(mediaType, sample)
+
+		return err
+	})
+
+	benchmarkDecode(b, "decode", data, sample, func(v any) error { return optional.DecodeAs(mediaType, data, v) })
+
+	benchmarkDecode(b, "decoder", data, sample, func(v any) error {
+		return optional.NewDecoder(bytes.NewReader(data)).Decode(v)
+	})
+}
+
+// RunCodec benchmarks the sample like [Run] does, encoding and decoding it with the codec instead,
+// so the codecs can be compared side by side without changing the global state.
+func RunCodec(b *testing.B, c optional.Codec, sample any) {
+	b.Helper()
+
+	data := prepare(b, sample, c.Marshal)
+	if data == nil {
+		return
+	}
+
+	benchmark(b, "encode", data, func() error {
+		_, err := c.Marshal(sample)
+
+		return err
+	})
+
+	benchmarkDecode(b, "decode", data, sample, func(v any) error { return c.Unmarshal(data, v) })
+}
+
+// prepare encodes the sample, failing b if it is not a struct or cannot be encoded.
+func prepare(b *testing.B, sample any, marshal func(v any) ([]byte, error)) []byte {
+	b.Helper()
+
+	if t := structType(sample); t == nil {
+		b.Fatalf("optbench: expects a struct, got %T", sample)
+
+		return nil
+	}
+
+	data, err := marshal(sample)
+	if err != nil {
+		b.Fatalf("optbench: encode %T: %v", sample, err)
+
+		return nil
+	}
+
+	return data
+}
+
+func benchmark(b *testing.B, name string, data []byte, fn func() error) {
+	b.Helper()
+
+	b.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			if err := fn(); err != nil {
+				b.Fatalf("optbench: %s: %v", name, err)
+			}
+		}
+	})
+}
+
+// benchmarkDecode benchmarks decoding into a new value of the type of the sample for each iteration.
+func benchmarkDecode(b *testing.B, name string, data []byte, sample any, decode func(v any) error) {
+	b.Helper()
+
+	t := structType(sample)
+
+	benchmark(b, name, data, func() error {
+		return decode(reflect.New(t).Interface())
+	})
+}
+
+// structType returns the struct type of the sample, nil if it is not a struct or a pointer to a struct.
+func structType(sample any) reflect.Type {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return t
+}
@@ -0,0 +1,69 @@
+package optbench_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optbench"
+)
+
+type address struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type user struct {
+	ID      int64                   `json:"id"`
+	Name    optional.Type[string]   `json:"name"`
+	Email   optional.Type[string]   `json:"email"`
+	Tags    optional.Type[[]string] `json:"tags"`
+	Address optional.Type[address]  `json:"address"`
+}
+
+var sample = user{
+	ID:      1,
+	Name:    optional.Some("John"),
+	Email:   optional.Null[string](),
+	Tags:    optional.Some([]string{"a", "b"}),
+	Address: optional.Some(address{City: optional.Some("Paris")}),
+}
+
+func BenchmarkRun(b *testing.B) {
+	optbench.Run(b, sample)
+}
+
+func BenchmarkRunCodec(b *testing.B) {
+	optbench.RunCodec(b, optional.Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}, &sample)
+}
+
+func TestRun_Invalid(t *testing.T) {
+	t.Parallel()
+
+	type unsupported struct {
+		Ch optional.Type[chan int] `json:"ch"`
+	}
+
+	tests := [...]struct {
+		name string
+		fn   func(b *testing.B)
+	}{
+		{"not struct", func(b *testing.B) { optbench.Run(b, 1) }},
+		{"nil", func(b *testing.B) { optbench.Run(b, nil) }},
+		{"unsupported", func(b *testing.B) { optbench.Run(b, unsupported{Ch: optional.Some(make(chan int))}) }},
+		{"codec", func(b *testing.B) {
+			optbench.RunCodec(b, optional.Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}, []int{1})
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Zero(t, testing.Benchmark(tt.fn).N)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Package optconformance provides the conformance tests of the codecs used with optional values.
+//
+// Alternative JSON libraries plugged with [optional.ChangeMarshal], [optional.ChangeUnmarshal] or
+// [optional.RegisterContentType] must call the methods of the values, such as UnmarshalJSON, for the presence
+// of the fields to be preserved. The tests check it for the fields of structs, the elements of slices and
+// maps, and the values of pointers.
+package optconformance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/micronull/optional"
+)
+
+type nested struct {
+	Name optional.Type[string] `json:"name"`
+}
+
+// document is the type decoded and encoded by the tests.
+type document struct {
+	String  optional.Type[string]            `json:"string"`
+	Int     optional.Type[int]               `json:"int"`
+	Float   optional.Type[float64]           `json:"float"`
+	Bool    optional.Type[bool]              `json:"bool"`
+	Slice   optional.Type[[]int]             `json:"slice"`
+	Nested  optional.Type[nested]            `json:"nested"`
+	Pointer *optional.Type[string]           `json:"pointer"`
+	List    []optional.Type[int]             `json:"list"`
+	Map     map[string]optional.Type[string] `json:"map"`
+}
+
+// Run runs the conformance tests of the codec as subtests of t.
+func Run(t *testing.T, c optional.Codec) {
+	t.Helper()
+
+	t.Run("unmarshal", func(t *testing.T) {
+		runUnmarshal(t, c)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		runRoundTrip(t, c)
+	})
+}
+
+func runUnmarshal(t *testing.T, c optional.Codec) {
+	tests := []struct {
+		name string
+		data string
+		want func(d document) bool
+	}{
+		{
+			name: "absent fields are unset",
+			data: `{}`,
+			want: func(d document) bool {
+				return !d.String.IsSet() && !d.Int.IsSet() && !d.Nested.IsSet() && d.Pointer == nil
+			},
+		},
+		{
+			name: "null fields are null",
+			data: `{"string":null,"int":null,"slice":null,"nested":null}`,
+			want: func(d document) bool {
+				return d.String.State() == optional.StateNull && d.Int.State() == optional.StateNull &&
+					d.Slice.State() == optional.StateNull && d.Nested.State() == optional.StateNull
+			},
+		},
+		{
+			name: "values are set",
+			data: `{"string":"a","int":1,"float":1.5,"bool":true,"slice":[1,2],"nested":{"name":"b"}}`,
+			want: func(d document) bool {
+				return d.String.Equal(optional.Some("a")) && d.Int.Equal(optional.Some(1)) &&
+					d.Float.Equal(optional.Some(1.5)) && d.Bool.Equal(optional.Some(true)) &&
+					d.Slice.Equal(optional.Some([]int{1, 2})) && d.Nested.Equal(optional.Some(nested{Name: optional.Some("b")}))
+			},
+		},
+		{
+			name: "zero values are set",
+			data: `{"string":"","int":0,"bool":false,"slice":[]}`,
+			want: func(d document) bool {
+				return d.String.Equal(optional.Some("")) && d.Int.Equal(optional.Some(0)) &&
+					d.Bool.Equal(optional.Some(false)) && d.Slice.Equal(optional.Some([]int{}))
+			},
+		},
+		{
+			name: "nested fields keep presence",
+			data: `{"nested":{}}`,
+			want: func(d document) bool {
+				return d.Nested.State() == optional.StateValue && !d.Nested.V.Name.IsSet()
+			},
+		},
+		{
+			name: "pointers keep presence",
+			data: `{"pointer":"a"}`,
+			want: func(d document) bool {
+				return d.Pointer != nil && d.Pointer.Equal(optional.Some("a"))
+			},
+		},
+		{
+			name: "elements keep presence",
+			data: `{"list":[1,null],"map":{"a":"b","c":null}}`,
+			want: func(d document) bool {
+				return len(d.List) == 2 && d.List[0].Equal(optional.Some(1)) && d.List[1].Equal(optional.Null[int]()) &&
+					d.Map["a"].Equal(optional.Some("b")) && d.Map["c"].Equal(optional.Null[string]())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var d document
+
+			if err := c.Unmarshal([]byte(tt.data), &d); err != nil {
+				t.Fatalf("unmarshal %s: %v", tt.data, err)
+			}
+
+			if !tt.want(d) {
+				t.Errorf("unmarshal %s: unexpected presence of the fields: %+v", tt.data, d)
+			}
+		})
+	}
+}
+
+func runRoundTrip(t *testing.T, c optional.Codec) {
+	s := optional.Some("a")
+
+	tests := []struct {
+		name string
+		doc  document
+	}{
+		{
+			name: "values",
+			doc: document{
+				String: optional.Some("a"),
+				Int:    optional.Some(1),
+				Bool:   optional.Some(false),
+				Slice:  optional.Some([]int{1}),
+				Nested: optional.Some(nested{Name: optional.Some("b")}),
+			},
+		},
+		{
+			name: "nulls",
+			doc: document{
+				String: optional.Null[string](),
+				Int:    optional.Null[int](),
+				Bool:   optional.Null[bool](),
+				Slice:  optional.Null[[]int](),
+				Nested: optional.Null[nested](),
+			},
+		},
+		{
+			name: "elements",
+			doc: document{
+				Pointer: &s,
+				List:    []optional.Type[int]{optional.Some(1), optional.Null[int]()},
+				Map:     map[string]optional.Type[string]{"a": optional.Null[string]()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := c.Marshal(tt.doc)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			var got document
+
+			if err := c.Unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+
+			if !setEqual(tt.doc, got) {
+				t.Errorf("round trip through %s changed the fields: want %+v, got %+v", data, tt.doc, got)
+			}
+		})
+	}
+}
+
+// setEqual reports whether the fields set in want are set equally in got. The unset fields and other zero fields
+// are not compared, as encoding/json does not omit them.
+func setEqual(want, got document) bool {
+	wv, gv := reflect.ValueOf(want), reflect.ValueOf(got)
+
+	for i := 0; i < wv.NumField(); i++ {
+		w := wv.Field(i)
+
+		if w.IsZero() {
+			continue
+		}
+
+		if !reflect.DeepEqual(w.Interface(), gv.Field(i).Interface()) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,24 @@
+package optconformance_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optconformance"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encoding/json", func(t *testing.T) {
+		optconformance.Run(t, optional.Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal})
+	})
+
+	t.Run("optional.Marshal", func(t *testing.T) {
+		optconformance.Run(t, optional.Codec{
+			Marshal:   func(v any) ([]byte, error) { return optional.Marshal(v) },
+			Unmarshal: json.Unmarshal,
+		})
+	})
+}
@@ -3,11 +3,18 @@
 // or explicitly set to null in JSON.
 package optional
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"reflect"
+)
 
 var (
 	marshaller   = json.Marshal
 	unmarshaller = json.Unmarshal
+
+	// customMarshal and customUnmarshal report whether the marshaller and the unmarshaller are changed from
+	// the ones of encoding/json, the primitive values are then encoded by them even without reflection.
+	customMarshal, customUnmarshal bool
 )
 
 // ChangeMarshal allows you to change the function used for marshalling.
@@ -15,6 +22,7 @@ var (
 // such as from a library like https://pkg.go.dev/github.com/json-iterator/go.
 func ChangeMarshal(m func(v any) ([]byte, error)) {
 	marshaller = m
+	customMarshal = !sameFunc(m, json.Marshal)
 }
 
 // ChangeUnmarshal allows you to change the function used for unmarshalling.
@@ -22,31 +30,91 @@ func ChangeMarshal(m func(v any) ([]byte, error)) {
 // such as from a library like https://pkg.go.dev/github.com/json-iterator/go.
 func ChangeUnmarshal(u func(data []byte, v any) error) {
 	unmarshaller = u
+	customUnmarshal = !sameFunc(u, json.Unmarshal)
+}
+
+// sameFunc reports whether the functions a and b are the same function.
+func sameFunc(a, b any) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
 }
 
 // Type represents a generic value that may or may not be set and could also be null.
 type Type[T any] struct {
-	V T    // V holds the actual value of type T.
-	n bool // n indicates if the value is explicitly null.
-	s bool // s indicates if the value has been set (either to a non-null value or explicitly to null).
+	V T     // V holds the actual value of type T.
+	f flags // f indicates if the value has been set and if it is explicitly null.
 }
 
+// flags packs the presence of a value into a single byte. It does not shrink the values of the types aligned
+// to more than a byte, such as int64 and string, because of the padding, [Slice] holds them compactly.
+type flags uint8
+
+const (
+	flagSet  flags = 1 << iota // flagSet indicates if the value has been set (either to a non-null value or explicitly to null).
+	flagNull                   // flagNull indicates if the value is explicitly null.
+)
+
 // New creates a new instance of [Type] with the specified value and null status.
 func New[T any](value T, null bool) Type[T] {
+	t := Type[T]{V: value}
+
+	if null {
+		t.f = flagNull
+	}
+
+	return t
+}
+
+// Some creates a new instance of [Type] set to the specified value.
+func Some[T any](value T) Type[T] {
 	return Type[T]{
 		V: value,
-		n: null,
+		f: flagSet,
+	}
+}
+
+// Null creates a new instance of [Type] explicitly set to null.
+func Null[T any]() Type[T] {
+	return Type[T]{
+		f: flagSet | flagNull,
 	}
 }
 
 // IsSetNull checks if the value is explicitly set to null.
 func (t Type[T]) IsSetNull() bool {
-	return t.n
+	return t.f&flagNull != 0
 }
 
 // IsSet checks if the value has been set, either to a non-null value or explicitly to null.
 func (t Type[T]) IsSet() bool {
-	return t.s
+	return t.f&flagSet != 0
+}
+
+// SetValue sets the value, marking it as set and not null.
+func (t *Type[T]) SetValue(value T) {
+	t.V = value
+	t.f = flagSet
+}
+
+// SetNull marks the value as explicitly set to null, resetting it to the zero value.
+func (t *Type[T]) SetNull() {
+	t.mark(true, true)
+}
+
+// Unset marks the value as not set, resetting it to the zero value.
+func (t *Type[T]) Unset() {
+	t.mark(false, false)
+}
+
+// Ptr returns a pointer to the copy of the value, or nil if the value is unset or null.
+// It is useful for converting into pointer based APIs.
+func (t Type[T]) Ptr() *T {
+	if t.f != flagSet {
+		return nil
+	}
+
+	v := t.V
+
+	return &v
 }
 
 var (
@@ -57,35 +125,115 @@ var (
 // UnmarshalJSON implements the [json.Unmarshaler] interface for [Type].
 // It handles unmarshalling JSON data into a [Type] instance, distinguishing between unset values,
 // null values, and actual non-null values.
+//
+// The value is reset to zero before decoding, so T may implement [json.Unmarshaler] on *T only.
+// When T is an interface type, the value is decoded into the variant registered with [RegisterOneOf] or,
+// like encoding/json does, into the value pointed to by the non-nil pointer V holds, which is kept
+// by the reset. Otherwise, only the empty interface can be decoded into.
 func (t *Type[T]) UnmarshalJSON(bytes []byte) error {
-	if len(bytes) == 0 {
+	if isBlank(bytes) {
 		return nil // Treat empty input as not setting the value
 	}
 
 	var zero T
 
-	t.V = zero  // Reset value
-	t.s = true  // Mark as set since we're processing data
-	t.n = false // Reset null flag
+	null := isNull(bytes)
+
+	// Only the zero values of interfaces are nil, the pointers held by interfaces are kept to decode into.
+	if null || any(zero) != nil || !holdsPointer(t.V) {
+		t.V = zero // Reset value
+	}
+
+	t.f = flagSet // Mark as set since we're processing data and reset null flag
 
-	if string(bytes) == "null" {
-		t.n = true // Explicitly null case
+	if null {
+		t.f |= flagNull // Explicitly null case
 
 		return nil
 	}
 
+	if p, ok := any(&t.V).(*json.RawMessage); ok {
+		return unmarshalRaw(bytes, p)
+	}
+
+	// The changed unmarshaller and the interner see the primitive values too.
+	if noReflect && !customUnmarshal && interner == nil {
+		if ok, err := parsePrimitive(bytes, &t.V); ok {
+			return err
+		}
+	}
+
+	if in := interner; in != nil {
+		if p, ok := any(&t.V).(*string); ok {
+			return unmarshalInterned(in, bytes, p)
+		}
+	}
+
+	// Only the zero values of interfaces are nil, which may have registered variants.
+	if any(t.V) == nil {
+		if ok, err := unmarshalOneOf(bytes, &t.V); ok {
+			return err
+		}
+	}
+
 	// Otherwise, unmarshal into the actual value
 	return unmarshaller(bytes, &t.V)
 }
 
+// isNull reports whether data is the null token, tolerating the surrounding whitespace and the letters
+// in any case, which some third-party encoders plugged with [ChangeUnmarshal] produce.
+func isNull(data []byte) bool {
+	data = trimJSONSpace(data)
+
+	return len(data) == 4 &&
+		data[0]|0x20 == 'n' && data[1]|0x20 == 'u' && data[2]|0x20 == 'l' && data[3]|0x20 == 'l'
+}
+
+// isBlank reports whether data is empty or consists of whitespace only.
+func isBlank(data []byte) bool {
+	return len(trimJSONSpace(data)) == 0
+}
+
+// trimJSONSpace returns data without the leading and trailing JSON whitespace.
+func trimJSONSpace(data []byte) []byte {
+	for len(data) > 0 && isJSONSpace(data[0]) {
+		data = data[1:]
+	}
+
+	for len(data) > 0 && isJSONSpace(data[len(data)-1]) {
+		data = data[:len(data)-1]
+	}
+
+	return data
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
 // MarshalJSON implements the [json.Marshaler] interface for [Type].
 // It handles marshalling a [Type] instance to JSON, correctly representing unset values as empty,
 // null values as `null`, and non-null values using the specified marshaller.
 func (t Type[T]) MarshalJSON() ([]byte, error) {
-	if t.n {
+	if t.f&flagNull != 0 {
 		return []byte(`null`), nil // Explicitly return 'null' if set to null
 	}
 
+	if raw, ok := any(t.V).(json.RawMessage); ok {
+		return marshalRaw(raw)
+	}
+
+	if noReflect && !customMarshal {
+		if b, ok, err := appendPrimitive(nil, t.V); ok {
+			return b, err
+		}
+	}
+
+	// Values holding optional values inside, such as structs of optionals, are encoded respecting their presence.
+	if hasNestedPresence[T]() {
+		return Marshal(t.V)
+	}
+
 	// Use the current marshaller for non-null values
 	return marshaller(t.V)
 }
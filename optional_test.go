@@ -3,7 +3,10 @@ package optional_test
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -257,3 +260,227 @@ func TestType_UnmarshalJSON_ChangeNull(t *testing.T) {
 	assert.True(t, got.Field.IsSet())
 	assert.False(t, got.Field.IsSetNull())
 }
+
+func TestSome(t *testing.T) {
+	t.Parallel()
+
+	got := optional.Some("some")
+
+	assert.Equal(t, "some", got.V)
+	assert.True(t, got.IsSet())
+	assert.False(t, got.IsSetNull())
+}
+
+func TestNull(t *testing.T) {
+	t.Parallel()
+
+	got := optional.Null[string]()
+
+	assert.Equal(t, "", got.V)
+	assert.True(t, got.IsSet())
+	assert.True(t, got.IsSetNull())
+}
+
+func TestType_Ptr(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, optional.Type[string]{}.Ptr())
+	assert.Nil(t, optional.Null[string]().Ptr())
+
+	got := optional.Some("some").Ptr()
+	require.NotNil(t, got)
+	assert.Equal(t, "some", *got)
+}
+
+func TestIsType(t *testing.T) {
+	t.Parallel()
+
+	type embedded struct {
+		optional.Type[string]
+	}
+
+	assert.True(t, optional.IsType(reflect.TypeOf(optional.Type[string]{})))
+	assert.True(t, optional.IsType(reflect.TypeOf(optional.Type[struct{}]{})))
+	assert.False(t, optional.IsType(reflect.TypeOf(&optional.Type[string]{})))
+	assert.False(t, optional.IsType(reflect.TypeOf(embedded{})))
+	assert.False(t, optional.IsType(reflect.TypeOf("")))
+}
+
+func TestType_SetValue(t *testing.T) {
+	t.Parallel()
+
+	got := optional.Null[string]()
+	got.SetValue("some")
+
+	assert.Equal(t, optional.Some("some"), got)
+
+	got.SetNull()
+
+	assert.Equal(t, optional.Null[string](), got)
+
+	got.SetValue("some")
+	got.Unset()
+
+	assert.Equal(t, optional.Type[string]{}, got)
+}
+
+func TestType_UnmarshalJSON_NullToken(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input string
+		want  optional.Type[string]
+	}{
+		{"null", `null`, optional.Null[string]()},
+		{"whitespace", " \n\tnull\r\n ", optional.Null[string]()},
+		{"upper case", `NULL`, optional.Null[string]()},
+		{"mixed case", ` Null `, optional.Null[string]()},
+		{"blank", " \n ", optional.Type[string]{}},
+		{"string", `"null"`, optional.Some("null")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got optional.Type[string]
+
+			require.NoError(t, got.UnmarshalJSON([]byte(tt.input)))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{`nul`, `nulls`, `n ull`} {
+		var got optional.Type[string]
+
+		require.Error(t, got.UnmarshalJSON([]byte(input)), input)
+	}
+}
+
+func TestType_UnmarshalJSON_NullToken_Allocs(t *testing.T) {
+	var got optional.Type[string]
+
+	null := []byte(" null ")
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = got.UnmarshalJSON(null)
+	}))
+	assert.Equal(t, optional.Null[string](), got)
+}
+
+// upperString implements json.Unmarshaler on the pointer receiver only.
+type upperString string
+
+func (u *upperString) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*u = upperString(strings.ToUpper(s))
+
+	return nil
+}
+
+// upperName implements json.Unmarshaler on the pointer receiver only.
+type upperName struct {
+	Name string
+}
+
+func (u *upperName) UnmarshalJSON(data []byte) error {
+	var v struct{ Name upperString }
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	u.Name = string(v.Name)
+
+	return nil
+}
+
+func TestType_UnmarshalJSON_PointerReceiver(t *testing.T) {
+	t.Parallel()
+
+	type some struct {
+		Code  optional.Type[upperString]   `json:"code"`
+		Name  optional.Type[upperName]     `json:"name"`
+		Codes []optional.Type[upperString] `json:"codes"`
+	}
+
+	input := `{"code":"abc","name":{"Name":"john"},"codes":["x",null]}`
+	want := some{
+		Code:  optional.Some(upperString("ABC")),
+		Name:  optional.Some(upperName{Name: "JOHN"}),
+		Codes: []optional.Type[upperString]{optional.Some(upperString("X")), optional.Null[upperString]()},
+	}
+
+	var got some
+
+	require.NoError(t, json.Unmarshal([]byte(input), &got))
+	assert.Equal(t, want, got)
+
+	got = some{}
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(input)).Decode(&got))
+	assert.Equal(t, want, got)
+}
+
+type shape interface {
+	Area() float64
+}
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+func (s *square) Area() float64 { return s.Side * s.Side }
+
+func TestType_UnmarshalJSON_Interface(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty interface", func(t *testing.T) {
+		t.Parallel()
+
+		var got optional.Type[any]
+
+		require.NoError(t, json.Unmarshal([]byte(`{"a":1}`), &got))
+		assert.Equal(t, optional.Some[any](map[string]any{"a": 1.0}), got)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		var got optional.Type[shape]
+
+		require.Error(t, json.Unmarshal([]byte(`{"side":2}`), &got))
+		assert.Nil(t, got.V)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		t.Parallel()
+
+		sq := &square{Side: 1}
+		got := optional.Some[shape](sq)
+
+		require.NoError(t, json.Unmarshal([]byte(`{"side":2}`), &got))
+		assert.Same(t, sq, got.V)
+		assert.Equal(t, 4.0, got.V.Area())
+
+		require.NoError(t, json.Unmarshal([]byte(`null`), &got))
+		assert.Equal(t, optional.Null[shape](), got)
+	})
+}
+
+func TestType_Size(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, unsafe.Sizeof(int64(0))*2, unsafe.Sizeof(optional.Type[int64]{}))
+	assert.Equal(t, unsafe.Sizeof(int32(0))*2, unsafe.Sizeof(optional.Type[int32]{}))
+	assert.Equal(t, uintptr(2), unsafe.Sizeof(optional.Type[int8]{}))
+	assert.Equal(t, uintptr(1), unsafe.Sizeof(optional.Type[struct{}]{}))
+}
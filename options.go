@@ -0,0 +1,207 @@
+package optional
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Option configures the behaviour of the decoding and encoding helpers.
+type Option func(*options)
+
+// EmptyMode defines how an explicitly empty textual value (an empty query parameter,
+// form field or multipart part) is stored into an optional field.
+type EmptyMode uint8
+
+const (
+	// EmptyValue parses the empty string as a regular value of the field type.
+	EmptyValue EmptyMode = iota
+	// EmptyNull marks the field as explicitly set to null.
+	EmptyNull
+	// EmptyUnset leaves the field unset, as if the value was not sent at all.
+	EmptyUnset
+)
+
+const defaultMaxMemory = 32 << 20 // 32 MB, the same as net/http uses.
+
+type options struct {
+	empty     EmptyMode
+	maxMemory int64
+	status    int
+	null      string
+	separator string
+	caseFold  bool
+
+	requireBody bool
+	normalize   bool
+
+	deprecated func(field string)
+	duplicates DuplicateMode
+	duplicate  func(field string)
+	merge      bool
+	keepNull   bool
+
+	maxDepth  int
+	maxString int
+	maxArray  int
+	schema    SchemaValidator
+
+	canonical  bool
+	escapeHTML bool
+	unset      []byte
+	fields     *fieldset
+	omitCompat bool
+
+	floatFormat byte
+	floatPrec   int
+	int64String bool
+
+	converters map[converterKey]reflect.Value
+	codec      *Codec
+
+	hook   Hook
+	redact func(path string) bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		empty:     EmptyValue,
+		maxMemory: defaultMaxMemory,
+		status:    http.StatusOK,
+		caseFold:  true,
+
+		escapeHTML: true,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithEmpty sets how explicitly empty textual values are handled. By default [EmptyValue] is used.
+func WithEmpty(m EmptyMode) Option {
+	return func(o *options) {
+		o.empty = m
+	}
+}
+
+// WithMaxMemory sets the maximum number of bytes of a multipart body stored in memory,
+// the rest is stored on disk in temporary files. By default, 32 MB is used.
+func WithMaxMemory(n int64) Option {
+	return func(o *options) {
+		o.maxMemory = n
+	}
+}
+
+// WithStatus sets the HTTP status code of the response written by [WriteJSON]. By default, 200 is used.
+func WithStatus(code int) Option {
+	return func(o *options) {
+		o.status = code
+	}
+}
+
+// WithNullValue sets the sentinel textual value, such as "null", which sets the field to null.
+// By default, there is no sentinel.
+func WithNullValue(s string) Option {
+	return func(o *options) {
+		o.null = s
+	}
+}
+
+// WithSeparator sets the separator splitting a single textual value into the elements of a slice field.
+// By default, query parameters, form fields and headers are not split, while environment variables are split by comma.
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithCaseInsensitive sets whether [Decoder] matches the keys to the names of the fields case-insensitively
+// when there is no exact match, like encoding/json does. By default, the keys are matched case-insensitively.
+func WithCaseInsensitive(on bool) Option {
+	return func(o *options) {
+		o.caseFold = on
+	}
+}
+
+// WithDeprecatedHook sets the function called by [Decoder] with the dotted path of each field marked
+// `optional:"deprecated"` present in the payload, so the clients still sending removed fields can be reported
+// with Warning headers or metrics.
+func WithDeprecatedHook(fn func(field string)) Option {
+	return func(o *options) {
+		o.deprecated = fn
+	}
+}
+
+// DuplicateMode defines how [Decoder] handles the same key appearing twice in the object for an optional field.
+type DuplicateMode uint8
+
+const (
+	// DuplicateLastWins keeps the last value, like encoding/json does.
+	DuplicateLastWins DuplicateMode = iota
+	// DuplicateError fails decoding with [ErrDuplicateKey].
+	DuplicateError
+)
+
+// WithDuplicateKeys sets how the duplicate keys of optional fields are handled. By default, [DuplicateLastWins] is used.
+// The keys matching the same field by its alias or case-insensitively are duplicates as well.
+func WithDuplicateKeys(m DuplicateMode) Option {
+	return func(o *options) {
+		o.duplicates = m
+	}
+}
+
+// WithDuplicateKeyHook sets the function called by [Decoder] with the dotted path of each optional field
+// whose key appears more than once in the object, so the duplicates can be recorded.
+func WithDuplicateKeyHook(fn func(field string)) Option {
+	return func(o *options) {
+		o.duplicate = fn
+	}
+}
+
+// WithEscapeHTML sets whether [Marshal] and [WriteJSON] escape the <, > and & characters in strings
+// like [json.Encoder.SetEscapeHTML] does. By default, the characters are escaped.
+func WithEscapeHTML(on bool) Option {
+	return func(o *options) {
+		o.escapeHTML = on
+	}
+}
+
+// WithRequiredBody makes [DecodeRequest] and [DecodePatch] return [ErrEmptyBody] for the missing, empty
+// or whitespace-only body instead of decoding it as an empty patch leaving all the fields unset.
+func WithRequiredBody() Option {
+	return func(o *options) {
+		o.requireBody = true
+	}
+}
+
+// WithMerge makes [Decoder] merge the objects into the values of the set [Type] fields, such as of Type[Address],
+// instead of resetting them to zero first, so the nested keys missing in the payload keep their current values.
+// It allows applying nested partial updates onto the loaded values. The null and unset values are reset as usual.
+func WithMerge() Option {
+	return func(o *options) {
+		o.merge = true
+	}
+}
+
+// WithOmitEmptyCompat makes [Marshal] and [WriteJSON] produce exactly what encoding/json produces for the legacy
+// struct with the pointers in place of the [Type] fields and the same tags, such as *string for Type[string],
+// for the dark-launch comparisons while migrating services field by field: the unset and the null fields are
+// both nil pointers, omitted with omitempty and encoded as null without it, also as the values of maps.
+// The `optional:"nullas=..."` tags and [WithUnsetSentinel] are ignored, other formatting options, such as
+// [WithCanonical] or [WithEscapeHTML], must not be given for the output to match.
+func WithOmitEmptyCompat() Option {
+	return func(o *options) {
+		o.omitCompat = true
+	}
+}
+
+// WithUnsetSentinel makes [Marshal] and [WriteJSON] emit the unset fields with the JSON literal, such as
+// `"__UNSET__"`, instead of omitting them, for the legacy consumers which cannot handle the omission of keys.
+// An error is returned if the literal is not a valid JSON value. Unset map values are omitted regardless.
+func WithUnsetSentinel(literal string) Option {
+	return func(o *options) {
+		o.unset = []byte(literal)
+	}
+}
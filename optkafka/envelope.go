@@ -0,0 +1,186 @@
+// Package optkafka encodes structs of optional values into versioned JSON envelopes for event streams, such as
+// Kafka change topics, recording the paths of the set fields next to the data:
+//
+//	{"version":1,"fields":["email","name"],"data":{"email":null,"name":"John"}}
+//
+// The fields list keeps the presence of the fields explicit for the consumers that do not track it themselves,
+// so a field omitted from an event is told apart from a field set to its zero value.
+package optkafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// Version is the version of the envelope written by [Marshal].
+const Version = 1
+
+// ErrVersion is returned when the envelope is written in an unsupported version.
+var ErrVersion = errors.New("optkafka: unsupported version")
+
+// Codec is the codec of the envelopes, for the APIs accepting an [optional.Codec].
+var Codec = optional.Codec{
+	Marshal:   func(v any) ([]byte, error) { return Marshal(v) },
+	Unmarshal: func(data []byte, v any) error { return Unmarshal(data, v) },
+}
+
+// Envelope is the envelope of an event.
+type Envelope struct {
+	Version int `json:"version"`
+	// Fields holds the dotted paths of the JSON names of the set [optional.Type] fields, including the null ones,
+	// sorted.
+	Fields []string        `json:"fields"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// IsSet reports whether the field of the dotted path is listed as set.
+func (e Envelope) IsSet(path string) bool {
+	i := sort.SearchStrings(e.Fields, path)
+
+	return i < len(e.Fields) && e.Fields[i] == path
+}
+
+// Marshal returns the envelope of the struct v, encoding the data with [optional.Marshal] and the options.
+func Marshal(v any, opts ...optional.Option) ([]byte, error) {
+	if rv := reflect.Indirect(reflect.ValueOf(v)); rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optkafka: Marshal expects a struct, got %T", v)
+	}
+
+	data, err := optional.Marshal(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+
+	for path, state := range optional.FieldStates(v) {
+		if state != optional.StateUnset {
+			fields = append(fields, path)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return json.Marshal(Envelope{Version: Version, Fields: fields, Data: data})
+}
+
+// Open parses the envelope without decoding its data, such as for routing the events by their fields.
+func Open(data []byte) (Envelope, error) {
+	var e Envelope
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Envelope{}, fmt.Errorf("optkafka: envelope: %w", err)
+	}
+
+	if e.Version < 1 || e.Version > Version {
+		return Envelope{}, fmt.Errorf("%w %d", ErrVersion, e.Version)
+	}
+
+	if len(bytes.TrimSpace(e.Data)) == 0 {
+		return Envelope{}, errors.New("optkafka: envelope has no data")
+	}
+
+	if !sort.StringsAreSorted(e.Fields) {
+		sort.Strings(e.Fields)
+	}
+
+	return e, nil
+}
+
+// Unmarshal decodes the data of the envelope into the struct pointed to by v with [optional.Decoder]
+// and the options, restoring the presence of the fields from the fields list: the [optional.Type]
+// fields not listed are left unset, even when the data of the producer holds their zero values.
+func Unmarshal(data []byte, v any, opts ...optional.Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optkafka: Unmarshal expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	e, err := Open(data)
+	if err != nil {
+		return err
+	}
+
+	if err := optional.NewDecoder(bytes.NewReader(e.Data), opts...).Decode(v); err != nil {
+		return err
+	}
+
+	unsetUnlisted(rv.Elem(), "", e)
+
+	return nil
+}
+
+// unsetUnlisted unsets the set [optional.Type] fields of the struct v whose paths are not listed in the envelope.
+func unsetUnlisted(v reflect.Value, prefix string, e Envelope) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			unsetUnlisted(fv, prefix, e)
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		path := prefix + name
+		inner := fv
+
+		if isTracked(fv.Type()) {
+			inner = fv.Field(0)
+		}
+
+		if !optional.IsType(inner.Type()) {
+			if sv := reflect.Indirect(fv); sv.Kind() == reflect.Struct && sv.CanSet() {
+				unsetUnlisted(sv, path+".", e)
+			}
+
+			continue
+		}
+
+		p := inner.Interface().(interface {
+			IsSet() bool
+			IsSetNull() bool
+		})
+
+		switch {
+		case !p.IsSet():
+		case !e.IsSet(path):
+			fv.Addr().MethodByName("Unset").Call(nil)
+		case !p.IsSetNull():
+			if sv := reflect.Indirect(inner.Field(0)); sv.Kind() == reflect.Struct && sv.CanSet() {
+				unsetUnlisted(sv, path+".", e)
+			}
+		}
+	}
+}
+
+var pkgPath = reflect.TypeOf(optional.Type[int]{}).PkgPath()
+
+// isTracked reports whether t is an instantiation of [optional.Tracked].
+func isTracked(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Tracked[")
+}
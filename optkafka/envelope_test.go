@@ -0,0 +1,121 @@
+package optkafka_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optkafka"
+)
+
+type address struct {
+	City optional.Type[string] `json:"city"`
+	Zip  optional.Type[string] `json:"zip"`
+}
+
+type userChanged struct {
+	ID      int64                    `json:"id"`
+	Name    optional.Type[string]    `json:"name"`
+	Email   optional.Tracked[string] `json:"email"`
+	Age     optional.Type[int]       `json:"age"`
+	Address optional.Type[address]   `json:"address"`
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	event := userChanged{
+		ID:      1,
+		Name:    optional.Some("John"),
+		Age:     optional.Null[int](),
+		Address: optional.Some(address{City: optional.Some("Berlin")}),
+	}
+
+	data, err := optkafka.Marshal(event)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"version": 1,
+		"fields": ["address", "address.city", "age", "name"],
+		"data": {"id": 1, "name": "John", "age": null, "address": {"city": "Berlin"}}
+	}`, string(data))
+
+	var got userChanged
+
+	require.NoError(t, optkafka.Unmarshal(data, &got))
+	assert.Equal(t, event, got)
+
+	e, err := optkafka.Open(data)
+	require.NoError(t, err)
+	assert.True(t, e.IsSet("age"))
+	assert.False(t, e.IsSet("email"))
+	assert.False(t, e.IsSet("address.zip"))
+}
+
+func TestUnmarshal_Fields(t *testing.T) {
+	t.Parallel()
+
+	// The producer writes all the keys, the fields list tells the set ones.
+	const data = `{
+		"version": 1,
+		"fields": ["name", "address", "address.zip"],
+		"data": {"id": 1, "name": "", "email": "", "age": 0, "address": {"city": "", "zip": "10115"}}
+	}`
+
+	var got userChanged
+
+	require.NoError(t, optkafka.Unmarshal([]byte(data), &got))
+
+	assert.Equal(t, userChanged{
+		ID:      1,
+		Name:    optional.Some(""),
+		Address: optional.Some(address{Zip: optional.Some("10115")}),
+	}, got)
+}
+
+func TestUnmarshal_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		data string
+		err  string
+	}{
+		{"not envelope", `[]`, "optkafka: envelope: json: cannot unmarshal array into Go value of type optkafka.Envelope"},
+		{"version", `{"version":2,"data":{}}`, "optkafka: unsupported version 2"},
+		{"no version", `{"data":{}}`, "optkafka: unsupported version 0"},
+		{"no data", `{"version":1}`, "optkafka: envelope has no data"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := optkafka.Unmarshal([]byte(tt.data), &userChanged{})
+			require.EqualError(t, err, tt.err)
+		})
+	}
+
+	require.ErrorIs(t, optkafka.Unmarshal([]byte(`{"version":3,"data":{}}`), &userChanged{}), optkafka.ErrVersion)
+	require.EqualError(t, optkafka.Unmarshal([]byte(`{}`), userChanged{}),
+		"optkafka: Unmarshal expects a non-nil pointer to a struct, got optkafka_test.userChanged")
+
+	_, err := optkafka.Marshal(1)
+	require.EqualError(t, err, "optkafka: Marshal expects a struct, got int")
+}
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	data, err := optkafka.Codec.Marshal(userChanged{ID: 2, Email: optional.Tracked[string]{}})
+	require.NoError(t, err)
+
+	var got userChanged
+
+	require.NoError(t, optkafka.Codec.Unmarshal(data, &got))
+	assert.Equal(t, userChanged{ID: 2}, got)
+}
@@ -0,0 +1,103 @@
+// Package optrules provides the rules of https://github.com/go-ozzo/ozzo-validation for [optional.Type] fields,
+// expressing presence-aware validation without custom rules:
+//
+//	return validation.ValidateStruct(&p,
+//		validation.Field(&p.Name, optrules.WhenSet(validation.Required, validation.Length(2, 64))),
+//		validation.Field(&p.Email, optrules.Present, optrules.NotNull),
+//	)
+package optrules
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// Rule is the rule of ozzo-validation, satisfied by the rules of the validation package.
+type Rule interface {
+	Validate(value any) error
+}
+
+var (
+	// ErrNotPresent is returned by [Present] for the unset field.
+	ErrNotPresent = errors.New("must be present")
+	// ErrNull is returned by [NotNull] for the field set to null.
+	ErrNull = errors.New("must not be null")
+)
+
+// Present requires the [optional.Type] field to be set, to a value or to null.
+var Present Rule = ruleFunc(func(value any) error {
+	if state, ok := stateOf(value); ok && state == optional.StateUnset {
+		return ErrNotPresent
+	}
+
+	return nil
+})
+
+// NotNull requires the [optional.Type] field not to be set to null, the unset field is valid.
+var NotNull Rule = ruleFunc(func(value any) error {
+	if state, ok := stateOf(value); ok && state == optional.StateNull {
+		return ErrNull
+	}
+
+	return nil
+})
+
+// WhenSet returns the rule applying the rules to the value of the [optional.Type] field set to a value,
+// the unset and null fields are valid. The rules stop at the first error like the rules of a field do.
+// The values other than [optional.Type] are passed to the rules as they are.
+func WhenSet(rules ...Rule) Rule {
+	return ruleFunc(func(value any) error {
+		state, ok := stateOf(value)
+		if ok && state != optional.StateValue {
+			return nil
+		}
+
+		if ok {
+			value = innerValue(value)
+		}
+
+		for _, r := range rules {
+			if err := r.Validate(value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+type ruleFunc func(value any) error
+
+func (f ruleFunc) Validate(value any) error {
+	return f(value)
+}
+
+type stater interface {
+	State() optional.State
+}
+
+// stateOf returns the state of the [optional.Type] or [optional.Tracked] value or the pointer to it.
+func stateOf(value any) (optional.State, bool) {
+	if s, ok := value.(stater); ok {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return optional.StateUnset, true
+		}
+
+		return s.State(), true
+	}
+
+	return optional.StateUnset, false
+}
+
+// innerValue returns the value held by the [optional.Type] or [optional.Tracked] value or the pointer to it.
+func innerValue(value any) any {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	return v.FieldByName("V").Interface()
+}
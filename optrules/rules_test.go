@@ -0,0 +1,72 @@
+package optrules_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optrules"
+)
+
+var errTooShort = errors.New("too short")
+
+// minLength mimics validation.Length of ozzo-validation.
+type minLength int
+
+func (m minLength) Validate(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("unexpected %T", value)
+	}
+
+	if len(s) < int(m) {
+		return errTooShort
+	}
+
+	return nil
+}
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+
+	set := optional.Some("John")
+	short := optional.Some("J")
+	null := optional.Null[string]()
+	unset := optional.Type[string]{}
+	tracked := optional.Tracked[string]{Type: short}
+
+	tests := [...]struct {
+		name  string
+		rule  optrules.Rule
+		value any
+		want  error
+	}{
+		{"when set valid", optrules.WhenSet(minLength(2)), set, nil},
+		{"when set invalid", optrules.WhenSet(minLength(2)), short, errTooShort},
+		{"when set pointer", optrules.WhenSet(minLength(2)), &short, errTooShort},
+		{"when set tracked", optrules.WhenSet(minLength(2)), tracked, errTooShort},
+		{"when set null", optrules.WhenSet(minLength(2)), null, nil},
+		{"when set unset", optrules.WhenSet(minLength(2)), unset, nil},
+		{"when set plain", optrules.WhenSet(minLength(2)), "J", errTooShort},
+		{"present value", optrules.Present, set, nil},
+		{"present null", optrules.Present, null, nil},
+		{"present unset", optrules.Present, unset, optrules.ErrNotPresent},
+		{"present nil pointer", optrules.Present, (*optional.Type[string])(nil), optrules.ErrNotPresent},
+		{"not null value", optrules.NotNull, set, nil},
+		{"not null unset", optrules.NotNull, unset, nil},
+		{"not null null", optrules.NotNull, &null, optrules.ErrNull},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, tt.rule.Validate(tt.value), tt.want)
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package optsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxParams is the number of the query parameters supported by PostgreSQL and MySQL.
+const maxParams = 65535
+
+// ErrUnset is returned by [CopySource] and [BulkInsert] for the unset [optional.Type] fields
+// when no value is configured for them with [WithUnsetValue].
+var ErrUnset = errors.New("optsql: unset field")
+
+// Option configures [NewCopySource] and [BulkInsert].
+type Option func(*options)
+
+type options struct {
+	unset       any
+	hasUnset    bool
+	batchSize   int
+	placeholder func(n int) string
+}
+
+// WithUnsetValue makes the unset fields emit the value v instead of failing with [ErrUnset],
+// nil emits SQL NULL.
+func WithUnsetValue(v any) Option {
+	return func(o *options) {
+		o.unset = v
+		o.hasUnset = true
+	}
+}
+
+// WithBatchSize sets the number of the rows inserted by a single statement of [BulkInsert].
+// By default, it is the largest one keeping the statement within 65535 parameters.
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		o.batchSize = n
+	}
+}
+
+// WithDollarPlaceholders makes [BulkInsert] use the $1, $2, ... placeholders of PostgreSQL instead of ?.
+func WithDollarPlaceholders() Option {
+	return func(o *options) {
+		o.placeholder = func(n int) string { return "$" + strconv.Itoa(n) }
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{placeholder: func(int) string { return "?" }}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// column is a column written from a field.
+type column struct {
+	name  string
+	index []int
+	wrap  wrap
+}
+
+// CopySource reads the rows of a COPY from a slice of structs, it implements the pgx.CopyFromSource interface:
+//
+//	src, err := optsql.NewCopySource(users)
+//	if err != nil {
+//		return err
+//	}
+//
+//	_, err = conn.CopyFrom(ctx, pgx.Identifier{"users"}, src.Columns(), src)
+//
+// The set-null fields are emitted as SQL NULL, the unset ones fail the copy with [ErrUnset] unless a value
+// is configured for them with [WithUnsetValue].
+type CopySource[T any] struct {
+	rows   reflect.Value
+	cols   []column
+	o      options
+	pos    int
+	values []any
+	err    error
+}
+
+// NewCopySource returns the source of the COPY of the rows, structs or pointers to structs. The columns are
+// named by the `db` tags like in [ScanRow], falling back to the Go field names, the fields of embedded structs
+// are columns of their own and the other fields, including nested structs, are written as single values.
+func NewCopySource[T any](rows []T, opts ...Option) (*CopySource[T], error) {
+	cols, err := columnsOf(reflect.TypeOf(rows).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return &CopySource[T]{rows: reflect.ValueOf(rows), cols: cols, o: newOptions(opts), pos: -1}, nil
+}
+
+// Columns returns the names of the columns in the order of the values.
+func (s *CopySource[T]) Columns() []string {
+	names := make([]string, len(s.cols))
+	for i, c := range s.cols {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+// Next advances to the next row, it returns false after the last row or on an error.
+func (s *CopySource[T]) Next() bool {
+	if s.err != nil || s.pos+1 >= s.rows.Len() {
+		return false
+	}
+
+	s.pos++
+	s.values, s.err = rowValues(s.rows.Index(s.pos), s.pos, s.cols, s.o)
+
+	return s.err == nil
+}
+
+// Values returns the values of the current row.
+func (s *CopySource[T]) Values() ([]any, error) {
+	return s.values, s.err
+}
+
+// Err returns the error reading the rows.
+func (s *CopySource[T]) Err() error {
+	return s.err
+}
+
+// Execer executes queries, such as [sql.DB], [sql.Tx] and [sql.Conn].
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// BulkInsert inserts the rows into the table with multi-row INSERT statements and returns the number of
+// the inserted rows. The rows are written like by [NewCopySource]: the set-null fields are SQL NULL
+// and the unset ones fail with [ErrUnset] unless [WithUnsetValue] is given.
+//
+// The table and the column names are written as they are, without quoting. The statements use the ?
+// placeholders, [WithDollarPlaceholders] switches to the ones of PostgreSQL.
+func BulkInsert[T any](ctx context.Context, db Execer, table string, rows []T, opts ...Option) (int64, error) {
+	cols, err := columnsOf(reflect.TypeOf(rows).Elem())
+	if err != nil {
+		return 0, err
+	}
+
+	o := newOptions(opts)
+
+	size := o.batchSize
+	if size <= 0 {
+		size = maxParams / len(cols)
+	}
+
+	rv := reflect.ValueOf(rows)
+
+	var total int64
+
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		query, args, err := insertQuery(table, rv, start, end, cols, o)
+		if err != nil {
+			return total, err
+		}
+
+		res, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, fmt.Errorf("optsql: insert: %w", err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("optsql: rows affected: %w", err)
+		}
+
+		total += n
+	}
+
+	return total, nil
+}
+
+// insertQuery returns the INSERT statement of the rows from start to end and its arguments.
+func insertQuery(table string, rows reflect.Value, start, end int, cols []column, o options) (string, []any, error) {
+	var b strings.Builder
+
+	b.WriteString("INSERT INTO ")
+	b.WriteString(table)
+	b.WriteString(" (")
+
+	for i, c := range cols {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(c.name)
+	}
+
+	b.WriteString(") VALUES ")
+
+	args := make([]any, 0, (end-start)*len(cols))
+
+	for i := start; i < end; i++ {
+		values, err := rowValues(rows.Index(i), i, cols, o)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if i > start {
+			b.WriteString(", ")
+		}
+
+		b.WriteByte('(')
+
+		for j, v := range values {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+
+			args = append(args, v)
+			b.WriteString(o.placeholder(len(args)))
+		}
+
+		b.WriteByte(')')
+	}
+
+	return b.String(), args, nil
+}
+
+// columnsOf returns the columns of the rows of type t, a struct or a pointer to a struct.
+func columnsOf(t reflect.Type) ([]column, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optsql: rows must be structs, got %s", t)
+	}
+
+	cols := appendColumns(nil, t, nil)
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("optsql: %s has no columns", t)
+	}
+
+	return cols, nil
+}
+
+func appendColumns(cols []column, t reflect.Type, index []int) []column {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		idx := append(append([]int(nil), index...), i)
+		fw, inner := unwrap(f.Type)
+
+		if f.Anonymous && !hasTag && fw == wrapNone && isNestable(inner) {
+			cols = appendColumns(cols, inner, idx)
+
+			continue
+		}
+
+		if f.IsExported() {
+			cols = append(cols, column{name: name, index: idx, wrap: fw})
+		}
+	}
+
+	return cols
+}
+
+// rowValues returns the values of the columns of the i-th row.
+func rowValues(row reflect.Value, i int, cols []column, o options) ([]any, error) {
+	if row.Kind() == reflect.Ptr {
+		if row.IsNil() {
+			return nil, fmt.Errorf("optsql: row %d is nil", i)
+		}
+
+		row = row.Elem()
+	}
+
+	values := make([]any, len(cols))
+
+	for j, c := range cols {
+		fv := row.FieldByIndex(c.index)
+
+		switch c.wrap {
+		case wrapOptional:
+			p := fv.Interface().(interface {
+				IsSet() bool
+				IsSetNull() bool
+			})
+
+			// The null check comes first: optional.New(v, true) is null without being set.
+			switch {
+			case p.IsSetNull():
+				values[j] = nil
+			case !p.IsSet() && !o.hasUnset:
+				return nil, fmt.Errorf("optsql: row %d: column %q: %w", i, c.name, ErrUnset)
+			case !p.IsSet():
+				values[j] = o.unset
+			default:
+				if isTracked(fv.Type()) {
+					fv = fv.Field(0)
+				}
+
+				values[j] = fv.Field(0).Interface()
+			}
+		case wrapPointer:
+			if !fv.IsNil() {
+				values[j] = fv.Elem().Interface()
+			}
+		default:
+			values[j] = fv.Interface()
+		}
+	}
+
+	return values, nil
+}
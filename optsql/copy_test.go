@@ -0,0 +1,201 @@
+package optsql_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optsql"
+)
+
+type importAudit struct {
+	Source string `db:"source"`
+}
+
+type importUser struct {
+	importAudit
+	ID    int64                    `db:"id"`
+	Name  optional.Type[string]    `db:"name"`
+	Email optional.Tracked[string] `db:"email"`
+	Age   *int                     `db:"age"`
+	Note  string                   `db:"-"`
+	score int
+}
+
+func TestCopySource(t *testing.T) {
+	t.Parallel()
+
+	age := 30
+
+	var email optional.Tracked[string]
+	email.SetValue("john@example.com")
+
+	users := []importUser{
+		{importAudit: importAudit{"crm"}, ID: 1, Name: optional.Some("John"), Email: email, Age: &age},
+		{importAudit: importAudit{"crm"}, ID: 2, Name: optional.Null[string](), Email: email},
+	}
+
+	src, err := optsql.NewCopySource(users)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"source", "id", "name", "email", "age"}, src.Columns())
+
+	var got [][]any
+
+	for src.Next() {
+		values, err := src.Values()
+		require.NoError(t, err)
+
+		got = append(got, values)
+	}
+
+	require.NoError(t, src.Err())
+	assert.Equal(t, [][]any{
+		{"crm", int64(1), "John", "john@example.com", 30},
+		{"crm", int64(2), nil, "john@example.com", nil},
+	}, got)
+}
+
+func TestCopySource_Unset(t *testing.T) {
+	t.Parallel()
+
+	users := []*importUser{{ID: 1, Name: optional.Some("John")}}
+
+	src, err := optsql.NewCopySource(users)
+	require.NoError(t, err)
+
+	assert.False(t, src.Next())
+	require.ErrorIs(t, src.Err(), optsql.ErrUnset)
+	assert.EqualError(t, src.Err(), `optsql: row 0: column "email": optsql: unset field`)
+
+	src, err = optsql.NewCopySource(users, optsql.WithUnsetValue("unknown"))
+	require.NoError(t, err)
+
+	require.True(t, src.Next())
+
+	values, err := src.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"", int64(1), "John", "unknown", nil}, values)
+	assert.False(t, src.Next())
+	require.NoError(t, src.Err())
+}
+
+func TestCopySource_New(t *testing.T) {
+	t.Parallel()
+
+	var email optional.Tracked[string]
+	email.SetValue("john@example.com")
+
+	users := []importUser{{ID: 1, Name: optional.New("John", true), Email: email}}
+
+	src, err := optsql.NewCopySource(users)
+	require.NoError(t, err)
+
+	require.True(t, src.Next())
+
+	values, err := src.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"", int64(1), nil, "john@example.com", nil}, values)
+}
+
+func TestNewCopySource_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optsql.NewCopySource([]int{1})
+	require.EqualError(t, err, "optsql: rows must be structs, got int")
+
+	_, err = optsql.NewCopySource([]struct{ id int }{})
+	require.EqualError(t, err, "optsql: struct { id int } has no columns")
+
+	src, err := optsql.NewCopySource([]*importUser{nil})
+	require.NoError(t, err)
+	assert.False(t, src.Next())
+	require.EqualError(t, src.Err(), "optsql: row 0 is nil")
+}
+
+type execCall struct {
+	query string
+	args  []any
+}
+
+type fakeExecer struct {
+	calls []execCall
+}
+
+func (e *fakeExecer) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	e.calls = append(e.calls, execCall{query, args})
+
+	return driverResult(len(args) / 2), nil
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestBulkInsert(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID   int                   `db:"id"`
+		Name optional.Type[string] `db:"name"`
+	}
+
+	rows := []row{{1, optional.Some("a")}, {2, optional.Null[string]()}, {3, optional.Type[string]{}}}
+
+	tests := [...]struct {
+		name  string
+		opts  []optsql.Option
+		calls []execCall
+	}{
+		{
+			"single statement",
+			[]optsql.Option{optsql.WithUnsetValue(nil)},
+			[]execCall{
+				{"INSERT INTO users (id, name) VALUES (?, ?), (?, ?), (?, ?)", []any{1, "a", 2, nil, 3, nil}},
+			},
+		},
+		{
+			"batches",
+			[]optsql.Option{optsql.WithUnsetValue("-"), optsql.WithBatchSize(2), optsql.WithDollarPlaceholders()},
+			[]execCall{
+				{"INSERT INTO users (id, name) VALUES ($1, $2), ($3, $4)", []any{1, "a", 2, nil}},
+				{"INSERT INTO users (id, name) VALUES ($1, $2)", []any{3, "-"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var db fakeExecer
+
+			n, err := optsql.BulkInsert(context.Background(), &db, "users", rows, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), n)
+			assert.Equal(t, tt.calls, db.calls)
+		})
+	}
+}
+
+func TestBulkInsert_Unset(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name optional.Type[string] `db:"name"`
+	}
+
+	var db fakeExecer
+
+	n, err := optsql.BulkInsert(context.Background(), &db, "users", []row{{}})
+	require.ErrorIs(t, err, optsql.ErrUnset)
+	assert.Zero(t, n)
+	assert.Empty(t, db.calls)
+}
@@ -0,0 +1,227 @@
+// Package optsql scans SQL result rows into structs of optional values.
+package optsql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/micronull/optional"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// ScanRow scans the current row into the struct pointed to by dest, like [sql.Rows.Scan] does for its arguments.
+//
+// The columns are matched with the fields by the name from the `db` tag, falling back to the case-insensitive
+// match of the Go field name. The fields of nested structs match the columns prefixed with the name of
+// the struct and a dot, such as "address.city", while the fields of embedded structs match the columns without
+// a prefix. SQL NULL sets [optional.Type] fields to null and the fields without a matching column are left
+// as they are.
+//
+// When all the columns of a nested struct wrapped into [optional.Type] or a pointer are NULL, such as
+// the columns of a LEFT JOIN without a match, the field is set to null or nil as a whole.
+//
+// An error is returned if a column has no matching field.
+func ScanRow(rows *sql.Rows, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optsql: ScanRow expects a non-nil pointer to a struct, got %T", dest)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("optsql: columns: %w", err)
+	}
+
+	cols := make(map[string]int, len(columns))
+	for i, c := range columns {
+		cols[strings.ToLower(c)] = i
+	}
+
+	dests := make([]any, len(columns))
+	p := newPlan(rv.Elem().Type(), "", cols, dests)
+
+	for i, d := range dests {
+		if d == nil {
+			return fmt.Errorf("optsql: no field for column %q", columns[i])
+		}
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return fmt.Errorf("optsql: scan: %w", err)
+	}
+
+	p.assign(rv.Elem(), dests)
+
+	return nil
+}
+
+// wrap is the way a field wraps its value.
+type wrap uint8
+
+const (
+	wrapNone wrap = iota
+	wrapOptional
+	wrapPointer
+)
+
+// plan maps the columns to the fields of a struct.
+type plan struct {
+	typ    reflect.Type
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	index  []int
+	wrap   wrap
+	col    int   // col is the index of the column of the field, -1 for nested structs.
+	nested *plan // nested is the plan of the nested struct.
+}
+
+// newPlan returns the plan of the struct type t, filling dests with the pointers to pointers to the values
+// of the fields, so NULL columns are scanned as nil pointers.
+func newPlan(t reflect.Type, prefix string, cols map[string]int, dests []any) *plan {
+	p := &plan{typ: t}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		fw, inner := unwrap(f.Type)
+
+		if f.Anonymous && !hasTag && fw == wrapNone && isNestable(inner) {
+			for _, ef := range newPlan(inner, prefix, cols, dests).fields {
+				ef.index = append([]int{i}, ef.index...)
+				p.fields = append(p.fields, ef)
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		key := strings.ToLower(prefix + name)
+
+		if col, ok := cols[key]; ok {
+			dests[col] = reflect.New(reflect.PtrTo(inner)).Interface()
+			p.fields = append(p.fields, fieldPlan{index: []int{i}, wrap: fw, col: col})
+
+			continue
+		}
+
+		if isNestable(inner) {
+			if nested := newPlan(inner, key+".", cols, dests); len(nested.fields) != 0 {
+				p.fields = append(p.fields, fieldPlan{index: []int{i}, wrap: fw, col: -1, nested: nested})
+			}
+		}
+	}
+
+	return p
+}
+
+// assign sets the fields of the struct v from the scanned values.
+func (p *plan) assign(v reflect.Value, dests []any) {
+	for _, f := range p.fields {
+		fv := v.FieldByIndex(f.index)
+
+		switch {
+		case f.nested != nil && f.wrap == wrapNone:
+			f.nested.assign(fv, dests)
+		case f.nested != nil && f.nested.null(dests):
+			set(fv, f.wrap, reflect.Value{})
+		case f.nested != nil:
+			val := reflect.New(f.nested.typ).Elem()
+			f.nested.assign(val, dests)
+			set(fv, f.wrap, val)
+		default:
+			var val reflect.Value
+
+			if ptr := reflect.ValueOf(dests[f.col]).Elem(); !ptr.IsNil() {
+				val = ptr.Elem()
+			}
+
+			set(fv, f.wrap, val)
+		}
+	}
+}
+
+// null reports whether all the columns of the struct are NULL.
+func (p *plan) null(dests []any) bool {
+	for _, f := range p.fields {
+		if f.nested != nil {
+			if !f.nested.null(dests) {
+				return false
+			}
+
+			continue
+		}
+
+		if !reflect.ValueOf(dests[f.col]).Elem().IsNil() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// set assigns the value to the field wrapping it in the way w, the invalid value means NULL.
+func set(fv reflect.Value, w wrap, val reflect.Value) {
+	switch {
+	case w == wrapOptional && !val.IsValid():
+		fv.Addr().MethodByName("SetNull").Call(nil)
+	case w == wrapOptional:
+		fv.Addr().MethodByName("SetValue").Call([]reflect.Value{val})
+	case !val.IsValid():
+		fv.Set(reflect.Zero(fv.Type()))
+	case w == wrapPointer:
+		p := reflect.New(val.Type())
+		p.Elem().Set(val)
+		fv.Set(p)
+	default:
+		fv.Set(val)
+	}
+}
+
+// unwrap returns the way the field of type t wraps its value and the type of the value.
+func unwrap(t reflect.Type) (wrap, reflect.Type) {
+	switch {
+	case optional.IsType(t):
+		return wrapOptional, t.Field(0).Type
+	case isTracked(t):
+		return wrapOptional, t.Field(0).Type.Field(0).Type
+	case t.Kind() == reflect.Ptr:
+		return wrapPointer, t.Elem()
+	}
+
+	return wrapNone, t
+}
+
+// isNestable reports whether the columns are scanned into the fields of the struct type t
+// rather than into a value of t.
+func isNestable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+var pkgPath = reflect.TypeOf(optional.Type[int]{}).PkgPath()
+
+// isTracked reports whether t is an instantiation of [optional.Tracked].
+func isTracked(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Tracked[")
+}
@@ -0,0 +1,168 @@
+package optsql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optsql"
+)
+
+// The fake driver returns the result of the query registered in results.
+var results = map[string]*fakeRows{}
+
+func init() {
+	sql.Register("optsql-fake", fakeDriver{})
+}
+
+type (
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct{ query string }
+)
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+	pos     int
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error)         { return fakeConn{}, nil }
+func (fakeConn) Prepare(query string) (driver.Stmt, error)  { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, errors.New("not supported") }
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return 0 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+func (r *fakeRows) Columns() []string                       { return r.columns }
+func (r *fakeRows) Close() error                            { return nil }
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	r := *results[s.query]
+
+	return &r, nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos == len(r.values) {
+		return io.EOF
+	}
+
+	copy(dest, r.values[r.pos])
+	r.pos++
+
+	return nil
+}
+
+type address struct {
+	City optional.Type[string] `db:"city"`
+	Zip  string                `db:"zip"`
+}
+
+type Audit struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type user struct {
+	Audit
+
+	ID      int64                  `db:"id"`
+	Name    optional.Type[string]  `db:"name"`
+	Age     optional.Type[int]     `db:"age"`
+	Nick    *string                `db:"nick"`
+	Email   optional.Type[string]  `db:"email"`
+	Address optional.Type[address] `db:"address"`
+	Home    *address               `db:"home"`
+	Work    address                `db:"work"`
+	Skipped string                 `db:"-"`
+}
+
+func query(t *testing.T, rows *fakeRows, each func(*sql.Rows)) {
+	t.Helper()
+
+	db, err := sql.Open("optsql-fake", "")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	results[t.Name()] = rows
+
+	r, err := db.Query(t.Name())
+	require.NoError(t, err)
+
+	defer r.Close()
+
+	for r.Next() {
+		each(r)
+	}
+
+	require.NoError(t, r.Err())
+}
+
+func TestScanRow(t *testing.T) {
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var got []user
+
+	query(t, &fakeRows{
+		columns: []string{"id", "NAME", "age", "nick", "address.city", "address.zip", "home.city", "home.zip", "work.zip", "created_at"},
+		values: [][]driver.Value{
+			{int64(1), "John", int64(30), "jj", "Paris", "75001", nil, nil, "10115", created},
+			{int64(2), nil, "31", nil, nil, nil, "Rome", nil, nil, created},
+		},
+	}, func(rows *sql.Rows) {
+		var u user
+
+		require.NoError(t, optsql.ScanRow(rows, &u))
+
+		got = append(got, u)
+	})
+
+	nick := "jj"
+
+	assert.Equal(t, []user{
+		{
+			Audit:   Audit{CreatedAt: created},
+			ID:      1,
+			Name:    optional.Some("John"),
+			Age:     optional.Some(30),
+			Nick:    &nick,
+			Address: optional.Some(address{City: optional.Some("Paris"), Zip: "75001"}),
+			Work:    address{Zip: "10115"},
+		},
+		{
+			Audit:   Audit{CreatedAt: created},
+			ID:      2,
+			Name:    optional.Null[string](),
+			Age:     optional.Some(31),
+			Address: optional.Null[address](),
+			Home:    &address{City: optional.Some("Rome")},
+		},
+	}, got)
+}
+
+func TestScanRow_Errors(t *testing.T) {
+	t.Run("unknown column", func(t *testing.T) {
+		query(t, &fakeRows{columns: []string{"id", "unknown"}, values: [][]driver.Value{{int64(1), "x"}}}, func(rows *sql.Rows) {
+			require.EqualError(t, optsql.ScanRow(rows, &user{}), `optsql: no field for column "unknown"`)
+		})
+	})
+
+	t.Run("conversion", func(t *testing.T) {
+		query(t, &fakeRows{columns: []string{"age"}, values: [][]driver.Value{{"x"}}}, func(rows *sql.Rows) {
+			require.ErrorContains(t, optsql.ScanRow(rows, &user{}), "optsql: scan:")
+		})
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		query(t, &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(1)}}}, func(rows *sql.Rows) {
+			require.ErrorContains(t, optsql.ScanRow(rows, user{}), "optsql: ScanRow expects a non-nil pointer to a struct")
+		})
+	})
+}
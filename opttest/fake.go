@@ -0,0 +1,211 @@
+// Package opttest provides helpers for testing code using optional values.
+package opttest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/micronull/optional"
+)
+
+// maxDepth limits the nesting of the generated values, so recursive types end.
+const maxDepth = 4
+
+// FakeOption configures [Fake].
+type FakeOption func(*fakeOptions)
+
+type fakeOptions struct {
+	unset     float64
+	null      float64
+	rand      *rand.Rand
+	generator func(t reflect.Type) (any, bool)
+}
+
+// WithProbabilities sets the probabilities of an optional field to be left unset and to be set to null,
+// the field is set to a value otherwise. By default, every state has the same probability.
+func WithProbabilities(unset, null float64) FakeOption {
+	return func(o *fakeOptions) {
+		o.unset = unset
+		o.null = null
+	}
+}
+
+// WithRand sets the source of randomness, such as seeded one for reproducible values.
+// By default, a source seeded with the current time is used.
+func WithRand(r *rand.Rand) FakeOption {
+	return func(o *fakeOptions) {
+		o.rand = r
+	}
+}
+
+// WithGenerator sets the function generating the values of the types, such as a fake data library.
+// If it returns false, the value is generated by [Fake] itself.
+func WithGenerator(g func(t reflect.Type) (any, bool)) FakeOption {
+	return func(o *fakeOptions) {
+		o.generator = g
+	}
+}
+
+// Fake fills the optional fields of the struct pointed to by v with random states: unset, null or set
+// to a random value. Nested structs, including the values of the optional fields, are filled recursively,
+// the other fields are kept.
+//
+// It panics if v is not a non-nil pointer to a struct.
+func Fake(v any, opts ...FakeOption) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("opttest: Fake expects a non-nil pointer to a struct, got %T", v))
+	}
+
+	o := fakeOptions{unset: 1.0 / 3, null: 1.0 / 3}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.rand == nil {
+		o.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	f := faker{o: o}
+	f.fillStruct(rv.Elem(), 0)
+}
+
+type faker struct {
+	o fakeOptions
+}
+
+// fillStruct fills the optional fields of the struct.
+func (f *faker) fillStruct(v reflect.Value, depth int) {
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		switch {
+		case isOptional(fv.Type()):
+			f.fillOptional(fv, depth)
+		case fv.Kind() == reflect.Struct:
+			f.fillStruct(fv, depth+1)
+		}
+	}
+}
+
+// fillOptional sets the optional value to a random state.
+func (f *faker) fillOptional(v reflect.Value, depth int) {
+	p := f.o.rand.Float64()
+
+	switch {
+	case p < f.o.unset:
+		v.Addr().MethodByName("Unset").Call(nil)
+	case p < f.o.unset+f.o.null:
+		v.Addr().MethodByName("SetNull").Call(nil)
+	default:
+		elem := v.Addr().MethodByName("SetValue").Type().In(0)
+		v.Addr().MethodByName("SetValue").Call([]reflect.Value{f.value(elem, depth+1)})
+	}
+}
+
+// value returns a random value of the type t.
+func (f *faker) value(t reflect.Type, depth int) reflect.Value {
+	if f.o.generator != nil {
+		if v, ok := f.o.generator(t); ok {
+			if v == nil {
+				return reflect.Zero(t)
+			}
+
+			return reflect.ValueOf(v).Convert(t)
+		}
+	}
+
+	v := reflect.New(t).Elem()
+	if depth > maxDepth {
+		return v
+	}
+
+	r := f.o.rand
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		v.Set(reflect.ValueOf(time.Unix(r.Int63n(4102444800), 0).UTC()))
+
+		return v
+	case isOptional(t):
+		f.fillOptional(v, depth)
+
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63n(1000))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(r.Int63n(250)))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(r.Float64() * 1000)
+	case reflect.String:
+		v.SetString(f.word())
+	case reflect.Ptr:
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(f.value(t.Elem(), depth+1))
+	case reflect.Slice:
+		n := r.Intn(3) + 1
+		v.Set(reflect.MakeSlice(t, n, n))
+
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(f.value(t.Elem(), depth+1))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(f.value(t.Elem(), depth+1))
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+
+		for i := r.Intn(3) + 1; i > 0; i-- {
+			v.SetMapIndex(f.value(t.Key(), depth+1), f.value(t.Elem(), depth+1))
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				v.Field(i).Set(f.value(t.Field(i).Type, depth+1))
+			}
+		}
+	}
+
+	return v
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// word returns a random lowercase word.
+func (f *faker) word() string {
+	b := make([]byte, f.o.rand.Intn(8)+3)
+	for i := range b {
+		b[i] = letters[f.o.rand.Intn(len(letters))]
+	}
+
+	return string(b)
+}
+
+// isOptional reports whether t is an [optional.Type] or a struct embedding it, such as [optional.Tracked].
+func isOptional(t reflect.Type) bool {
+	if optional.IsType(t) {
+		return true
+	}
+
+	return t.Kind() == reflect.Struct && t.NumField() > 0 && t.Field(0).Anonymous && optional.IsType(t.Field(0).Type) &&
+		reflect.PtrTo(t).Implements(setterType)
+}
+
+// setterType is implemented by the pointers to the optional values.
+var setterType = reflect.TypeOf((*interface {
+	SetNull()
+	Unset()
+})(nil)).Elem()
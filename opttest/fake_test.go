@@ -0,0 +1,104 @@
+package opttest_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/opttest"
+)
+
+type address struct {
+	City   optional.Type[string] `json:"city"`
+	Street optional.Type[string] `json:"street"`
+}
+
+type user struct {
+	ID       int                       `json:"id"`
+	Name     optional.Type[string]     `json:"name"`
+	Age      optional.Type[int]        `json:"age"`
+	Tags     optional.Type[[]string]   `json:"tags"`
+	Birthday optional.Type[time.Time]  `json:"birthday"`
+	Address  optional.Type[address]    `json:"address"`
+	Billing  address                   `json:"billing"`
+	Score    optional.Tracked[float64] `json:"score"`
+}
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	seen := map[optional.State]bool{}
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		u := user{ID: 7}
+
+		opttest.Fake(&u, opttest.WithRand(r))
+
+		assert.Equal(t, 7, u.ID)
+
+		seen[u.Name.State()] = true
+	}
+
+	assert.Len(t, seen, 3)
+}
+
+func TestFake_Probabilities(t *testing.T) {
+	t.Parallel()
+
+	var u user
+
+	opttest.Fake(&u, opttest.WithProbabilities(0, 0), opttest.WithRand(rand.New(rand.NewSource(1))))
+
+	assert.Equal(t, optional.StateValue, u.Name.State())
+	assert.NotEmpty(t, u.Name.V)
+	assert.Equal(t, optional.StateValue, u.Tags.State())
+	assert.NotEmpty(t, u.Tags.V)
+	assert.Equal(t, optional.StateValue, u.Address.State())
+	assert.Equal(t, optional.StateValue, u.Address.V.City.State())
+	assert.Equal(t, optional.StateValue, u.Billing.Street.State())
+	assert.Equal(t, optional.StateValue, u.Score.State())
+
+	opttest.Fake(&u, opttest.WithProbabilities(0, 1))
+
+	assert.Equal(t, optional.StateNull, u.Name.State())
+	assert.Equal(t, optional.StateNull, u.Address.State())
+	assert.Equal(t, optional.StateNull, u.Billing.City.State())
+
+	opttest.Fake(&u, opttest.WithProbabilities(1, 0))
+
+	assert.Equal(t, optional.StateUnset, u.Name.State())
+	assert.Equal(t, optional.StateUnset, u.Score.State())
+}
+
+func TestFake_Generator(t *testing.T) {
+	t.Parallel()
+
+	var u user
+
+	generator := func(t reflect.Type) (any, bool) {
+		if t.Kind() == reflect.String {
+			return "fake", true
+		}
+
+		return nil, false
+	}
+
+	opttest.Fake(&u, opttest.WithProbabilities(0, 0), opttest.WithGenerator(generator),
+		opttest.WithRand(rand.New(rand.NewSource(1))))
+
+	assert.Equal(t, "fake", u.Name.V)
+	assert.Equal(t, "fake", u.Address.V.Street.V)
+	assert.NotZero(t, u.Age.V)
+}
+
+func TestFake_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { opttest.Fake(user{}) })
+}
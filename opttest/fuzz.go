@@ -0,0 +1,106 @@
+package opttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/micronull/optional"
+)
+
+// fuzzSeeds is the number of random documents added to the seed corpus by [FuzzRoundTrip].
+const fuzzSeeds = 8
+
+// FuzzRoundTrip fuzzes the decoding of the JSON documents into the struct type of proto, checking that
+// the decoded values round trip: marshalled with [optional.Marshal] and unmarshalled again, they keep
+// the same fields set and encode into the same document.
+//
+// The seed corpus has the empty document, documents with every field set to null and documents filled
+// with [Fake]. Call it from a fuzz test:
+//
+//	func FuzzUser(f *testing.F) {
+//		opttest.FuzzRoundTrip(f, User{})
+//	}
+func FuzzRoundTrip(f *testing.F, proto any) {
+	f.Helper()
+
+	st := reflect.TypeOf(proto)
+	for st != nil && st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+
+	if st == nil || st.Kind() != reflect.Struct {
+		f.Fatalf("opttest: FuzzRoundTrip expects a struct, got %T", proto)
+
+		return
+	}
+
+	for _, seed := range seedDocuments(f, st) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		first := reflect.New(st)
+
+		if err := json.Unmarshal(data, first.Interface()); err != nil {
+			return
+		}
+
+		encoded, err := optional.Marshal(first.Interface())
+		if err != nil {
+			t.Fatalf("opttest: marshal %s: %v", data, err)
+		}
+
+		second := reflect.New(st)
+
+		if err := json.Unmarshal(encoded, second.Interface()); err != nil {
+			t.Fatalf("opttest: unmarshal the marshalled %s: %v", encoded, err)
+		}
+
+		got, want := optional.SetFieldNames(second.Interface()), optional.SetFieldNames(first.Interface())
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("opttest: round trip of %s changed the set fields from %q to %q", data, want, got)
+		}
+
+		reencoded, err := optional.Marshal(second.Interface())
+		if err != nil {
+			t.Fatalf("opttest: marshal %s: %v", encoded, err)
+		}
+
+		if !bytes.Equal(encoded, reencoded) {
+			t.Fatalf("opttest: round trip of %s is not stable: %s, then %s", data, encoded, reencoded)
+		}
+	})
+}
+
+// seedDocuments returns the seed corpus of the struct type t.
+func seedDocuments(f *testing.F, t reflect.Type) [][]byte {
+	f.Helper()
+
+	seeds := [][]byte{[]byte(`{}`)}
+
+	nulls := reflect.New(t)
+	Fake(nulls.Interface(), WithProbabilities(0, 1))
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i <= fuzzSeeds; i++ {
+		v := nulls
+
+		if i > 0 {
+			v = reflect.New(t)
+			Fake(v.Interface(), WithRand(r))
+		}
+
+		data, err := optional.Marshal(v.Interface())
+		if err != nil {
+			f.Fatalf("opttest: marshal the seed: %v", err)
+		}
+
+		seeds = append(seeds, data)
+	}
+
+	return seeds
+}
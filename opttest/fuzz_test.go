@@ -0,0 +1,19 @@
+package opttest_test
+
+import (
+	"testing"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/opttest"
+)
+
+type profile struct {
+	Name  optional.Type[string]   `json:"name"`
+	Age   optional.Type[int]      `json:"age"`
+	Tags  optional.Type[[]string] `json:"tags"`
+	Admin bool                    `json:"admin"`
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	opttest.FuzzRoundTrip(f, &profile{})
+}
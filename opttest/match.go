@@ -0,0 +1,79 @@
+package opttest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// Matcher matches the optional values in a state, with a value deeply equal to the expected one.
+//
+// It implements the Matcher interface of gomock, and its Matches method can be passed to mock.MatchedBy
+// of testify.
+type Matcher[T any] struct {
+	state optional.State
+	value T
+}
+
+// SetTo returns the matcher of the optional values set to the value.
+func SetTo[T any](value T) Matcher[T] {
+	return Matcher[T]{state: optional.StateValue, value: value}
+}
+
+// Null returns the matcher of the optional values set to null.
+func Null[T any]() Matcher[T] {
+	return Matcher[T]{state: optional.StateNull}
+}
+
+// Unset returns the matcher of the unset optional values.
+func Unset[T any]() Matcher[T] {
+	return Matcher[T]{state: optional.StateUnset}
+}
+
+// Matches reports whether x is an [optional.Type] or an [optional.Tracked] of T, or a pointer to them,
+// matching the state and the value.
+func (m Matcher[T]) Matches(x any) bool {
+	var o optional.Type[T]
+
+	switch x := x.(type) {
+	case optional.Type[T]:
+		o = x
+	case *optional.Type[T]:
+		if x == nil {
+			return false
+		}
+
+		o = *x
+	case optional.Tracked[T]:
+		o = x.Type
+	case *optional.Tracked[T]:
+		if x == nil {
+			return false
+		}
+
+		o = x.Type
+	default:
+		return false
+	}
+
+	if o.State() != m.state {
+		return false
+	}
+
+	return m.state != optional.StateValue || reflect.DeepEqual(o.V, m.value)
+}
+
+// String describes the matched values, such as `is Some("x")`.
+func (m Matcher[T]) String() string {
+	o := optional.Some(m.value)
+
+	switch m.state {
+	case optional.StateNull:
+		o = optional.Null[T]()
+	case optional.StateUnset:
+		o = optional.Type[T]{}
+	}
+
+	return fmt.Sprintf("is %#v", o)
+}
@@ -0,0 +1,53 @@
+package opttest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/opttest"
+)
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+
+	tracked := optional.Tracked[string]{Type: optional.Some("x")}
+
+	tests := []struct {
+		name    string
+		matcher opttest.Matcher[string]
+		x       any
+		want    bool
+	}{
+		{name: "set to", matcher: opttest.SetTo("x"), x: optional.Some("x"), want: true},
+		{name: "set to pointer", matcher: opttest.SetTo("x"), x: &tracked.Type, want: true},
+		{name: "set to tracked", matcher: opttest.SetTo("x"), x: tracked, want: true},
+		{name: "set to other", matcher: opttest.SetTo("x"), x: optional.Some("y")},
+		{name: "set to null", matcher: opttest.SetTo(""), x: optional.Null[string]()},
+		{name: "null", matcher: opttest.Null[string](), x: optional.Null[string](), want: true},
+		{name: "null unset", matcher: opttest.Null[string](), x: optional.Type[string]{}},
+		{name: "unset", matcher: opttest.Unset[string](), x: optional.Type[string]{}, want: true},
+		{name: "unset tracked", matcher: opttest.Unset[string](), x: &tracked},
+		{name: "other type", matcher: opttest.SetTo("x"), x: "x"},
+		{name: "nil pointer", matcher: opttest.Unset[string](), x: (*optional.Type[string])(nil)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.matcher.Matches(tt.x))
+		})
+	}
+}
+
+func TestMatcher_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `is Some("x")`, opttest.SetTo("x").String())
+	assert.Equal(t, "is Null", opttest.Null[int]().String())
+	assert.Equal(t, "is Unset", opttest.Unset[int]().String())
+}
@@ -0,0 +1,76 @@
+package opttest
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/micronull/optional"
+)
+
+// TB is the part of [testing.TB] used by the assertions.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// stater is implemented by every optional value.
+type stater interface {
+	State() optional.State
+}
+
+// RequireSet fails the test unless the optional value is set, to a value or to null.
+func RequireSet(t TB, o stater) {
+	t.Helper()
+
+	if o.State() == optional.StateUnset {
+		t.Fatalf("opttest: want set, got %#v", o)
+	}
+}
+
+// RequireUnset fails the test unless the optional value is unset.
+func RequireUnset(t TB, o stater) {
+	t.Helper()
+
+	if o.State() != optional.StateUnset {
+		t.Fatalf("opttest: want Unset, got %#v", o)
+	}
+}
+
+// RequireNull fails the test unless the optional value is set to null.
+func RequireNull(t TB, o stater) {
+	t.Helper()
+
+	if o.State() != optional.StateNull {
+		t.Fatalf("opttest: want Null, got %#v", o)
+	}
+}
+
+// RequireValue fails the test unless the optional value is set to a value deeply equal to want.
+func RequireValue[T any](t TB, o optional.Type[T], want T) {
+	t.Helper()
+
+	if !o.Equal(optional.Some(want)) {
+		t.Fatalf("opttest: want %#v, got %#v", optional.Some(want), o)
+	}
+}
+
+// RequireOnlySet fails the test unless exactly the fields with the names are set in the struct v,
+// to values or to null. The names are taken from the json tags, with dotted paths for the fields
+// of nested structs, as [optional.SetFieldNames] returns them.
+func RequireOnlySet(t TB, v any, names ...string) {
+	t.Helper()
+
+	got := optional.SetFieldNames(v)
+
+	want := append([]string(nil), names...)
+	sort.Strings(want)
+
+	sorted := append([]string(nil), got...)
+	sort.Strings(sorted)
+
+	if reflect.DeepEqual(sorted, want) {
+		return
+	}
+
+	t.Fatalf("opttest: want only %q set, got %q", names, got)
+}
@@ -0,0 +1,107 @@
+package opttest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/opttest"
+)
+
+// recorder records the failure of an assertion.
+type recorder struct {
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func TestRequire(t *testing.T) {
+	t.Parallel()
+
+	u := user{
+		Name:    optional.Some("John"),
+		Age:     optional.Null[int](),
+		Address: optional.Some(address{City: optional.Some("Paris")}),
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t opttest.TB)
+		want   string
+	}{
+		{
+			name:   "set value",
+			assert: func(t opttest.TB) { opttest.RequireSet(t, u.Name) },
+		},
+		{
+			name:   "set null",
+			assert: func(t opttest.TB) { opttest.RequireSet(t, u.Age) },
+		},
+		{
+			name:   "set unset",
+			assert: func(t opttest.TB) { opttest.RequireSet(t, u.Tags) },
+			want:   "opttest: want set, got Unset",
+		},
+		{
+			name:   "unset",
+			assert: func(t opttest.TB) { opttest.RequireUnset(t, u.Name) },
+			want:   `opttest: want Unset, got Some("John")`,
+		},
+		{
+			name:   "null",
+			assert: func(t opttest.TB) { opttest.RequireNull(t, u.Age) },
+		},
+		{
+			name:   "null value",
+			assert: func(t opttest.TB) { opttest.RequireNull(t, u.Name) },
+			want:   `opttest: want Null, got Some("John")`,
+		},
+		{
+			name:   "value",
+			assert: func(t opttest.TB) { opttest.RequireValue(t, u.Name, "John") },
+		},
+		{
+			name:   "value differs",
+			assert: func(t opttest.TB) { opttest.RequireValue(t, u.Name, "Jane") },
+			want:   `opttest: want Some("Jane"), got Some("John")`,
+		},
+		{
+			name:   "value null",
+			assert: func(t opttest.TB) { opttest.RequireValue(t, u.Age, 42) },
+			want:   "opttest: want Some(42), got Null",
+		},
+		{
+			name:   "only set",
+			assert: func(t opttest.TB) { opttest.RequireOnlySet(t, u, "age", "name", "address.city") },
+		},
+		{
+			name:   "only set differs",
+			assert: func(t opttest.TB) { opttest.RequireOnlySet(t, u, "name") },
+			want:   `opttest: want only ["name"] set, got ["name" "age" "address.city"]`,
+		},
+		{
+			name:   "only set none",
+			assert: func(t opttest.TB) { opttest.RequireOnlySet(t, user{}) },
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var r recorder
+
+			tt.assert(&r)
+
+			assert.Equal(t, tt.want, r.failure)
+		})
+	}
+}
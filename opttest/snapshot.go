@@ -0,0 +1,153 @@
+package opttest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// UpdateEnv is the environment variable which, when not empty, makes [RequireSnapshot] write the golden files
+// instead of comparing with them.
+const UpdateEnv = "OPTTEST_UPDATE"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Snapshot returns the canonical textual form of the struct v for golden files, which, unlike JSON,
+// tells the unset fields from the zero values.
+//
+// Every field is written on its own line in the order of declaration, as "name: value". The names are taken
+// from the json tags, with dotted paths for the fields of nested structs, including the values of the optional
+// fields. The values are encoded as JSON, the unset optional fields as <unset> and the null ones as <null>.
+//
+// It panics if v is not a struct or a pointer to a struct.
+func Snapshot(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("opttest: Snapshot expects a struct, got %T", v))
+	}
+
+	var buf bytes.Buffer
+
+	writeSnapshot(&buf, rv, "")
+
+	return buf.String()
+}
+
+// RequireSnapshot fails the test unless the snapshot of the struct v equals the content of the golden file.
+// If the environment variable [UpdateEnv] is set, the file is written instead.
+func RequireSnapshot(t TB, path string, v any) {
+	t.Helper()
+
+	got := Snapshot(v)
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("opttest: write the snapshot: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("opttest: read the snapshot: %v, set %s=1 to write it", err, UpdateEnv)
+
+		return
+	}
+
+	if got != string(want) {
+		t.Fatalf("opttest: snapshot %s differs, set %s=1 to update it:\n--- want\n%s--- got\n%s", path, UpdateEnv, want, got)
+	}
+}
+
+func writeSnapshot(buf *bytes.Buffer, v reflect.Value, prefix string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		fv := v.Field(i)
+
+		if f.Anonymous && name == "" && !isOptional(f.Type) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct && f.IsExported() {
+				writeSnapshot(buf, fv, prefix)
+
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		writeSnapshotValue(buf, fv, prefix+name)
+	}
+}
+
+func writeSnapshotValue(buf *bytes.Buffer, v reflect.Value, path string) {
+	if isOptional(v.Type()) {
+		switch v.Interface().(stater).State() {
+		case optional.StateUnset:
+			fmt.Fprintf(buf, "%s: <unset>\n", path)
+
+			return
+		case optional.StateNull:
+			fmt.Fprintf(buf, "%s: <null>\n", path)
+
+			return
+		}
+
+		v = v.FieldByName("V")
+	}
+
+	if isSnapshotStruct(v.Type()) {
+		writeSnapshot(buf, v, path+".")
+
+		return
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		data = []byte(fmt.Sprintf("<error: %v>", err))
+	}
+
+	fmt.Fprintf(buf, "%s: %s\n", path, data)
+}
+
+// isSnapshotStruct reports whether the fields of the struct type t are written one by one.
+func isSnapshotStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isOptional(t) && !reflect.PtrTo(t).Implements(jsonMarshalerType) &&
+		!reflect.PtrTo(t).Implements(textMarshalerType)
+}
@@ -0,0 +1,64 @@
+package opttest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/opttest"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	u := user{
+		ID:       1,
+		Name:     optional.Some("John"),
+		Age:      optional.Null[int](),
+		Birthday: optional.Some(time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Address:  optional.Some(address{City: optional.Some("Paris")}),
+	}
+
+	assert.Equal(t, `id: 1
+name: "John"
+age: <null>
+tags: <unset>
+birthday: "1990-01-02T00:00:00Z"
+address.city: "Paris"
+address.street: <unset>
+billing.city: <unset>
+billing.street: <unset>
+score: <unset>
+`, opttest.Snapshot(&u))
+
+	assert.Panics(t, func() { opttest.Snapshot(1) })
+}
+
+func TestRequireSnapshot(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "user.golden")
+	u := user{ID: 1, Name: optional.Some("John")}
+
+	var r recorder
+
+	opttest.RequireSnapshot(&r, path, u)
+	assert.Contains(t, r.failure, "opttest: read the snapshot")
+
+	require.NoError(t, os.WriteFile(path, []byte(opttest.Snapshot(u)), 0o644))
+
+	r = recorder{}
+	opttest.RequireSnapshot(&r, path, u)
+	assert.Empty(t, r.failure)
+
+	u.Name.SetNull()
+
+	opttest.RequireSnapshot(&r, path, u)
+	assert.Contains(t, r.failure, "--- want\nid: 1\nname: \"John\"\n")
+	assert.Contains(t, r.failure, "--- got\nid: 1\nname: <null>\n")
+}
@@ -0,0 +1,112 @@
+package optwire
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// deltaFlag marks the frames of the deltas in the version byte.
+const deltaFlag = 0x80
+
+// Delta returns the delta between the old and the new versions of a struct in the binary format,
+// encoding only the fields that differ, so [ApplyDelta] applied onto old produces new. Nested plain
+// structs are encoded as nested deltas, other changed fields are encoded as a whole.
+func Delta(old, new any) ([]byte, error) {
+	ov, nv := indirect(reflect.ValueOf(old)), indirect(reflect.ValueOf(new))
+	if ov.Kind() != reflect.Struct || nv.Kind() != reflect.Struct || ov.Type() != nv.Type() {
+		return nil, fmt.Errorf("optwire: Delta expects structs of the same type, got %T and %T", old, new)
+	}
+
+	return appendDelta(nil, ov, nv)
+}
+
+// ApplyDelta applies the delta produced by [Delta] onto the struct pointed to by dst.
+func ApplyDelta(dst any, delta []byte) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optwire: ApplyDelta expects a non-nil pointer to a struct, got %T", dst)
+	}
+
+	if len(delta) > 0 && delta[0]&deltaFlag == 0 {
+		return errors.New("optwire: ApplyDelta got a frame, use Unmarshal")
+	}
+
+	r := &reader{data: delta}
+
+	return r.frame(rv.Elem())
+}
+
+func appendDelta(dst []byte, ov, nv reflect.Value) ([]byte, error) {
+	idx := fields(nv.Type())
+
+	dst = append(dst, Version|deltaFlag)
+	dst = appendUvarint(dst, uint64(len(idx)))
+
+	bitmap := make([]byte, (len(idx)*2+7)/8)
+	values := make([]reflect.Value, len(idx))
+
+	for i, x := range idx {
+		of, nf := ov.Field(x), nv.Field(x)
+
+		bits, fv := fieldState(nf)
+
+		switch {
+		case sameField(of, nf):
+			bits = bitsUnchanged
+		case bits == bitsValue:
+			values[i] = fv
+		}
+
+		bitmap[i/4] |= bits << (i % 4 * 2)
+	}
+
+	dst = append(dst, bitmap...)
+
+	for i, fv := range values {
+		if !fv.IsValid() {
+			continue
+		}
+
+		var err error
+
+		if nf := nv.Field(idx[i]); isPlainStruct(nf.Type()) {
+			var frame []byte
+
+			if frame, err = appendDelta(nil, ov.Field(idx[i]), fv); err == nil {
+				dst = appendBytes(dst, frame)
+			}
+		} else {
+			dst, err = appendValue(dst, fv)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("optwire: field %s: %w", nv.Type().Field(idx[i]).Name, err)
+		}
+	}
+
+	return dst, nil
+}
+
+// sameField reports whether the fields have the same state and value.
+func sameField(of, nf reflect.Value) bool {
+	ob, ov := fieldState(of)
+	nb, nv := fieldState(nf)
+
+	return ob == nb && (ob != bitsValue || reflect.DeepEqual(ov.Interface(), nv.Interface()))
+}
+
+// isPlainStruct reports whether the field of type t is a struct encoded as a nested frame.
+func isPlainStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !optional.IsType(t) && !isTracked(t) && !t.Implements(binaryMarshalerType)
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v
+}
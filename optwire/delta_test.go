@@ -0,0 +1,158 @@
+package optwire_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optwire"
+)
+
+type profile struct {
+	Bio     optional.Type[string] `json:"bio"`
+	Website optional.Type[string] `json:"website"`
+}
+
+type entity struct {
+	ID      int64                    `json:"id"`
+	Name    optional.Type[string]    `json:"name"`
+	Email   optional.Type[string]    `json:"email"`
+	Tags    []string                 `json:"tags"`
+	Profile profile                  `json:"profile"`
+	Address optional.Type[address]   `json:"address"`
+	Phone   optional.Tracked[string] `json:"phone"`
+}
+
+func TestDelta(t *testing.T) {
+	t.Parallel()
+
+	old := entity{
+		ID:      1,
+		Name:    optional.Some("John"),
+		Email:   optional.Some("john@example.com"),
+		Tags:    []string{"a"},
+		Profile: profile{Bio: optional.Some("bio"), Website: optional.Some("https://example.com")},
+		Address: optional.Some(address{City: optional.Some("Paris")}),
+	}
+
+	old.Phone.SetValue("1")
+	old.Phone.MarkClean()
+
+	tests := [...]struct {
+		name   string
+		change func(e *entity)
+	}{
+		{"unchanged", func(*entity) {}},
+		{"value", func(e *entity) { e.Name = optional.Some("Jane") }},
+		{"null", func(e *entity) { e.Email = optional.Null[string]() }},
+		{"unset", func(e *entity) { e.Name = optional.Type[string]{} }},
+		{"plain", func(e *entity) { e.ID = 2; e.Tags = []string{"b", "c"} }},
+		{"nested", func(e *entity) { e.Profile.Website = optional.Null[string]() }},
+		{"nested optional", func(e *entity) { e.Address = optional.Some(address{Zip: "75001"}) }},
+		{"tracked", func(e *entity) { e.Phone.SetValue("2") }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			newer := old
+			newer.Tags = append([]string(nil), old.Tags...)
+			tt.change(&newer)
+
+			delta, err := optwire.Delta(old, newer)
+			require.NoError(t, err)
+
+			got := old
+			got.Tags = append([]string(nil), old.Tags...)
+
+			require.NoError(t, optwire.ApplyDelta(&got, delta))
+			assert.Equal(t, newer.Phone.Type, got.Phone.Type)
+
+			got.Phone, newer.Phone = optional.Tracked[string]{}, optional.Tracked[string]{}
+			assert.Equal(t, newer, got)
+		})
+	}
+}
+
+func TestDelta_Size(t *testing.T) {
+	t.Parallel()
+
+	old := entity{Name: optional.Some("John"), Profile: profile{Bio: optional.Some("a long biography")}}
+	newer := old
+	newer.Email = optional.Some("a@b.c")
+
+	delta, err := optwire.Delta(old, newer)
+	require.NoError(t, err)
+
+	full, err := optwire.Marshal(newer)
+	require.NoError(t, err)
+
+	// The version, the number of the fields, the bitmap and the value of Email.
+	assert.Len(t, delta, 1+1+2+6)
+	assert.Less(t, len(delta), len(full))
+}
+
+func TestDelta_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := optwire.Delta(entity{}, profile{})
+	require.ErrorContains(t, err, "optwire: Delta expects structs of the same type")
+
+	delta, err := optwire.Delta(entity{}, entity{ID: 1})
+	require.NoError(t, err)
+
+	frame, err := optwire.Marshal(entity{})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, optwire.Unmarshal(delta, &entity{}), "optwire: Unmarshal got a delta")
+	require.ErrorContains(t, optwire.ApplyDelta(&entity{}, frame), "optwire: ApplyDelta got a frame")
+	require.ErrorContains(t, optwire.ApplyDelta(entity{}, delta), "optwire: ApplyDelta expects a non-nil pointer")
+}
+
+func TestApplyDelta_Malformed(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		data []byte
+	}{
+		{"no count", []byte{optwire.Version | 0x80}},
+		{"huge count", appendUvarint([]byte{optwire.Version | 0x80}, 1<<63)},
+		{"no bitmap", []byte{optwire.Version | 0x80, 1}},
+		{"no value", []byte{optwire.Version | 0x80, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorContains(t, optwire.ApplyDelta(&entity{}, tt.data), "optwire: ")
+		})
+	}
+}
+
+func FuzzApplyDelta(f *testing.F) {
+	delta, err := optwire.Delta(entity{}, entity{
+		ID:      1,
+		Tags:    []string{"a"},
+		Profile: profile{Bio: optional.Some("bio")},
+		Address: optional.Some(address{Zip: "10115"}),
+	})
+	require.NoError(f, err)
+
+	f.Add(delta)
+	f.Add(appendUvarint([]byte{optwire.Version | 0x80}, 1<<63))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var got entity
+
+		_ = optwire.ApplyDelta(&got, data)
+	})
+}
@@ -0,0 +1,553 @@
+// Package optwire serializes structs of optional values into a compact binary format keeping the unset state,
+// such as for internal queue messages where the overhead of JSON is a problem.
+//
+// A frame starts with the format version and the number of the fields followed by the presence bitmap,
+// two bits per field, and the packed values of the fields set to non-null values. The exported fields
+// are identified by their position, so new fields must be added to the end of the struct: the fields
+// of newer writers are ignored by older readers, while the fields missing in older frames are left untouched.
+package optwire
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// Version is the version of the format written by [Marshal].
+const Version = 1
+
+// ErrVersion is returned when the frame is written in an unsupported version of the format.
+var ErrVersion = errors.New("optwire: unsupported version")
+
+// The states of the fields in the presence bitmap.
+const (
+	bitsUnset     = 0
+	bitsNull      = 1
+	bitsValue     = 2
+	bitsUnchanged = 3 // bitsUnchanged marks the fields left as they are by the deltas.
+)
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// presence is implemented by every [optional.Type] value.
+type presence interface {
+	IsSet() bool
+	IsSetNull() bool
+}
+
+// Marshal returns the binary frame of the struct v.
+//
+// Booleans, numbers, strings and byte slices are packed, slices, arrays and pointers are encoded element by element,
+// nested structs are encoded as nested frames and values implementing [encoding.BinaryMarshaler], such as
+// [time.Time], are encoded by it. Other values, such as maps and interfaces, are encoded as JSON.
+// Nil pointers are kept, while nil and empty slices are not distinguished.
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("optwire: Marshal expects a struct, got %T", v)
+	}
+
+	return appendFrame(nil, rv)
+}
+
+// Unmarshal decodes the binary frame into the struct pointed to by v. The fields absent in the frame are left as they are.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optwire: Unmarshal expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	if len(data) > 0 && data[0]&deltaFlag != 0 {
+		return errors.New("optwire: Unmarshal got a delta, use ApplyDelta")
+	}
+
+	r := &reader{data: data}
+
+	return r.frame(rv.Elem())
+}
+
+// fields returns the indexes of the exported fields of the struct type t.
+func fields(t reflect.Type) []int {
+	var idx []int
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			idx = append(idx, i)
+		}
+	}
+
+	return idx
+}
+
+// fieldState returns the state bits and the value of the field.
+func fieldState(fv reflect.Value) (byte, reflect.Value) {
+	if isTracked(fv.Type()) {
+		fv = fv.Field(0)
+	}
+
+	if !optional.IsType(fv.Type()) {
+		return bitsValue, fv
+	}
+
+	p := fv.Interface().(presence)
+
+	switch {
+	case p.IsSetNull():
+		return bitsNull, fv
+	case p.IsSet():
+		return bitsValue, fv.FieldByName("V")
+	}
+
+	return bitsUnset, fv
+}
+
+var pkgPath = reflect.TypeOf(optional.Type[int]{}).PkgPath()
+
+// isTracked reports whether t is an instantiation of [optional.Tracked].
+func isTracked(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Tracked[")
+}
+
+func appendFrame(dst []byte, v reflect.Value) ([]byte, error) {
+	idx := fields(v.Type())
+
+	dst = append(dst, Version)
+	dst = appendUvarint(dst, uint64(len(idx)))
+
+	bitmap := make([]byte, (len(idx)*2+7)/8)
+	values := make([]reflect.Value, len(idx))
+
+	for i, x := range idx {
+		bits, fv := fieldState(v.Field(x))
+		bitmap[i/4] |= bits << (i % 4 * 2)
+
+		if bits == bitsValue {
+			values[i] = fv
+		}
+	}
+
+	dst = append(dst, bitmap...)
+
+	for i, fv := range values {
+		if !fv.IsValid() {
+			continue
+		}
+
+		var err error
+
+		if dst, err = appendValue(dst, fv); err != nil {
+			return nil, fmt.Errorf("optwire: field %s: %w", v.Type().Field(idx[i]).Name, err)
+		}
+	}
+
+	return dst, nil
+}
+
+func appendValue(dst []byte, v reflect.Value) ([]byte, error) {
+	if optional.IsType(v.Type()) || isTracked(v.Type()) {
+		// The optional values outside of the fields, such as the elements of slices, are prefixed with the state.
+		bits, fv := fieldState(v)
+		if dst = append(dst, bits); bits != bitsValue {
+			return dst, nil
+		}
+
+		return appendValue(dst, fv)
+	}
+
+	if v.Type().Implements(binaryMarshalerType) {
+		b, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		return appendBytes(dst, b), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+
+		return append(dst, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(dst, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUvarint(dst, v.Uint()), nil
+	case reflect.Float32:
+		var b [4]byte
+
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(v.Float())))
+
+		return append(dst, b[:]...), nil
+	case reflect.Float64:
+		var b [8]byte
+
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v.Float()))
+
+		return append(dst, b[:]...), nil
+	case reflect.String:
+		return appendBytes(dst, []byte(v.String())), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendBytes(dst, v.Bytes()), nil
+		}
+
+		fallthrough
+	case reflect.Array:
+		dst = appendUvarint(dst, uint64(v.Len()))
+
+		for i := 0; i < v.Len(); i++ {
+			var err error
+
+			if dst, err = appendValue(dst, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+
+		return dst, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(dst, 0), nil
+		}
+
+		return appendValue(append(dst, 1), v.Elem())
+	case reflect.Struct:
+		frame, err := appendFrame(nil, v)
+		if err != nil {
+			return nil, err
+		}
+
+		return appendBytes(dst, frame), nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return appendBytes(dst, b), nil
+}
+
+func appendUvarint(dst []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+
+	return append(dst, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendVarint(dst []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+
+	return append(dst, b[:binary.PutVarint(b[:], x)]...)
+}
+
+func appendBytes(dst, b []byte) []byte {
+	return append(appendUvarint(dst, uint64(len(b))), b...)
+}
+
+// errShort is returned when the frame ends unexpectedly.
+var errShort = errors.New("optwire: unexpected end of frame")
+
+// reader reads the values of a frame.
+type reader struct {
+	data []byte
+}
+
+func (r *reader) frame(v reflect.Value) error {
+	b, err := r.byte()
+	if err != nil {
+		return err
+	}
+
+	delta := b&deltaFlag != 0
+
+	if b&^deltaFlag != Version {
+		return fmt.Errorf("%w %d", ErrVersion, b&^deltaFlag)
+	}
+
+	n, err := r.uvarint()
+	if err != nil {
+		return err
+	}
+
+	// Each field takes two bits of the bitmap, so a hostile count cannot exceed the size of the rest of the frame.
+	if n > uint64(len(r.data))*4 {
+		return errShort
+	}
+
+	bitmap, err := r.bytes(int((n*2 + 7) / 8))
+	if err != nil {
+		return err
+	}
+
+	idx := fields(v.Type())
+
+	for i := 0; i < len(idx) && uint64(i) < n; i++ {
+		fv := v.Field(idx[i])
+
+		bits := bitmap[i/4] >> (i % 4 * 2) & 3
+		if bits == bitsUnchanged && delta {
+			continue
+		}
+
+		if err := r.field(fv, bits); err != nil {
+			return fmt.Errorf("optwire: field %s: %w", v.Type().Field(idx[i]).Name, err)
+		}
+	}
+
+	// The values of the fields unknown to the reader follow the known ones and are ignored.
+	return nil
+}
+
+func (r *reader) field(fv reflect.Value, bits byte) error {
+	if !optional.IsType(fv.Type()) && !isTracked(fv.Type()) {
+		if bits != bitsValue {
+			return nil
+		}
+
+		return r.value(fv)
+	}
+
+	switch bits {
+	case bitsUnset:
+		fv.Addr().MethodByName("Unset").Call(nil)
+	case bitsNull:
+		fv.Addr().MethodByName("SetNull").Call(nil)
+	case bitsValue:
+		inner := fv
+		if isTracked(fv.Type()) {
+			inner = fv.Field(0)
+		}
+
+		val := reflect.New(inner.FieldByName("V").Type()).Elem()
+
+		if err := r.value(val); err != nil {
+			return err
+		}
+
+		fv.Addr().MethodByName("SetValue").Call([]reflect.Value{val})
+	default:
+		return fmt.Errorf("invalid state %d", bits)
+	}
+
+	return nil
+}
+
+func (r *reader) value(v reflect.Value) error {
+	if optional.IsType(v.Type()) || isTracked(v.Type()) {
+		bits, err := r.byte()
+		if err != nil {
+			return err
+		}
+
+		return r.field(v, bits)
+	}
+
+	if reflect.PtrTo(v.Type()).Implements(binaryUnmarshalerType) {
+		b, err := r.lenBytes()
+		if err != nil {
+			return err
+		}
+
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := r.byte()
+		v.SetBool(b != 0)
+
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(r.data)
+		if n <= 0 {
+			return errShort
+		}
+
+		r.data = r.data[n:]
+
+		if v.OverflowInt(x) {
+			return fmt.Errorf("value %d overflows %s", x, v.Type())
+		}
+
+		v.SetInt(x)
+
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := r.uvarint()
+		if err != nil {
+			return err
+		}
+
+		if v.OverflowUint(x) {
+			return fmt.Errorf("value %d overflows %s", x, v.Type())
+		}
+
+		v.SetUint(x)
+
+		return nil
+	case reflect.Float32:
+		b, err := r.bytes(4)
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+
+		return nil
+	case reflect.Float64:
+		b, err := r.bytes(8)
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+
+		return nil
+	case reflect.String:
+		b, err := r.lenBytes()
+		v.SetString(string(b))
+
+		return err
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := r.lenBytes()
+			v.SetBytes(append([]byte(nil), b...))
+
+			return err
+		}
+
+		n, err := r.count()
+		if err != nil {
+			return err
+		}
+
+		s := reflect.MakeSlice(v.Type(), n, n)
+
+		for i := 0; i < n; i++ {
+			if err := r.value(s.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(s)
+
+		return nil
+	case reflect.Array:
+		n, err := r.count()
+		if err != nil {
+			return err
+		}
+
+		if n != v.Len() {
+			return fmt.Errorf("array of %d elements decoded into %s", n, v.Type())
+		}
+
+		for i := 0; i < n; i++ {
+			if err := r.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Ptr:
+		b, err := r.byte()
+		if err != nil || b == 0 {
+			v.Set(reflect.Zero(v.Type()))
+
+			return err
+		}
+
+		p := reflect.New(v.Type().Elem())
+
+		if err := r.value(p.Elem()); err != nil {
+			return err
+		}
+
+		v.Set(p)
+
+		return nil
+	case reflect.Struct:
+		b, err := r.lenBytes()
+		if err != nil {
+			return err
+		}
+
+		return (&reader{data: b}).frame(v)
+	}
+
+	b, err := r.lenBytes()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v.Addr().Interface())
+}
+
+func (r *reader) byte() (byte, error) {
+	b, err := r.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data) {
+		return nil, errShort
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+
+	return b, nil
+}
+
+func (r *reader) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		return 0, errShort
+	}
+
+	r.data = r.data[n:]
+
+	return x, nil
+}
+
+// count reads the number of the elements, which cannot exceed the size of the rest of the frame.
+func (r *reader) count() (int, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+
+	if n > uint64(len(r.data)) {
+		return 0, errShort
+	}
+
+	return int(n), nil
+}
+
+func (r *reader) lenBytes() ([]byte, error) {
+	n, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.bytes(n)
+}
@@ -0,0 +1,218 @@
+package optwire_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/optwire"
+)
+
+type address struct {
+	City optional.Type[string] `json:"city"`
+	Zip  string                `json:"zip"`
+}
+
+type message struct {
+	ID       int64                         `json:"id"`
+	Name     optional.Type[string]         `json:"name"`
+	Age      optional.Type[int]            `json:"age"`
+	Active   optional.Type[bool]           `json:"active"`
+	Score    optional.Type[float64]        `json:"score"`
+	Ratio    optional.Type[float32]        `json:"ratio"`
+	Count    optional.Type[uint16]         `json:"count"`
+	Data     optional.Type[[]byte]         `json:"data"`
+	Tags     optional.Type[[]string]       `json:"tags"`
+	Address  optional.Type[address]        `json:"address"`
+	Home     *address                      `json:"home"`
+	Created  optional.Type[time.Time]      `json:"created"`
+	Meta     optional.Type[map[string]int] `json:"meta"`
+	Values   [2]optional.Type[int]         `json:"values"`
+	Email    optional.Tracked[string]      `json:"email"`
+	internal int
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := [...]struct {
+		name  string
+		input message
+	}{
+		{"empty", message{}},
+		{"nulls", message{Name: optional.Null[string](), Address: optional.Null[address](), Email: optional.Tracked[string]{Type: optional.Null[string]()}}},
+		{
+			"values",
+			message{
+				ID:      -42,
+				Name:    optional.Some("John"),
+				Age:     optional.Some(0),
+				Active:  optional.Some(false),
+				Score:   optional.Some(1.5),
+				Ratio:   optional.Some(float32(0.25)),
+				Count:   optional.Some(uint16(300)),
+				Data:    optional.Some([]byte{1, 2}),
+				Tags:    optional.Some([]string{"a", ""}),
+				Address: optional.Some(address{City: optional.Null[string](), Zip: "123"}),
+				Home:    &address{City: optional.Some("Paris")},
+				Created: optional.Some(created),
+				Meta:    optional.Some(map[string]int{"a": 1}),
+				Values:  [2]optional.Type[int]{optional.Some(1), optional.Null[int]()},
+				Email:   optional.Tracked[string]{Type: optional.Some("a@b.c")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := optwire.Marshal(tt.input)
+			require.NoError(t, err)
+
+			var got message
+
+			require.NoError(t, optwire.Unmarshal(data, &got))
+			assert.Equal(t, tt.input.Name, got.Name)
+			assert.True(t, tt.input.Created.V.Equal(got.Created.V))
+
+			got.Created = tt.input.Created
+			assert.Equal(t, tt.input, got)
+		})
+	}
+}
+
+func TestMarshal_Size(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		ID    int64                 `json:"id"`
+		Name  optional.Type[string] `json:"name"`
+		Age   optional.Type[int]    `json:"age"`
+		Email optional.Type[string] `json:"email"`
+	}
+
+	v := event{ID: 1, Name: optional.Some("John"), Age: optional.Null[int]()}
+
+	data, err := optwire.Marshal(v)
+	require.NoError(t, err)
+
+	js, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	// The version, the number of the fields, the bitmap and the values of ID and Name.
+	assert.Len(t, data, 1+1+1+1+5)
+	assert.Less(t, len(data), len(js))
+}
+
+func TestUnmarshal_Versions(t *testing.T) {
+	t.Parallel()
+
+	type v1 struct {
+		Name optional.Type[string]
+	}
+
+	type v2 struct {
+		Name  optional.Type[string]
+		Email optional.Type[string]
+		Tags  []string
+	}
+
+	old, err := optwire.Marshal(v1{Name: optional.Some("John")})
+	require.NoError(t, err)
+
+	var newer v2
+
+	require.NoError(t, optwire.Unmarshal(old, &newer))
+	assert.Equal(t, v2{Name: optional.Some("John")}, newer)
+
+	data, err := optwire.Marshal(v2{Name: optional.Null[string](), Email: optional.Some("a@b.c"), Tags: []string{"x"}})
+	require.NoError(t, err)
+
+	var older v1
+
+	require.NoError(t, optwire.Unmarshal(data, &older))
+	assert.Equal(t, v1{Name: optional.Null[string]()}, older)
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	t.Parallel()
+
+	data, err := optwire.Marshal(message{Name: optional.Some("John")})
+	require.NoError(t, err)
+
+	var got message
+
+	require.ErrorIs(t, optwire.Unmarshal(append([]byte{2}, data[1:]...), &got), optwire.ErrVersion)
+	require.ErrorContains(t, optwire.Unmarshal(data[:len(data)-1], &got), "optwire: unexpected end of frame")
+	require.ErrorContains(t, optwire.Unmarshal(data, got), "optwire: Unmarshal expects a non-nil pointer to a struct")
+
+	_, err = optwire.Marshal(1)
+	require.ErrorContains(t, err, "optwire: Marshal expects a struct")
+}
+
+func TestUnmarshal_Malformed(t *testing.T) {
+	t.Parallel()
+
+	type one struct {
+		Name optional.Type[string]
+	}
+
+	tests := [...]struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"no count", []byte{optwire.Version}},
+		{"huge count", appendUvarint([]byte{optwire.Version}, 1<<63)},
+		{"count beyond frame", appendUvarint([]byte{optwire.Version}, 9)},
+		{"no bitmap", []byte{optwire.Version, 1}},
+		{"no value", []byte{optwire.Version, 1, 2}},
+		{"huge string", appendUvarint([]byte{optwire.Version, 1, 2}, 1<<40)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorContains(t, optwire.Unmarshal(tt.data, &one{}), "optwire: ")
+		})
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	data, err := optwire.Marshal(message{
+		ID:      1,
+		Name:    optional.Some("John"),
+		Tags:    optional.Some([]string{"a"}),
+		Address: optional.Some(address{City: optional.Null[string]()}),
+		Meta:    optional.Some(map[string]int{"a": 1}),
+	})
+	require.NoError(f, err)
+
+	f.Add(data)
+	f.Add(appendUvarint([]byte{optwire.Version}, 1<<63))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var got message
+
+		_ = optwire.Unmarshal(data, &got)
+	})
+}
+
+func appendUvarint(dst []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	return append(dst, buf[:binary.PutUvarint(buf[:], x)]...)
+}
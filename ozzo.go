@@ -0,0 +1,20 @@
+package optional
+
+// Validate implements the validation.Validatable interface of https://github.com/go-ozzo/ozzo-validation,
+// so the values of set [Type] fields are validated by their own Validate methods, such as the nested structs.
+// Unset and null values are valid, the rules on their presence are set with the optrules package.
+func (t Type[T]) Validate() error {
+	if t.f != flagSet {
+		return nil
+	}
+
+	if v, ok := any(t.V).(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+
+	if v, ok := any(&t.V).(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package optional_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+var errEmptyCity = errors.New("city is empty")
+
+type validatableAddress struct {
+	City string
+}
+
+func (a validatableAddress) Validate() error {
+	if a.City == "" {
+		return errEmptyCity
+	}
+
+	return nil
+}
+
+type validatableName string
+
+func (n *validatableName) Validate() error {
+	if *n == "" {
+		return errEmptyCity
+	}
+
+	return nil
+}
+
+func TestType_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		v    interface{ Validate() error }
+		want error
+	}{
+		{"valid", optional.Some(validatableAddress{City: "Paris"}), nil},
+		{"invalid", optional.Some(validatableAddress{}), errEmptyCity},
+		{"pointer receiver", optional.Some(validatableName("")), errEmptyCity},
+		{"unset", optional.Type[validatableAddress]{}, nil},
+		{"null", optional.Null[validatableAddress](), nil},
+		{"not validatable", optional.Some(""), nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, tt.v.Validate(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package patch
+
+import (
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// Redacted replaces the values of the fields redacted by [RedactPaths].
+const Redacted = "[REDACTED]"
+
+// AuditEntry records a field changed by [ApplyWithAudit].
+type AuditEntry struct {
+	Path   string // Path is the dotted path of the Go field names of the patch, such as "Address.City".
+	Before any
+	After  any
+}
+
+// Redactor returns the value recorded in the audit entry instead of the actual value of the field,
+// e.g. to hide secrets from audit logs.
+type Redactor func(path string, value any) any
+
+// RedactPaths returns the redactor replacing the values of the fields with the paths by [Redacted].
+func RedactPaths(paths ...string) Redactor {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[p] = struct{}{}
+	}
+
+	return func(path string, value any) any {
+		if _, ok := set[path]; ok {
+			return Redacted
+		}
+
+		return value
+	}
+}
+
+// ApplyWithAudit is like [Apply], but returns the audit entries with the values before and after the patch
+// for every field that has changed, in the order of the fields of the patch. Nested patch structs are
+// recorded field by field. The values of the entries
+// are passed through the redactors in order.
+func ApplyWithAudit(dst, patch any, redactors ...Redactor) ([]AuditEntry, error) {
+	type applied struct {
+		path   string
+		target reflect.Value
+		before any
+	}
+
+	var fields []applied
+
+	opts := MergeOptions{
+		Field: func(path string, _ optional.State, value any, target reflect.Value) (bool, error) {
+			// Nested patch structs are recorded field by field.
+			if value != nil && isPatchStruct(reflect.TypeOf(value)) && target.Kind() == reflect.Struct {
+				return false, nil
+			}
+
+			before := reflect.New(target.Type()).Elem()
+			before.Set(target)
+
+			fields = append(fields, applied{path: path, target: target, before: before.Interface()})
+
+			return false, nil
+		},
+	}
+
+	if err := ApplyWithOptions(dst, patch, opts); err != nil {
+		return nil, err
+	}
+
+	var entries []AuditEntry
+
+	for _, f := range fields {
+		after := f.target.Interface()
+		if reflect.DeepEqual(f.before, after) {
+			continue
+		}
+
+		e := AuditEntry{Path: f.path, Before: f.before, After: after}
+
+		for _, r := range redactors {
+			e.Before, e.After = r(f.path, e.Before), r(f.path, e.After)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,39 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestApplyWithAudit(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	dst := user{Name: "some", Email: &email, Age: 42, Address: address{City: "Moscow"}}
+
+	p := userPatch{
+		Name:    optional.Some("other"),
+		Email:   optional.Null[string](),
+		Age:     optional.Some(42),
+		Address: optional.Some(addressPatch{City: optional.Some("Paris")}),
+	}
+
+	got, err := patch.ApplyWithAudit(&dst, p, patch.RedactPaths("Email"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []patch.AuditEntry{
+		{Path: "Name", Before: "some", After: "other"},
+		{Path: "Email", Before: patch.Redacted, After: patch.Redacted},
+		{Path: "Address.City", Before: "Moscow", After: "Paris"},
+	}, got)
+
+	assert.Equal(t, user{Name: "other", Age: 42, Address: address{City: "Paris"}}, dst)
+
+	_, err = patch.ApplyWithAudit(dst, p)
+	require.Error(t, err)
+}
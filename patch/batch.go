@@ -0,0 +1,100 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is reported by [ApplyBatch] and [ApplyBatchMap] for the patch without a matching element.
+var ErrNotFound = errors.New("patch: no matching element")
+
+// BatchError reports the patches of a batch which could not be applied, by their keys.
+type BatchError[K comparable] map[K]error
+
+func (e BatchError[K]) Error() string {
+	msgs := make([]string, 0, len(e))
+	for k, err := range e {
+		msgs = append(msgs, fmt.Sprintf("%v: %v", k, err))
+	}
+
+	sort.Strings(msgs)
+
+	return "patch: batch items failed: " + strings.Join(msgs, "; ")
+}
+
+// ApplyBatch applies the patches onto the elements of dst with the same keys returned by key, like [Apply] does,
+// for the bulk update endpoints. The elements are structs or pointers to structs, the patches may be applied
+// to several elements with the same key.
+//
+// The patches failing to apply, including the ones without a matching element reported with [ErrNotFound],
+// are returned as [BatchError], while the other patches are applied. The element may be partially patched
+// when its patch fails, like with [Apply].
+func ApplyBatch[E any, K comparable, P any](dst []E, patches map[K]P, key func(E) K) error {
+	errs := BatchError[K]{}
+	found := make(map[K]bool, len(patches))
+
+	for i := range dst {
+		k := key(dst[i])
+
+		p, ok := patches[k]
+		if !ok {
+			continue
+		}
+
+		found[k] = true
+
+		if err := Apply(elemTarget(&dst[i]), p); err != nil {
+			errs[k] = err
+		}
+	}
+
+	for k := range patches {
+		if !found[k] {
+			errs[k] = ErrNotFound
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ApplyBatchMap is like [ApplyBatch], but applies the patches onto the elements of the map with the same keys.
+func ApplyBatchMap[K comparable, E any, P any](dst map[K]E, patches map[K]P) error {
+	errs := BatchError[K]{}
+
+	for k, p := range patches {
+		e, ok := dst[k]
+		if !ok {
+			errs[k] = ErrNotFound
+
+			continue
+		}
+
+		if err := Apply(elemTarget(&e), p); err != nil {
+			errs[k] = err
+		}
+
+		dst[k] = e
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// elemTarget returns the pointer to the struct of the element: the element itself if it is a pointer.
+func elemTarget[E any](e *E) any {
+	if v := reflect.ValueOf(e).Elem(); v.Kind() == reflect.Ptr {
+		return v.Interface()
+	}
+
+	return e
+}
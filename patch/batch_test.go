@@ -0,0 +1,78 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+type batchPatch struct {
+	Name optional.Type[string]
+	Age  optional.Type[int]
+}
+
+type badBatchPatch struct {
+	Phone optional.Type[string]
+}
+
+func TestApplyBatch(t *testing.T) {
+	t.Parallel()
+
+	users := []user{{ID: 1, Name: "John"}, {ID: 2, Name: "Jane"}, {ID: 3, Name: "Bob"}}
+
+	err := patch.ApplyBatch(users, map[int]batchPatch{
+		1: {Name: optional.Some("Johnny")},
+		3: {Age: optional.Some(30), Name: optional.Null[string]()},
+		4: {Name: optional.Some("Nobody")},
+	}, func(u user) int { return u.ID })
+
+	var be patch.BatchError[int]
+	require.ErrorAs(t, err, &be)
+	assert.Len(t, be, 1)
+	assert.ErrorIs(t, be[4], patch.ErrNotFound)
+	assert.EqualError(t, err, "patch: batch items failed: 4: patch: no matching element")
+
+	assert.Equal(t, []user{{ID: 1, Name: "Johnny"}, {ID: 2, Name: "Jane"}, {ID: 3, Age: 30}}, users)
+}
+
+func TestApplyBatch_Pointers(t *testing.T) {
+	t.Parallel()
+
+	users := []*user{{ID: 1, Name: "John"}, {ID: 2, Name: "Jane"}}
+
+	err := patch.ApplyBatch(users, map[int]batchPatch{2: {Age: optional.Some(20)}}, func(u *user) int { return u.ID })
+	require.NoError(t, err)
+
+	assert.Equal(t, user{ID: 2, Name: "Jane", Age: 20}, *users[1])
+	assert.Equal(t, user{ID: 1, Name: "John"}, *users[0])
+
+	err = patch.ApplyBatch(users, map[int]badBatchPatch{1: {Phone: optional.Some("1")}}, func(u *user) int { return u.ID })
+
+	var be patch.BatchError[int]
+	require.ErrorAs(t, err, &be)
+	assert.EqualError(t, be[1], `patch: field "Phone" has no matching field in patch_test.user`)
+}
+
+func TestApplyBatchMap(t *testing.T) {
+	t.Parallel()
+
+	users := map[string]user{"a": {ID: 1, Name: "John"}, "b": {ID: 2, Name: "Jane"}}
+
+	err := patch.ApplyBatchMap(users, map[string]batchPatch{
+		"a": {Name: optional.Some("Johnny")},
+		"c": {Name: optional.Some("Nobody")},
+	})
+
+	var be patch.BatchError[string]
+	require.ErrorAs(t, err, &be)
+	assert.Equal(t, patch.BatchError[string]{"c": patch.ErrNotFound}, be)
+
+	assert.Equal(t, map[string]user{"a": {ID: 1, Name: "Johnny"}, "b": {ID: 2, Name: "Jane"}}, users)
+
+	require.NoError(t, patch.ApplyBatchMap(users, map[string]batchPatch{"b": {Age: optional.Some(20)}}))
+	assert.Equal(t, user{ID: 2, Name: "Jane", Age: 20}, users["b"])
+}
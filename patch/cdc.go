@@ -0,0 +1,176 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// The operations of the change events, as named by Debezium.
+const (
+	OpCreate = "c"
+	OpUpdate = "u"
+)
+
+// ChangeEvent is the change-data-capture event of a patch, such as published through an outbox table.
+type ChangeEvent struct {
+	Op      string         `json:"op"`
+	Before  map[string]any `json:"before"`  // Before holds the changed fields before the patch, nil for creates.
+	After   map[string]any `json:"after"`   // After holds the changed fields after the patch.
+	Changed []string       `json:"changed"` // Changed lists the dotted JSON paths of the changed fields.
+}
+
+// NewChangeEvent returns the change event of the patch, such as applied with [Apply] or produced by [Diff],
+// turning the record before into the record after. The before record is nil for creates.
+//
+// The fields of the patch are matched with the fields of the records like [Apply] does, and the events
+// are keyed by the JSON names of the record fields, the nested patch structs producing nested objects.
+// Only the fields set in the patch and differing between the records are included, in the order
+// of the fields of the patch:
+//
+//	{"op":"u","before":{"name":"John"},"after":{"name":"Jane"},"changed":["name"]}
+func NewChangeEvent(before, after, patch any) (ChangeEvent, error) {
+	bv, av := indirect(reflect.ValueOf(before)), indirect(reflect.ValueOf(after))
+	if av.Kind() != reflect.Struct || bv.IsValid() && bv.Type() != av.Type() {
+		return ChangeEvent{}, fmt.Errorf("patch: NewChangeEvent expects records of the same struct type, got %T and %T",
+			before, after)
+	}
+
+	pv := indirect(reflect.ValueOf(patch))
+	if pv.Kind() != reflect.Struct {
+		return ChangeEvent{}, fmt.Errorf("patch: NewChangeEvent expects a struct patch, got %T", patch)
+	}
+
+	e := ChangeEvent{Op: OpUpdate, After: map[string]any{}, Changed: []string{}}
+
+	if bv.IsValid() {
+		e.Before = map[string]any{}
+	} else {
+		e.Op = OpCreate
+	}
+
+	if err := e.collect(pv, bv, av, av.Type(), nil, ""); err != nil {
+		return ChangeEvent{}, err
+	}
+
+	return e, nil
+}
+
+// collect records the changed fields of the records of the type rt set in the patch.
+// The invalid records, such as nil pointers, have no fields.
+func (e *ChangeEvent) collect(patch, before, after reflect.Value, rt reflect.Type, keys []string, prefix string) error {
+	pt := patch.Type()
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		path := prefix + pf.Name
+
+		rf, ok := targetStructField(rt, pf)
+		if !ok {
+			return fmt.Errorf("patch: field %q has no matching field in %s", path, rt)
+		}
+
+		name, _ := jsonName(rf)
+		fkeys := append(keys[:len(keys):len(keys)], name)
+
+		bf, af := recordField(before, rf), recordField(after, rf)
+		fv := patch.Field(i)
+
+		if !optional.IsType(fv.Type()) {
+			if fv.Kind() != reflect.Struct || indirectType(rf.Type).Kind() != reflect.Struct {
+				return fmt.Errorf("patch: field %q is not optional", path)
+			}
+
+			if err := e.collect(fv, indirect(bf), indirect(af), indirectType(rf.Type), fkeys, path+"."); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		state := fv.Interface().(stater).State()
+		if state == optional.StateUnset {
+			continue
+		}
+
+		if v := fv.FieldByName("V"); state == optional.StateValue && isPatchStruct(v.Type()) &&
+			indirectType(rf.Type).Kind() == reflect.Struct && v.Type() != indirectType(rf.Type) {
+			if err := e.collect(v, indirect(bf), indirect(af), indirectType(rf.Type), fkeys, path+"."); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		e.record(fkeys, bf, af)
+	}
+
+	return nil
+}
+
+// record adds the field with the values of the records unless they are equal or the field is recorded.
+func (e *ChangeEvent) record(keys []string, before, after reflect.Value) {
+	b, a := fieldValue(before), fieldValue(after)
+	path := strings.Join(keys, ".")
+
+	if e.Before != nil && reflect.DeepEqual(b, a) || e.changed(path) {
+		return
+	}
+
+	if e.Before != nil {
+		setKey(e.Before, keys, b)
+	}
+
+	setKey(e.After, keys, a)
+
+	e.Changed = append(e.Changed, path)
+}
+
+// changed reports whether the field is recorded, such as by another field of the patch targeting it.
+func (e *ChangeEvent) changed(path string) bool {
+	for _, p := range e.Changed {
+		if p == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordField returns the field of the record, the invalid Value for the invalid record.
+func recordField(record reflect.Value, f reflect.StructField) reflect.Value {
+	if !record.IsValid() {
+		return reflect.Value{}
+	}
+
+	return record.FieldByIndex(f.Index)
+}
+
+func fieldValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// setKey sets the value in the nested maps by the keys.
+func setKey(m map[string]any, keys []string, v any) {
+	for _, k := range keys[:len(keys)-1] {
+		sub, ok := m[k].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			m[k] = sub
+		}
+
+		m = sub
+	}
+
+	m[keys[len(keys)-1]] = v
+}
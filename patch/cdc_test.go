@@ -0,0 +1,120 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestNewChangeEvent(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	before := user{ID: 1, Name: "some", Email: &email, Age: 42, Address: address{City: "Moscow", Street: "Tverskaya"}}
+
+	tests := [...]struct {
+		name   string
+		before any
+		patch  userPatch
+		want   string
+	}{
+		{
+			"update",
+			before,
+			userPatch{
+				Name:    optional.Some("other"),
+				Login:   optional.Some("other"),
+				Email:   optional.Null[string](),
+				Age:     optional.Some(42),
+				Address: optional.Some(addressPatch{City: optional.Some("Paris")}),
+				State:   optional.Some("active"),
+			},
+			`{"op":"u","before":{"Name":"some","Email":"some@example.com","Address":{"City":"Moscow"},"status":""},` +
+				`"after":{"Name":"other","Email":null,"Address":{"City":"Paris"},"status":"active"},` +
+				`"changed":["Name","Email","Address.City","status"]}`,
+		},
+		{
+			"unchanged",
+			before,
+			userPatch{Name: optional.Some("some")},
+			`{"op":"u","before":{},"after":{},"changed":[]}`,
+		},
+		{
+			"create",
+			nil,
+			userPatch{Name: optional.Some("other"), Age: optional.Some(42)},
+			`{"op":"c","before":null,"after":{"Name":"other","Age":42},"changed":["Name","Age"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var after user
+
+			if tt.before != nil {
+				after = tt.before.(user)
+			}
+
+			require.NoError(t, patch.Apply(&after, tt.patch))
+
+			var before *user
+
+			if tt.before != nil {
+				b := tt.before.(user)
+				before = &b
+			}
+
+			e, err := patch.NewChangeEvent(before, after, tt.patch)
+			require.NoError(t, err)
+
+			got, err := json.Marshal(e)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestNewChangeEvent_Diff(t *testing.T) {
+	t.Parallel()
+
+	before := user{ID: 1, Name: "some", Address: address{City: "Moscow"}}
+	after := user{ID: 1, Name: "some", Address: address{City: "Paris"}}
+
+	var p userPatch
+
+	require.NoError(t, patch.Diff(before, after, &p))
+
+	e, err := patch.NewChangeEvent(before, after, p)
+	require.NoError(t, err)
+
+	assert.Equal(t, patch.ChangeEvent{
+		Op:      patch.OpUpdate,
+		Before:  map[string]any{"Address": map[string]any{"City": "Moscow"}},
+		After:   map[string]any{"Address": map[string]any{"City": "Paris"}},
+		Changed: []string{"Address.City"},
+	}, e)
+}
+
+func TestNewChangeEvent_Error(t *testing.T) {
+	t.Parallel()
+
+	type unknownPatch struct {
+		Unknown optional.Type[string]
+	}
+
+	_, err := patch.NewChangeEvent(user{}, user{}, unknownPatch{Unknown: optional.Some("x")})
+	require.EqualError(t, err, `patch: field "Unknown" has no matching field in patch_test.user`)
+
+	_, err = patch.NewChangeEvent(address{}, user{}, userPatch{})
+	require.EqualError(t, err, "patch: NewChangeEvent expects records of the same struct type, "+
+		"got patch_test.address and patch_test.user")
+}
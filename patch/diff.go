@@ -0,0 +1,126 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// Diff fills the patch pointed to by patchPtr with the fields that differ between the old and the new
+// structs, so applying the patch onto old with [Apply] produces new. The other fields of the patch are unset.
+//
+// The fields are matched like [Apply] does. A field is set to null when the new value is a nil pointer,
+// slice or map. Values are converted to the types of the patch fields the same way [Apply] converts them
+// back, nested patch structs are filled recursively.
+func Diff(old, new any, patchPtr any) error {
+	pv := reflect.ValueOf(patchPtr)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("patch: Diff expects a non-nil pointer to a struct patch, got %T", patchPtr)
+	}
+
+	ov, nv := indirect(reflect.ValueOf(old)), indirect(reflect.ValueOf(new))
+	if ov.Kind() != reflect.Struct || nv.Kind() != reflect.Struct || ov.Type() != nv.Type() {
+		return fmt.Errorf("patch: Diff expects structs of the same type, got %T and %T", old, new)
+	}
+
+	pv.Elem().Set(reflect.Zero(pv.Elem().Type()))
+
+	_, err := diffStruct(pv.Elem(), ov, nv, "")
+
+	return err
+}
+
+// diffStruct fills the patch and reports whether any field was set.
+func diffStruct(patch, ov, nv reflect.Value, prefix string) (bool, error) {
+	pt := patch.Type()
+	changed := false
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		path := prefix + pf.Name
+
+		of, ok := targetField(ov, pf)
+		if !ok {
+			return false, fmt.Errorf("patch: field %q has no matching field in %s", path, ov.Type())
+		}
+
+		nf, _ := targetField(nv, pf)
+
+		if reflect.DeepEqual(of.Interface(), nf.Interface()) {
+			continue
+		}
+
+		set, err := diffField(patch.Field(i), of, nf, path)
+		if err != nil {
+			return false, err
+		}
+
+		changed = changed || set
+	}
+
+	return changed, nil
+}
+
+func diffField(pf, of, nf reflect.Value, path string) (bool, error) {
+	if !optional.IsType(pf.Type()) {
+		if pf.Kind() == reflect.Struct && nf.Kind() == reflect.Struct {
+			return diffStruct(pf, of, nf, path+".")
+		}
+
+		return false, fmt.Errorf("patch: field %q is not optional", path)
+	}
+
+	if isNil(nf) {
+		pf.Addr().MethodByName("SetNull").Call(nil)
+
+		return true, nil
+	}
+
+	vt := pf.FieldByName("V").Type()
+	v := reflect.New(vt).Elem()
+	nv := indirect(nf)
+
+	if vt.Kind() == reflect.Struct && !optional.IsType(vt) && nv.Kind() == reflect.Struct && vt != nv.Type() {
+		ov := indirect(of)
+		if !ov.IsValid() {
+			ov = reflect.Zero(nv.Type())
+		}
+
+		if _, err := diffStruct(v, ov, nv, path+"."); err != nil {
+			return false, err
+		}
+	} else if err := assign(v, nv, path, &MergeOptions{}); err != nil {
+		return false, err
+	}
+
+	pf.Addr().MethodByName("SetValue").Call([]reflect.Value{v})
+
+	return true, nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// indirect dereferences the pointers, returning the zero Value for nil ones.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
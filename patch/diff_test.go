@@ -0,0 +1,112 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	old := user{ID: 1, Name: "some", Email: &email, Age: 42, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}}
+
+	tests := [...]struct {
+		name string
+		new  user
+		want userPatch
+	}{
+		{"same", old, userPatch{}},
+		{
+			"values",
+			user{ID: 1, Name: "other", Email: &email, Age: 43, Tags: []string{"a"}, Address: address{City: "Paris", Street: "Tverskaya"}, Status: "active"},
+			userPatch{
+				Name:    optional.Some("other"),
+				Login:   optional.Some("other"),
+				Age:     optional.Some(43),
+				Address: optional.Some(addressPatch{City: optional.Some("Paris")}),
+				State:   optional.Some("active"),
+			},
+		},
+		{
+			"null",
+			user{ID: 1, Name: "some", Age: 42, Address: address{City: "Moscow", Street: "Tverskaya"}},
+			userPatch{Email: optional.Null[string](), Tags: optional.Null[[]string]()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got userPatch
+
+			require.NoError(t, patch.Diff(old, &tt.new, &got))
+
+			assert.Equal(t, tt.want, got)
+
+			// Applying the diff onto old produces new.
+			dst := old
+			require.NoError(t, patch.Apply(&dst, got))
+			assert.Equal(t, tt.new, dst)
+		})
+	}
+}
+
+func TestDiff_Error(t *testing.T) {
+	t.Parallel()
+
+	var got userPatch
+
+	require.Error(t, patch.Diff(user{}, user{}, got))
+	require.Error(t, patch.Diff(user{}, address{}, &got))
+}
+
+func TestDiff_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name     string
+		old, new based
+	}{
+		{
+			"nested pointer",
+			based{Name: "some", Home: &address{City: "Moscow", Street: "Tverskaya"}},
+			based{Name: "some", Home: &address{City: "Paris", Street: "Tverskaya"}},
+		},
+		{
+			"nested pointer allocated",
+			based{Name: "some"},
+			based{Name: "some", Home: &address{City: "Oslo"}},
+		},
+		{
+			"embedded",
+			based{Base: &Base{Owner: "john"}, Name: "some"},
+			based{Base: &Base{Owner: "jane"}, Name: "other"},
+		},
+		{
+			"nil embedded",
+			based{Name: "some"},
+			based{Base: &Base{Owner: "jane"}, Name: "some"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var p basedPatch
+
+			require.NoError(t, patch.Diff(tt.old, tt.new, &p))
+
+			got := tt.old
+			require.NoError(t, patch.Apply(&got, p))
+			assert.Equal(t, tt.new, got)
+		})
+	}
+}
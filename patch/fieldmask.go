@@ -0,0 +1,189 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// FieldMask is the field mask of a gRPC update request, implemented by *fieldmaskpb.FieldMask.
+type FieldMask interface {
+	GetPaths() []string
+}
+
+// FromFieldMask fills the patch pointed to by patchPtr from the message of a gRPC update request, such as
+// the resource of the UpdateUserRequest, and its field mask, so gRPC and REST partial updates
+// are handled by the same patch struct and [Apply].
+//
+// The fields of the patch are matched with the fields of the message like [Apply] does, the paths of the mask
+// are matched with the names from the `protobuf` tags of the message, falling back to the names from the `json`
+// tags and the Go field names. The masked fields are set, to null when the message field is a nil pointer, slice
+// or map, and the other fields are unset. Nested paths, such as "address.city", fill the nested patch structs,
+// a masked message field replaces the nested patch struct as a whole.
+//
+// An error is returned if a path of the mask has no matching field in the patch.
+func FromFieldMask(msg any, mask FieldMask, patchPtr any) error {
+	pv := reflect.ValueOf(patchPtr)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("patch: FromFieldMask expects a non-nil pointer to a struct patch, got %T", patchPtr)
+	}
+
+	mv := indirect(reflect.ValueOf(msg))
+	if mv.Kind() != reflect.Struct {
+		return fmt.Errorf("patch: FromFieldMask expects a struct message, got %T", msg)
+	}
+
+	tree := maskTree{}
+
+	if mask != nil {
+		for _, p := range mask.GetPaths() {
+			tree.add(p)
+		}
+	}
+
+	pv.Elem().Set(reflect.Zero(pv.Elem().Type()))
+
+	return fromMask(pv.Elem(), mv, tree, "")
+}
+
+// maskTree is the tree of the paths of a field mask, the nil subtree masks the field as a whole.
+type maskTree map[string]maskTree
+
+func (m maskTree) add(path string) {
+	name, rest, nested := strings.Cut(path, ".")
+
+	sub, ok := m[name]
+
+	switch {
+	case ok && sub == nil:
+		return
+	case !nested:
+		m[name] = nil
+
+		return
+	case !ok:
+		sub = maskTree{}
+		m[name] = sub
+	}
+
+	sub.add(rest)
+}
+
+// fromMask fills the patch with the fields of the message masked by the tree, the nil tree masks all the fields.
+func fromMask(patch, msg reflect.Value, mask maskTree, prefix string) error {
+	pt := patch.Type()
+	seen := make(map[string]bool, len(mask))
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		mf, ok := targetStructField(msg.Type(), pf)
+		if !ok {
+			continue
+		}
+
+		name := maskName(mf)
+
+		sub, ok := mask[name]
+		if !ok && mask != nil {
+			continue
+		}
+
+		seen[name] = true
+
+		if err := fromMaskField(patch.Field(i), msg.FieldByIndex(mf.Index), sub, prefix+name); err != nil {
+			return err
+		}
+	}
+
+	unknown := make([]string, 0, len(mask))
+
+	for name := range mask {
+		if !seen[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("patch: field mask path %q has no matching field in %s", prefix+unknown[0], pt)
+	}
+
+	return nil
+}
+
+func fromMaskField(pf, mf reflect.Value, mask maskTree, path string) error {
+	if !optional.IsType(pf.Type()) {
+		if pf.Kind() == reflect.Struct && indirectType(mf.Type()).Kind() == reflect.Struct {
+			return fromMask(pf, valueOrZero(mf), mask, path+".")
+		}
+
+		return fmt.Errorf("patch: field %q is not optional", path)
+	}
+
+	if mask == nil && isNil(mf) {
+		pf.Addr().MethodByName("SetNull").Call(nil)
+
+		return nil
+	}
+
+	vt := pf.FieldByName("V").Type()
+	v := reflect.New(vt).Elem()
+	mt := indirectType(mf.Type())
+
+	switch {
+	case isPatchStruct(vt) && mt.Kind() == reflect.Struct && vt != mt:
+		if err := fromMask(v, valueOrZero(mf), mask, path+"."); err != nil {
+			return err
+		}
+	case mask != nil:
+		return fmt.Errorf("patch: field mask path %q: field is not a message", path)
+	default:
+		if err := assign(v, indirect(mf), path, &MergeOptions{}); err != nil {
+			return err
+		}
+	}
+
+	pf.Addr().MethodByName("SetValue").Call([]reflect.Value{v})
+
+	return nil
+}
+
+// maskName returns the name of the field in the paths of a field mask.
+func maskName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+
+	if name := tagName(f.Tag.Get("json")); name != "" {
+		return name
+	}
+
+	return f.Name
+}
+
+// valueOrZero dereferences the pointers, returning the zero value of the pointed type for nil ones.
+func valueOrZero(v reflect.Value) reflect.Value {
+	if iv := indirect(v); iv.IsValid() {
+		return iv
+	}
+
+	return reflect.Zero(indirectType(v.Type()))
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
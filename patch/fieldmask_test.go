@@ -0,0 +1,138 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+// pbAddress and pbUser mimic the structs generated by protoc-gen-go.
+type pbAddress struct {
+	City   string `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Street string `protobuf:"bytes,2,opt,name=street,proto3" json:"street,omitempty"`
+}
+
+type pbUser struct {
+	Name        string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email       *string    `protobuf:"bytes,2,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Age         int32      `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	Tags        []string   `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	HomeAddress *pbAddress `protobuf:"bytes,5,opt,name=home_address,json=homeAddress,proto3" json:"home_address,omitempty"`
+}
+
+type pbUserPatch struct {
+	Name        optional.Type[string]
+	Email       optional.Type[string]
+	Age         optional.Type[int]
+	Tags        optional.Type[[]string]
+	HomeAddress optional.Type[addressPatch]
+}
+
+type fieldMask []string
+
+func (m fieldMask) GetPaths() []string { return m }
+
+func TestFromFieldMask(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	msg := &pbUser{Name: "some", Email: &email, Age: 42, HomeAddress: &pbAddress{City: "Moscow", Street: "Tverskaya"}}
+
+	tests := [...]struct {
+		name string
+		msg  *pbUser
+		mask patch.FieldMask
+		want pbUserPatch
+	}{
+		{"nil mask", msg, nil, pbUserPatch{}},
+		{"empty", msg, fieldMask{}, pbUserPatch{}},
+		{
+			"values",
+			msg,
+			fieldMask{"name", "email", "age"},
+			pbUserPatch{Name: optional.Some("some"), Email: optional.Some(email), Age: optional.Some(42)},
+		},
+		{
+			"zero value",
+			&pbUser{},
+			fieldMask{"name", "age"},
+			pbUserPatch{Name: optional.Some(""), Age: optional.Some(0)},
+		},
+		{
+			"null",
+			&pbUser{},
+			fieldMask{"email", "tags", "home_address"},
+			pbUserPatch{Email: optional.Null[string](), Tags: optional.Null[[]string](), HomeAddress: optional.Null[addressPatch]()},
+		},
+		{
+			"whole message",
+			msg,
+			fieldMask{"home_address"},
+			pbUserPatch{HomeAddress: optional.Some(addressPatch{City: optional.Some("Moscow")})},
+		},
+		{
+			"nested",
+			msg,
+			fieldMask{"home_address.city"},
+			pbUserPatch{HomeAddress: optional.Some(addressPatch{City: optional.Some("Moscow")})},
+		},
+		{
+			"nested of nil message",
+			&pbUser{},
+			fieldMask{"home_address.city"},
+			pbUserPatch{HomeAddress: optional.Some(addressPatch{City: optional.Some("")})},
+		},
+		{
+			"nested and whole",
+			msg,
+			fieldMask{"home_address.city", "home_address"},
+			pbUserPatch{HomeAddress: optional.Some(addressPatch{City: optional.Some("Moscow")})},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := pbUserPatch{Name: optional.Some("stale")}
+
+			require.NoError(t, patch.FromFieldMask(tt.msg, tt.mask, &got))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromFieldMask_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name     string
+		msg      any
+		mask     patch.FieldMask
+		patchPtr any
+		wantErr  string
+	}{
+		{"not a pointer", pbUser{}, nil, pbUserPatch{}, "patch: FromFieldMask expects a non-nil pointer to a struct patch, got patch_test.pbUserPatch"},
+		{"not a struct", 42, nil, &pbUserPatch{}, "patch: FromFieldMask expects a struct message, got int"},
+		{"unknown", pbUser{}, fieldMask{"name", "unknown"}, &pbUserPatch{}, `patch: field mask path "unknown" has no matching field in patch_test.pbUserPatch`},
+		{"unknown nested", pbUser{}, fieldMask{"home_address.zip"}, &pbUserPatch{}, `patch: field mask path "home_address.zip" has no matching field in patch_test.addressPatch`},
+		{"not a message", pbUser{}, fieldMask{"name.first"}, &pbUserPatch{}, `patch: field mask path "name": field is not a message`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.EqualError(t, patch.FromFieldMask(tt.msg, tt.mask, tt.patchPtr), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// Operations of JSON Patch (RFC 6902) emitted by [ToJSONPatch].
+const (
+	OpAdd    = "add"
+	OpRemove = "remove"
+)
+
+// Operation is an operation of JSON Patch (RFC 6902).
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface for [Operation].
+// The value is omitted only for the remove operation, so nil values are encoded as null.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// ToJSONPatch returns the JSON Patch (RFC 6902) operations of the patch struct v, with the paths
+// of the members named after the `json` tags, with the fields of the embedded structs promoted like encoding/json
+// promotes them, and prefixed with the JSON Pointer basePath, such as "/spec".
+//
+// The fields set to values are added with [OpAdd], which replaces the existing members and creates the absent
+// ones. The values of [optional.Type] holding patch structs are added as whole objects of their set fields,
+// so their members are created with the absent parents, while the nested patch structs outside of [optional.Type]
+// produce the operations of their set fields. The fields set to null are added as null and then removed with
+// [OpRemove], so the removal does not fail on the absent members.
+func ToJSONPatch(v any, basePath string) ([]Operation, error) {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("patch: ToJSONPatch expects a struct patch, got %T", v)
+	}
+
+	return appendOperations(nil, rv, strings.TrimSuffix(basePath, "/")), nil
+}
+
+func appendOperations(ops []Operation, v reflect.Value, prefix string) []Operation {
+	for _, m := range jsonMembers(v.Type()) {
+		fv, err := v.FieldByIndexErr(m.index)
+		if err != nil {
+			continue // The field is promoted through a nil embedded pointer.
+		}
+
+		path := prefix + "/" + escapePointer(m.name)
+
+		if !optional.IsType(fv.Type()) {
+			if isPatchStruct(fv.Type()) {
+				ops = appendOperations(ops, fv, path)
+			}
+
+			continue
+		}
+
+		switch fv.Interface().(stater).State() {
+		case optional.StateNull:
+			ops = append(ops, Operation{Op: OpAdd, Path: path}, Operation{Op: OpRemove, Path: path})
+		case optional.StateValue:
+			ops = append(ops, Operation{Op: OpAdd, Path: path, Value: patchValue(fv.FieldByName("V"))})
+		}
+	}
+
+	return ops
+}
+
+// patchValue returns the value of the set field, the object of the set fields of the patch structs.
+func patchValue(v reflect.Value) any {
+	if !isPatchStruct(v.Type()) {
+		return v.Interface()
+	}
+
+	obj := map[string]any{}
+
+	for _, m := range jsonMembers(v.Type()) {
+		fv, err := v.FieldByIndexErr(m.index)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case optional.IsType(fv.Type()):
+			if fv.Interface().(stater).State() == optional.StateValue {
+				obj[m.name] = patchValue(fv.FieldByName("V"))
+			}
+		case isPatchStruct(fv.Type()):
+			if nested := patchValue(fv).(map[string]any); len(nested) != 0 {
+				obj[m.name] = nested
+			}
+		}
+	}
+
+	return obj
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointer escapes the reference token of JSON Pointer (RFC 6901).
+func escapePointer(s string) string {
+	return pointerEscaper.Replace(s)
+}
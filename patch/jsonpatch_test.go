@@ -0,0 +1,229 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestToJSONPatch(t *testing.T) {
+	t.Parallel()
+
+	type metadata struct {
+		Labels optional.Type[map[string]string] `json:"labels"`
+	}
+
+	type spec struct {
+		Replicas optional.Type[int]      `json:"replicas"`
+		Image    optional.Type[string]   `json:"image"`
+		Args     optional.Type[[]string] `json:"args"`
+		Paused   optional.Type[bool]     `json:"paused"`
+	}
+
+	type deployment struct {
+		Metadata metadata              `json:"metadata"`
+		Spec     optional.Type[spec]   `json:"spec"`
+		Note     optional.Type[string] `json:"a/b~c"`
+	}
+
+	input := deployment{
+		Metadata: metadata{Labels: optional.Null[map[string]string]()},
+		Spec:     optional.Some(spec{Replicas: optional.Some(3), Args: optional.Some([]string(nil)), Paused: optional.Some(false)}),
+		Note:     optional.Some("note"),
+	}
+
+	got, err := patch.ToJSONPatch(input, "/")
+	require.NoError(t, err)
+
+	assert.Equal(t, []patch.Operation{
+		{Op: patch.OpAdd, Path: "/metadata/labels"},
+		{Op: patch.OpRemove, Path: "/metadata/labels"},
+		{Op: patch.OpAdd, Path: "/spec", Value: map[string]any{
+			"replicas": 3,
+			"args":     []string(nil),
+			"paused":   false,
+		}},
+		{Op: patch.OpAdd, Path: "/a~1b~0c", Value: "note"},
+	}, got)
+
+	b, err := json.Marshal(got)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `[
+		{"op":"add","path":"/metadata/labels","value":null},
+		{"op":"remove","path":"/metadata/labels"},
+		{"op":"add","path":"/spec","value":{"replicas":3,"args":null,"paused":false}},
+		{"op":"add","path":"/a~1b~0c","value":"note"}
+	]`, string(b))
+
+	got, err = patch.ToJSONPatch(&input, "/items/0")
+	require.NoError(t, err)
+	assert.Equal(t, "/items/0/metadata/labels", got[0].Path)
+
+	_, err = patch.ToJSONPatch("some", "")
+	require.Error(t, err)
+}
+
+func TestToJSONPatch_Apply(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+	}
+
+	type profile struct {
+		Address optional.Type[address] `json:"address"`
+		Phone   optional.Type[string]  `json:"phone"`
+		Name    optional.Type[string]  `json:"name"`
+	}
+
+	type user struct {
+		Profile profile `json:"profile"`
+	}
+
+	p := user{Profile: profile{
+		Address: optional.Some(address{City: optional.Some("Paris"), Street: optional.Null[string]()}),
+		Phone:   optional.Null[string](),
+		Name:    optional.Some("John"),
+	}}
+
+	tests := [...]struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"absent members", `{"profile":{}}`, `{"profile":{"address":{"city":"Paris"},"name":"John"}}`},
+		{
+			"present members",
+			`{"profile":{"address":{"city":"Rome","street":"Via"},"phone":"1","name":"Jane","age":3}}`,
+			`{"profile":{"address":{"city":"Paris"},"name":"John","age":3}}`,
+		},
+	}
+
+	ops, err := patch.ToJSONPatch(p, "")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := applyJSONPatch([]byte(tt.doc), data)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	_, err = applyJSONPatch([]byte(`{}`), data)
+	require.EqualError(t, err, `op 0: path "/profile/address": parent does not exist`)
+}
+
+func TestToJSONPatch_Embedded(t *testing.T) {
+	t.Parallel()
+
+	p := embeddedPatch{
+		AuditPatch: AuditPatch{Source: optional.Some("crm")},
+		OwnerPatch: &OwnerPatch{Owner: optional.Null[string]()},
+		Name:       optional.Some("John"),
+	}
+
+	ops, err := patch.ToJSONPatch(p, "/spec")
+	require.NoError(t, err)
+
+	assert.Equal(t, []patch.Operation{
+		{Op: patch.OpAdd, Path: "/spec/source", Value: "crm"},
+		{Op: patch.OpAdd, Path: "/spec/owner"},
+		{Op: patch.OpRemove, Path: "/spec/owner"},
+		{Op: patch.OpAdd, Path: "/spec/name", Value: "John"},
+	}, ops)
+
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+
+	got, err := applyJSONPatch([]byte(`{"spec":{"source":"web","owner":"jane"}}`), data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spec":{"source":"crm","name":"John"}}`, string(got))
+
+	type wrapper struct {
+		Value optional.Type[embeddedPatch] `json:"value"`
+	}
+
+	ops, err = patch.ToJSONPatch(wrapper{Value: optional.Some(p)}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []patch.Operation{
+		{Op: patch.OpAdd, Path: "/value", Value: map[string]any{"source": "crm", "name": "John"}},
+	}, ops)
+}
+
+// applyJSONPatch applies the add and remove operations of JSON Patch (RFC 6902) onto the objects of the document,
+// failing like RFC 6902 requires on the absent parents and the removal of the absent members.
+func applyJSONPatch(doc, patchData []byte) ([]byte, error) {
+	var (
+		root any
+		ops  []struct {
+			Op    string          `json:"op"`
+			Path  string          `json:"path"`
+			Value json.RawMessage `json:"value"`
+		}
+	)
+
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(patchData, &ops); err != nil {
+		return nil, err
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+
+	for i, op := range ops {
+		tokens := strings.Split(op.Path, "/")[1:]
+		parent := root
+
+		for _, token := range tokens[:len(tokens)-1] {
+			obj, _ := parent.(map[string]any)
+			parent = obj[unescape.Replace(token)]
+		}
+
+		obj, ok := parent.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("op %d: path %q: parent does not exist", i, op.Path)
+		}
+
+		key := unescape.Replace(tokens[len(tokens)-1])
+
+		switch op.Op {
+		case "add":
+			var value any
+
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("op %d: %w", i, err)
+			}
+
+			obj[key] = value
+		case "remove":
+			if _, ok := obj[key]; !ok {
+				return nil, fmt.Errorf("op %d: path %q: member does not exist", i, op.Path)
+			}
+
+			delete(obj, key)
+		default:
+			return nil, fmt.Errorf("op %d: unsupported operation %q", i, op.Op)
+		}
+	}
+
+	return json.Marshal(root)
+}
@@ -0,0 +1,111 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// ToMergePatch returns the JSON Merge Patch (RFC 7386) of the patch struct v: the fields set in v
+// are the members of the document, the fields set to null are null, so they are deleted by the merge.
+// The members are named after the `json` tags and the fields of the embedded structs are promoted
+// like encoding/json promotes them, so the patch matches the wire shape of the struct. Nested patch structs, including the values of
+// [optional.Type], become nested objects with only their set fields.
+func ToMergePatch(v any) ([]byte, error) {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("patch: ToMergePatch expects a struct patch, got %T", v)
+	}
+
+	return json.Marshal(toMap(rv))
+}
+
+// toMap returns the members of the set fields of the patch struct v, promoting the fields
+// of the embedded structs like encoding/json.
+func toMap(v reflect.Value) map[string]any {
+	m := map[string]any{}
+
+	for _, jm := range jsonMembers(v.Type()) {
+		name := jm.name
+
+		fv, err := v.FieldByIndexErr(jm.index)
+		if err != nil {
+			continue // The field is promoted through a nil embedded pointer.
+		}
+
+		if !optional.IsType(fv.Type()) {
+			if isPatchStruct(fv.Type()) {
+				if sub := toMap(fv); len(sub) != 0 {
+					m[name] = sub
+				}
+			}
+
+			continue
+		}
+
+		switch fv.Interface().(stater).State() {
+		case optional.StateNull:
+			m[name] = nil
+		case optional.StateValue:
+			if val := fv.FieldByName("V"); isPatchStruct(val.Type()) {
+				m[name] = toMap(val)
+			} else {
+				m[name] = val.Interface()
+			}
+		}
+	}
+
+	return m
+}
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7386) onto the JSON document and returns the result.
+// Members of the patch set to null are removed from the document, objects are merged recursively and
+// any other values replace the members of the document.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	var d, p any
+
+	if err := decodeJSON(doc, &d); err != nil {
+		return nil, fmt.Errorf("patch: decode document: %w", err)
+	}
+
+	if err := decodeJSON(patch, &p); err != nil {
+		return nil, fmt.Errorf("patch: decode merge patch: %w", err)
+	}
+
+	return json.Marshal(mergePatch(d, p))
+}
+
+func mergePatch(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+
+			continue
+		}
+
+		tm[k] = mergePatch(tm[k], v)
+	}
+
+	return tm
+}
+
+// decodeJSON decodes the data keeping numbers as is.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
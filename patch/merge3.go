@@ -0,0 +1,114 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// Conflict describes a field set differently by both patches of [Merge3].
+type Conflict struct {
+	Path        string // Path is the dotted path of the Go field names of the patch, such as "Address.City".
+	MineState   optional.State
+	TheirsState optional.State
+	Mine        any // Mine is the value of mine, nil unless MineState is StateValue.
+	Theirs      any // Theirs is the value of theirs, nil unless TheirsState is StateValue.
+}
+
+// Merge3 merges two concurrent patches made against the base entity and applies them onto a copy of it.
+//
+// The base must be a struct or a pointer to a struct, the result has the same type. Mine and theirs
+// must be patch structs of the same type. A field set by only one of the patches is applied, a field set
+// by both to the same state and value is applied once. A field set by both differently is a conflict:
+// it keeps the base value and is reported for resolution. Nested patch structs, including the values
+// of [optional.Type], are merged field by field.
+func Merge3(base, mine, theirs any) (result any, conflicts []Conflict, err error) {
+	return Merge3WithOptions(base, mine, theirs, MergeOptions{})
+}
+
+// Merge3WithOptions is like [Merge3], but applies the merged patch with the strategies of the options.
+func Merge3WithOptions(base, mine, theirs any, opts MergeOptions) (result any, conflicts []Conflict, err error) {
+	bv := reflect.ValueOf(base)
+
+	isPtr := bv.Kind() == reflect.Ptr
+	if isPtr {
+		bv = bv.Elem()
+	}
+
+	if bv.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("patch: Merge3 expects a struct or a pointer to a struct, got %T", base)
+	}
+
+	mv, tv := indirect(reflect.ValueOf(mine)), indirect(reflect.ValueOf(theirs))
+	if mv.Kind() != reflect.Struct || tv.Kind() != reflect.Struct || mv.Type() != tv.Type() {
+		return nil, nil, fmt.Errorf("patch: Merge3 expects struct patches of the same type, got %T and %T", mine, theirs)
+	}
+
+	merged := reflect.New(mv.Type()).Elem()
+	conflicts = mergeStruct(merged, mv, tv, "", nil)
+
+	cp := reflect.New(bv.Type())
+	cp.Elem().Set(bv)
+
+	if err := ApplyWithOptions(cp.Interface(), merged.Interface(), opts); err != nil {
+		return nil, nil, err
+	}
+
+	if isPtr {
+		return cp.Interface(), conflicts, nil
+	}
+
+	return cp.Elem().Interface(), conflicts, nil
+}
+
+func mergeStruct(dst, mine, theirs reflect.Value, prefix string, conflicts []Conflict) []Conflict {
+	t := dst.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		path := prefix + t.Field(i).Name
+		df, mf, tf := dst.Field(i), mine.Field(i), theirs.Field(i)
+
+		if !optional.IsType(df.Type()) {
+			if isPatchStruct(df.Type()) {
+				conflicts = mergeStruct(df, mf, tf, path+".", conflicts)
+			}
+
+			continue
+		}
+
+		ms, ts := mf.Interface().(stater).State(), tf.Interface().(stater).State()
+
+		switch {
+		case ts == optional.StateUnset:
+			df.Set(mf)
+		case ms == optional.StateUnset:
+			df.Set(tf)
+		case ms == ts && reflect.DeepEqual(mf.Interface(), tf.Interface()):
+			df.Set(mf)
+		case ms == optional.StateValue && ts == optional.StateValue && isPatchStruct(df.FieldByName("V").Type()):
+			df.Set(mf)
+			df.FieldByName("V").Set(reflect.Zero(df.FieldByName("V").Type()))
+
+			conflicts = mergeStruct(df.FieldByName("V"), mf.FieldByName("V"), tf.FieldByName("V"), path+".", conflicts)
+		default:
+			c := Conflict{Path: path, MineState: ms, TheirsState: ts}
+
+			if ms == optional.StateValue {
+				c.Mine = mf.FieldByName("V").Interface()
+			}
+
+			if ts == optional.StateValue {
+				c.Theirs = tf.FieldByName("V").Interface()
+			}
+
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,53 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestMerge3(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	base := user{ID: 1, Name: "some", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}}
+
+	mine := userPatch{
+		Name:    optional.Some("mine"),
+		Age:     optional.Some(42),
+		Address: optional.Some(addressPatch{City: optional.Some("Paris")}),
+		State:   optional.Some("active"),
+	}
+
+	theirs := userPatch{
+		Name:    optional.Some("theirs"),
+		Age:     optional.Some(42),
+		Email:   optional.Null[string](),
+		Address: optional.Some(addressPatch{City: optional.Null[string]()}),
+	}
+
+	got, conflicts, err := patch.Merge3(&base, mine, &theirs)
+	require.NoError(t, err)
+
+	assert.Equal(t, &user{ID: 1, Name: "some", Age: 42, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}, Status: "active"}, got)
+	assert.Equal(t, []patch.Conflict{
+		{Path: "Name", MineState: optional.StateValue, TheirsState: optional.StateValue, Mine: "mine", Theirs: "theirs"},
+		{Path: "Address.City", MineState: optional.StateValue, TheirsState: optional.StateNull, Mine: "Paris"},
+	}, conflicts)
+
+	assert.Equal(t, "some", base.Name, "base must not be modified")
+}
+
+func TestMerge3_Error(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := patch.Merge3(1, userPatch{}, userPatch{})
+	require.Error(t, err)
+
+	_, _, err = patch.Merge3(user{}, userPatch{}, addressPatch{})
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestToMergePatch(t *testing.T) {
+	t.Parallel()
+
+	type addressPatch struct {
+		City   optional.Type[string] `json:"city"`
+		Street optional.Type[string] `json:"street"`
+	}
+
+	type userPatch struct {
+		Name    optional.Type[string]       `json:"name"`
+		Email   optional.Type[string]       `json:"email"`
+		Phone   optional.Type[string]       `json:"phone"`
+		Address optional.Type[addressPatch] `json:"address"`
+		Billing addressPatch                `json:"billing"`
+		Skipped optional.Type[string]       `json:"-"`
+	}
+
+	tests := [...]struct {
+		name  string
+		input any
+		want  string
+	}{
+		{"empty", userPatch{}, `{}`},
+		{
+			"set",
+			&userPatch{
+				Name:    optional.Some("some"),
+				Email:   optional.Null[string](),
+				Address: optional.Some(addressPatch{City: optional.Some("Paris"), Street: optional.Null[string]()}),
+				Billing: addressPatch{City: optional.Some("Moscow")},
+				Skipped: optional.Some("skipped"),
+			},
+			`{"name":"some","email":null,"address":{"city":"Paris","street":null},"billing":{"city":"Moscow"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.ToMergePatch(tt.input)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	_, err := patch.ToMergePatch(1)
+	require.Error(t, err)
+}
+
+type AuditPatch struct {
+	Source optional.Type[string] `json:"source"`
+}
+
+type OwnerPatch struct {
+	Owner optional.Type[string] `json:"owner"`
+}
+
+type embeddedPatch struct {
+	AuditPatch
+	*OwnerPatch
+	Name optional.Type[string] `json:"name"`
+}
+
+func TestToMergePatch_Embedded(t *testing.T) {
+	t.Parallel()
+
+	got, err := patch.ToMergePatch(embeddedPatch{
+		AuditPatch: AuditPatch{Source: optional.Some("crm")},
+		Name:       optional.Null[string](),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"source":"crm","name":null}`, string(got))
+
+	got, err = patch.ToMergePatch(embeddedPatch{OwnerPatch: &OwnerPatch{Owner: optional.Some("john")}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"owner":"john"}`, string(got))
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	t.Parallel()
+
+	// The examples from the appendix of RFC 7386.
+	tests := [...]struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"n":12345678901234567890}`, `{}`, `{"n":12345678901234567890}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			got, err := patch.ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	_, err := patch.ApplyMergePatch([]byte(`{`), []byte(`{}`))
+	require.Error(t, err)
+
+	_, err = patch.ApplyMergePatch([]byte(`{}`), []byte(`{`))
+	require.Error(t, err)
+}
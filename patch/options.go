@@ -0,0 +1,40 @@
+package patch
+
+import (
+	"reflect"
+
+	"github.com/micronull/optional"
+)
+
+// NullStrategy defines what a field set to null in the patch does to the target field.
+type NullStrategy uint8
+
+const (
+	// NullWins resets the target field to zero.
+	NullWins NullStrategy = iota
+	// ValueWins keeps the target field if it holds a non-zero value.
+	ValueWins
+)
+
+// SliceStrategy defines how a slice set in the patch is applied to the target slice.
+type SliceStrategy uint8
+
+const (
+	// SliceReplace replaces the target slice.
+	SliceReplace SliceStrategy = iota
+	// SliceAppend appends the elements to the target slice.
+	SliceAppend
+)
+
+// MergeOptions configures how patches are applied by [ApplyWithOptions] and [Merge3WithOptions].
+// The zero value applies patches like [Apply] does.
+type MergeOptions struct {
+	Null   NullStrategy
+	Slices SliceStrategy
+
+	// Field, if not nil, is called for every field set in the patch before the strategies are applied,
+	// with the dotted path of the Go field names of the patch, the state and the value of the field,
+	// which is nil unless the state is StateValue, and the settable target field. If it returns true,
+	// the field is considered applied and the strategies are skipped.
+	Field func(path string, state optional.State, value any, target reflect.Value) (bool, error)
+}
@@ -0,0 +1,92 @@
+package patch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+func TestApplyWithOptions(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+
+	p := userPatch{
+		Name:  optional.Some("other"),
+		Email: optional.Null[string](),
+		Tags:  optional.Some([]string{"b"}),
+		Age:   optional.Null[int](),
+	}
+
+	tests := [...]struct {
+		name string
+		opts patch.MergeOptions
+		want user
+	}{
+		{"default", patch.MergeOptions{}, user{Name: "other", Tags: []string{"b"}}},
+		{"value wins", patch.MergeOptions{Null: patch.ValueWins}, user{Name: "other", Email: &email, Tags: []string{"b"}, Age: 42}},
+		{"slice append", patch.MergeOptions{Slices: patch.SliceAppend}, user{Name: "other", Tags: []string{"a", "b"}}},
+		{
+			"field",
+			patch.MergeOptions{
+				Field: func(path string, state optional.State, value any, target reflect.Value) (bool, error) {
+					if path != "Name" {
+						return false, nil
+					}
+
+					assert.Equal(t, optional.StateValue, state)
+					target.SetString("custom " + value.(string))
+
+					return true, nil
+				},
+			},
+			user{Name: "custom other", Tags: []string{"b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := user{Name: "some", Email: &email, Tags: []string{"a"}, Age: 42}
+
+			require.NoError(t, patch.ApplyWithOptions(&dst, p, tt.opts))
+
+			assert.Equal(t, tt.want, dst)
+		})
+	}
+}
+
+func TestApplyWithOptions_FieldError(t *testing.T) {
+	t.Parallel()
+
+	errExpect := errors.New("some error")
+
+	opts := patch.MergeOptions{
+		Field: func(string, optional.State, any, reflect.Value) (bool, error) {
+			return false, errExpect
+		},
+	}
+
+	var dst user
+
+	err := patch.ApplyWithOptions(&dst, userPatch{Name: optional.Some("some")}, opts)
+	require.ErrorIs(t, err, errExpect)
+}
+
+func TestMerge3WithOptions(t *testing.T) {
+	t.Parallel()
+
+	base := user{Tags: []string{"a"}}
+
+	got, conflicts, err := patch.Merge3WithOptions(base, userPatch{Tags: optional.Some([]string{"b"})}, userPatch{},
+		patch.MergeOptions{Slices: patch.SliceAppend})
+	require.NoError(t, err)
+
+	assert.Empty(t, conflicts)
+	assert.Equal(t, user{Tags: []string{"a", "b"}}, got)
+}
@@ -0,0 +1,420 @@
+// Package patch applies patches made of optional values onto plain domain structs.
+//
+// A patch is a struct with [optional.Type] fields, such as decoded from the body of a PATCH request.
+// Only the fields set in the patch are applied, the fields set to null clear the target fields.
+package patch
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/micronull/optional"
+)
+
+// stater is implemented by every [optional.Type] value.
+type stater interface {
+	State() optional.State
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Apply copies the fields set in the patch onto the struct pointed to by dst.
+//
+// The fields of the patch are matched with the fields of dst by the name from the `patch` tag,
+// falling back to the Go field name and then to the name from the `json` tag. The fields set to null
+// reset the target fields to zero, clearing pointers, slices and maps. Values are assigned to fields
+// of the same type, converted to pointers, to types of the same kind or to numbers of other sizes.
+// Nested structs of the patch, including the values of [optional.Type], are applied onto the nested
+// target structs recursively, including the structs pointed to by the target fields and the embedded ones,
+// the nil pointers are allocated.
+//
+// An error is returned if a field of the patch has no matching field in dst.
+func Apply(dst any, patch any) error {
+	return ApplyWithOptions(dst, patch, MergeOptions{})
+}
+
+// ApplyWithOptions is like [Apply], but uses the strategies of the options.
+func ApplyWithOptions(dst any, patch any, opts MergeOptions) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("patch: ApplyWithOptions expects a non-nil pointer to a struct, got %T", dst)
+	}
+
+	pv := reflect.ValueOf(patch)
+	for pv.Kind() == reflect.Ptr && !pv.IsNil() {
+		pv = pv.Elem()
+	}
+
+	if pv.Kind() != reflect.Struct {
+		return fmt.Errorf("patch: ApplyWithOptions expects a struct patch, got %T", patch)
+	}
+
+	return applyStruct(dv.Elem(), pv, "", &opts)
+}
+
+func applyStruct(dst, patch reflect.Value, prefix string, o *MergeOptions) error {
+	pt := patch.Type()
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		path := prefix + pf.Name
+
+		f, ok := targetStructField(dst.Type(), pf)
+		if !ok {
+			return fmt.Errorf("patch: field %q has no matching field in %s", path, dst.Type())
+		}
+
+		// The unset fields are skipped before the target is resolved, so nil embedded pointers are left alone.
+		if isUnset(patch.Field(i)) {
+			continue
+		}
+
+		df, err := settableField(dst, f.Index, path)
+		if err != nil {
+			return err
+		}
+
+		if err := applyField(df, patch.Field(i), path, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isUnset reports whether the patch field sets nothing: an unset value or a nested patch without set fields.
+func isUnset(pf reflect.Value) bool {
+	if s, ok := pf.Interface().(stater); ok {
+		return s.State() == optional.StateUnset
+	}
+
+	return pf.IsZero()
+}
+
+// settableField returns the field of dst at the index, allocating the nil embedded pointers on the way.
+func settableField(dst reflect.Value, index []int, path string) (reflect.Value, error) {
+	v := dst
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("patch: field %q: cannot set embedded pointer to unexported %s",
+						path, v.Type().Elem())
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, nil
+}
+
+func applyField(df, pf reflect.Value, path string, o *MergeOptions) error {
+	if !optional.IsType(pf.Type()) {
+		if pf.Kind() == reflect.Struct && df.Kind() == reflect.Struct && pf.Type() != df.Type() {
+			return applyStruct(df, pf, path+".", o)
+		}
+
+		return fmt.Errorf("patch: field %q is not optional", path)
+	}
+
+	state := pf.Interface().(stater).State()
+	if state == optional.StateUnset {
+		return nil
+	}
+
+	v := pf.FieldByName("V")
+
+	if o.Field != nil {
+		var value any
+		if state == optional.StateValue {
+			value = v.Interface()
+		}
+
+		if handled, err := o.Field(path, state, value, df); handled || err != nil {
+			return err
+		}
+	}
+
+	if state == optional.StateNull {
+		if o.Null == ValueWins && !df.IsZero() {
+			return nil
+		}
+
+		df.Set(reflect.Zero(df.Type()))
+
+		return nil
+	}
+
+	if o.Slices == SliceAppend && df.Kind() == reflect.Slice && v.Kind() == reflect.Slice {
+		tail := reflect.New(df.Type()).Elem()
+
+		if err := assign(tail, v, path, o); err != nil {
+			return err
+		}
+
+		df.Set(reflect.AppendSlice(df, tail))
+
+		return nil
+	}
+
+	return assign(df, v, path, o)
+}
+
+// assign stores the value v into the target field.
+func assign(df, v reflect.Value, path string, o *MergeOptions) error {
+	switch {
+	case v.Type().AssignableTo(df.Type()):
+		df.Set(v)
+	case df.Kind() == reflect.Ptr && v.Kind() != reflect.Ptr:
+		// Nested patches are applied onto the existing struct to keep the fields they do not touch.
+		if !df.IsNil() && v.Kind() == reflect.Struct && !v.Type().AssignableTo(df.Type().Elem()) {
+			return assign(df.Elem(), v, path, o)
+		}
+
+		p := reflect.New(df.Type().Elem())
+
+		if err := assign(p.Elem(), v, path, o); err != nil {
+			return err
+		}
+
+		df.Set(p)
+	case v.Kind() == reflect.Struct && df.Kind() == reflect.Struct:
+		return applyStruct(df, v, path+".", o)
+	case kindClass(v.Kind()) == kindClass(df.Kind()) && v.Type().ConvertibleTo(df.Type()):
+		if overflows(v, df) {
+			return fmt.Errorf("patch: field %q: value %v overflows %s", path, v, df.Type())
+		}
+
+		df.Set(v.Convert(df.Type()))
+	default:
+		return fmt.Errorf("patch: field %q: cannot assign %s to %s", path, v.Type(), df.Type())
+	}
+
+	return nil
+}
+
+// kindClass groups the kinds of numbers, so values can be converted between sizes.
+func kindClass(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32:
+		return reflect.Float64
+	}
+
+	return k
+}
+
+// overflows reports whether the number v does not fit into the number df of the same kind class.
+func overflows(v, df reflect.Value) bool {
+	switch kindClass(df.Kind()) {
+	case reflect.Int:
+		return df.OverflowInt(v.Int())
+	case reflect.Uint:
+		return df.OverflowUint(v.Uint())
+	case reflect.Float64:
+		f := v.Float()
+
+		return !math.IsInf(f, 0) && df.OverflowFloat(f)
+	}
+
+	return false
+}
+
+// targetField finds the field of the struct dst matching the patch field for reading.
+// The fields promoted through nil embedded pointers read as zero.
+func targetField(dst reflect.Value, pf reflect.StructField) (reflect.Value, bool) {
+	f, ok := targetStructField(dst.Type(), pf)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	v, err := dst.FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Zero(f.Type), true
+	}
+
+	return v, true
+}
+
+// targetStructField finds the field of the struct type dt matching the patch field.
+func targetStructField(dt reflect.Type, pf reflect.StructField) (reflect.StructField, bool) {
+	name := pf.Name
+	if tag, ok := pf.Tag.Lookup("patch"); ok && tag != "" {
+		name = tag
+	}
+
+	if f, ok := dt.FieldByName(name); ok && f.IsExported() {
+		return f, true
+	}
+
+	jsonName := tagName(pf.Tag.Get("json"))
+	if jsonName == "" {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < dt.NumField(); i++ {
+		if f := dt.Field(i); f.IsExported() && tagName(f.Tag.Get("json")) == jsonName {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// jsonName returns the name of the member of the field like encoding/json does.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	if name := tagName(tag); name != "" {
+		return name, true
+	}
+
+	return f.Name, true
+}
+
+// jsonMember is a field of a struct as encoding/json sees it.
+type jsonMember struct {
+	name   string
+	index  []int
+	tagged bool
+}
+
+// jsonMembers returns the members of the struct type t as encoding/json sees them: the fields of the embedded
+// structs without a name in the tag are promoted, and of the fields of the same name the shallowest one wins,
+// then the tagged one, the ambiguous ones are dropped.
+func jsonMembers(t reflect.Type) []jsonMember {
+	all := appendMembers(nil, t, nil, map[reflect.Type]bool{t: true})
+
+	byName := make(map[string][]int, len(all))
+	for i, m := range all {
+		byName[m.name] = append(byName[m.name], i)
+	}
+
+	members := make([]jsonMember, 0, len(all))
+
+	for i, m := range all {
+		if dominantMember(all, byName[m.name]) == i {
+			members = append(members, m)
+		}
+	}
+
+	return members
+}
+
+func appendMembers(members []jsonMember, t reflect.Type, index []int, path map[reflect.Type]bool) []jsonMember {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && tagName(tag) == "" && ft.Kind() == reflect.Struct && !optional.IsType(ft) &&
+			(f.IsExported() || f.Type.Kind() == reflect.Struct) {
+			if !path[ft] {
+				path[ft] = true
+				members = appendMembers(members, ft, append(index[:len(index):len(index)], i), path)
+				delete(path, ft)
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _ := jsonName(f)
+
+		members = append(members, jsonMember{
+			name:   name,
+			index:  append(index[:len(index):len(index)], i),
+			tagged: tagName(tag) != "",
+		})
+	}
+
+	return members
+}
+
+// dominantMember returns the position of the member winning among the members at the positions of the same name,
+// or -1 if none of them wins.
+func dominantMember(members []jsonMember, positions []int) int {
+	depth := len(members[positions[0]].index)
+	for _, i := range positions[1:] {
+		if d := len(members[i].index); d < depth {
+			depth = d
+		}
+	}
+
+	var shallow, tagged []int
+
+	for _, i := range positions {
+		if len(members[i].index) != depth {
+			continue
+		}
+
+		shallow = append(shallow, i)
+		if members[i].tagged {
+			tagged = append(tagged, i)
+		}
+	}
+
+	switch {
+	case len(tagged) == 1:
+		return tagged[0]
+	case len(tagged) == 0 && len(shallow) == 1:
+		return shallow[0]
+	}
+
+	return -1
+}
+
+// isPatchStruct reports whether t is a struct of patch fields, rather than a value with its own encoding.
+func isPatchStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || optional.IsType(t) {
+		return false
+	}
+
+	pt := reflect.PtrTo(t)
+
+	return !pt.Implements(jsonMarshalerType) && !pt.Implements(textMarshalerType)
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,178 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+type address struct {
+	City   string
+	Street string
+}
+
+type user struct {
+	ID      int
+	Name    string
+	Email   *string
+	Age     int64
+	Tags    []string
+	Address address
+	Status  string `json:"status"`
+}
+
+type addressPatch struct {
+	City optional.Type[string]
+}
+
+type userPatch struct {
+	Name    optional.Type[string]
+	Email   optional.Type[string]
+	Age     optional.Type[int]
+	Tags    optional.Type[[]string]
+	Address optional.Type[addressPatch]
+	State   optional.Type[string] `json:"status"`
+	Login   optional.Type[string] `patch:"Name" json:"-"`
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	email := "some@example.com"
+	other := "other@example.com"
+
+	tests := [...]struct {
+		name  string
+		patch userPatch
+		want  user
+	}{
+		{"empty", userPatch{}, user{ID: 1, Name: "some", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}}},
+		{
+			"values",
+			userPatch{
+				Name:    optional.Some("other"),
+				Email:   optional.Some(other),
+				Age:     optional.Some(42),
+				Tags:    optional.Some([]string{"b"}),
+				Address: optional.Some(addressPatch{City: optional.Some("Paris")}),
+				State:   optional.Some("active"),
+			},
+			user{ID: 1, Name: "other", Email: &other, Age: 42, Tags: []string{"b"}, Address: address{City: "Paris", Street: "Tverskaya"}, Status: "active"},
+		},
+		{
+			"null",
+			userPatch{Email: optional.Null[string](), Tags: optional.Null[[]string](), Address: optional.Null[addressPatch]()},
+			user{ID: 1, Name: "some"},
+		},
+		{"tag", userPatch{Login: optional.Some("login")}, user{ID: 1, Name: "login", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := user{ID: 1, Name: "some", Email: &email, Tags: []string{"a"}, Address: address{City: "Moscow", Street: "Tverskaya"}}
+
+			require.NoError(t, patch.Apply(&dst, tt.patch))
+
+			assert.Equal(t, tt.want, dst)
+		})
+	}
+}
+
+func TestApply_Error(t *testing.T) {
+	t.Parallel()
+
+	type unknown struct {
+		Unknown optional.Type[string]
+	}
+
+	type mismatch struct {
+		Name optional.Type[int]
+	}
+
+	var dst user
+
+	require.Error(t, patch.Apply(dst, userPatch{}))
+	require.Error(t, patch.Apply(&dst, 1))
+	require.Error(t, patch.Apply(&dst, unknown{Unknown: optional.Some("some")}))
+	require.Error(t, patch.Apply(&dst, mismatch{Name: optional.Some(1)}))
+}
+
+func TestApply_Numbers(t *testing.T) {
+	t.Parallel()
+
+	type target struct {
+		Small int8
+		Count uint8
+		Ratio float32
+		Whole int
+	}
+
+	type numbers struct {
+		Small optional.Type[int64]
+		Count optional.Type[uint64]
+		Ratio optional.Type[float64]
+		Whole optional.Type[float64]
+	}
+
+	var dst target
+
+	require.NoError(t, patch.Apply(&dst, numbers{Small: optional.Some(int64(-128)), Count: optional.Some(uint64(255))}))
+	assert.Equal(t, target{Small: -128, Count: 255}, dst)
+
+	require.EqualError(t, patch.Apply(&dst, numbers{Small: optional.Some(int64(300))}),
+		`patch: field "Small": value 300 overflows int8`)
+	require.EqualError(t, patch.Apply(&dst, numbers{Count: optional.Some(uint64(256))}),
+		`patch: field "Count": value 256 overflows uint8`)
+	require.EqualError(t, patch.Apply(&dst, numbers{Ratio: optional.Some(1e39)}),
+		`patch: field "Ratio": value 1e+39 overflows float32`)
+	require.EqualError(t, patch.Apply(&dst, numbers{Whole: optional.Some(1.5)}),
+		`patch: field "Whole": cannot assign float64 to int`)
+	assert.Equal(t, target{Small: -128, Count: 255}, dst)
+}
+
+type Base struct {
+	Version int
+	Owner   string
+}
+
+type based struct {
+	*Base
+	Name string
+	Home *address
+}
+
+type basedPatch struct {
+	Name  optional.Type[string]
+	Home  optional.Type[addressPatch]
+	Owner optional.Type[string]
+}
+
+func TestApply_NestedPointer(t *testing.T) {
+	t.Parallel()
+
+	dst := based{Name: "some", Home: &address{City: "Moscow", Street: "Tverskaya"}}
+
+	require.NoError(t, patch.Apply(&dst, basedPatch{Home: optional.Some(addressPatch{City: optional.Some("Paris")})}))
+	assert.Equal(t, &address{City: "Paris", Street: "Tverskaya"}, dst.Home)
+
+	dst.Home = nil
+
+	require.NoError(t, patch.Apply(&dst, basedPatch{Home: optional.Some(addressPatch{City: optional.Some("Oslo")})}))
+	assert.Equal(t, &address{City: "Oslo"}, dst.Home)
+}
+
+func TestApply_NilEmbedded(t *testing.T) {
+	t.Parallel()
+
+	dst := based{Name: "some"}
+
+	require.NoError(t, patch.Apply(&dst, basedPatch{Name: optional.Some("other")}))
+	assert.Equal(t, based{Name: "other"}, dst, "the nil embedded pointer is left alone")
+
+	require.NoError(t, patch.Apply(&dst, basedPatch{Owner: optional.Some("admin")}))
+	assert.Equal(t, based{Base: &Base{Owner: "admin"}, Name: "other"}, dst)
+}
@@ -0,0 +1,379 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/micronull/optional"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// ProtoMessage is a protobuf message written through its reflection API, such as protoreflect.Message adapted
+// by a few lines of glue shown in the README. It mirrors the part of protoreflect.Message used by [ApplyToProto]
+// with plain Go types, so the package stays free of the protobuf module.
+type ProtoMessage interface {
+	// ProtoField returns the field named by its proto name or its JSON name, ok is false if there is none.
+	ProtoField(name string) (f ProtoField, ok bool)
+	// SetProto sets the scalar or the repeated field to the value of the Go type of the field.
+	// Setting a field of a oneof clears the other fields of the oneof.
+	SetProto(f ProtoField, v any) error
+	// ClearProto clears the field.
+	ClearProto(f ProtoField)
+	// MutableProto returns the message of the message field, allocating it when unset.
+	MutableProto(f ProtoField) ProtoMessage
+}
+
+// ProtoField is a field of a [ProtoMessage].
+type ProtoField struct {
+	Name string // Name is the proto name of the field, such as "home_address".
+	// Message is the full name of the message type of the field, such as "google.protobuf.Timestamp",
+	// empty for the scalar and the repeated fields.
+	Message string
+	// Type is the Go type of the values of the scalar and the repeated fields, such as int32, []string or
+	// protoreflect.EnumNumber. It is nil for the message fields.
+	Type reflect.Type
+	Desc any // Desc is the descriptor of the field kept by the implementation, such as protoreflect.FieldDescriptor.
+}
+
+// ApplyToProto copies the fields set in the patch onto the protobuf message msg, such as decoded from the body
+// of a REST PATCH request, so gRPC services need no hand-written mapping layer.
+//
+// The message is written through [ProtoMessage] when it implements it, supporting any message, including
+// the ones of the opaque API and the fields of oneofs. Otherwise, msg must be a pointer to the struct generated
+// by protoc-gen-go with the open struct API, accessed by reflection: the fields of oneofs are not supported then.
+//
+// The fields of the patch are matched by their JSON names with the proto names and the JSON names of the fields
+// of the message, and for the generated structs like [Apply] does as well. The fields set to null clear the message
+// fields. Nested patch structs are applied onto the nested messages, allocated when unset. Values are stored into
+// the well-known wrapper messages, such as google.protobuf.StringValue, time.Time into google.protobuf.Timestamp
+// and time.Duration into google.protobuf.Duration.
+//
+// An error is returned if a field of the patch has no matching field in the message.
+func ApplyToProto(msg any, patch any) error {
+	pv := indirect(reflect.ValueOf(patch))
+	if pv.Kind() != reflect.Struct {
+		return fmt.Errorf("patch: ApplyToProto expects a struct patch, got %T", patch)
+	}
+
+	if pm, ok := msg.(ProtoMessage); ok {
+		return applyProtoMessage(pm, pv, "")
+	}
+
+	mv := reflect.ValueOf(msg)
+	if mv.Kind() != reflect.Ptr || mv.IsNil() || !isMessage(mv.Elem().Type()) {
+		return fmt.Errorf("patch: ApplyToProto expects a ProtoMessage or a non-nil pointer to a message, got %T", msg)
+	}
+
+	return applyProto(mv.Elem(), pv, "")
+}
+
+func applyProto(msg, patch reflect.Value, prefix string) error {
+	pt := patch.Type()
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		path := prefix + pf.Name
+
+		mf, ok := protoField(msg.Type(), pf)
+		if !ok && hasOneofs(msg.Type()) {
+			return fmt.Errorf("patch: field %q has no matching field in %s, the fields of oneofs are set through "+
+				"ProtoMessage only", path, msg.Type())
+		}
+
+		if !ok {
+			return fmt.Errorf("patch: field %q has no matching field in %s", path, msg.Type())
+		}
+
+		if err := applyProtoField(msg.FieldByIndex(mf.Index), patch.Field(i), path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyProtoField(mf, pf reflect.Value, path string) error {
+	if !optional.IsType(pf.Type()) {
+		if pf.Kind() == reflect.Struct && isMessage(indirectType(mf.Type())) {
+			return applyProto(allocate(mf), pf, path+".")
+		}
+
+		return fmt.Errorf("patch: field %q is not optional", path)
+	}
+
+	switch pf.Interface().(stater).State() {
+	case optional.StateUnset:
+		return nil
+	case optional.StateNull:
+		mf.Set(reflect.Zero(mf.Type()))
+
+		return nil
+	}
+
+	v := pf.FieldByName("V")
+	mt := indirectType(mf.Type())
+
+	switch {
+	case v.Type() == timeType && isTimestamp(mt):
+		t := v.Interface().(time.Time)
+
+		setSecondsNanos(allocate(mf), t.Unix(), int64(t.Nanosecond()))
+	case v.Type() == durationType && isDuration(mt):
+		d := v.Interface().(time.Duration)
+
+		setSecondsNanos(allocate(mf), int64(d/time.Second), int64(d%time.Second))
+	case isWrapper(mt) && !isWrapper(v.Type()):
+		return assign(allocate(mf).FieldByName("Value"), v, path, &MergeOptions{})
+	case isPatchStruct(v.Type()) && isMessage(mt) && v.Type() != mt:
+		return applyProto(allocate(mf), v, path+".")
+	default:
+		return assign(mf, v, path, &MergeOptions{})
+	}
+
+	return nil
+}
+
+// protoField finds the field of the message type mt matching the patch field.
+func protoField(mt reflect.Type, pf reflect.StructField) (reflect.StructField, bool) {
+	if f, ok := targetStructField(mt, pf); ok {
+		return f, true
+	}
+
+	name, ok := jsonName(pf)
+	if !ok {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < mt.NumField(); i++ {
+		f := mt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		for _, opt := range strings.Split(f.Tag.Get("protobuf"), ",") {
+			if opt == "name="+name || opt == "json="+name {
+				return f, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// allocate returns the message struct of the field, allocating the nil pointers.
+func allocate(f reflect.Value) reflect.Value {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+
+		f = f.Elem()
+	}
+
+	return f
+}
+
+// isMessage reports whether t is the struct of a message generated by protoc-gen-go with the open struct API.
+func isMessage(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("protobuf"); ok && t.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasOneofs reports whether the message type t has oneofs, held by the interface fields of the generated structs.
+func hasOneofs(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("protobuf_oneof"); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWrapper reports whether t is a well-known wrapper message, such as wrapperspb.StringValue.
+func isWrapper(t reflect.Type) bool {
+	return hasProtoFields(t, "Value")
+}
+
+func isTimestamp(t reflect.Type) bool {
+	return t.Name() == "Timestamp" && hasProtoFields(t, "Seconds", "Nanos")
+}
+
+func isDuration(t reflect.Type) bool {
+	return t.Name() == "Duration" && hasProtoFields(t, "Seconds", "Nanos")
+}
+
+// hasProtoFields reports whether the exported fields of the message type t are exactly the named ones.
+func hasProtoFields(t reflect.Type, names ...string) bool {
+	if !isMessage(t) {
+		return false
+	}
+
+	n := 0
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			n++
+		}
+	}
+
+	for _, name := range names {
+		if f, ok := t.FieldByName(name); !ok || !f.IsExported() {
+			return false
+		}
+	}
+
+	return n == len(names)
+}
+
+func setSecondsNanos(v reflect.Value, seconds, nanos int64) {
+	v.FieldByName("Seconds").SetInt(seconds)
+	v.FieldByName("Nanos").SetInt(nanos)
+}
+
+func applyProtoMessage(msg ProtoMessage, patch reflect.Value, prefix string) error {
+	pt := patch.Type()
+
+	for i := 0; i < pt.NumField(); i++ {
+		pf := pt.Field(i)
+		if !pf.IsExported() || pf.Tag.Get("patch") == "-" {
+			continue
+		}
+
+		path := prefix + pf.Name
+
+		f, ok := protoMessageField(msg, pf)
+		if !ok {
+			return fmt.Errorf("patch: field %q has no matching field in the message", path)
+		}
+
+		if err := applyProtoMessageField(msg, f, patch.Field(i), path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyProtoMessageField(msg ProtoMessage, f ProtoField, pf reflect.Value, path string) error {
+	if !optional.IsType(pf.Type()) {
+		if pf.Kind() == reflect.Struct && f.Message != "" {
+			return applyProtoMessage(msg.MutableProto(f), pf, path+".")
+		}
+
+		return fmt.Errorf("patch: field %q is not optional", path)
+	}
+
+	switch pf.Interface().(stater).State() {
+	case optional.StateUnset:
+		return nil
+	case optional.StateNull:
+		msg.ClearProto(f)
+
+		return nil
+	}
+
+	v := pf.FieldByName("V")
+
+	switch {
+	case v.Type() == timeType && f.Message == "google.protobuf.Timestamp":
+		t := v.Interface().(time.Time)
+
+		return setSecondsNanosProto(msg.MutableProto(f), t.Unix(), int64(t.Nanosecond()), path)
+	case v.Type() == durationType && f.Message == "google.protobuf.Duration":
+		d := v.Interface().(time.Duration)
+
+		return setSecondsNanosProto(msg.MutableProto(f), int64(d/time.Second), int64(d%time.Second), path)
+	case wrapperMessages[f.Message]:
+		m := msg.MutableProto(f)
+
+		vf, ok := m.ProtoField("value")
+		if !ok {
+			return fmt.Errorf("patch: field %q: %s has no value field", path, f.Message)
+		}
+
+		return setProto(m, vf, v, path)
+	case f.Message != "" && isPatchStruct(v.Type()):
+		return applyProtoMessage(msg.MutableProto(f), v, path+".")
+	case f.Message != "":
+		return fmt.Errorf("patch: field %q: cannot assign %s to %s", path, v.Type(), f.Message)
+	}
+
+	return setProto(msg, f, v, path)
+}
+
+// wrapperMessages are the full names of the well-known wrapper messages.
+var wrapperMessages = map[string]bool{
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+}
+
+// protoMessageField finds the field of the message matching the patch field by the JSON name or the Go name.
+func protoMessageField(msg ProtoMessage, pf reflect.StructField) (ProtoField, bool) {
+	if name, ok := jsonName(pf); ok {
+		if f, ok := msg.ProtoField(name); ok {
+			return f, true
+		}
+	}
+
+	return msg.ProtoField(pf.Name)
+}
+
+// setProto sets the scalar or the repeated field to the value v converted to the Go type of the field.
+func setProto(msg ProtoMessage, f ProtoField, v reflect.Value, path string) error {
+	if f.Type == nil {
+		return fmt.Errorf("patch: field %q has no Go type", path)
+	}
+
+	dv := reflect.New(f.Type).Elem()
+	if err := assign(dv, v, path, &MergeOptions{}); err != nil {
+		return err
+	}
+
+	if err := msg.SetProto(f, dv.Interface()); err != nil {
+		return fmt.Errorf("patch: field %q: %w", path, err)
+	}
+
+	return nil
+}
+
+func setSecondsNanosProto(msg ProtoMessage, seconds, nanos int64, path string) error {
+	for name, v := range map[string]int64{"seconds": seconds, "nanos": nanos} {
+		f, ok := msg.ProtoField(name)
+		if !ok {
+			return fmt.Errorf("patch: field %q: message has no %s field", path, name)
+		}
+
+		if err := setProto(msg, f, reflect.ValueOf(v), path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package patch_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+	"github.com/micronull/optional/patch"
+)
+
+// The messages mimic the structs generated by protoc-gen-go, including the well-known types.
+type (
+	StringValue struct {
+		state int
+		Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	}
+
+	Timestamp struct {
+		state   int
+		Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+		Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	}
+
+	Duration struct {
+		state   int
+		Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+		Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	}
+
+	pbProfile struct {
+		state       int
+		Name        string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+		Nickname    *StringValue `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+		Age         *int32       `protobuf:"varint,3,opt,name=age,proto3,oneof" json:"age,omitempty"`
+		Tags        []string     `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+		HomeAddress *pbAddress   `protobuf:"bytes,5,opt,name=home_address,json=homeAddress,proto3" json:"home_address,omitempty"`
+		UpdatedAt   *Timestamp   `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+		Timeout     *Duration    `protobuf:"bytes,7,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	}
+)
+
+type profilePatch struct {
+	Name     optional.Type[string]        `json:"name"`
+	Nickname optional.Type[string]        `json:"nickname"`
+	Age      optional.Type[int]           `json:"age"`
+	Tags     optional.Type[[]string]      `json:"tags"`
+	Home     optional.Type[addressPatch]  `json:"homeAddress"`
+	Updated  optional.Type[time.Time]     `json:"updated_at"`
+	Timeout  optional.Type[time.Duration] `json:"timeout"`
+}
+
+func TestApplyToProto(t *testing.T) {
+	t.Parallel()
+
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	age := int32(40)
+
+	tests := [...]struct {
+		name  string
+		patch profilePatch
+		want  pbProfile
+	}{
+		{"empty", profilePatch{}, pbProfile{Name: "some", Age: &age, Tags: []string{"a"}}},
+		{
+			"values",
+			profilePatch{
+				Name:     optional.Some("other"),
+				Nickname: optional.Some("nick"),
+				Age:      optional.Some(42),
+				Home:     optional.Some(addressPatch{City: optional.Some("Paris")}),
+				Updated:  optional.Some(updated),
+				Timeout:  optional.Some(1500 * time.Millisecond),
+			},
+			pbProfile{
+				Name:        "other",
+				Nickname:    &StringValue{Value: "nick"},
+				Age:         func() *int32 { v := int32(42); return &v }(),
+				Tags:        []string{"a"},
+				HomeAddress: &pbAddress{City: "Paris"},
+				UpdatedAt:   &Timestamp{Seconds: updated.Unix(), Nanos: 500},
+				Timeout:     &Duration{Seconds: 1, Nanos: 500000000},
+			},
+		},
+		{
+			"null",
+			profilePatch{Age: optional.Null[int](), Tags: optional.Null[[]string](), Home: optional.Null[addressPatch]()},
+			pbProfile{Name: "some"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := pbProfile{Name: "some", Age: &age, Tags: []string{"a"}}
+
+			require.NoError(t, patch.ApplyToProto(&msg, tt.patch))
+
+			assert.Equal(t, tt.want, msg)
+		})
+	}
+}
+
+func TestApplyToProto_Error(t *testing.T) {
+	t.Parallel()
+
+	type unknown struct {
+		Unknown optional.Type[string] `json:"unknown"`
+	}
+
+	type plain struct {
+		Name string
+	}
+
+	type opaque struct {
+		xxx_hidden_Name string `protobuf:"bytes,1,opt,name=name"`
+	}
+
+	type withOneof struct {
+		Name    string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+		Contact interface{} `protobuf_oneof:"contact"`
+	}
+
+	type contactPatch struct {
+		Email optional.Type[string] `json:"email"`
+	}
+
+	require.EqualError(t, patch.ApplyToProto(pbProfile{}, profilePatch{}), "patch: ApplyToProto expects a ProtoMessage or a non-nil pointer to a message, got patch_test.pbProfile")
+	require.EqualError(t, patch.ApplyToProto(&opaque{xxx_hidden_Name: "a"}, profilePatch{}), "patch: ApplyToProto expects a ProtoMessage or a non-nil pointer to a message, got *patch_test.opaque")
+	require.EqualError(t, patch.ApplyToProto(&withOneof{}, contactPatch{}), `patch: field "Email" has no matching field in patch_test.withOneof, the fields of oneofs are set through ProtoMessage only`)
+	require.EqualError(t, patch.ApplyToProto(&pbProfile{}, 1), "patch: ApplyToProto expects a struct patch, got int")
+	require.EqualError(t, patch.ApplyToProto(&pbProfile{}, unknown{}), `patch: field "Unknown" has no matching field in patch_test.pbProfile`)
+	require.EqualError(t, patch.ApplyToProto(&pbProfile{}, plain{}), `patch: field "Name" is not optional`)
+}
+
+// fakeMessage is a ProtoMessage like protoreflect.Message adapted to it, the values of the fields are held by
+// their proto names.
+type fakeMessage struct {
+	name   string
+	values map[string]any
+}
+
+// fakeSchema holds the fields of the messages by their full names, the descriptors are the names of the oneofs.
+var fakeSchema = map[string][]patch.ProtoField{
+	"example.Profile": {
+		{Name: "name", Type: reflect.TypeOf("")},
+		{Name: "nickname", Message: "google.protobuf.StringValue"},
+		{Name: "age", Type: reflect.TypeOf(int32(0))},
+		{Name: "tags", Type: reflect.TypeOf([]string(nil))},
+		{Name: "home_address", Message: "example.Address"},
+		{Name: "updated_at", Message: "google.protobuf.Timestamp"},
+		{Name: "timeout", Message: "google.protobuf.Duration"},
+		{Name: "email", Type: reflect.TypeOf(""), Desc: "contact"},
+		{Name: "phone", Type: reflect.TypeOf(""), Desc: "contact"},
+	},
+	"example.Address":             {{Name: "city", Type: reflect.TypeOf("")}},
+	"google.protobuf.StringValue": {{Name: "value", Type: reflect.TypeOf("")}},
+	"google.protobuf.Timestamp":   {{Name: "seconds", Type: reflect.TypeOf(int64(0))}, {Name: "nanos", Type: reflect.TypeOf(int32(0))}},
+	"google.protobuf.Duration":    {{Name: "seconds", Type: reflect.TypeOf(int64(0))}, {Name: "nanos", Type: reflect.TypeOf(int32(0))}},
+}
+
+func newFakeMessage(name string) *fakeMessage {
+	return &fakeMessage{name: name, values: map[string]any{}}
+}
+
+func (m *fakeMessage) ProtoField(name string) (patch.ProtoField, bool) {
+	for _, f := range fakeSchema[m.name] {
+		if f.Name == name || strings.ReplaceAll(f.Name, "_", "") == strings.ToLower(name) {
+			return f, true
+		}
+	}
+
+	return patch.ProtoField{}, false
+}
+
+func (m *fakeMessage) SetProto(f patch.ProtoField, v any) error {
+	if reflect.TypeOf(v) != f.Type {
+		return fmt.Errorf("%T is not %s", v, f.Type)
+	}
+
+	if f.Desc != nil {
+		for _, other := range fakeSchema[m.name] {
+			if other.Desc == f.Desc {
+				delete(m.values, other.Name)
+			}
+		}
+	}
+
+	m.values[f.Name] = v
+
+	return nil
+}
+
+func (m *fakeMessage) ClearProto(f patch.ProtoField) {
+	delete(m.values, f.Name)
+}
+
+func (m *fakeMessage) MutableProto(f patch.ProtoField) patch.ProtoMessage {
+	if v, ok := m.values[f.Name].(*fakeMessage); ok {
+		return v
+	}
+
+	v := newFakeMessage(f.Message)
+	m.values[f.Name] = v
+
+	return v
+}
+
+func TestApplyToProto_ProtoMessage(t *testing.T) {
+	t.Parallel()
+
+	type contactPatch struct {
+		Name     optional.Type[string]        `json:"name"`
+		Nickname optional.Type[string]        `json:"nickname"`
+		Age      optional.Type[int]           `json:"age"`
+		Tags     optional.Type[[]string]      `json:"tags"`
+		Home     optional.Type[addressPatch]  `json:"homeAddress"`
+		Updated  optional.Type[time.Time]     `json:"updated_at"`
+		Timeout  optional.Type[time.Duration] `json:"timeout"`
+		Email    optional.Type[string]        `json:"email"`
+	}
+
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+
+	msg := newFakeMessage("example.Profile")
+	msg.values["age"] = int32(40)
+	msg.values["phone"] = "+100"
+
+	err := patch.ApplyToProto(msg, contactPatch{
+		Name:     optional.Some("other"),
+		Nickname: optional.Some("nick"),
+		Age:      optional.Null[int](),
+		Tags:     optional.Some([]string{"a"}),
+		Home:     optional.Some(addressPatch{City: optional.Some("Paris")}),
+		Updated:  optional.Some(updated),
+		Timeout:  optional.Some(1500 * time.Millisecond),
+		Email:    optional.Some("john@example.com"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"name":         "other",
+		"nickname":     &fakeMessage{name: "google.protobuf.StringValue", values: map[string]any{"value": "nick"}},
+		"tags":         []string{"a"},
+		"home_address": &fakeMessage{name: "example.Address", values: map[string]any{"city": "Paris"}},
+		"updated_at": &fakeMessage{name: "google.protobuf.Timestamp", values: map[string]any{
+			"seconds": updated.Unix(), "nanos": int32(500),
+		}},
+		"timeout": &fakeMessage{name: "google.protobuf.Duration", values: map[string]any{
+			"seconds": int64(1), "nanos": int32(500000000),
+		}},
+		"email": "john@example.com",
+	}, msg.values)
+}
+
+func TestApplyToProto_ProtoMessageError(t *testing.T) {
+	t.Parallel()
+
+	type unknown struct {
+		Unknown optional.Type[string] `json:"unknown"`
+	}
+
+	type overflow struct {
+		Age optional.Type[int64] `json:"age"`
+	}
+
+	type mismatch struct {
+		Timeout optional.Type[string] `json:"timeout"`
+	}
+
+	msg := newFakeMessage("example.Profile")
+
+	require.EqualError(t, patch.ApplyToProto(msg, unknown{}), `patch: field "Unknown" has no matching field in the message`)
+	require.EqualError(t, patch.ApplyToProto(msg, overflow{Age: optional.Some(int64(1) << 40)}),
+		`patch: field "Age": value 1099511627776 overflows int32`)
+	require.EqualError(t, patch.ApplyToProto(msg, mismatch{Timeout: optional.Some("1s")}),
+		`patch: field "Timeout": cannot assign string to google.protobuf.Duration`)
+}
@@ -0,0 +1,326 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetPointer returns the value found by the JSON Pointer (RFC 6901), such as "/address/city", in v and its state.
+// The reference tokens are matched with the names of the fields like [Marshal] does, with the keys of maps
+// and the indexes of slices and arrays.
+//
+// The [Type] values are presence aware: the value of the set [Type] is returned with [StateValue], while
+// the unset or null [Type] is reported as [StateUnset] or [StateNull] with the nil value. Values under
+// an unset or null [Type], a nil pointer or a missing map key are reported as [StateUnset].
+// Other nil pointers, maps, slices and interfaces are reported as [StateNull].
+//
+// An error is returned if the pointer is malformed or refers to a field that does not exist.
+func GetPointer(v any, ptr string) (any, State, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, StateUnset, err
+	}
+
+	cur := reflect.ValueOf(v)
+
+	for i, tok := range tokens {
+		cur = derefPointer(cur)
+
+		if cur.IsValid() && isPresenceType(cur.Type()) {
+			if fieldState(cur) != StateValue {
+				return nil, StateUnset, nil
+			}
+
+			cur = derefPointer(presenceValue(cur))
+		}
+
+		if !cur.IsValid() {
+			return nil, StateUnset, nil
+		}
+
+		next, found, err := pointerChild(cur, tok)
+		if err != nil {
+			return nil, StateUnset, fmt.Errorf("optional: pointer %q: %w", joinPointer(tokens[:i+1]), err)
+		}
+
+		if !found {
+			return nil, StateUnset, nil
+		}
+
+		cur = next
+	}
+
+	if !cur.IsValid() {
+		return nil, StateNull, nil
+	}
+
+	if isPresenceType(cur.Type()) {
+		state := fieldState(cur)
+		if state != StateValue {
+			return nil, state, nil
+		}
+
+		return presenceValue(cur).Interface(), state, nil
+	}
+
+	switch cur.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if cur.IsNil() {
+			return nil, StateNull, nil
+		}
+	}
+
+	return cur.Interface(), StateValue, nil
+}
+
+// SetPointer sets the value found by the JSON Pointer (RFC 6901), such as "/address/city", in the value
+// pointed to by v. The reference tokens are matched like [GetPointer] does, the "-" token appends to slices.
+//
+// The [Type] values are presence aware: setting the [Type] marks it set, setting it to nil marks it null.
+// The unset or null [Type] values, nil pointers and maps on the way are set to the zero values and allocated.
+// Values of other types are converted when possible, composite values are converted through JSON.
+func SetPointer(v any, ptr string, value any) error {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("optional: SetPointer expects a non-nil pointer, got %T", v)
+	}
+
+	return setPointer(rv.Elem(), tokens, 0, value)
+}
+
+func setPointer(cur reflect.Value, tokens []string, i int, value any) error {
+	if i == len(tokens) {
+		return setPointerValue(cur, value)
+	}
+
+	for cur.Kind() == reflect.Ptr {
+		if cur.IsNil() {
+			cur.Set(reflect.New(cur.Type().Elem()))
+		}
+
+		cur = cur.Elem()
+	}
+
+	if a, ok := asAccessor(cur); ok && isPresenceType(cur.Type()) {
+		if fieldState(cur) != StateValue {
+			a.mark(true, false)
+		}
+
+		return setPointer(a.value(), tokens, i, value)
+	}
+
+	tok := tokens[i]
+
+	wrap := func(err error) error {
+		return fmt.Errorf("optional: pointer %q: %w", joinPointer(tokens[:i+1]), err)
+	}
+
+	switch cur.Kind() {
+	case reflect.Struct:
+		next, _, err := pointerChild(cur, tok)
+		if err != nil {
+			return wrap(err)
+		}
+
+		return setPointer(next, tokens, i+1, value)
+	case reflect.Map:
+		key, err := pointerMapKey(cur.Type(), tok)
+		if err != nil {
+			return wrap(err)
+		}
+
+		if cur.IsNil() {
+			cur.Set(reflect.MakeMap(cur.Type()))
+		}
+
+		// The values of maps are not addressable, so the value is modified in a copy.
+		elem := reflect.New(cur.Type().Elem()).Elem()
+		if mv := cur.MapIndex(key); mv.IsValid() {
+			elem.Set(mv)
+		}
+
+		if err := setPointer(elem, tokens, i+1, value); err != nil {
+			return err
+		}
+
+		cur.SetMapIndex(key, elem)
+
+		return nil
+	case reflect.Slice:
+		if tok == "-" {
+			elem := reflect.New(cur.Type().Elem()).Elem()
+
+			if err := setPointer(elem, tokens, i+1, value); err != nil {
+				return err
+			}
+
+			cur.Set(reflect.Append(cur, elem))
+
+			return nil
+		}
+
+		fallthrough
+	case reflect.Array:
+		next, _, err := pointerChild(cur, tok)
+		if err != nil {
+			return wrap(err)
+		}
+
+		return setPointer(next, tokens, i+1, value)
+	}
+
+	return wrap(fmt.Errorf("cannot descend into %s", cur.Type()))
+}
+
+// setPointerValue stores the value into the addressable v, marking the [Type] values set or null.
+func setPointerValue(v reflect.Value, value any) error {
+	a, ok := asAccessor(v)
+	if !ok || !isPresenceType(v.Type()) {
+		return assign(v, value)
+	}
+
+	if value == nil {
+		a.mark(true, true)
+
+		return nil
+	}
+
+	if err := assign(a.value(), value); err != nil {
+		return err
+	}
+
+	a.mark(true, false)
+
+	return nil
+}
+
+// pointerChild returns the member of the struct, map, slice or array v referred to by the token,
+// reporting false for missing map keys.
+func pointerChild(v reflect.Value, tok string) (reflect.Value, bool, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		f, ok := lookupField(jsonFields(v.Type()), tok, false)
+		if !ok {
+			return reflect.Value{}, false, fmt.Errorf("no field %q in %s", tok, v.Type())
+		}
+
+		fv, ok := fieldByIndex(v, f.index, v.CanSet())
+
+		return fv, ok, nil
+	case reflect.Map:
+		key, err := pointerMapKey(v.Type(), tok)
+		if err != nil {
+			return reflect.Value{}, false, err
+		}
+
+		mv := v.MapIndex(key)
+
+		return mv, mv.IsValid(), nil
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(tok)
+		if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') {
+			return reflect.Value{}, false, fmt.Errorf("invalid array index %q", tok)
+		}
+
+		if i >= v.Len() {
+			return reflect.Value{}, false, fmt.Errorf("index %d out of range of length %d", i, v.Len())
+		}
+
+		return v.Index(i), true, nil
+	}
+
+	return reflect.Value{}, false, fmt.Errorf("cannot descend into %s", v.Type())
+}
+
+// pointerMapKey converts the token into the key of the map type t.
+func pointerMapKey(t reflect.Type, tok string) (reflect.Value, error) {
+	key := reflect.New(t.Key()).Elem()
+
+	switch t.Key().Kind() {
+	case reflect.String:
+		key.SetString(tok)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(tok, 10, t.Key().Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid map key %q: %w", tok, err)
+		}
+
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(tok, 10, t.Key().Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid map key %q: %w", tok, err)
+		}
+
+		key.SetUint(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type %s", t.Key())
+	}
+
+	return key, nil
+}
+
+// presenceValue returns the inner value of the [Type] or [Tracked] value v.
+func presenceValue(v reflect.Value) reflect.Value {
+	if isTrackedType(v.Type()) {
+		v = v.Field(0)
+	}
+
+	return v.FieldByName("V")
+}
+
+// derefPointer dereferences the pointers and interfaces, returning the zero Value for nil ones.
+func derefPointer(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+var (
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// parsePointer splits the JSON Pointer into the unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("optional: invalid JSON pointer %q", ptr)
+	}
+
+	tokens := strings.Split(ptr[1:], "/")
+
+	for i, tok := range tokens {
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+
+	return tokens, nil
+}
+
+// joinPointer escapes and joins the reference tokens into the JSON Pointer.
+func joinPointer(tokens []string) string {
+	var b strings.Builder
+
+	for _, tok := range tokens {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(tok))
+	}
+
+	return b.String()
+}
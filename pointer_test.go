@@ -0,0 +1,137 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type pointerAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type pointerUser struct {
+	Name    optional.Type[string]            `json:"name"`
+	Email   optional.Type[string]            `json:"email"`
+	Age     optional.Type[int]               `json:"age"`
+	Address optional.Type[pointerAddress]    `json:"address"`
+	Home    *pointerAddress                  `json:"home"`
+	Tags    []string                         `json:"tags"`
+	Attrs   map[string]optional.Type[string] `json:"attrs"`
+	Plain   string                           `json:"a/b"`
+}
+
+func TestGetPointer(t *testing.T) {
+	t.Parallel()
+
+	u := pointerUser{
+		Name:    optional.Some("John"),
+		Email:   optional.Null[string](),
+		Address: optional.Some(pointerAddress{City: optional.Some("Paris")}),
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]optional.Type[string]{"role": optional.Some("admin")},
+		Plain:   "plain",
+	}
+
+	tests := [...]struct {
+		name      string
+		ptr       string
+		want      any
+		wantState optional.State
+	}{
+		{"root", "", &u, optional.StateValue},
+		{"value", "/name", "John", optional.StateValue},
+		{"null", "/email", nil, optional.StateNull},
+		{"unset", "/age", nil, optional.StateUnset},
+		{"nested", "/address/city", "Paris", optional.StateValue},
+		{"nested object", "/address", pointerAddress{City: optional.Some("Paris")}, optional.StateValue},
+		{"under nil pointer", "/home/city", nil, optional.StateUnset},
+		{"nil pointer", "/home", nil, optional.StateNull},
+		{"index", "/tags/1", "b", optional.StateValue},
+		{"map", "/attrs/role", "admin", optional.StateValue},
+		{"missing key", "/attrs/team", nil, optional.StateUnset},
+		{"escaped", "/a~1b", "plain", optional.StateValue},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, state, err := optional.GetPointer(&u, tt.ptr)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantState, state)
+		})
+	}
+}
+
+func TestGetPointer_Error(t *testing.T) {
+	t.Parallel()
+
+	u := pointerUser{Name: optional.Some("John"), Tags: []string{"a"}}
+
+	tests := [...]struct {
+		name    string
+		ptr     string
+		wantErr string
+	}{
+		{"malformed", "name", `optional: invalid JSON pointer "name"`},
+		{"unknown field", "/unknown", `optional: pointer "/unknown": no field "unknown" in optional_test.pointerUser`},
+		{"out of range", "/tags/1", `optional: pointer "/tags/1": index 1 out of range of length 1`},
+		{"invalid index", "/tags/01", `optional: pointer "/tags/01": invalid array index "01"`},
+		{"scalar", "/name/first", `optional: pointer "/name/first": cannot descend into string`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := optional.GetPointer(u, tt.ptr)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	t.Parallel()
+
+	var u pointerUser
+
+	require.NoError(t, optional.SetPointer(&u, "/name", "John"))
+	require.NoError(t, optional.SetPointer(&u, "/email", nil))
+	require.NoError(t, optional.SetPointer(&u, "/age", 42.0))
+	require.NoError(t, optional.SetPointer(&u, "/address/city", "Paris"))
+	require.NoError(t, optional.SetPointer(&u, "/home/city", "Rome"))
+	require.NoError(t, optional.SetPointer(&u, "/tags/-", "a"))
+	require.NoError(t, optional.SetPointer(&u, "/tags/0", "b"))
+	require.NoError(t, optional.SetPointer(&u, "/attrs/role", "admin"))
+	require.NoError(t, optional.SetPointer(&u, "/a~1b", "plain"))
+
+	assert.Equal(t, pointerUser{
+		Name:    optional.Some("John"),
+		Email:   optional.Null[string](),
+		Age:     optional.Some(42),
+		Address: optional.Some(pointerAddress{City: optional.Some("Paris")}),
+		Home:    &pointerAddress{City: optional.Some("Rome")},
+		Tags:    []string{"b"},
+		Attrs:   map[string]optional.Type[string]{"role": optional.Some("admin")},
+		Plain:   "plain",
+	}, u)
+}
+
+func TestSetPointer_Error(t *testing.T) {
+	t.Parallel()
+
+	require.EqualError(t, optional.SetPointer(pointerUser{}, "/name", "John"), "optional: SetPointer expects a non-nil pointer, got optional_test.pointerUser")
+	require.EqualError(t, optional.SetPointer(&pointerUser{}, "/unknown", 1), `optional: pointer "/unknown": no field "unknown" in optional_test.pointerUser`)
+	require.EqualError(t, optional.SetPointer(&pointerUser{}, "/tags/0", "a"), `optional: pointer "/tags/0": index 0 out of range of length 0`)
+	require.ErrorContains(t, optional.SetPointer(&pointerUser{}, "/age", "x"), "optional: cannot convert string to int")
+}
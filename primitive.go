@@ -0,0 +1,410 @@
+package optional
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// appendPrimitive appends the JSON encoding of the value of a primitive type, encoded like encoding/json does,
+// without reflection. The second result is false if the type is not primitive.
+func appendPrimitive(dst []byte, v any) ([]byte, bool, error) {
+	switch v := v.(type) {
+	case string:
+		return appendString(dst, v, true), true, nil
+	case bool:
+		return strconv.AppendBool(dst, v), true, nil
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int8:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int16:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), true, nil
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint8:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint16:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint64:
+		return strconv.AppendUint(dst, v, 10), true, nil
+	case float32:
+		b, err := appendFloat(dst, float64(v), 32)
+
+		return b, true, err
+	case float64:
+		b, err := appendFloat(dst, v, 64)
+
+		return b, true, err
+	}
+
+	return dst, false, nil
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends the JSON string of s, escaping HTML characters when html is true and replacing
+// invalid UTF-8 like encoding/json does.
+func appendString(dst []byte, s string, html bool) []byte {
+	dst = append(dst, '"')
+
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+			case c == '\t':
+				dst = append(dst, '\\', 't')
+			case c < 0x20 || html && (c == '<' || c == '>' || c == '&'):
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			default:
+				dst = append(dst, c)
+			}
+
+			i++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+
+		i += size
+	}
+
+	return append(dst, '"')
+}
+
+// appendFloat appends the JSON number of f formatted like encoding/json does.
+func appendFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return dst, fmt.Errorf("optional: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+
+	abs := math.Abs(f)
+
+	format := byte('f')
+	if abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst, nil
+}
+
+var errSyntax = errors.New("optional: invalid JSON value")
+
+// parsePrimitive decodes the JSON value into the pointer to a primitive type without reflection.
+// The first result is false if the type is not primitive.
+func parsePrimitive(data []byte, v any) (bool, error) {
+	switch v := v.(type) {
+	case *string:
+		if len(data) == 0 || data[0] != '"' {
+			return true, typeError(data, v)
+		}
+
+		s, err := parseString(data)
+		if err == nil {
+			*v = s
+		}
+
+		return true, err
+	case *bool:
+		switch string(data) {
+		case "true":
+			*v = true
+		case "false":
+			*v = false
+		default:
+			return true, typeError(data, v)
+		}
+
+		return true, nil
+	case *int:
+		return true, parseInt(data, strconv.IntSize, v, func(i int64) { *v = int(i) })
+	case *int8:
+		return true, parseInt(data, 8, v, func(i int64) { *v = int8(i) })
+	case *int16:
+		return true, parseInt(data, 16, v, func(i int64) { *v = int16(i) })
+	case *int32:
+		return true, parseInt(data, 32, v, func(i int64) { *v = int32(i) })
+	case *int64:
+		return true, parseInt(data, 64, v, func(i int64) { *v = i })
+	case *uint:
+		return true, parseUint(data, strconv.IntSize, v, func(i uint64) { *v = uint(i) })
+	case *uint8:
+		return true, parseUint(data, 8, v, func(i uint64) { *v = uint8(i) })
+	case *uint16:
+		return true, parseUint(data, 16, v, func(i uint64) { *v = uint16(i) })
+	case *uint32:
+		return true, parseUint(data, 32, v, func(i uint64) { *v = uint32(i) })
+	case *uint64:
+		return true, parseUint(data, 64, v, func(i uint64) { *v = i })
+	case *float32:
+		return true, parseFloat(data, 32, v, func(f float64) { *v = float32(f) })
+	case *float64:
+		return true, parseFloat(data, 64, v, func(f float64) { *v = f })
+	}
+
+	return false, nil
+}
+
+func parseInt(data []byte, bits int, v any, set func(int64)) error {
+	i, err := strconv.ParseInt(string(data), 10, bits)
+	if err != nil {
+		return numberError(data, v)
+	}
+
+	set(i)
+
+	return nil
+}
+
+func parseUint(data []byte, bits int, v any, set func(uint64)) error {
+	i, err := strconv.ParseUint(string(data), 10, bits)
+	if err != nil {
+		return numberError(data, v)
+	}
+
+	set(i)
+
+	return nil
+}
+
+func parseFloat(data []byte, bits int, v any, set func(float64)) error {
+	if !isJSONNumber(data) {
+		return numberError(data, v)
+	}
+
+	f, err := strconv.ParseFloat(string(data), bits)
+	if err != nil {
+		return numberError(data, v)
+	}
+
+	set(f)
+
+	return nil
+}
+
+// numberError returns the error of decoding the JSON value data into the number pointed to by v: a syntax
+// error for the malformed numbers and a type error for the other values and the numbers out of the range.
+func numberError(data []byte, v any) error {
+	if len(data) == 0 || data[0] != '-' && (data[0] < '0' || data[0] > '9') {
+		return typeError(data, v)
+	}
+
+	if !isJSONNumber(data) {
+		return fmt.Errorf("%w: %s", errSyntax, data)
+	}
+
+	// Like encoding/json, the numbers not fitting the type are reported with their values.
+	return &json.UnmarshalTypeError{Value: "number " + string(data), Type: reflect.TypeOf(v).Elem(), Offset: int64(len(data))}
+}
+
+// typeError returns the *json.UnmarshalTypeError encoding/json returns for decoding the JSON value data
+// into the value pointed to by v, so the decoders report the fields and the offsets of the errors.
+func typeError(data []byte, v any) error {
+	value := "number"
+
+	if len(data) > 0 {
+		switch data[0] {
+		case '"':
+			value = "string"
+		case 't', 'f':
+			value = "bool"
+		case '[':
+			value = "array"
+		case '{':
+			value = "object"
+		}
+	}
+
+	return &json.UnmarshalTypeError{Value: value, Type: reflect.TypeOf(v).Elem(), Offset: int64(len(data))}
+}
+
+// isJSONNumber reports whether data is a number in the JSON syntax, which is stricter than the syntax of Go.
+func isJSONNumber(data []byte) bool {
+	i := 0
+
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+
+	digits := func() int {
+		start := i
+		for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+			i++
+		}
+
+		return i - start
+	}
+
+	switch {
+	case i < len(data) && data[i] == '0':
+		i++
+	case digits() == 0:
+		return false
+	}
+
+	if i < len(data) && data[i] == '.' {
+		i++
+
+		if digits() == 0 {
+			return false
+		}
+	}
+
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+
+		if digits() == 0 {
+			return false
+		}
+	}
+
+	return i == len(data)
+}
+
+// parseString decodes the JSON string, replacing invalid UTF-8 and unpaired surrogates like encoding/json does.
+func parseString(data []byte) (string, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return "", fmt.Errorf("optional: cannot decode %s into string", data)
+	}
+
+	data = data[1 : len(data)-1]
+
+	b := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(data) {
+				return "", errSyntax
+			}
+
+			i++
+
+			switch data[i] {
+			case '"', '\\', '/':
+				b = append(b, data[i])
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'u':
+				r, ok := parseHex(data[i+1:])
+				if !ok {
+					return "", errSyntax
+				}
+
+				i += 4
+
+				if utf16.IsSurrogate(r) {
+					lo, ok := rune(0), false
+					if i+2 < len(data) && data[i+1] == '\\' && data[i+2] == 'u' {
+						lo, ok = parseHex(data[i+3:])
+					}
+
+					if dec := utf16.DecodeRune(r, lo); ok && dec != utf8.RuneError {
+						r = dec
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+
+				b = utf8.AppendRune(b, r)
+			default:
+				return "", errSyntax
+			}
+
+			i++
+		case c == '"' || c < 0x20:
+			return "", errSyntax
+		case c < utf8.RuneSelf:
+			b = append(b, c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				b = append(b, "\ufffd"...)
+			} else {
+				b = append(b, data[i:i+size]...)
+			}
+
+			i += size
+		}
+	}
+
+	return string(b), nil
+}
+
+// parseHex parses the four hexadecimal digits at the beginning of data.
+func parseHex(data []byte) (rune, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+
+	var r rune
+
+	for _, c := range data[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+
+		r = r<<4 | rune(c)
+	}
+
+	return r, true
+}
@@ -0,0 +1,50 @@
+package optional
+
+import "net/http"
+
+// Problem is the problem details document of RFC 7807 (https://www.rfc-editor.org/rfc/rfc7807) with the members
+// emitted only when set, so the error responses do not include the empty members, such as "detail":"".
+// The extension members are the optional fields of the structs embedding it:
+//
+//	type OutOfCredit struct {
+//		optional.Problem
+//		Balance optional.Type[int] `json:"balance"`
+//	}
+//
+// The documents are encoded by [Marshal] and written by [WriteProblem].
+type Problem struct {
+	Type     Type[string] `json:"type"`     // Type is the URI reference of the problem type, "about:blank" if unset.
+	Title    Type[string] `json:"title"`    // Title is the short summary of the problem type.
+	Status   Type[int]    `json:"status"`   // Status is the HTTP status code of the response.
+	Detail   Type[string] `json:"detail"`   // Detail explains the occurrence of the problem.
+	Instance Type[string] `json:"instance"` // Instance is the URI reference of the occurrence of the problem.
+}
+
+// NewProblem returns the problem with the status and the title, the text of the status if the title is empty.
+func NewProblem(status int, title string) Problem {
+	if title == "" {
+		title = http.StatusText(status)
+	}
+
+	return Problem{Title: Some(title), Status: Some(status)}
+}
+
+// StatusCode returns the status of the problem, 500 if it is not set.
+func (p Problem) StatusCode() int {
+	if !p.Status.IsSet() || p.Status.IsSetNull() {
+		return http.StatusInternalServerError
+	}
+
+	return p.Status.V
+}
+
+// WriteProblem writes the problem, [Problem] or a struct embedding it, to the response like [WriteJSON] does,
+// with the status of the problem and the application/problem+json content type unless it is already set.
+// The status is overridden by [WithStatus].
+func WriteProblem(w http.ResponseWriter, p interface{ StatusCode() int }, opts ...Option) error {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/problem+json")
+	}
+
+	return WriteJSON(w, p, append([]Option{WithStatus(p.StatusCode())}, opts...)...)
+}
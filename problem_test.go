@@ -0,0 +1,93 @@
+package optional_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type outOfCredit struct {
+	optional.Problem
+	Balance  optional.Type[int]      `json:"balance"`
+	Accounts optional.Type[[]string] `json:"accounts"`
+}
+
+func TestProblem_Marshal(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name    string
+		problem any
+		want    string
+	}{
+		{"empty", optional.Problem{}, `{}`},
+		{"new", optional.NewProblem(http.StatusNotFound, ""), `{"title":"Not Found","status":404}`},
+		{
+			"extensions",
+			outOfCredit{
+				Problem: optional.Problem{
+					Type:   optional.Some("https://example.com/probs/out-of-credit"),
+					Title:  optional.Some("You do not have enough credit."),
+					Status: optional.Some(http.StatusForbidden),
+				},
+				Balance: optional.Some(30),
+			},
+			`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.",` +
+				`"status":403,"balance":30}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := optional.Marshal(tt.problem)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestProblem_Decode(t *testing.T) {
+	t.Parallel()
+
+	var got outOfCredit
+
+	err := optional.NewDecoder(strings.NewReader(`{"title":"No credit","detail":null,"balance":30}`)).Decode(&got)
+	require.NoError(t, err)
+
+	assert.Equal(t, optional.Some("No credit"), got.Title)
+	assert.True(t, got.Detail.IsSetNull())
+	assert.False(t, got.Status.IsSet())
+	assert.Equal(t, optional.Some(30), got.Balance)
+	assert.Equal(t, http.StatusInternalServerError, got.StatusCode())
+}
+
+func TestWriteProblem(t *testing.T) {
+	t.Parallel()
+
+	p := outOfCredit{Problem: optional.NewProblem(http.StatusForbidden, "No credit"), Balance: optional.Some(30)}
+
+	w := httptest.NewRecorder()
+
+	require.NoError(t, optional.WriteProblem(w, p))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"title":"No credit","status":403,"balance":30}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+
+	require.NoError(t, optional.WriteProblem(w, optional.Problem{}, optional.WithStatus(http.StatusConflict)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{}`, w.Body.String())
+}
@@ -0,0 +1,40 @@
+package optional
+
+import (
+	"math/rand"
+	"reflect"
+	"testing/quick"
+)
+
+var _ quick.Generator = Type[int]{}
+
+// Generate implements the [quick.Generator] interface, so property-based tests receive values in every state:
+// unset, null or set to a value generated by [quick.Value], with the size limiting the values of slices and maps.
+func (Type[T]) Generate(r *rand.Rand, size int) reflect.Value {
+	var t Type[T]
+
+	switch r.Intn(3) {
+	case 0:
+		t.SetNull()
+	case 1:
+		var v T
+
+		if rv, ok := quick.Value(reflect.TypeOf(&v).Elem(), r); ok {
+			v = rv.Interface().(T)
+		}
+
+		t.SetValue(v)
+	}
+
+	return reflect.ValueOf(t)
+}
+
+// Generate implements the [quick.Generator] interface like [Type.Generate] does. The generated value is clean.
+func (Tracked[T]) Generate(r *rand.Rand, size int) reflect.Value {
+	var t Tracked[T]
+
+	t.Type = Type[T]{}.Generate(r, size).Interface().(Type[T])
+	t.MarkClean()
+
+	return reflect.ValueOf(t)
+}
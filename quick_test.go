@@ -0,0 +1,58 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_Generate(t *testing.T) {
+	t.Parallel()
+
+	seen := map[optional.State]bool{}
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 30; i++ {
+		v, ok := quick.Value(reflect.TypeOf(optional.Type[[]string]{}), r)
+		require.True(t, ok)
+
+		o := v.Interface().(optional.Type[[]string])
+		seen[o.State()] = true
+
+		if o.State() != optional.StateValue {
+			assert.Nil(t, o.V)
+		}
+	}
+
+	assert.Len(t, seen, 3)
+}
+
+func TestType_Generate_Check(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name  optional.Type[string]   `json:"name"`
+		Score optional.Tracked[int64] `json:"score"`
+	}
+
+	roundTrip := func(in user) bool {
+		data, err := optional.Marshal(in)
+		if err != nil {
+			return false
+		}
+
+		var out user
+
+		return json.Unmarshal(data, &out) == nil && out.Name.Equal(in.Name) && out.Score.Equal(in.Score)
+	}
+
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
@@ -0,0 +1,57 @@
+package optional
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Raw is the optional raw JSON value, for the gateways forwarding the sub-documents they do not interpret
+// while knowing whether they were sent or set to null. The bytes are kept verbatim: decoding copies them
+// without the surrounding whitespace and encoding writes them as they are, bypassing the marshallers set
+// by [ChangeMarshal] and [ChangeUnmarshal], so the numbers and strings are never re-encoded. Both check
+// that the bytes are valid JSON. Note that encoding/json and [Marshal] compact the whitespace inside
+// the values they embed.
+type Raw = Type[json.RawMessage]
+
+// errInvalidRaw is returned for the raw values that are not valid JSON.
+var errInvalidRaw = errors.New("optional: invalid raw JSON value")
+
+// SomeRaw returns the [Raw] value set to the copy of data, or an error if data is not valid JSON.
+// The null literal sets the value to null.
+func SomeRaw(data []byte) (Raw, error) {
+	var r Raw
+
+	if isBlank(data) {
+		return r, errInvalidRaw
+	}
+
+	if err := r.UnmarshalJSON(data); err != nil {
+		return Raw{}, err
+	}
+
+	return r, nil
+}
+
+// unmarshalRaw stores the copy of the valid JSON data into dst.
+func unmarshalRaw(data []byte, dst *json.RawMessage) error {
+	if !json.Valid(data) {
+		return errInvalidRaw
+	}
+
+	*dst = append((*dst)[:0], trimJSONSpace(data)...)
+
+	return nil
+}
+
+// marshalRaw returns the raw value as it is, the nil value as null.
+func marshalRaw(raw json.RawMessage) ([]byte, error) {
+	if raw == nil {
+		return []byte("null"), nil
+	}
+
+	if !json.Valid(raw) {
+		return nil, errInvalidRaw
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,111 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type rawEnvelope struct {
+	ID   int          `json:"id"`
+	Data optional.Raw `json:"data"`
+}
+
+func TestRaw_Decode(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input string
+		state optional.State
+		want  string
+	}{
+		{"object", `{"id":1,"data": { "b" : [1, 2.50, "é"], "a":1e3 } }`, optional.StateValue, `{ "b" : [1, 2.50, "é"], "a":1e3 }`},
+		{"string null", `{"id":1,"data":"null"}`, optional.StateValue, `"null"`},
+		{"number", `{"id":1,"data":10.0}`, optional.StateValue, `10.0`},
+		{"null", `{"id":1,"data":null}`, optional.StateNull, ``},
+		{"absent", `{"id":1}`, optional.StateUnset, ``},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got rawEnvelope
+
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &got))
+			assert.Equal(t, tt.state, got.Data.State())
+			assert.Equal(t, tt.want, string(got.Data.V))
+
+			got = rawEnvelope{}
+
+			require.NoError(t, optional.NewDecoder(strings.NewReader(tt.input)).Decode(&got))
+			assert.Equal(t, tt.state, got.Data.State())
+			assert.Equal(t, tt.want, string(got.Data.V))
+		})
+	}
+}
+
+func TestRaw_Copy(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"a":1}`)
+
+	var got optional.Raw
+
+	require.NoError(t, got.UnmarshalJSON(input))
+
+	input[1] = 'x'
+
+	assert.Equal(t, `{"a":1}`, string(got.V))
+}
+
+func TestRaw_Marshal(t *testing.T) {
+	t.Parallel()
+
+	data, err := optional.SomeRaw([]byte(` [1, 2.50] `))
+	require.NoError(t, err)
+
+	b, err := data.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `[1, 2.50]`, string(b))
+
+	b, err = optional.Marshal(rawEnvelope{ID: 1, Data: data})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"data":[1,2.50]}`, string(b))
+
+	b, err = optional.Marshal(rawEnvelope{ID: 1, Data: optional.Null[json.RawMessage]()})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"data":null}`, string(b))
+
+	b, err = optional.Some[json.RawMessage](nil).MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+
+	null, err := optional.SomeRaw([]byte("null"))
+	require.NoError(t, err)
+	assert.True(t, null.IsSetNull())
+}
+
+func TestRaw_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.SomeRaw([]byte(`{"a":`))
+	require.EqualError(t, err, "optional: invalid raw JSON value")
+
+	_, err = optional.SomeRaw(nil)
+	require.EqualError(t, err, "optional: invalid raw JSON value")
+
+	_, err = optional.Some(json.RawMessage(`{bad`)).MarshalJSON()
+	require.EqualError(t, err, "optional: invalid raw JSON value")
+
+	_, err = optional.Marshal(rawEnvelope{Data: optional.Some(json.RawMessage(`{bad`))})
+	require.ErrorContains(t, err, `optional: field "data"`)
+}
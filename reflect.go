@@ -0,0 +1,88 @@
+package optional
+
+import (
+	"reflect"
+	"strings"
+)
+
+// accessor gives reflection based helpers access to the state of a [Type] without knowing T.
+type accessor interface {
+	value() reflect.Value
+	mark(set, null bool)
+}
+
+// value returns the addressable inner value.
+func (t *Type[T]) value() reflect.Value {
+	return reflect.ValueOf(&t.V).Elem()
+}
+
+// mark sets the presence flags, resetting the inner value for the null and unset states.
+func (t *Type[T]) mark(set, null bool) {
+	if !set || null {
+		var zero T
+
+		t.V = zero
+	}
+
+	t.f = 0
+
+	if set {
+		t.f = flagSet
+	}
+
+	if set && null {
+		t.f |= flagNull
+	}
+}
+
+// asAccessor returns the accessor of the addressable value v if it holds a [Type].
+func asAccessor(v reflect.Value) (accessor, bool) {
+	if !v.CanAddr() {
+		return nil, false
+	}
+
+	a, ok := v.Addr().Interface().(accessor)
+
+	return a, ok
+}
+
+// holdsPointer reports whether v is a non-nil pointer.
+func holdsPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Ptr && !rv.IsNil()
+}
+
+var pkgPath = reflect.TypeOf(Type[int]{}).PkgPath()
+
+// IsType reports whether t is an instantiation of [Type].
+// It is useful for reflection based helpers working with structs of optional values.
+func IsType(t reflect.Type) bool {
+	return isOptionalType(t)
+}
+
+func isOptionalType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Type[")
+}
+
+// fieldByIndex returns the nested field of the struct v by index.
+// Nil embedded pointers are allocated when alloc is true, otherwise the second result is false.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
@@ -0,0 +1,8 @@
+//go:build !tinygo && !optional_noreflect
+
+package optional
+
+// noReflect is false, so the values are marshalled and unmarshalled by the current marshaller and unmarshaller.
+// Build with the optional_noreflect tag, or with TinyGo, to marshal the values of primitive types without
+// reflection.
+const noReflect = false
@@ -0,0 +1,245 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	numberType     = reflect.TypeOf(json.Number(""))
+)
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the struct v as encoded by encoding/json.
+//
+// The [Type] fields may be absent or null, so they are not required and their schemas allow null.
+// Other fields are required unless tagged with omitempty, pointers allow null. Named nested structs
+// are described once in "$defs" and referenced, so recursive types are supported.
+func JSONSchema(v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct || isOptionalType(t) {
+		return nil, fmt.Errorf("optional: JSONSchema expects a struct, got %T", v)
+	}
+
+	b := newSchemaBuilder("#/$defs/")
+	b.names[t] = "#"
+
+	s := b.structSchema(t)
+	s["$schema"] = jsonSchemaDraft
+
+	if len(b.defs) != 0 {
+		s["$defs"] = b.defs
+	}
+
+	return json.Marshal(s)
+}
+
+// schemaBuilder builds the schemas of types, collecting the named structs into definitions
+// referenced with the prefix.
+type schemaBuilder struct {
+	prefix string
+	defs   map[string]any
+	names  map[reflect.Type]string // names holds the references of the structs being defined or defined.
+}
+
+func newSchemaBuilder(prefix string) *schemaBuilder {
+	return &schemaBuilder{prefix: prefix, defs: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+// schema returns the schema of the values of the type t.
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	case t == numberType:
+		return map[string]any{"type": "number"}
+	case isOptionalType(t), isTrackedType(t):
+		return nullable(b.schema(optionalElem(t)))
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Ptr:
+		return nullable(b.schema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+
+		return map[string]any{"$ref": b.define(t)}
+	}
+
+	return map[string]any{}
+}
+
+// define adds the definition of the named struct type t unless it is defined, and returns its reference.
+func (b *schemaBuilder) define(t reflect.Type) string {
+	if ref, ok := b.names[t]; ok {
+		return ref
+	}
+
+	name := schemaName(t)
+	for i := 2; b.defs[name] != nil; i++ {
+		name = schemaName(t) + strconv.Itoa(i)
+	}
+
+	ref := b.prefix + name
+	b.names[t] = ref
+	b.defs[name] = map[string]any{} // reserves the name while the struct is being defined
+
+	b.defs[name] = b.structSchema(t)
+
+	return ref
+}
+
+// structSchema returns the object schema of the struct type t.
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, f := range jsonFields(t) {
+		sf := t.FieldByIndex(f.index)
+
+		s := b.schema(sf.Type)
+		if f.quoted {
+			s = quotedSchema(s)
+		}
+
+		properties[f.name] = s
+
+		if !f.omitEmpty && !isOptionalType(sf.Type) && !isTrackedType(sf.Type) {
+			required = append(required, f.name)
+		}
+	}
+
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) != 0 {
+		s["required"] = required
+	}
+
+	return s
+}
+
+// optionalElem returns the type T of the [Type] or [Tracked] type t.
+func optionalElem(t reflect.Type) reflect.Type {
+	if isTrackedType(t) {
+		t = t.Field(0).Type
+	}
+
+	return t.Field(0).Type
+}
+
+// nullable returns the schema s allowing null.
+func nullable(s map[string]any) map[string]any {
+	switch typ := s["type"].(type) {
+	case string:
+		s["type"] = []string{typ, "null"}
+	case []string:
+		for _, t := range typ {
+			if t == "null" {
+				return s
+			}
+		}
+
+		s["type"] = append(typ, "null")
+	case nil:
+		if _, ok := s["$ref"]; ok {
+			return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+		}
+	}
+
+	return s
+}
+
+// quotedSchema returns the schema of the value encoded as a string with the ",string" option.
+func quotedSchema(s map[string]any) map[string]any {
+	switch typ := s["type"].(type) {
+	case string:
+		if isScalarSchema(typ) {
+			return map[string]any{"type": "string"}
+		}
+	case []string:
+		if len(typ) == 2 && isScalarSchema(typ[0]) {
+			return map[string]any{"type": []string{"string", "null"}}
+		}
+	}
+
+	return s
+}
+
+func isScalarSchema(typ string) bool {
+	return typ == "integer" || typ == "number" || typ == "boolean"
+}
+
+// schemaName returns the name of the definition of the named type t.
+func schemaName(t reflect.Type) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+
+		return '_'
+	}, t.Name())
+}
+
+// OpenAPISchemas returns the schemas of the named structs of values for the components of an OpenAPI 3.1
+// document, keyed by the names of the types. The schemas are described like [JSONSchema] does, the nested named
+// structs are added to the components too and referenced as "#/components/schemas/Name".
+//
+// The result can be encoded into the "components/schemas" member of a document, or decoded into the schemas
+// of an OpenAPI library.
+func OpenAPISchemas(values ...any) (map[string]any, error) {
+	b := newSchemaBuilder("#/components/schemas/")
+
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() != reflect.Struct || isOptionalType(t) || t.Name() == "" {
+			return nil, fmt.Errorf("optional: OpenAPISchemas expects named structs, got %T", v)
+		}
+
+		b.define(t)
+	}
+
+	return b.defs, nil
+}
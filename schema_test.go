@@ -0,0 +1,125 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City optional.Type[string] `json:"city"`
+	}
+
+	type node struct {
+		Value    int     `json:"value"`
+		Children []*node `json:"children,omitempty"`
+	}
+
+	type user struct {
+		ID        uint                      `json:"id"`
+		Name      optional.Type[string]     `json:"name"`
+		Age       optional.Type[int]        `json:"age,string"`
+		Tags      []string                  `json:"tags,omitempty"`
+		Address   optional.Type[address]    `json:"address"`
+		Billing   *address                  `json:"billing"`
+		Tree      node                      `json:"tree"`
+		CreatedAt time.Time                 `json:"created_at"`
+		Score     optional.Tracked[float64] `json:"score"`
+		Ignored   string                    `json:"-"`
+	}
+
+	got, err := optional.JSONSchema(&user{})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 0},
+			"name": {"type": ["string", "null"]},
+			"age": {"type": ["string", "null"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"address": {"anyOf": [{"$ref": "#/$defs/address"}, {"type": "null"}]},
+			"billing": {"anyOf": [{"$ref": "#/$defs/address"}, {"type": "null"}]},
+			"tree": {"$ref": "#/$defs/node"},
+			"created_at": {"type": "string", "format": "date-time"},
+			"score": {"type": ["number", "null"]}
+		},
+		"required": ["id", "billing", "tree", "created_at"],
+		"$defs": {
+			"address": {
+				"type": "object",
+				"properties": {"city": {"type": ["string", "null"]}}
+			},
+			"node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": "array", "items": {"anyOf": [{"$ref": "#/$defs/node"}, {"type": "null"}]}}
+				},
+				"required": ["value"]
+			}
+		}
+	}`, string(got))
+}
+
+func TestJSONSchema_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.JSONSchema(1)
+	require.EqualError(t, err, "optional: JSONSchema expects a struct, got int")
+}
+
+type Pet struct {
+	Name  optional.Type[string] `json:"name"`
+	Owner *Owner                `json:"owner"`
+}
+
+type Owner struct {
+	Name string `json:"name"`
+	Pets []Pet  `json:"pets,omitempty"`
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	t.Parallel()
+
+	got, err := optional.OpenAPISchemas(Pet{}, &Owner{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"Pet": {
+			"type": "object",
+			"properties": {
+				"name": {"type": ["string", "null"]},
+				"owner": {"anyOf": [{"$ref": "#/components/schemas/Owner"}, {"type": "null"}]}
+			},
+			"required": ["owner"]
+		},
+		"Owner": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}
+			},
+			"required": ["name"]
+		}
+	}`, string(data))
+}
+
+func TestOpenAPISchemas_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.OpenAPISchemas(struct{}{})
+	require.EqualError(t, err, "optional: OpenAPISchemas expects named structs, got struct {}")
+}
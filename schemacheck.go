@@ -0,0 +1,127 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaValidator validates the documents decoded into any with numbers as [json.Number], such as
+// *jsonschema.Schema of https://github.com/santhosh-tekuri/jsonschema compiled from the contract of the API.
+type SchemaValidator interface {
+	Validate(doc any) error
+}
+
+// SchemaViolation is the violation of the schema by the value of the field.
+type SchemaViolation struct {
+	Field   string // Field is the dotted path of the keys and indexes of the value, empty for the top-level value.
+	Message string // Message describes the violation.
+}
+
+// SchemaError is returned by [Decoder] for the document not conforming to the schema set by [WithSchema].
+type SchemaError struct {
+	Violations []SchemaViolation // Violations are the violations of the schema by the fields, at least one.
+	Err        error             // Err is the error returned by the validator.
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Violations))
+
+	for i, v := range e.Violations {
+		msgs[i] = fmt.Sprintf("field %q %s", v.Field, v.Message)
+	}
+
+	return "optional: schema violation: " + strings.Join(msgs, "; ")
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// WithSchema makes [Decoder] validate each document against the schema before decoding it, so the contract
+// is enforced and the fields are decoded in a single step. The document not conforming to the schema
+// is not decoded and is reported by *[SchemaError], with the violations attributed to the fields
+// when the validator reports the locations as *jsonschema.ValidationError does.
+func WithSchema(s SchemaValidator) Option {
+	return func(o *options) {
+		o.schema = s
+	}
+}
+
+// checkSchema validates the raw document with the schema of the options.
+func checkSchema(raw json.RawMessage, o options) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var doc any
+
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	if err := o.schema.Validate(doc); err != nil {
+		return &SchemaError{Violations: schemaViolations(err, nil), Err: err}
+	}
+
+	return nil
+}
+
+// schemaViolations collects the violations from the leaves of the tree of the causes of the error,
+// located by the InstanceLocation field, either a JSON pointer or its tokens, and described by the Message field.
+func schemaViolations(err error, dst []SchemaViolation) []SchemaViolation {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return append(dst, SchemaViolation{Message: err.Error()})
+	}
+
+	if causes := v.FieldByName("Causes"); causes.IsValid() && causes.Kind() == reflect.Slice && causes.Len() > 0 {
+		n := len(dst)
+
+		for i := 0; i < causes.Len(); i++ {
+			if cause, ok := causes.Index(i).Interface().(error); ok && !isNilValue(causes.Index(i)) {
+				dst = schemaViolations(cause, dst)
+			}
+		}
+
+		if len(dst) > n {
+			return dst
+		}
+	}
+
+	violation := SchemaViolation{Message: err.Error()}
+
+	switch loc := v.FieldByName("InstanceLocation"); {
+	case !loc.IsValid():
+	case loc.Kind() == reflect.String:
+		violation.Field = pointerPath(loc.String())
+	case loc.Kind() == reflect.Slice && loc.Type().Elem().Kind() == reflect.String:
+		tokens := make([]string, loc.Len())
+		for i := range tokens {
+			tokens[i] = loc.Index(i).String()
+		}
+
+		violation.Field = strings.Join(tokens, ".")
+	}
+
+	if msg := v.FieldByName("Message"); msg.IsValid() && msg.Kind() == reflect.String && msg.String() != "" {
+		violation.Message = msg.String()
+	}
+
+	return append(dst, violation)
+}
+
+// pointerPath converts the JSON pointer, such as "/items/0/name", into the dotted path, such as "items.0.name".
+func pointerPath(ptr string) string {
+	tokens, err := parsePointer(strings.TrimPrefix(ptr, "#"))
+	if err != nil {
+		return ptr
+	}
+
+	return strings.Join(tokens, ".")
+}
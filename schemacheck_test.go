@@ -0,0 +1,131 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// schemaError mirrors the shape of *jsonschema.ValidationError.
+type schemaError struct {
+	InstanceLocation string
+	Message          string
+	Causes           []*schemaError
+}
+
+func (e *schemaError) Error() string {
+	return fmt.Sprintf("jsonschema: %q: %s", e.InstanceLocation, e.Message)
+}
+
+// schemaFunc validates the documents by requiring the "name" string and the non-negative "items.*.qty" numbers.
+type schemaFunc func(doc any) error
+
+func (f schemaFunc) Validate(doc any) error {
+	return f(doc)
+}
+
+var orderSchema = schemaFunc(func(doc any) error {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return &schemaError{Message: "expected object"}
+	}
+
+	root := &schemaError{Message: "doesn't validate"}
+
+	if _, ok := obj["name"].(string); !ok {
+		root.Causes = append(root.Causes, &schemaError{InstanceLocation: "/name", Message: "expected string"})
+	}
+
+	items, _ := obj["items"].([]any)
+	for i, item := range items {
+		qty := item.(map[string]any)["qty"].(json.Number)
+		if strings.HasPrefix(qty.String(), "-") {
+			root.Causes = append(root.Causes, &schemaError{
+				InstanceLocation: fmt.Sprintf("/items/%d/qty", i),
+				Message:          "must be >= 0",
+			})
+		}
+	}
+
+	if len(root.Causes) == 0 {
+		return nil
+	}
+
+	return root
+})
+
+type schemaOrder struct {
+	Name  optional.Type[string] `json:"name"`
+	Items []struct {
+		Qty optional.Type[int] `json:"qty"`
+	} `json:"items"`
+}
+
+func TestWithSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input string
+		want  []optional.SchemaViolation
+	}{
+		{"valid", `{"name":"John","items":[{"qty":1}]}`, nil},
+		{"missing", `{"items":[]}`, []optional.SchemaViolation{{Field: "name", Message: "expected string"}}},
+		{
+			"nested",
+			`{"name":"John","items":[{"qty":1},{"qty":-1}]}`,
+			[]optional.SchemaViolation{{Field: "items.1.qty", Message: "must be >= 0"}},
+		},
+		{"top-level", `[]`, []optional.SchemaViolation{{Message: "expected object"}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got schemaOrder
+
+			err := optional.NewDecoder(strings.NewReader(tt.input), optional.WithSchema(orderSchema)).Decode(&got)
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.Equal(t, optional.Some("John"), got.Name)
+
+				return
+			}
+
+			var se *optional.SchemaError
+			require.ErrorAs(t, err, &se)
+			assert.Equal(t, tt.want, se.Violations)
+			assert.Equal(t, schemaOrder{}, got)
+
+			var cause *schemaError
+			assert.ErrorAs(t, err, &cause)
+		})
+	}
+}
+
+func TestWithSchema_PlainError(t *testing.T) {
+	t.Parallel()
+
+	schema := schemaFunc(func(any) error { return errors.New("invalid document") })
+
+	dec := optional.NewLinesDecoder(strings.NewReader("{\"name\":\"John\"}\n"), optional.WithSchema(schema))
+
+	var got schemaOrder
+
+	err := dec.Decode(&got)
+	require.EqualError(t, err, `optional: line 1: optional: schema violation: field "" invalid document`)
+
+	var se *optional.SchemaError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, []optional.SchemaViolation{{Message: "invalid document"}}, se.Violations)
+}
@@ -0,0 +1,63 @@
+package optional
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSignature is returned by [VerifySignature] when the signature does not match the body.
+var ErrInvalidSignature = errors.New("optional: invalid signature")
+
+// SignedMarshal returns the canonical JSON encoding of v, as [Marshal] with [WithCanonical] returns it,
+// and its HMAC-SHA256 signature with the key, so webhook producers sign exactly the sparse payload sent:
+// the unset fields are neither sent nor signed. The signature is raw bytes, to be hex or base64 encoded
+// into a header, and is checked by the consumers with [VerifySignature].
+func SignedMarshal(v any, key []byte) (body, signature []byte, err error) {
+	body, err = Marshal(v, WithCanonical())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, sign(body, key), nil
+}
+
+// VerifySignature checks the HMAC-SHA256 signature of the body made by [SignedMarshal] with the key.
+// The body is canonicalized before the check, so the payloads reformatted on the way, such as indented
+// by a proxy, are still verified, while any change of the values is not.
+//
+// [ErrInvalidSignature] is returned if the signature does not match, other errors if the body is not valid JSON.
+func VerifySignature(body, signature, key []byte) error {
+	canonical, err := canonicalize(nil, body)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(sign(canonical, key), signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// CanonicalKey returns the stable digest of the set fields of v and their values, the hex encoded SHA-256
+// of its canonical JSON encoding, as [Marshal] with [WithCanonical] and the options returns it, for the
+// idempotency keys and the request deduplication caches. The same request bodies produce the same keys regardless
+// of the order of their members and their formatting, while the keys of the bodies differing in a value or in
+// the presence of a field, such as a field set to null rather than unset, differ.
+func CanonicalKey(v any, opts ...Option) (string, error) {
+	body, err := Marshal(v, append(opts, WithCanonical())...)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(body)), nil
+}
+
+func sign(body, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,115 @@
+package optional_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestSignedMarshal(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Type  string                `json:"type"`
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+	}
+
+	key := []byte("secret")
+
+	body, sig, err := optional.SignedMarshal(event{Type: "user.updated", Email: optional.Null[string]()}, key)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"email":null,"type":"user.updated"}`, string(body))
+	assert.Equal(t, "727bd7f0c6bf0d734117a8f873fedac3091a3e095332f234fe6fb3859f4a9203", hex.EncodeToString(sig))
+
+	tests := [...]struct {
+		name string
+		body string
+		key  []byte
+		want error
+	}{
+		{"valid", string(body), key, nil},
+		{"reformatted", "{\n  \"type\": \"user.updated\",\n  \"email\": null\n}", key, nil},
+		{"modified", `{"email":"john@example.com","type":"user.updated"}`, key, optional.ErrInvalidSignature},
+		{"added field", `{"email":null,"name":"John","type":"user.updated"}`, key, optional.ErrInvalidSignature},
+		{"wrong key", string(body), []byte("other"), optional.ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, optional.VerifySignature([]byte(tt.body), sig, tt.key), tt.want)
+		})
+	}
+}
+
+func TestVerifySignature_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	err := optional.VerifySignature([]byte(`{"type":`), nil, []byte("secret"))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, optional.ErrInvalidSignature)
+}
+
+func TestCanonicalKey(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Name  optional.Type[string]  `json:"name"`
+		Email optional.Type[string]  `json:"email"`
+		Price optional.Type[float64] `json:"price"`
+	}
+
+	key, err := optional.CanonicalKey(request{Name: optional.Some("John"), Price: optional.Some(1.5)})
+	require.NoError(t, err)
+	assert.Len(t, key, 64)
+
+	var decoded request
+
+	require.NoError(t, optional.NewDecoder(strings.NewReader(`{ "price": 1.50, "name": "John" }`)).Decode(&decoded))
+
+	same, err := optional.CanonicalKey(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	tests := [...]struct {
+		name string
+		v    request
+	}{
+		{"null field", request{Name: optional.Some("John"), Email: optional.Null[string](), Price: optional.Some(1.5)}},
+		{"other value", request{Name: optional.Some("Jane"), Price: optional.Some(1.5)}},
+		{"unset field", request{Name: optional.Some("John")}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			other, err := optional.CanonicalKey(tt.v)
+			require.NoError(t, err)
+			assert.NotEqual(t, key, other)
+		})
+	}
+
+	subset, err := optional.CanonicalKey(request{Name: optional.Some("John"), Email: optional.Some("x")},
+		optional.WithFields("name"))
+	require.NoError(t, err)
+
+	nameOnly, err := optional.CanonicalKey(request{Name: optional.Some("John")})
+	require.NoError(t, err)
+	assert.Equal(t, nameOnly, subset)
+
+	_, err = optional.CanonicalKey(func() {})
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Slice is a slice of optional values in the columnar layout for large collections: the values are held in
+// a plain []T and their presence in a bitmap of two bits per value. A []Type[int64] takes 16 bytes per value
+// because of the padding after the flags, a Slice[int64] takes 8 bytes and a quarter of a byte.
+//
+// The zero Slice is empty and ready to use. Like slices, the copies of a Slice share the values.
+type Slice[T any] struct {
+	values []T
+	bits   []uint64 // bits holds the set and the null flags of 32 values per word.
+}
+
+// MakeSlice returns a slice of n unset values.
+func MakeSlice[T any](n int) Slice[T] {
+	return Slice[T]{values: make([]T, n), bits: make([]uint64, (n+31)/32)}
+}
+
+// SliceOf returns a slice holding the values.
+func SliceOf[T any](values ...Type[T]) Slice[T] {
+	s := MakeSlice[T](len(values))
+
+	for i, v := range values {
+		s.Set(i, v)
+	}
+
+	return s
+}
+
+// Len returns the number of the values.
+func (s Slice[T]) Len() int {
+	return len(s.values)
+}
+
+// Get returns the i-th value.
+func (s Slice[T]) Get(i int) Type[T] {
+	return Type[T]{V: s.values[i], f: flags(s.bits[i/32]>>(i%32*2)) & (flagSet | flagNull)}
+}
+
+// Set sets the i-th value.
+func (s Slice[T]) Set(i int, v Type[T]) {
+	var zero T
+
+	if v.f != flagSet {
+		v.V = zero // Only the values set to non-null values are kept.
+	}
+
+	s.values[i] = v.V
+
+	shift := i % 32 * 2
+	s.bits[i/32] = s.bits[i/32]&^(uint64(flagSet|flagNull)<<shift) | uint64(v.f)<<shift
+}
+
+// Append appends the values to the slice.
+func (s *Slice[T]) Append(values ...Type[T]) {
+	for _, v := range values {
+		i := len(s.values)
+
+		var zero T
+
+		s.values = append(s.values, zero)
+		if i%32 == 0 && i/32 == len(s.bits) {
+			s.bits = append(s.bits, 0)
+		}
+
+		s.Set(i, v)
+	}
+}
+
+// Values returns the values of the slice, zero for the unset and the null ones. The result shares
+// the memory of the slice.
+func (s Slice[T]) Values() []T {
+	return s.values
+}
+
+// Types returns the values of the slice as a []Type[T].
+func (s Slice[T]) Types() []Type[T] {
+	types := make([]Type[T], len(s.values))
+	for i := range types {
+		types[i] = s.Get(i)
+	}
+
+	return types
+}
+
+// MarshalJSON encodes the slice as an array like a []Type[T] is encoded.
+func (s Slice[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('[')
+
+	for i := range s.values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		b, err := s.Get(i).MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("optional: Slice element %d: %w", i, err)
+		}
+
+		buf.Write(b)
+	}
+
+	buf.WriteByte(']')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes an array like a []Type[T], the null elements are set to null.
+func (s *Slice[T]) UnmarshalJSON(data []byte) error {
+	var elems []json.RawMessage
+
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+
+	*s = MakeSlice[T](len(elems))
+
+	for i, e := range elems {
+		var v Type[T]
+
+		if err := v.UnmarshalJSON(e); err != nil {
+			return fmt.Errorf("optional: Slice element %d: %w", i, err)
+		}
+
+		s.Set(i, v)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestSlice(t *testing.T) {
+	t.Parallel()
+
+	var s optional.Slice[int64]
+
+	values := make([]optional.Type[int64], 0, 70)
+	for i := 0; i < 70; i++ {
+		switch i % 3 {
+		case 0:
+			values = append(values, optional.Some(int64(i)))
+		case 1:
+			values = append(values, optional.Null[int64]())
+		default:
+			values = append(values, optional.Type[int64]{})
+		}
+
+		s.Append(values[i])
+	}
+
+	require.Equal(t, 70, s.Len())
+	assert.Equal(t, values, s.Types())
+	assert.Equal(t, values, optional.SliceOf(values...).Types())
+
+	s.Set(1, optional.Some(int64(-1)))
+	s.Set(0, optional.Null[int64]())
+	s.Set(69, optional.New(int64(5), false))
+
+	assert.Equal(t, optional.Some(int64(-1)), s.Get(1))
+	assert.Equal(t, optional.Null[int64](), s.Get(0))
+	assert.Equal(t, optional.Type[int64]{}, s.Get(69))
+	assert.Equal(t, values[2:69], s.Types()[2:69])
+	assert.Equal(t, []int64{0, -1, 0}, s.Values()[:3])
+
+	assert.Equal(t, 0, optional.MakeSlice[string](0).Len())
+	assert.Equal(t, optional.Type[string]{}, optional.MakeSlice[string](3).Get(2))
+}
+
+func TestSlice_JSON(t *testing.T) {
+	t.Parallel()
+
+	s := optional.SliceOf(optional.Some("a"), optional.Null[string](), optional.Some(""))
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["a",null,""]`, string(data))
+
+	var got optional.Slice[string]
+
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, s.Types(), got.Types())
+
+	require.EqualError(t, json.Unmarshal([]byte(`["a",1]`), &got),
+		"optional: Slice element 1: json: cannot unmarshal number into Go value of type string")
+}
+
+func benchmarkValues(n int) []optional.Type[int64] {
+	values := make([]optional.Type[int64], n)
+
+	for i := range values {
+		switch i % 3 {
+		case 0:
+			values[i] = optional.Some(int64(i))
+		case 1:
+			values[i] = optional.Null[int64]()
+		}
+	}
+
+	return values
+}
+
+// The benchmarks compare the []Type[int64] layout with the columnar [optional.Slice] one, the B/elem metric
+// reports the memory taken by a value.
+
+func BenchmarkSlice_Make(b *testing.B) {
+	for _, n := range [...]int{1_000, 100_000} {
+		values := benchmarkValues(n)
+
+		b.Run("types/"+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			var got []optional.Type[int64]
+
+			for i := 0; i < b.N; i++ {
+				got = make([]optional.Type[int64], n)
+				copy(got, values)
+			}
+
+			b.ReportMetric(float64(uintptr(cap(got))*unsafe.Sizeof(got[0]))/float64(n), "B/elem")
+		})
+
+		b.Run("columnar/"+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			var got optional.Slice[int64]
+
+			for i := 0; i < b.N; i++ {
+				got = optional.SliceOf(values...)
+			}
+
+			b.ReportMetric(float64(got.Len()*8+(got.Len()+31)/32*8)/float64(n), "B/elem")
+		})
+	}
+}
+
+func BenchmarkSlice_Marshal(b *testing.B) {
+	values := benchmarkValues(10_000)
+	columnar := optional.SliceOf(values...)
+
+	for name, v := range map[string]any{"types": values, "columnar": columnar} {
+		v := v
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSlice_Unmarshal(b *testing.B) {
+	data, err := json.Marshal(benchmarkValues(10_000))
+	require.NoError(b, err)
+
+	b.Run("types", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			var values []optional.Type[int64]
+
+			if err := json.Unmarshal(data, &values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("columnar", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			var values optional.Slice[int64]
+
+			if err := json.Unmarshal(data, &values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSlice_Scan(b *testing.B) {
+	values := benchmarkValues(100_000)
+	columnar := optional.SliceOf(values...)
+
+	b.Run("types", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var sum int64
+
+			for _, v := range values {
+				if v.IsSet() && !v.IsSetNull() {
+					sum += v.V
+				}
+			}
+
+			_ = sum
+		}
+	})
+
+	b.Run("columnar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var sum int64
+
+			// The values of the unset and the null elements are zero, so they are summed without checks.
+			for _, v := range columnar.Values() {
+				sum += v
+			}
+
+			_ = sum
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package optional
+
+import "fmt"
+
+// State is the state of a [Type] value.
+type State uint8
+
+const (
+	// StateUnset means the value has not been set.
+	StateUnset State = iota
+	// StateNull means the value has been explicitly set to null.
+	StateNull
+	// StateValue means the value has been set to a non-null value.
+	StateValue
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateUnset:
+		return "unset"
+	case StateNull:
+		return "null"
+	case StateValue:
+		return "value"
+	}
+
+	return "unknown"
+}
+
+// MarshalText encodes the state as its name, so the states are encoded in JSON as "unset", "null" and "value",
+// such as the reports of [FieldStates] returned by debug endpoints or stored in audit records.
+func (s State) MarshalText() ([]byte, error) {
+	if s > StateValue {
+		return nil, fmt.Errorf("optional: invalid state %d", s)
+	}
+
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the state from its name.
+func (s *State) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "unset":
+		*s = StateUnset
+	case "null":
+		*s = StateNull
+	case "value":
+		*s = StateValue
+	default:
+		return fmt.Errorf("optional: invalid state %q", text)
+	}
+
+	return nil
+}
+
+// State returns the state of the value.
+func (t Type[T]) State() State {
+	return stateOf(t)
+}
+
+func stateOf(p presence) State {
+	switch {
+	case p.IsSetNull():
+		return StateNull
+	case p.IsSet():
+		return StateValue
+	}
+
+	return StateUnset
+}
@@ -0,0 +1,65 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestType_State(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		input optional.Type[string]
+		want  optional.State
+		str   string
+	}{
+		{"unset", optional.Type[string]{}, optional.StateUnset, "unset"},
+		{"null", optional.Null[string](), optional.StateNull, "null"},
+		{"value", optional.Some(""), optional.StateValue, "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.State())
+			assert.Equal(t, tt.str, tt.input.State().String())
+		})
+	}
+}
+
+func TestState_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name    optional.Type[string] `json:"name"`
+		Email   optional.Type[string] `json:"email"`
+		Address optional.Type[struct {
+			City optional.Type[string] `json:"city"`
+		}] `json:"address"`
+	}
+
+	u := user{Email: optional.Null[string]()}
+	u.Address.SetValue(u.Address.V)
+
+	data, err := json.Marshal(optional.FieldStates(u))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"unset","email":"null","address":"value","address.city":"unset"}`, string(data))
+
+	var states map[string]optional.State
+
+	require.NoError(t, json.Unmarshal(data, &states))
+	assert.Equal(t, optional.FieldStates(u), states)
+
+	var s optional.State
+
+	require.EqualError(t, json.Unmarshal([]byte(`"missing"`), &s),
+		`optional: invalid state "missing"`)
+
+	_, err = json.Marshal(optional.State(3))
+	require.ErrorContains(t, err, "optional: invalid state 3")
+}
@@ -0,0 +1,48 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtoStruct is the part of *structpb.Struct of https://pkg.go.dev/google.golang.org/protobuf/types/known/structpb
+// used by [FromProtoStruct].
+type ProtoStruct interface {
+	AsMap() map[string]any
+}
+
+// ProtoStructMap returns the map of the fields of the struct v to be converted into *structpb.Struct
+// with structpb.NewStruct, so the dynamic payloads are forwarded through gRPC APIs using the well-known Struct.
+//
+// The map holds the JSON representation of v encoded by [Marshal]: unset fields are absent, fields set to null
+// are nil, which structpb turns into NullValue, numbers are float64 and other values are strings,
+// slices and maps like structpb expects them:
+//
+//	m, err := optional.ProtoStructMap(payload)
+//	s, err := structpb.NewStruct(m)
+func ProtoStructMap(v any) (map[string]any, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return nil, fmt.Errorf("optional: ProtoStructMap expects a value encoded as a JSON object, got %T", v)
+	}
+
+	return m, nil
+}
+
+// FromProtoStruct fills the fields of the struct pointed to by v from *structpb.Struct like [FromMap] does:
+// the keys with NullValue set the [Type] fields to null and the absent keys leave the fields untouched.
+func FromProtoStruct(s ProtoStruct, v any) error {
+	var m map[string]any
+
+	if s != nil {
+		m = s.AsMap()
+	}
+
+	return FromMap(m, v)
+}
@@ -0,0 +1,78 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// fakeProtoStruct mimics *structpb.Struct holding the values converted by structpb.
+type fakeProtoStruct map[string]any
+
+func (s fakeProtoStruct) AsMap() map[string]any {
+	return s
+}
+
+type protoPayload struct {
+	Name    optional.Type[string]    `json:"name"`
+	Email   optional.Type[string]    `json:"email"`
+	Phone   optional.Type[string]    `json:"phone"`
+	Score   optional.Type[int]       `json:"score"`
+	Tags    optional.Type[[]string]  `json:"tags"`
+	Created optional.Type[time.Time] `json:"created"`
+	Meta    optional.Type[struct {
+		Source optional.Type[string] `json:"source"`
+		Trace  optional.Type[string] `json:"trace"`
+	}] `json:"meta"`
+}
+
+func TestProtoStructMap(t *testing.T) {
+	t.Parallel()
+
+	v := protoPayload{
+		Name:    optional.Some("John"),
+		Email:   optional.Null[string](),
+		Score:   optional.Some(7),
+		Tags:    optional.Some([]string{"a"}),
+		Created: optional.Some(time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)),
+	}
+	v.Meta.SetValue(v.Meta.V)
+	v.Meta.V.Source.SetValue("api")
+
+	got, err := optional.ProtoStructMap(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"name":    "John",
+		"email":   nil,
+		"score":   float64(7),
+		"tags":    []any{"a"},
+		"created": "2024-01-02T03:04:05Z",
+		"meta":    map[string]any{"source": "api"},
+	}, got)
+
+	var back protoPayload
+
+	require.NoError(t, optional.FromProtoStruct(fakeProtoStruct(got), &back))
+	assert.Equal(t, v, back)
+}
+
+func TestProtoStructMap_NotObject(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.ProtoStructMap([]int{1})
+	require.EqualError(t, err, "optional: ProtoStructMap expects a value encoded as a JSON object, got []int")
+}
+
+func TestFromProtoStruct_Nil(t *testing.T) {
+	t.Parallel()
+
+	v := protoPayload{Name: optional.Some("John")}
+
+	require.NoError(t, optional.FromProtoStruct(nil, &v))
+	assert.Equal(t, protoPayload{Name: optional.Some("John")}, v)
+}
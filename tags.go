@@ -0,0 +1,226 @@
+package optional
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagName returns the name from the struct tag key of the field.
+// The second result is false if the tag is absent or the field is excluded with "-".
+func tagName(f reflect.StructField, key string) (string, bool) {
+	tag, ok := f.Tag.Lookup(key)
+	if !ok || tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+
+	return name, true
+}
+
+// jsonField describes a struct field as seen by encoding/json, or by another decoder using the tags of some key.
+type jsonField struct {
+	name      string
+	index     []int
+	tagged    bool // tagged reports whether the name comes from the tag.
+	omitEmpty bool
+	quoted    bool
+	aliases   []string // aliases are the alternative names accepted by [Decoder], from the `optional` tag.
+	deprecate bool     // deprecate reports whether the field is marked deprecated by the `optional` tag.
+	nullAs    string   // nullAs is how [Marshal] encodes the field set to null, from the `optional` tag.
+	codec     string   // codec is the name of the codec registered with [RegisterFieldCodec], from the `optional` tag.
+	prec      string   // prec is the precision of the floats encoded by [Marshal], from the `optional` tag.
+	oneOf     string   // oneOf is the key of the sibling discriminator resolved by [Decoder], from the `optional` tag.
+}
+
+// jsonFields returns the fields of the struct type t as encoding/json sees them,
+// including the fields promoted from embedded structs without a name in the tag.
+func jsonFields(t reflect.Type) []jsonField {
+	return taggedFields(t, "json")
+}
+
+// taggedFields is like jsonFields, but uses the tags of the key. The fields of the same name are resolved
+// with the rules of encoding/json: the shallowest one wins, then the tagged one, the ambiguous ones are dropped.
+func taggedFields(t reflect.Type, key string) []jsonField {
+	all := appendTaggedFields(nil, t, key, nil, map[reflect.Type]bool{t: true})
+
+	byName := make(map[string][]int, len(all))
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+
+	fields := make([]jsonField, 0, len(all))
+
+	for i, f := range all {
+		if dominantField(all, byName[f.name]) == i {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// appendTaggedFields appends the fields of t and the fields promoted from its embedded structs in the order
+// of declaration. The embedded pointers to unexported structs are skipped as they cannot be allocated.
+func appendTaggedFields(
+	fields []jsonField, t reflect.Type, key string, index []int, path map[reflect.Type]bool,
+) []jsonField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag, hasTag := f.Tag.Lookup(key)
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && !isOptionalType(ft) &&
+			(f.IsExported() || f.Type.Kind() == reflect.Struct) {
+			if !path[ft] {
+				path[ft] = true
+				fields = appendTaggedFields(fields, ft, key, append(index[:len(index):len(index)], i), path)
+				delete(path, ft)
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		tagged := hasTag && name != ""
+		if !tagged {
+			name = f.Name
+		}
+
+		fields = append(fields, jsonField{
+			name:      name,
+			index:     append(index[:len(index):len(index)], i),
+			tagged:    tagged,
+			omitEmpty: hasOption(opts, "omitempty"),
+			quoted:    hasOption(opts, "string"),
+			aliases:   tagList(f.Tag.Get("optional"), "alias"),
+			deprecate: hasTagFlag(f.Tag.Get("optional"), "deprecated"),
+			nullAs:    tagValue(f.Tag.Get("optional"), "nullas"),
+			codec:     tagValue(f.Tag.Get("optional"), "codec"),
+			prec:      tagValue(f.Tag.Get("optional"), "prec"),
+			oneOf:     tagValue(f.Tag.Get("optional"), "oneof"),
+		})
+	}
+
+	return fields
+}
+
+// dominantField returns the position of the field winning among the fields at the positions of the same name,
+// or -1 if none of them wins.
+func dominantField(fields []jsonField, positions []int) int {
+	if len(positions) == 1 {
+		return positions[0]
+	}
+
+	depth := len(fields[positions[0]].index)
+	for _, i := range positions[1:] {
+		if d := len(fields[i].index); d < depth {
+			depth = d
+		}
+	}
+
+	var shallow, tagged []int
+
+	for _, i := range positions {
+		if len(fields[i].index) != depth {
+			continue
+		}
+
+		shallow = append(shallow, i)
+		if fields[i].tagged {
+			tagged = append(tagged, i)
+		}
+	}
+
+	switch {
+	case len(tagged) == 1:
+		return tagged[0]
+	case len(tagged) == 0 && len(shallow) == 1:
+		return shallow[0]
+	}
+
+	return -1
+}
+
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tagFlags are the options of the `optional` tag without values.
+var tagFlags = map[string]bool{"deprecated": true}
+
+// tagList returns the values of the option of the `optional` tag, such as `optional:"alias=user_name,userName"`.
+// The values are separated by commas and end at the next option with a value, flag or at the end of the tag.
+func tagList(tag, name string) []string {
+	var (
+		values []string
+		in     bool
+	)
+
+	for tag != "" {
+		var item string
+
+		item, tag, _ = strings.Cut(tag, ",")
+
+		if key, value, ok := strings.Cut(item, "="); ok {
+			in = key == name
+			item = value
+		} else if tagFlags[item] {
+			in = false
+		}
+
+		if in && item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// tagValue returns the first value of the option of the `optional` tag, such as `optional:"nullas=omit"`.
+func tagValue(tag, name string) string {
+	if values := tagList(tag, name); len(values) != 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// hasTagFlag reports whether the `optional` tag has the flag, such as `optional:"deprecated"`.
+func hasTagFlag(tag, name string) bool {
+	for tag != "" {
+		var item string
+
+		item, tag, _ = strings.Cut(tag, ",")
+		if item == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+package optional
+
+import "reflect"
+
+// Hook observes the optional fields marshalled by [Marshal] and [WriteJSON] and unmarshalled by [Decoder],
+// so the metrics, such as the null and error rates per field, and traces can be emitted without wrapping
+// every call site. The fields are identified by the dotted paths of their JSON names.
+type Hook interface {
+	// OnMarshal is called for every optional field with its state and the error of encoding its value.
+	OnMarshal(field string, state State, err error)
+	// OnUnmarshal is called for every optional field of the decoded objects with its state after decoding
+	// and the error of decoding its value. The fields absent in the object are reported as [StateUnset].
+	OnUnmarshal(field string, state State, err error)
+}
+
+// HookFuncs is the [Hook] calling the functions, nil functions are skipped.
+type HookFuncs struct {
+	Marshal   func(field string, state State, err error)
+	Unmarshal func(field string, state State, err error)
+}
+
+// OnMarshal implements the [Hook] interface.
+func (h HookFuncs) OnMarshal(field string, state State, err error) {
+	if h.Marshal != nil {
+		h.Marshal(field, state, err)
+	}
+}
+
+// OnUnmarshal implements the [Hook] interface.
+func (h HookFuncs) OnUnmarshal(field string, state State, err error) {
+	if h.Unmarshal != nil {
+		h.Unmarshal(field, state, err)
+	}
+}
+
+// WithHook sets the hook observing the marshalled and unmarshalled optional fields.
+func WithHook(h Hook) Option {
+	return func(o *options) {
+		o.hook = h
+	}
+}
+
+// fieldState returns the state of the [Type] or [Tracked] value v.
+func fieldState(v reflect.Value) State {
+	return stateOf(v.Interface().(presence))
+}
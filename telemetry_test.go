@@ -0,0 +1,103 @@
+package optional_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type recordingHook struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *recordingHook) record(op, field string, state optional.State, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events = append(h.events, fmt.Sprintf("%s %s %s %v", op, field, state, err != nil))
+}
+
+func (h *recordingHook) OnMarshal(field string, state optional.State, err error) {
+	h.record("marshal", field, state, err)
+}
+
+func (h *recordingHook) OnUnmarshal(field string, state optional.State, err error) {
+	h.record("unmarshal", field, state, err)
+}
+
+type telemetryAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type telemetryUser struct {
+	Name    optional.Type[string]    `json:"name"`
+	Age     optional.Type[int]       `json:"age"`
+	Email   optional.Tracked[string] `json:"email"`
+	Address telemetryAddress         `json:"address"`
+	Plain   string                   `json:"plain"`
+}
+
+func TestWithHook_Marshal(t *testing.T) {
+	t.Parallel()
+
+	h := &recordingHook{}
+
+	_, err := optional.Marshal(telemetryUser{
+		Name:    optional.Some("John"),
+		Age:     optional.Null[int](),
+		Address: telemetryAddress{City: optional.Some("Paris")},
+	}, optional.WithHook(h))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"marshal name value false",
+		"marshal age null false",
+		"marshal email unset false",
+		"marshal address.city value false",
+	}, h.events)
+}
+
+func TestWithHook_Unmarshal(t *testing.T) {
+	t.Parallel()
+
+	h := &recordingHook{}
+
+	dec := optional.NewDecoder(strings.NewReader(`{"name":"John","age":"x"}`), optional.WithHook(h))
+
+	require.Error(t, dec.Decode(&telemetryUser{}))
+	assert.Equal(t, []string{"unmarshal name value false", "unmarshal age value true"}, h.events)
+
+	h.events = nil
+
+	dec = optional.NewDecoder(strings.NewReader(`{"email":null,"address":{"city":"Paris"}}`), optional.WithHook(h))
+
+	require.NoError(t, dec.Decode(&telemetryUser{}))
+	assert.Equal(t, []string{
+		"unmarshal email null false",
+		"unmarshal address.city value false",
+		"unmarshal name unset false",
+		"unmarshal age unset false",
+	}, h.events)
+}
+
+func TestHookFuncs(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+
+	h := optional.HookFuncs{
+		Unmarshal: func(field string, state optional.State, _ error) { got = append(got, field+" "+state.String()) },
+	}
+
+	h.OnMarshal("skipped", optional.StateValue, nil)
+	h.OnUnmarshal("name", optional.StateNull, nil)
+
+	assert.Equal(t, []string{"name null"}, got)
+}
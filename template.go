@@ -0,0 +1,53 @@
+package optional
+
+import "reflect"
+
+// Or returns the value, or def if the value is unset or null.
+// It is handy in templates, such as {{ .Name.Or "anonymous" }}.
+func (t Type[T]) Or(def T) T {
+	if t.f != flagSet {
+		return def
+	}
+
+	return t.V
+}
+
+// FuncMap returns the functions for text/template and html/template handling the optional values:
+//
+//   - optOr returns the value of the optional value, or the default if it is unset or null: {{ optOr .Name "-" }};
+//   - optSet reports whether the optional value is set, to a value or to null: {{ if optSet .Name }};
+//   - optNull reports whether the optional value is set to null: {{ if optNull .Name }};
+//   - optValue reports whether the optional value is set to a value: {{ if optValue .Name }}.
+//
+// Other arguments are treated as values, so optOr returns them and optValue reports true.
+// The result can be passed to the Funcs method of the templates.
+func FuncMap() map[string]any {
+	return map[string]any{
+		"optOr": func(v, def any) any {
+			if p, ok := v.(presence); ok {
+				if !p.IsSet() || p.IsSetNull() {
+					return def
+				}
+
+				return indirect(reflect.ValueOf(v)).FieldByName("V").Interface()
+			}
+
+			return v
+		},
+		"optSet": func(v any) bool {
+			p, ok := v.(presence)
+
+			return !ok || p.IsSet()
+		},
+		"optNull": func(v any) bool {
+			p, ok := v.(presence)
+
+			return ok && p.IsSetNull()
+		},
+		"optValue": func(v any) bool {
+			p, ok := v.(presence)
+
+			return !ok || p.IsSet() && !p.IsSetNull()
+		},
+	}
+}
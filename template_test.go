@@ -0,0 +1,74 @@
+package optional_test
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestFuncMap(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name  optional.Type[string]
+		Email optional.Type[string]
+		Phone optional.Type[string]
+		Age   optional.Tracked[int]
+		ID    int
+	}
+
+	u := user{Name: optional.Some("<John>"), Email: optional.Null[string](), ID: 1}
+	u.Age.SetValue(30)
+
+	const text = `{{ optOr .Name "-" }} {{ optOr .Email "-" }} {{ optOr .Phone "-" }} {{ optOr .Age 0 }} ` +
+		`{{ .Phone.Or "none" }} {{ optOr .ID 0 }}|` +
+		`{{ optSet .Email }} {{ optNull .Email }} {{ optValue .Email }} {{ optSet .Phone }} {{ optValue .ID }}`
+
+	tests := []struct {
+		name string
+		exec func(b *strings.Builder) error
+		want string
+	}{
+		{
+			name: "text",
+			exec: func(b *strings.Builder) error {
+				return template.Must(template.New("").Funcs(optional.FuncMap()).Parse(text)).Execute(b, u)
+			},
+			want: "<John> - - 30 none 1|true true false false true",
+		},
+		{
+			name: "html",
+			exec: func(b *strings.Builder) error {
+				return htmltemplate.Must(htmltemplate.New("").Funcs(optional.FuncMap()).Parse(text)).Execute(b, u)
+			},
+			want: "&lt;John&gt; - - 30 none 1|true true false false true",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b strings.Builder
+
+			require.NoError(t, tt.exec(&b))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestType_Or(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a", optional.Some("a").Or("b"))
+	assert.Equal(t, "b", optional.Null[string]().Or("b"))
+	assert.Equal(t, "b", optional.Type[string]{}.Or("b"))
+}
@@ -0,0 +1,109 @@
+package optional
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// setText parses the textual representation s into the addressable value dst.
+func setText(dst reflect.Value, s string) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if dst.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return textError(s, dst.Type(), err)
+		}
+
+		dst.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return textError(s, dst.Type(), err)
+		}
+
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return textError(s, dst.Type(), err)
+		}
+
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return textError(s, dst.Type(), err)
+		}
+
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return textError(s, dst.Type(), err)
+		}
+
+		dst.SetFloat(n)
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		return setText(dst.Elem(), s)
+	default:
+		// Composite values are expected to be sent as JSON.
+		if err := unmarshaller([]byte(s), dst.Addr().Interface()); err != nil {
+			return textError(s, dst.Type(), err)
+		}
+	}
+
+	return nil
+}
+
+// setTexts stores the values into dst, filling all elements when dst is a slice.
+// A single value is split into the elements by the separator if it is not empty.
+func setTexts(dst reflect.Value, values []string, sep string) error {
+	if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() != reflect.Uint8 &&
+		!(dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType)) {
+		if sep != "" && len(values) == 1 {
+			values = strings.Split(values[0], sep)
+		}
+
+		s := reflect.MakeSlice(dst.Type(), len(values), len(values))
+
+		for i, v := range values {
+			if err := setText(s.Index(i), v); err != nil {
+				return err
+			}
+		}
+
+		dst.Set(s)
+
+		return nil
+	}
+
+	return setText(dst, values[0])
+}
+
+func textError(s string, t reflect.Type, err error) error {
+	return fmt.Errorf("optional: cannot parse %q as %s: %w", s, t, err)
+}
@@ -0,0 +1,139 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Tracked is a [Type] tracking changes since it was marked clean, such as after loading from a storage,
+// so repositories can persist only what has actually changed. The zero value is clean.
+//
+// The value is compared with the snapshot taken by [Tracked.MarkClean], so changes made by assigning V
+// directly are tracked as well, while changes of the elements of slices or maps shared with the snapshot
+// are not.
+type Tracked[T any] struct {
+	Type[T]
+	clean    Type[T]
+	onChange func(old, new Type[T])
+}
+
+// OnChange sets the callback invoked when the value or its state is changed by [Tracked.SetValue],
+// [Tracked.SetNull], [Tracked.Unset] or unmarshalling, such as becoming set, null or cleared.
+// It allows driving domain events and cache invalidation from field changes.
+// Assigning V directly does not invoke the callback.
+func (t *Tracked[T]) OnChange(fn func(old, new Type[T])) {
+	t.onChange = fn
+}
+
+// SetValue sets the value like [Type.SetValue] does, notifying the callback set by [Tracked.OnChange].
+func (t *Tracked[T]) SetValue(value T) {
+	defer t.notify(t.Type)
+
+	t.Type.SetValue(value)
+}
+
+// SetNull marks the value as null like [Type.SetNull] does, notifying the callback set by [Tracked.OnChange].
+func (t *Tracked[T]) SetNull() {
+	defer t.notify(t.Type)
+
+	t.Type.SetNull()
+}
+
+// Unset marks the value as not set like [Type.Unset] does, notifying the callback set by [Tracked.OnChange].
+func (t *Tracked[T]) Unset() {
+	defer t.notify(t.Type)
+
+	t.Type.Unset()
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface like [Type.UnmarshalJSON] does,
+// notifying the callback set by [Tracked.OnChange].
+func (t *Tracked[T]) UnmarshalJSON(bytes []byte) error {
+	defer t.notify(t.Type)
+
+	return t.Type.UnmarshalJSON(bytes)
+}
+
+func (t *Tracked[T]) notify(old Type[T]) {
+	if t.onChange != nil && !reflect.DeepEqual(old, t.Type) {
+		t.onChange(old, t.Type)
+	}
+}
+
+// IsDirty reports whether the value or its state has changed since it was marked clean.
+func (t Tracked[T]) IsDirty() bool {
+	return !reflect.DeepEqual(t.Type, t.clean)
+}
+
+// MarkClean takes the snapshot of the current value and state, so the value is not dirty.
+func (t *Tracked[T]) MarkClean() {
+	t.clean = t.Type
+}
+
+// tracker is implemented by every [Tracked] value.
+type tracker interface {
+	IsDirty() bool
+}
+
+// isTrackedType reports whether t is an instantiation of [Tracked].
+func isTrackedType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Tracked[")
+}
+
+// DirtyFields returns the names of the dirty [Tracked] fields of the struct v in declaration order.
+// The names are taken from the `json` tags, fields of nested structs have dotted paths, such as "address.city".
+func DirtyFields(v any) []string {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: DirtyFields expects a struct, got %T", v))
+	}
+
+	return appendDirtyFields(nil, rv, "")
+}
+
+func appendDirtyFields(names []string, v reflect.Value, prefix string) []string {
+	for _, f := range jsonFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case isTrackedType(fv.Type()):
+			if fv.Interface().(tracker).IsDirty() {
+				names = append(names, prefix+f.name)
+			}
+		case isPlainStruct(fv.Type()):
+			names = appendDirtyFields(names, fv, prefix+f.name+".")
+		}
+	}
+
+	return names
+}
+
+// MarkAllClean marks all [Tracked] fields of the struct pointed to by v clean, including nested structs.
+func MarkAllClean(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("optional: MarkAllClean expects a non-nil pointer to a struct, got %T", v))
+	}
+
+	markAllClean(rv.Elem())
+}
+
+func markAllClean(v reflect.Value) {
+	for _, f := range jsonFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case isTrackedType(fv.Type()):
+			fv.Addr().MethodByName("MarkClean").Call(nil)
+		case isPlainStruct(fv.Type()):
+			markAllClean(fv)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestTracked(t *testing.T) {
+	t.Parallel()
+
+	var got optional.Tracked[string]
+
+	assert.False(t, got.IsDirty())
+
+	got.SetValue("some")
+	assert.True(t, got.IsDirty())
+
+	got.MarkClean()
+	assert.False(t, got.IsDirty())
+
+	got.V = "other"
+	assert.True(t, got.IsDirty())
+
+	got.V = "some"
+	assert.False(t, got.IsDirty())
+
+	got.SetNull()
+	assert.True(t, got.IsDirty())
+}
+
+type trackedUser struct {
+	Name    optional.Tracked[string] `json:"name"`
+	Email   optional.Tracked[string] `json:"email"`
+	Address struct {
+		City optional.Tracked[string] `json:"city"`
+	} `json:"address"`
+}
+
+func TestDirtyFields(t *testing.T) {
+	t.Parallel()
+
+	var got trackedUser
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"some","email":null,"address":{"city":"Moscow"}}`), &got))
+
+	assert.Equal(t, []string{"name", "email", "address.city"}, optional.DirtyFields(got))
+
+	optional.MarkAllClean(&got)
+
+	assert.Empty(t, optional.DirtyFields(&got))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"other","address":{"city":"Moscow"}}`), &got))
+
+	assert.Equal(t, []string{"name"}, optional.DirtyFields(got))
+
+	assert.Panics(t, func() { optional.DirtyFields(1) })
+	assert.Panics(t, func() { optional.MarkAllClean(got) })
+}
+
+func TestMarshal_Tracked(t *testing.T) {
+	t.Parallel()
+
+	var v struct {
+		Name  optional.Tracked[string] `json:"name"`
+		Email optional.Tracked[string] `json:"email"`
+		Phone optional.Tracked[string] `json:"phone"`
+	}
+
+	v.Name.SetValue("some")
+	v.Email.SetNull()
+
+	got, err := optional.Marshal(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":"some","email":null}`, string(got))
+}
+
+func TestTracked_OnChange(t *testing.T) {
+	t.Parallel()
+
+	type change struct {
+		old, new optional.Type[string]
+	}
+
+	var (
+		got     optional.Tracked[string]
+		changes []change
+	)
+
+	got.OnChange(func(old, new optional.Type[string]) {
+		changes = append(changes, change{old, new})
+	})
+
+	got.SetValue("some")
+	got.SetValue("some")
+	got.SetNull()
+	require.NoError(t, json.Unmarshal([]byte(`"other"`), &got))
+	got.Unset()
+
+	assert.Equal(t, []change{
+		{optional.Type[string]{}, optional.Some("some")},
+		{optional.Some("some"), optional.Null[string]()},
+		{optional.Null[string](), optional.Some("other")},
+		{optional.Some("other"), optional.Type[string]{}},
+	}, changes)
+}
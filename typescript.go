@@ -0,0 +1,196 @@
+package optional
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TypeScript returns the TypeScript interfaces of the named structs of values as encoded by encoding/json,
+// such as "export interface User {...}", so the types of a frontend stay in sync with the presence semantics
+// of the Go types. The nested named structs are declared too and referenced by their names.
+//
+// The [Type] fields may be absent or null, so they are optional properties, "field?: T | null". Other fields
+// are required unless tagged with omitempty, pointers are unions with null. The output is a module of
+// the interfaces only, so it can be written to a .ts file as it is.
+func TypeScript(values ...any) ([]byte, error) {
+	b := &tsBuilder{names: map[reflect.Type]string{}, taken: map[string]bool{}}
+
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() != reflect.Struct || isOptionalType(t) || t.Name() == "" {
+			return nil, fmt.Errorf("optional: TypeScript expects named structs, got %T", v)
+		}
+
+		b.define(t)
+	}
+
+	var buf bytes.Buffer
+
+	for i, d := range b.defs {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		fmt.Fprintf(&buf, "export interface %s %s\n", d.name, d.body)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// tsBuilder builds the TypeScript types of Go types, collecting the named structs into interfaces.
+type tsBuilder struct {
+	names map[reflect.Type]string // names holds the interfaces of the structs being declared or declared.
+	taken map[string]bool
+	defs  []tsDef
+}
+
+type tsDef struct {
+	name string
+	body string
+}
+
+// typ returns the TypeScript type of the values of the type t, indented by depth tabs.
+func (b *tsBuilder) typ(t reflect.Type, depth int) string {
+	switch {
+	case t == timeType:
+		return "string"
+	case t == rawMessageType:
+		return "unknown"
+	case t == numberType:
+		return "number"
+	case isOptionalType(t), isTrackedType(t):
+		return tsNullable(b.typ(optionalElem(t), depth))
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return "unknown"
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Ptr:
+		return tsNullable(b.typ(t.Elem(), depth))
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+
+		e := b.typ(t.Elem(), depth)
+		if strings.Contains(e, " | ") {
+			e = "(" + e + ")"
+		}
+
+		return e + "[]"
+	case reflect.Map:
+		return "Record<string, " + b.typ(t.Elem(), depth) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structType(t, depth)
+		}
+
+		return b.define(t)
+	}
+
+	return "unknown"
+}
+
+// define adds the interface of the named struct type t unless it is declared, and returns its name.
+func (b *tsBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := schemaName(t)
+	for i := 2; b.taken[name]; i++ {
+		name = schemaName(t) + strconv.Itoa(i)
+	}
+
+	b.names[t] = name
+	b.taken[name] = true
+
+	i := len(b.defs)
+	b.defs = append(b.defs, tsDef{name: name}) // reserves the position while the struct is being declared
+
+	body := b.structType(t, 0)
+	b.defs[i].body = body
+
+	return name
+}
+
+// structType returns the TypeScript object type of the fields of the struct type t.
+func (b *tsBuilder) structType(t reflect.Type, depth int) string {
+	fields := jsonFields(t)
+	if len(fields) == 0 {
+		return "{}"
+	}
+
+	indent := strings.Repeat("\t", depth+1)
+
+	var sb strings.Builder
+
+	sb.WriteString("{\n")
+
+	for _, f := range fields {
+		sf := t.FieldByIndex(f.index)
+
+		e := b.typ(sf.Type, depth+1)
+		if f.quoted && isQuotable(indirectType(optionalOrSelf(sf.Type)).Kind()) {
+			e = "string"
+			if isOptionalType(sf.Type) || isTrackedType(sf.Type) || sf.Type.Kind() == reflect.Ptr {
+				e = tsNullable(e)
+			}
+		}
+
+		marker := ""
+		if f.omitEmpty || isOptionalType(sf.Type) || isTrackedType(sf.Type) {
+			marker = "?"
+		}
+
+		sb.WriteString(indent + tsProperty(f.name) + marker + ": " + e + ";\n")
+	}
+
+	sb.WriteString(strings.Repeat("\t", depth) + "}")
+
+	return sb.String()
+}
+
+// tsNullable returns the union of the type e with null.
+func tsNullable(e string) string {
+	if strings.HasSuffix(e, " | null") {
+		return e
+	}
+
+	return e + " | null"
+}
+
+// tsProperty returns the field name as the TypeScript property name, quoting the names that are not identifiers.
+func tsProperty(name string) string {
+	if name == "" {
+		return `""`
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '$', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return strconv.Quote(name)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,79 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type TsAddress struct {
+	City optional.Type[string] `json:"city"`
+}
+
+type TsNode struct {
+	Value    int       `json:"value"`
+	Children []*TsNode `json:"children,omitempty"`
+}
+
+type TsUser struct {
+	ID        uint                      `json:"id"`
+	Name      optional.Type[string]     `json:"name"`
+	Age       optional.Type[int]        `json:"age,string"`
+	Tags      []string                  `json:"tags,omitempty"`
+	Labels    map[string]string         `json:"labels"`
+	Address   optional.Type[TsAddress]  `json:"address"`
+	Billing   *TsAddress                `json:"billing"`
+	Tree      TsNode                    `json:"tree"`
+	Geo       struct{ Lat float64 }     `json:"geo"`
+	CreatedAt time.Time                 `json:"created_at"`
+	Score     optional.Tracked[float64] `json:"score"`
+	Avatar    []byte                    `json:"avatar"`
+	ContentID string                    `json:"content-id"`
+	Ignored   string                    `json:"-"`
+}
+
+func TestTypeScript(t *testing.T) {
+	t.Parallel()
+
+	got, err := optional.TypeScript(&TsUser{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `export interface TsUser {
+	id: number;
+	name?: string | null;
+	age?: string | null;
+	tags?: string[];
+	labels: Record<string, string>;
+	address?: TsAddress | null;
+	billing: TsAddress | null;
+	tree: TsNode;
+	geo: {
+		Lat: number;
+	};
+	created_at: string;
+	score?: number | null;
+	avatar: string;
+	"content-id": string;
+}
+
+export interface TsAddress {
+	city?: string | null;
+}
+
+export interface TsNode {
+	value: number;
+	children?: (TsNode | null)[];
+}
+`, string(got))
+}
+
+func TestTypeScript_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := optional.TypeScript(struct{}{})
+	require.EqualError(t, err, "optional: TypeScript expects named structs, got struct {}")
+}
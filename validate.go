@@ -0,0 +1,294 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError describes the value of a field violating a constraint checked by [Validate].
+type ValidationError struct {
+	Field      string // Field is the dotted path of the JSON names of the field, such as "items.0.price".
+	Constraint string // Constraint is the violated constraint from the tag, such as "min=1".
+	Message    string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("optional: field %q %s", e.Field, e.Message)
+}
+
+// ValidationErrors are the violations of the constraints found by [Validate], in the order of the fields.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the values of the fields of the struct v against the constraints of the `optional` tags,
+// such as `optional:"min=1,max=100"`:
+//
+//   - min= and max= bound numbers, and the lengths of strings, in characters, slices, arrays and maps;
+//   - len= requires the exact length of strings, slices, arrays and maps;
+//   - pattern= requires strings to match the regular expression, it must be the last option of the tag,
+//     so the expression may contain commas.
+//
+// The constraints are checked only when the values are present: the unset and null [Type] fields and nil
+// pointers, slices and maps never fail, so presence and values are validated in one pass. The nested structs,
+// including the values of the set [Type] fields, and the elements of slices and arrays are validated recursively.
+//
+// The violations are returned as [ValidationErrors], an error is returned as is if a constraint is malformed.
+func Validate(v any) error {
+	rv := derefPointer(reflect.ValueOf(v))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return fmt.Errorf("optional: Validate expects a struct, got %T", v)
+	}
+
+	var errs ValidationErrors
+
+	if err := validateValue(rv, "", nil, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// NoFieldSetError is returned by [RequireAnySet] when none of the accepted fields is set.
+type NoFieldSetError struct {
+	Fields []string // Fields are the JSON names of the accepted fields.
+}
+
+func (e *NoFieldSetError) Error() string {
+	return "optional: no updatable field provided, expected at least one of: " + strings.Join(e.Fields, ", ")
+}
+
+// RequireAnySet checks that at least one of the fields of the struct v, given by their JSON names, is sent,
+// so PATCH endpoints reject the empty patches. Without the names, all the fields of v are accepted.
+//
+// The [Type] and [Tracked] fields count as sent when set to a value or null, pointers, slices and maps when
+// not nil and other fields when not zero. *[NoFieldSetError] listing the accepted fields is returned
+// if none is sent, an error is returned as is if a name does not match a field.
+func RequireAnySet(v any, fields ...string) error {
+	rv := derefPointer(reflect.ValueOf(v))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return fmt.Errorf("optional: RequireAnySet expects a struct, got %T", v)
+	}
+
+	all := jsonFields(rv.Type())
+
+	accepted := all
+	if len(fields) != 0 {
+		accepted = make([]jsonField, len(fields))
+
+		for i, name := range fields {
+			f, ok := lookupField(all, name, false)
+			if !ok {
+				return fmt.Errorf("optional: RequireAnySet: %s has no field %q", rv.Type(), name)
+			}
+
+			accepted[i] = f
+		}
+	}
+
+	names := make([]string, len(accepted))
+
+	for i, f := range accepted {
+		if fv, ok := fieldByIndex(rv, f.index, false); ok && isFieldSent(fv) {
+			return nil
+		}
+
+		names[i] = f.name
+	}
+
+	return &NoFieldSetError{Fields: names}
+}
+
+// isFieldSent reports whether the value of the field v is sent: present for [Type] and [Tracked],
+// not nil for pointers, slices, maps and interfaces and not zero otherwise.
+func isFieldSent(v reflect.Value) bool {
+	if isPresenceType(v.Type()) {
+		return fieldState(v) != StateUnset
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return !v.IsNil()
+	}
+
+	return !v.IsZero()
+}
+
+// validateValue checks the value v at the path against the constraints from the tag, if any,
+// and validates the values inside it.
+func validateValue(v reflect.Value, path string, constraints []string, errs *ValidationErrors) error {
+	if isPresenceType(v.Type()) {
+		if fieldState(v) != StateValue {
+			return nil
+		}
+
+		v = presenceValue(v)
+	}
+
+	if v = derefPointer(v); !v.IsValid() || isNilValue(v) {
+		return nil
+	}
+
+	for _, c := range constraints {
+		msg, err := checkConstraint(v, c)
+		if err != nil {
+			return fmt.Errorf("optional: field %q: invalid constraint %q: %w", path, c, err)
+		}
+
+		if msg != "" {
+			*errs = append(*errs, &ValidationError{Field: path, Constraint: c, Message: msg})
+		}
+	}
+
+	switch {
+	case isPlainStruct(v.Type()) && !isTrackedType(v.Type()):
+		for _, f := range jsonFields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok {
+				continue
+			}
+
+			tag := v.Type().FieldByIndex(f.index).Tag.Get("optional")
+
+			if err := validateValue(fv, joinPath(path, f.name), tagConstraints(tag), errs); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), joinPath(path, strconv.Itoa(i)), nil, errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// constraintNames are the options of the `optional` tag checked by [Validate].
+var constraintNames = [...]string{"min", "max", "len"}
+
+// tagConstraints returns the constraints of the `optional` tag, such as "min=1", in the order of the checks.
+func tagConstraints(tag string) []string {
+	var (
+		constraints []string
+		pattern     string
+	)
+
+	if i := strings.Index(","+tag, ",pattern="); i != -1 {
+		tag, pattern = tag[:i], tag[i:]
+	}
+
+	for _, name := range constraintNames {
+		if value := tagValue(tag, name); value != "" {
+			constraints = append(constraints, name+"="+value)
+		}
+	}
+
+	if pattern != "" {
+		constraints = append(constraints, pattern)
+	}
+
+	return constraints
+}
+
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// checkConstraint returns the message describing the violation of the constraint by the value v,
+// empty if the value satisfies it.
+func checkConstraint(v reflect.Value, constraint string) (string, error) {
+	name, arg, _ := strings.Cut(constraint, "=")
+
+	if name == "pattern" {
+		if v.Kind() != reflect.String {
+			return "", fmt.Errorf("not applicable to %s", v.Type())
+		}
+
+		re, err := compilePattern(arg)
+		if err != nil {
+			return "", err
+		}
+
+		if !re.MatchString(v.String()) {
+			return fmt.Sprintf("must match %q", arg), nil
+		}
+
+		return "", nil
+	}
+
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return "", err
+	}
+
+	n, isLength, ok := measure(v)
+	if !ok || name == "len" && !isLength {
+		return "", fmt.Errorf("not applicable to %s", v.Type())
+	}
+
+	subject := "must be"
+	if isLength {
+		subject = "length must be"
+	}
+
+	switch {
+	case name == "min" && n < bound:
+		return fmt.Sprintf("%s at least %s", subject, arg), nil
+	case name == "max" && n > bound:
+		return fmt.Sprintf("%s at most %s", subject, arg), nil
+	case name == "len" && n != bound:
+		return fmt.Sprintf("%s %s", subject, arg), nil
+	}
+
+	return "", nil
+}
+
+// measure returns the number compared with the bounds of the constraints: the value of numbers
+// or the length of strings, slices, arrays and maps.
+func measure(v reflect.Value) (n float64, isLength, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, true
+	}
+
+	return 0, false, false
+}
+
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(expr, re)
+
+	return re, nil
+}
@@ -0,0 +1,195 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type validateItem struct {
+	SKU   string                 `json:"sku" optional:"pattern=^[A-Z]{2,4}-[0-9]+$"`
+	Price optional.Type[float64] `json:"price" optional:"min=0.01"`
+}
+
+type validateOrder struct {
+	Name     optional.Type[string]         `json:"name" optional:"min=2,max=5"`
+	Code     optional.Type[string]         `json:"code" optional:"len=3"`
+	Qty      optional.Type[int]            `json:"qty" optional:"min=1,max=10"`
+	Tags     optional.Type[[]string]       `json:"tags" optional:"max=2"`
+	Discount *int                          `json:"discount" optional:"max=50"`
+	Items    []validateItem                `json:"items" optional:"min=1"`
+	Shipping optional.Type[validateItem]   `json:"shipping"`
+	Labels   optional.Tracked[string]      `json:"labels" optional:"alias=label,max=3"`
+	Meta     optional.Type[map[string]int] `json:"meta" optional:"len=1"`
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	fifty, ninety := 50, 90
+
+	tests := [...]struct {
+		name string
+		v    validateOrder
+		want []string
+	}{
+		{
+			"unset and null never fail",
+			validateOrder{Name: optional.Null[string](), Items: []validateItem{{SKU: "AB-1"}}},
+			nil,
+		},
+		{
+			"valid",
+			validateOrder{
+				Name:     optional.Some("Jöhn"),
+				Code:     optional.Some("abc"),
+				Qty:      optional.Some(10),
+				Tags:     optional.Some([]string{"a", "b"}),
+				Discount: &fifty,
+				Items:    []validateItem{{SKU: "ABC-12", Price: optional.Some(1.0)}},
+				Meta:     optional.Some(map[string]int{"a": 1}),
+			},
+			nil,
+		},
+		{
+			"violations",
+			validateOrder{
+				Name:     optional.Some("J"),
+				Code:     optional.Some("abcd"),
+				Qty:      optional.Some(11),
+				Tags:     optional.Some([]string{"a", "b", "c"}),
+				Discount: &ninety,
+				Items:    []validateItem{{SKU: "abc", Price: optional.Some(0.0)}},
+				Shipping: optional.Some(validateItem{SKU: "X-1"}),
+				Meta:     optional.Some(map[string]int{}),
+			},
+			[]string{
+				"name:min=2:length must be at least 2",
+				"code:len=3:length must be 3",
+				"qty:max=10:must be at most 10",
+				"tags:max=2:length must be at most 2",
+				"discount:max=50:must be at most 50",
+				`items.0.sku:pattern=^[A-Z]{2,4}-[0-9]+$:must match "^[A-Z]{2,4}-[0-9]+$"`,
+				"items.0.price:min=0.01:must be at least 0.01",
+				`shipping.sku:pattern=^[A-Z]{2,4}-[0-9]+$:must match "^[A-Z]{2,4}-[0-9]+$"`,
+				"meta:len=1:length must be 1",
+			},
+		},
+		{
+			"empty slice",
+			validateOrder{Items: []validateItem{}},
+			[]string{"items:min=1:length must be at least 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := optional.Validate(&tt.v)
+			if tt.want == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			var errs optional.ValidationErrors
+			require.ErrorAs(t, err, &errs)
+
+			got := make([]string, len(errs))
+			for i, e := range errs {
+				got[i] = e.Field + ":" + e.Constraint + ":" + e.Message
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate_Error(t *testing.T) {
+	t.Parallel()
+
+	type badBound struct {
+		Age optional.Type[int] `json:"age" optional:"min=ten"`
+	}
+
+	type badKind struct {
+		Active optional.Type[bool] `json:"active" optional:"len=1"`
+	}
+
+	type badPattern struct {
+		Name optional.Type[string] `json:"name" optional:"pattern=[a-"`
+	}
+
+	err := optional.Validate(badBound{Age: optional.Some(1)})
+	require.EqualError(t, err, `optional: field "age": invalid constraint "min=ten": `+
+		`strconv.ParseFloat: parsing "ten": invalid syntax`)
+
+	err = optional.Validate(badKind{Active: optional.Some(true)})
+	require.EqualError(t, err, `optional: field "active": invalid constraint "len=1": not applicable to bool`)
+
+	err = optional.Validate(badPattern{Name: optional.Some("a")})
+	require.ErrorContains(t, err, `optional: field "name": invalid constraint "pattern=[a-": error parsing regexp`)
+
+	err = optional.Validate(validateOrder{Qty: optional.Some(0), Code: optional.Some("ab")})
+	require.EqualError(t, err, `optional: field "code" length must be 3; optional: field "qty" must be at least 1`)
+
+	require.EqualError(t, optional.Validate(1), "optional: Validate expects a struct, got int")
+}
+
+func TestRequireAnySet(t *testing.T) {
+	t.Parallel()
+
+	type userPatch struct {
+		ID    int                   `json:"-"`
+		Name  optional.Type[string] `json:"name"`
+		Email optional.Type[string] `json:"email"`
+		Tags  []string              `json:"tags"`
+		Note  string                `json:"note"`
+	}
+
+	tests := [...]struct {
+		name   string
+		patch  userPatch
+		fields []string
+		want   []string
+	}{
+		{"value", userPatch{Name: optional.Some("John")}, nil, nil},
+		{"null", userPatch{Email: optional.Null[string]()}, []string{"name", "email"}, nil},
+		{"empty slice", userPatch{Tags: []string{}}, nil, nil},
+		{"empty", userPatch{ID: 1}, nil, []string{"name", "email", "tags", "note"}},
+		{"other field", userPatch{Note: "x"}, []string{"name", "email"}, []string{"name", "email"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := optional.RequireAnySet(&tt.patch, tt.fields...)
+			if tt.want == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			var nse *optional.NoFieldSetError
+			require.ErrorAs(t, err, &nse)
+			assert.Equal(t, tt.want, nse.Fields)
+		})
+	}
+
+	err := optional.RequireAnySet(userPatch{}, "name", "email")
+	require.EqualError(t, err, "optional: no updatable field provided, expected at least one of: name, email")
+
+	err = optional.RequireAnySet(userPatch{}, "phone")
+	require.EqualError(t, err, `optional: RequireAnySet: optional_test.userPatch has no field "phone"`)
+
+	require.EqualError(t, optional.RequireAnySet(1), "optional: RequireAnySet expects a struct, got int")
+}
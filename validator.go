@@ -0,0 +1,55 @@
+package optional
+
+import "reflect"
+
+// ValidatorTypeFunc is the CustomTypeFunc of https://github.com/go-playground/validator making the `validate` tags
+// apply to the values of [Type] and [Tracked] fields. Register it for the types of the fields:
+//
+//	validate.RegisterCustomTypeFunc(optional.ValidatorTypeFunc, optional.Type[string]{}, optional.Type[int]{})
+//
+// The value of the set field is validated as is. The unset field is reported as nil and the field set
+// to null as the zero value, so the rules after `omitempty` are skipped for both, `required` fails for both
+// and `required_set` registered with [ValidatorRequiredSet] fails only for the unset field.
+func ValidatorTypeFunc(field reflect.Value) any {
+	if !field.IsValid() || !isPresenceType(field.Type()) {
+		return nil
+	}
+
+	switch fieldState(field) {
+	case StateUnset:
+		return nil
+	case StateNull:
+		return reflect.Zero(presenceValue(field).Type()).Interface()
+	}
+
+	return presenceValue(field).Interface()
+}
+
+// ValidatorField is the part of validator.FieldLevel of https://github.com/go-playground/validator
+// used by [ValidatorRequiredSet].
+type ValidatorField interface {
+	Parent() reflect.Value
+	StructFieldName() string
+}
+
+// ValidatorRequiredSet is the validation function of the `required_set` tag requiring the [Type] field
+// to be set, to a value or to null, for validator of https://github.com/go-playground/validator:
+//
+//	validate.RegisterValidation("required_set", func(fl validator.FieldLevel) bool {
+//		return optional.ValidatorRequiredSet(fl)
+//	})
+//
+// The fields other than [Type] and [Tracked] are always set.
+func ValidatorRequiredSet(fl ValidatorField) bool {
+	parent := derefPointer(fl.Parent())
+	if !parent.IsValid() || parent.Kind() != reflect.Struct {
+		return true
+	}
+
+	f := parent.FieldByName(fl.StructFieldName())
+	if !f.IsValid() || !isPresenceType(f.Type()) {
+		return true
+	}
+
+	return fieldState(f) != StateUnset
+}
@@ -0,0 +1,64 @@
+package optional_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/micronull/optional"
+)
+
+func TestValidatorTypeFunc(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name  string
+		field any
+		want  any
+	}{
+		{"value", optional.Some("John"), "John"},
+		{"unset", optional.Type[string]{}, nil},
+		{"null", optional.Null[int](), 0},
+		{"tracked", optional.Tracked[float64]{Type: optional.Some(1.5)}, 1.5},
+		{"other", "plain", nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, optional.ValidatorTypeFunc(reflect.ValueOf(tt.field)))
+		})
+	}
+}
+
+// fakeFieldLevel mimics validator.FieldLevel.
+type fakeFieldLevel struct {
+	parent any
+	name   string
+}
+
+func (f fakeFieldLevel) Parent() reflect.Value { return reflect.ValueOf(f.parent) }
+
+func (f fakeFieldLevel) StructFieldName() string { return f.name }
+
+func TestValidatorRequiredSet(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Name  optional.Type[string]
+		Email optional.Type[string]
+		Phone optional.Type[string]
+		ID    int
+	}
+
+	req := &request{Name: optional.Some("John"), Email: optional.Null[string]()}
+
+	assert.True(t, optional.ValidatorRequiredSet(fakeFieldLevel{req, "Name"}))
+	assert.True(t, optional.ValidatorRequiredSet(fakeFieldLevel{req, "Email"}))
+	assert.False(t, optional.ValidatorRequiredSet(fakeFieldLevel{req, "Phone"}))
+	assert.True(t, optional.ValidatorRequiredSet(fakeFieldLevel{*req, "ID"}))
+}
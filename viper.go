@@ -0,0 +1,48 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ViperSource is the part of *viper.Viper of https://github.com/spf13/viper used by [ViperUnmarshal].
+type ViperSource interface {
+	AllKeys() []string
+	Get(key string) any
+}
+
+// ViperUnmarshal decodes the configuration of viper into the struct pointed to by target,
+// using the `mapstructure` tags like viper does.
+//
+// Keys absent from the configuration leave [Type] fields unset, keys explicitly set to null
+// in YAML or JSON set them to null. Values of environment variables bound with BindEnv are parsed
+// from strings, the sentinel set by [WithNullValue] sets the fields to null.
+func ViperUnmarshal(v ViperSource, target any, opts ...Option) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("optional: ViperUnmarshal expects a non-nil pointer to a struct, got %T", target)
+	}
+
+	// Unlike AllSettings, AllKeys keeps the keys of null values.
+	settings := map[string]any{}
+
+	for _, k := range v.AllKeys() {
+		path := strings.Split(k, ".")
+		m := settings
+
+		for _, p := range path[:len(path)-1] {
+			sub, ok := m[p].(map[string]any)
+			if !ok {
+				sub = map[string]any{}
+				m[p] = sub
+			}
+
+			m = sub
+		}
+
+		m[path[len(path)-1]] = v.Get(k)
+	}
+
+	return decodeMap(settings, rv.Elem(), "mapstructure", newOptions(append([]Option{WithSeparator(",")}, opts...)))
+}
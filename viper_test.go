@@ -0,0 +1,88 @@
+package optional_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+// fakeViper mimics *viper.Viper with flattened lowercase keys.
+type fakeViper map[string]any
+
+func (v fakeViper) AllKeys() []string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (v fakeViper) Get(key string) any {
+	return v[key]
+}
+
+func TestViperUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	type database struct {
+		Host optional.Type[string] `mapstructure:"host"`
+		Port optional.Type[int]    `mapstructure:"port"`
+	}
+
+	type config struct {
+		Name     optional.Type[string]   `mapstructure:"name"`
+		Proxy    optional.Type[string]   `mapstructure:"proxy"`
+		Timeout  optional.Type[string]   `mapstructure:"timeout"`
+		Tags     optional.Type[[]string] `mapstructure:"tags"`
+		Debug    optional.Type[bool]     `mapstructure:"debug"`
+		Database database                `mapstructure:"database"`
+		LogLevel string                  `mapstructure:"logLevel"`
+	}
+
+	v := fakeViper{
+		"name":          "some",
+		"proxy":         nil,
+		"tags":          []any{"a", "b"},
+		"debug":         "true", // from environment
+		"database.host": "localhost",
+		"database.port": 5432,
+		"loglevel":      "info",
+	}
+
+	var got config
+
+	require.NoError(t, optional.ViperUnmarshal(v, &got))
+
+	assert.Equal(t, optional.Some("some"), got.Name)
+	assert.Equal(t, optional.Null[string](), got.Proxy)
+	assert.False(t, got.Timeout.IsSet())
+	assert.Equal(t, optional.Some([]string{"a", "b"}), got.Tags)
+	assert.Equal(t, optional.Some(true), got.Debug)
+	assert.Equal(t, optional.Some("localhost"), got.Database.Host)
+	assert.Equal(t, optional.Some(5432), got.Database.Port)
+	assert.Equal(t, "info", got.LogLevel)
+}
+
+func TestViperUnmarshal_Error(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Port optional.Type[int] `mapstructure:"port"`
+	}
+
+	var got config
+
+	require.Error(t, optional.ViperUnmarshal(fakeViper{"port": "port"}, &got))
+	require.ErrorContains(t, optional.ViperUnmarshal(fakeViper{"port": 80.5}, &got), "value 80.5 is not an integer")
+
+	type small struct {
+		Level optional.Type[uint8] `mapstructure:"level"`
+	}
+
+	require.ErrorContains(t, optional.ViperUnmarshal(fakeViper{"level": 256}, &small{}), "value 256 overflows uint8")
+	require.Error(t, optional.ViperUnmarshal(fakeViper{}, got))
+}
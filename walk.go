@@ -0,0 +1,137 @@
+package optional
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SkipValue is returned by the function passed to [Walk] to skip the value of the visited [Type].
+var SkipValue = errors.New("optional: skip value")
+
+// WalkFunc is the function called by [Walk] for each visited [Type] or [Tracked] value.
+// The value is the value held by the set [Type], nil for the unset and null ones.
+type WalkFunc func(path string, state State, value any) error
+
+// Walk traverses v, visiting every [Type] and [Tracked] value inside, such as to build custom exporters,
+// sanitizers and validators. The paths are the dotted JSON names of the fields, the indexes of the elements
+// of slices and arrays and the keys of maps, such as "items.0.price", the path of v itself is empty.
+//
+// The nested structs, pointers, interfaces, slices, arrays and maps are traversed, including the values
+// of the set [Type] after they are visited, unless fn returns [SkipValue]. The keys of maps are visited
+// in the sorted order and the pointers are not followed in cycles. The traversal stops at the first other
+// error returned by fn, which Walk returns.
+func Walk(v any, fn WalkFunc) error {
+	w := walker{fn: fn, seen: map[visit]bool{}}
+
+	return w.walk(reflect.ValueOf(v), "")
+}
+
+type walker struct {
+	fn   WalkFunc
+	seen map[visit]bool // seen holds the pointers on the current path.
+}
+
+func (w *walker) walk(v reflect.Value, path string) error {
+	if !v.IsValid() || !hasPresence(v.Type()) {
+		return nil
+	}
+
+	if isPresenceType(v.Type()) {
+		return w.walkPresence(v, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return w.walk(v.Elem(), path)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		key := visit{ptr: v.Pointer(), typ: v.Type()}
+		if w.seen[key] {
+			return nil
+		}
+
+		w.seen[key] = true
+		defer delete(w.seen, key)
+
+		return w.walk(v.Elem(), path)
+	case reflect.Struct:
+		for _, f := range jsonFields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok {
+				continue
+			}
+
+			if err := w.walk(fv, joinPath(path, f.name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return w.walkMap(v, path)
+	}
+
+	return nil
+}
+
+func (w *walker) walkPresence(v reflect.Value, path string) error {
+	state := fieldState(v)
+
+	var value any
+	if state == StateValue {
+		value = presenceValue(v).Interface()
+	}
+
+	if err := w.fn(path, state, value); err != nil {
+		if errors.Is(err, SkipValue) {
+			return nil
+		}
+
+		return err
+	}
+
+	if state != StateValue {
+		return nil
+	}
+
+	return w.walk(presenceValue(v), path)
+}
+
+func (w *walker) walkMap(v reflect.Value, path string) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := w.walk(values[key], joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package optional_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+type walkItem struct {
+	SKU   string                 `json:"sku"`
+	Price optional.Type[float64] `json:"price"`
+}
+
+type walkNode struct {
+	Name optional.Type[string] `json:"name"`
+	Next *walkNode             `json:"next"`
+}
+
+type walkOrder struct {
+	ID       int                                `json:"id"`
+	Note     optional.Type[string]              `json:"note"`
+	Coupon   optional.Type[string]              `json:"coupon"`
+	Items    []walkItem                         `json:"items"`
+	Shipping optional.Type[walkItem]            `json:"shipping"`
+	Meta     map[string]optional.Type[int]      `json:"meta"`
+	Extra    any                                `json:"extra"`
+	Tracked  optional.Tracked[string]           `json:"tracked"`
+	Ignored  map[string]string                  `json:"ignored"`
+	Nested   optional.Type[map[string]walkItem] `json:"nested"`
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	v := walkOrder{
+		ID:       1,
+		Note:     optional.Some("fragile"),
+		Coupon:   optional.Null[string](),
+		Items:    []walkItem{{SKU: "a", Price: optional.Some(1.5)}, {SKU: "b"}},
+		Shipping: optional.Some(walkItem{Price: optional.Some(5.0)}),
+		Meta:     map[string]optional.Type[int]{"b": optional.Some(2), "a": optional.Null[int]()},
+		Extra:    &walkItem{Price: optional.Null[float64]()},
+		Ignored:  map[string]string{"x": "y"},
+	}
+
+	var got []string
+
+	err := optional.Walk(&v, func(path string, state optional.State, value any) error {
+		if item, ok := value.(walkItem); ok {
+			value = item.Price.V
+		}
+
+		got = append(got, fmt.Sprintf("%s %s %v", path, state, value))
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"note value fragile",
+		"coupon null <nil>",
+		"items.0.price value 1.5",
+		"items.1.price unset <nil>",
+		"shipping value 5",
+		"shipping.price value 5",
+		"meta.a null <nil>",
+		"meta.b value 2",
+		"extra.price null <nil>",
+		"tracked unset <nil>",
+		"nested unset <nil>",
+	}, got)
+}
+
+func TestWalk_SkipValue(t *testing.T) {
+	t.Parallel()
+
+	v := walkOrder{
+		Shipping: optional.Some(walkItem{Price: optional.Some(5.0)}),
+		Nested:   optional.Some(map[string]walkItem{"k": {Price: optional.Some(1.0)}}),
+	}
+
+	var got []string
+
+	err := optional.Walk(v, func(path string, _ optional.State, _ any) error {
+		got = append(got, path)
+
+		if path == "shipping" {
+			return optional.SkipValue
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"note", "coupon", "shipping", "tracked", "nested", "nested.k.price"}, got)
+}
+
+func TestWalk_Error(t *testing.T) {
+	t.Parallel()
+
+	errStop := errors.New("stop")
+
+	calls := 0
+
+	err := optional.Walk(walkOrder{}, func(string, optional.State, any) error {
+		calls++
+
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWalk_Cycle(t *testing.T) {
+	t.Parallel()
+
+	n := &walkNode{Name: optional.Some("a")}
+	n.Next = n
+
+	var got []string
+
+	require.NoError(t, optional.Walk(n, func(path string, _ optional.State, _ any) error {
+		got = append(got, path)
+
+		return nil
+	}))
+
+	assert.Equal(t, []string{"name"}, got)
+}
@@ -0,0 +1,147 @@
+package optional
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// excelEpoch is the day zero of the serial dates of spreadsheets, accounting for the leap year bug of 1900.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// sheetDateLayouts are the layouts of the dates accepted in the cells besides the serial dates.
+var sheetDateLayouts = [...]string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// DecodeSheetRows decodes the rows of a worksheet, such as returned by GetRows of *excelize.File
+// of https://github.com/qax-os/excelize, into the slice of structs pointed to by v, one element per row.
+// The first row is the header naming the columns, matched case-insensitively with the fields by the name
+// from the `xlsx` tag, falling back to the Go field name. The columns without a matching field are ignored
+// and the empty rows are skipped.
+//
+// The empty cells leave the fields unset, so the uploaded spreadsheets can drive partial updates where
+// a blank cell and zero differ, unless changed by [WithEmpty]. The sentinel set by [WithNullValue],
+// such as "NULL", sets the field to null. The numbers may have thousands separators, such as "1,234.50",
+// and the [time.Time] fields accept the serial dates of spreadsheets, such as "45292.5", besides
+// the RFC 3339 and "2006-01-02" formats.
+func DecodeSheetRows(rows [][]string, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice ||
+		indirectType(rv.Elem().Type().Elem()).Kind() != reflect.Struct {
+		return fmt.Errorf("optional: DecodeSheetRows expects a non-nil pointer to a slice of structs, got %T", v)
+	}
+
+	o := newOptions(append([]Option{WithEmpty(EmptyUnset)}, opts...))
+
+	sv := rv.Elem()
+	sv.Set(sv.Slice(0, 0))
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	et := sv.Type().Elem()
+	fields := taggedFields(indirectType(et), "xlsx")
+
+	columns := make([]*jsonField, len(rows[0]))
+
+	for i, name := range rows[0] {
+		if f, ok := lookupField(fields, strings.TrimSpace(name), true); ok {
+			f := f
+			columns[i] = &f
+		}
+	}
+
+	for r, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+
+		sv.Set(reflect.Append(sv, reflect.Zero(et)))
+
+		dst := sv.Index(sv.Len() - 1)
+		if dst.Kind() == reflect.Ptr {
+			dst.Set(reflect.New(et.Elem()))
+			dst = dst.Elem()
+		}
+
+		for c, cell := range row {
+			if c >= len(columns) || columns[c] == nil {
+				continue
+			}
+
+			fv, _ := fieldByIndex(dst, columns[c].index, true)
+
+			cell = strings.TrimSpace(cell)
+			if cell != o.null {
+				cell = normalizeCell(sheetValueType(fv.Type()), cell)
+			}
+
+			if err := decodeValue([]string{cell}, fv, o); err != nil {
+				return fmt.Errorf("optional: row %d column %q: %w", r+2, rows[0][c], err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sheetValueType returns the type of the values of the field of the type t.
+func sheetValueType(t reflect.Type) reflect.Type {
+	if isOptionalType(t) || isTrackedType(t) {
+		t = optionalElem(t)
+	}
+
+	return indirectType(t)
+}
+
+// normalizeCell converts the text of the cell into the text parsed by the values of the type t:
+// strips the thousands separators from the numbers and converts the dates into RFC 3339.
+func normalizeCell(t reflect.Type, s string) string {
+	if s == "" {
+		return s
+	}
+
+	if t == timeType {
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			d := time.Duration(math.Round(n * float64(24*time.Hour) / float64(time.Millisecond)))
+
+			return excelEpoch.Add(d * time.Millisecond).Format(time.RFC3339Nano)
+		}
+
+		for _, layout := range sheetDateLayouts {
+			if tm, err := time.Parse(layout, s); err == nil {
+				return tm.Format(time.RFC3339Nano)
+			}
+		}
+
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strings.ReplaceAll(s, ",", "")
+
+		// The spreadsheets store the numbers as floats, so the integers may come with zero fractions.
+		if i := strings.IndexByte(s, '.'); i != -1 && strings.Trim(s[i+1:], "0") == "" {
+			s = s[:i]
+		}
+	case reflect.Float32, reflect.Float64:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	return s
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/micronull/optional"
+)
+
+func TestDecodeSheetRows(t *testing.T) {
+	t.Parallel()
+
+	type entry struct {
+		Account optional.Type[string]    `xlsx:"Account"`
+		Amount  optional.Type[float64]   `xlsx:"Amount"`
+		Units   optional.Type[int]       `xlsx:"Units"`
+		Booked  optional.Type[time.Time] `xlsx:"Booked"`
+		Paid    optional.Type[bool]      `xlsx:"Paid"`
+		Note    string                   `xlsx:"-"`
+	}
+
+	rows := [][]string{
+		{"account", " Amount ", "Units", "Booked", "Paid", "Note"},
+		{"ACC-1", "1,234.50", "3.0", "45292.5", "TRUE", "ignored"},
+		{},
+		{"ACC-2", "0", "", "2024-01-02", "NULL"},
+		{"ACC-3", "", "1,000", "2024-01-02T10:00:00Z"},
+	}
+
+	var got []entry
+
+	require.NoError(t, optional.DecodeSheetRows(rows, &got, optional.WithNullValue("NULL")))
+
+	want := []entry{
+		{
+			Account: optional.Some("ACC-1"),
+			Amount:  optional.Some(1234.5),
+			Units:   optional.Some(3),
+			Booked:  optional.Some(time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			Paid:    optional.Some(true),
+		},
+		{
+			Account: optional.Some("ACC-2"),
+			Amount:  optional.Some(0.0),
+			Booked:  optional.Some(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)),
+			Paid:    optional.Null[bool](),
+		},
+		{
+			Account: optional.Some("ACC-3"),
+			Units:   optional.Some(1000),
+			Booked:  optional.Some(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	require.Len(t, got, len(want))
+
+	for i := range want {
+		assert.Equal(t, want[i].Account, got[i].Account)
+		assert.Equal(t, want[i].Amount, got[i].Amount)
+		assert.Equal(t, want[i].Units, got[i].Units)
+		assert.Equal(t, want[i].Paid, got[i].Paid)
+		assert.Equal(t, want[i].Booked.State(), got[i].Booked.State())
+		assert.True(t, want[i].Booked.V.Equal(got[i].Booked.V), "row %d: %s", i, got[i].Booked.V)
+	}
+}
+
+func TestDecodeSheetRows_Pointers(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name  string
+		Price optional.Type[float64]
+	}
+
+	var got []*item
+
+	require.NoError(t, optional.DecodeSheetRows([][]string{{"Name", "Price"}, {"pen"}}, &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, &item{Name: "pen"}, got[0])
+}
+
+func TestDecodeSheetRows_Error(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Price optional.Type[float64] `xlsx:"Price"`
+	}
+
+	var got []item
+
+	err := optional.DecodeSheetRows([][]string{{"Price"}, {"1"}, {"free"}}, &got)
+	require.EqualError(t, err, `optional: row 3 column "Price": optional: cannot parse "free" as float64: `+
+		`strconv.ParseFloat: parsing "free": invalid syntax`)
+
+	require.EqualError(t, optional.DecodeSheetRows(nil, &item{}),
+		"optional: DecodeSheetRows expects a non-nil pointer to a slice of structs, got *optional_test.item")
+}